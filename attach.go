@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type (
+	// The ProcessInfo type describes a running plugin process. Host applications can record it so that, once
+	// restarted, they can attach to processes started by their previous instance using Connect and WithPID, rather
+	// than leaving them running.
+	ProcessInfo struct {
+		// The process identifier of the plugin process.
+		PID int
+		// The path to the UNIX domain socket the plugin is listening on.
+		Socket string
+		// The token the plugin uses to authenticate the host application, to be provided using WithToken.
+		Token string
+	}
+)
+
+// exitPollInterval is how often a process attached to using WithPID is checked for having exited, on platforms where
+// it cannot be waited on by a host application that did not start it.
+const exitPollInterval = 100 * time.Millisecond
+
+// Processes returns a description of each running plugin process. Plugins served in-process have no process to
+// describe, and plugins connected to using Connect are only described if their process was given using WithPID.
+// Returns nil if the plugin is not running.
+func (p *Plugin) Processes() []ProcessInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.pool == nil {
+		return nil
+	}
+
+	var infos []ProcessInfo
+	for _, proc := range p.pool.processes {
+		if proc.command.Process == nil {
+			continue
+		}
+
+		infos = append(infos, ProcessInfo{
+			PID:    proc.command.Process.Pid,
+			Socket: proc.socket,
+			Token:  proc.token,
+		})
+	}
+
+	return infos
+}
+
+// attach associates the connection with the process given using WithPID, so that closing the connection stops it.
+// The process is only adopted if the plugin reports the same process identifier, so that a process that has since
+// been reused is never signalled.
+func (p *Plugin) attach(ctx context.Context, proc *process) error {
+	process, err := findProcess(p.options.pid)
+	if err != nil {
+		return fmt.Errorf("failed to find process %d: %w", p.options.pid, err)
+	}
+
+	details, err := proc.client.Process(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to describe process %d: %w", p.options.pid, err)
+	}
+
+	if details.PID != p.options.pid {
+		return fmt.Errorf("%w: expected process %d, plugin reports process %d", ErrUnexpectedPID, p.options.pid, details.PID)
+	}
+
+	proc.command.Process = process
+	proc.stop = nil
+
+	go func() {
+		proc.status = waitAttached(process, p.options.clock)
+		p.recordExit(proc.status)
+		close(proc.exited)
+	}()
+
+	return nil
+}
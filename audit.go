@@ -0,0 +1,187 @@
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The Audit type describes how calls to Plugin.Exec are audited, as given to WithAudit.
+	Audit struct {
+		// The Sink each AuditRecord is written to.
+		Sink AuditSink
+		// HashPayloads, if true, includes the SHA-256 hash of the input and output of each call within its AuditRecord,
+		// so that the data given to and returned by a plugin can later be verified without being stored alongside the
+		// audit log.
+		HashPayloads bool
+	}
+
+	// The AuditSink interface describes types that store the records of calls made to plugins, such as the
+	// JSONAuditSink type. A single AuditSink can be shared across many plugins, so implementations must be safe for
+	// concurrent use.
+	AuditSink interface {
+		// WriteAudit is called once a call to Plugin.Exec has completed, before Plugin.Exec returns. Errors returned
+		// do not affect the call, but are logged using the logger given to WithDiagnosticLogger.
+		WriteAudit(ctx context.Context, record AuditRecord) error
+	}
+
+	// The AuditSinkFunc type is an adapter that allows ordinary functions to be used as an AuditSink.
+	AuditSinkFunc func(ctx context.Context, record AuditRecord) error
+
+	// The AuditRecord type describes a single completed call to Plugin.Exec.
+	AuditRecord struct {
+		// The name of the Plugin.
+		Plugin string
+		// The name of the Command executed.
+		Command string
+		// The RequestID of the call, as given using ContextWithRequestID or generated otherwise.
+		RequestID string
+		// The Metadata sent to the plugin with the call, given using ContextWithMetadata and WithMetadata, which
+		// typically identifies who the call was made on behalf of.
+		Metadata map[string]string
+		// The time the call Started.
+		Started time.Time
+		// The Duration of the call, including any retries.
+		Duration time.Duration
+		// The error returned by the call, if any.
+		Err error
+		// The SHA-256 hash of the input given to the command, encoded using the deterministic protobuf binary
+		// format. Only set when Audit.HashPayloads is true.
+		InputHash []byte
+		// The SHA-256 hash of the output returned by the command, encoded in the same way as the input. Only set when
+		// Audit.HashPayloads is true and the call succeeded.
+		OutputHash []byte
+	}
+
+	// The JSONAuditSink type is an AuditSink that writes each AuditRecord to an io.Writer as a single line of JSON,
+	// such as to an append-only file.
+	JSONAuditSink struct {
+		mu sync.Mutex
+		w  io.Writer
+	}
+
+	jsonAuditRecord struct {
+		Time       time.Time         `json:"time"`
+		Plugin     string            `json:"plugin"`
+		Command    string            `json:"command"`
+		RequestID  string            `json:"request_id"`
+		Metadata   map[string]string `json:"metadata,omitempty"`
+		Duration   string            `json:"duration"`
+		Error      string            `json:"error,omitempty"`
+		InputHash  string            `json:"input_sha256,omitempty"`
+		OutputHash string            `json:"output_sha256,omitempty"`
+	}
+)
+
+// WithAudit is a UseOption that writes a record of every call to Plugin.Exec to the Sink of the given Audit, describing
+// which command was executed, with what metadata, how long it took and whether it succeeded. This allows host
+// applications to reconstruct the commands executed by their plugins and on whose behalf.
+func WithAudit(audit Audit) UseOption {
+	return func(o *useOptions) {
+		o.audit = &audit
+	}
+}
+
+// WriteAudit calls f(ctx, record).
+func (f AuditSinkFunc) WriteAudit(ctx context.Context, record AuditRecord) error {
+	return f(ctx, record)
+}
+
+// NewJSONAuditSink returns a JSONAuditSink that writes records to w.
+func NewJSONAuditSink(w io.Writer) *JSONAuditSink {
+	return &JSONAuditSink{w: w}
+}
+
+// WriteAudit writes the record as a single line of JSON.
+func (s *JSONAuditSink) WriteAudit(_ context.Context, record AuditRecord) error {
+	encoded := jsonAuditRecord{
+		Time:       record.Started,
+		Plugin:     record.Plugin,
+		Command:    record.Command,
+		RequestID:  record.RequestID,
+		Metadata:   record.Metadata,
+		Duration:   record.Duration.String(),
+		InputHash:  hex.EncodeToString(record.InputHash),
+		OutputHash: hex.EncodeToString(record.OutputHash),
+	}
+
+	if record.Err != nil {
+		encoded.Error = record.Err.Error()
+	}
+
+	data, err := json.Marshal(encoded)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Records are written with a single call so that they are not interleaved with those of other writers.
+	_, err = s.w.Write(append(data, '\n'))
+	return err
+}
+
+// audited executes the command using fn, writing its AuditRecord to the Sink given via WithAudit.
+func (p *Plugin) audited(ctx context.Context, name string, input, output proto.Message, options execOptions, fn func(ctx context.Context) error) error {
+	audit := p.options.audit
+
+	// The request identifier is chosen here so that the record matches the request sent to the plugin.
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	if !ok || id == "" {
+		id = xid.New().String()
+		ctx = ContextWithRequestID(ctx, id)
+	}
+
+	metadata := plugin.OutgoingMetadata(ctx)
+	for key, value := range options.metadata {
+		metadata[strings.ToLower(key)] = value
+	}
+
+	record := AuditRecord{
+		Plugin:    p.name,
+		Command:   name,
+		RequestID: id,
+		Metadata:  metadata,
+		Started:   p.options.clock.Now(),
+	}
+
+	if audit.HashPayloads {
+		record.InputHash = hashMessage(input)
+	}
+
+	err := fn(ctx)
+	record.Duration = p.options.clock.Now().Sub(record.Started)
+	record.Err = err
+	if audit.HashPayloads && err == nil {
+		record.OutputHash = hashMessage(output)
+	}
+
+	// Records are written even if the call was cancelled.
+	if writeErr := audit.Sink.WriteAudit(context.WithoutCancel(ctx), record); writeErr != nil {
+		p.diagnostics().Warn("failed to write audit record", "command", name, "request_id", id, "error", writeErr)
+	}
+
+	return err
+}
+
+func hashMessage(message proto.Message) []byte {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+	if err != nil {
+		return nil
+	}
+
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
@@ -0,0 +1,150 @@
+package plugin_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestWithAudit(t *testing.T) {
+	t.Parallel()
+
+	config := plugin.Config{
+		Name: "audited",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "echo",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					if input.GetValue() == "fail" {
+						return nil, errors.New("failed")
+					}
+
+					return input, nil
+				},
+			},
+		},
+	}
+
+	hash := func(t *testing.T, message proto.Message) []byte {
+		data, err := proto.MarshalOptions{Deterministic: true}.Marshal(message)
+		require.NoError(t, err)
+
+		sum := sha256.Sum256(data)
+		return sum[:]
+	}
+
+	tt := []struct {
+		Name         string
+		Input        string
+		HashPayloads bool
+		ExpectError  bool
+	}{
+		{
+			Name:  "records successful calls",
+			Input: "hello",
+		},
+		{
+			Name:         "records payload hashes",
+			Input:        "hello",
+			HashPayloads: true,
+		},
+		{
+			Name:         "records failed calls",
+			Input:        "fail",
+			HashPayloads: true,
+			ExpectError:  true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var (
+				mu      sync.Mutex
+				records []plugin.AuditRecord
+			)
+
+			p := plugintest.New(t, config, plugin.WithAudit(plugin.Audit{
+				HashPayloads: tc.HashPayloads,
+				Sink: plugin.AuditSinkFunc(func(ctx context.Context, record plugin.AuditRecord) error {
+					mu.Lock()
+					defer mu.Unlock()
+
+					records = append(records, record)
+					return nil
+				}),
+			}))
+
+			ctx := plugin.ContextWithMetadata(t.Context(), map[string]string{"User": "alice", "tenant": "a"})
+			ctx = plugin.ContextWithRequestID(ctx, "request-1")
+
+			input := wrapperspb.String(tc.Input)
+			output := &wrapperspb.StringValue{}
+			err := p.Exec(ctx, "echo", input, output, plugin.WithMetadata(map[string]string{"tenant": "b"}))
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			require.Len(t, records, 1)
+			record := records[0]
+			assert.EqualValues(t, "audited", record.Plugin)
+			assert.EqualValues(t, "echo", record.Command)
+			assert.EqualValues(t, "request-1", record.RequestID)
+			assert.EqualValues(t, map[string]string{"user": "alice", "tenant": "b"}, record.Metadata)
+			assert.False(t, record.Started.IsZero())
+			assert.Positive(t, record.Duration)
+
+			if tc.ExpectError {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, record.Err)
+				assert.Empty(t, record.OutputHash)
+			} else {
+				require.NoError(t, err)
+				assert.NoError(t, record.Err)
+			}
+
+			switch {
+			case !tc.HashPayloads:
+				assert.Empty(t, record.InputHash)
+				assert.Empty(t, record.OutputHash)
+			case !tc.ExpectError:
+				assert.EqualValues(t, hash(t, input), record.InputHash)
+				assert.EqualValues(t, hash(t, output), record.OutputHash)
+			default:
+				assert.EqualValues(t, hash(t, input), record.InputHash)
+			}
+		})
+	}
+
+	t.Run("writes records as JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		p := plugintest.New(t, config, plugin.WithAudit(plugin.Audit{Sink: plugin.NewJSONAuditSink(&buf)}))
+
+		require.NoError(t, p.Exec(t.Context(), "echo", wrapperspb.String("hello"), &wrapperspb.StringValue{}))
+		require.Error(t, p.Exec(t.Context(), "echo", wrapperspb.String("fail"), &wrapperspb.StringValue{}))
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+		require.Len(t, lines, 2)
+
+		var record map[string]any
+		require.NoError(t, json.Unmarshal(lines[0], &record))
+		assert.EqualValues(t, "audited", record["plugin"])
+		assert.EqualValues(t, "echo", record["command"])
+		assert.NotEmpty(t, record["request_id"])
+		assert.NotContains(t, record, "error")
+
+		require.NoError(t, json.Unmarshal(lines[1], &record))
+		assert.Contains(t, record["error"], "failed")
+	})
+}
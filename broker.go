@@ -0,0 +1,46 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+// hostHandlers returns the handlers for the services the host application provides to the plugin, keyed by the full
+// names of their methods.
+func (p *Plugin) hostHandlers() plugin.HostHandlers {
+	handlers := make(plugin.HostHandlers)
+	if p.options.logger != nil {
+		handlers[pb.HostService_Log_FullMethodName] = p.logHandler()
+	}
+
+	if p.options.egress != nil {
+		handlers[pb.HostService_RoundTrip_FullMethodName] = p.egressHandler()
+	}
+
+	if p.options.sharedDirectory != nil {
+		maps.Copy(handlers, p.sharedDirectoryHandlers())
+	}
+
+	return handlers
+}
+
+// openBroker opens the stream through which a newly started plugin process calls services provided by the host
+// application, if it provides any. Plugins that predate the broker cannot call host services, but can still be used.
+func (p *Plugin) openBroker(ctx context.Context, proc *process) error {
+	handlers := p.hostHandlers()
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	err := proc.client.OpenBroker(ctx, handlers)
+	if err != nil && !errors.Is(err, plugin.ErrBrokerUnsupported) {
+		return fmt.Errorf("failed to open broker for plugin %q: %w", p.name, err)
+	}
+
+	return nil
+}
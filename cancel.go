@@ -0,0 +1,61 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/xid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	requestIDKey struct{}
+)
+
+// ErrUnknownRequest is the error returned by Plugin.Cancel when no in-flight call to Plugin.Exec has the given request
+// identifier.
+var ErrUnknownRequest = errors.New("unknown request")
+
+// ContextWithRequestID returns a copy of the provided context that causes Plugin.Exec to use the given identifier for
+// the request it sends to the plugin. The identifier can then be passed to Plugin.Cancel to cancel the command. It must
+// be unique among the in-flight calls to the plugin. When not set, a unique identifier is generated for each call.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+func requestID(ctx context.Context) string {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok && id != "" {
+		return id
+	}
+
+	return xid.New().String()
+}
+
+// Cancel an in-flight call to Plugin.Exec by the request identifier provided using ContextWithRequestID. The context
+// given to the command within the plugin is cancelled, and the call to Plugin.Exec returns context.Canceled. This is
+// useful when the call cannot be cancelled via its own context, such as when it was made by another part of the host
+// application. Returns ErrUnknownRequest if no call with the identifier is in-flight.
+func (p *Plugin) Cancel(ctx context.Context, id string) error {
+	p.mu.RLock()
+	pl := p.pool
+	p.mu.RUnlock()
+
+	if pl == nil {
+		return fmt.Errorf("%w: %q", ErrUnknownRequest, id)
+	}
+
+	// The request may have been sent to any instance of the plugin.
+	for _, proc := range pl.processes {
+		err := proc.client.Cancel(ctx, id)
+		switch {
+		case err == nil:
+			return nil
+		case status.Code(err) != codes.NotFound:
+			return convertError(ctx, "", err)
+		}
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnknownRequest, id)
+}
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type (
+	// The ManagedCommand type describes a command provided by one of the plugins within a Manager, as returned by
+	// Manager.Commands.
+	ManagedCommand struct {
+		CommandInfo
+
+		// The name of the Plugin that provides the command.
+		Plugin string
+		// The Input message of the command. This is nil if the plugin does not describe the command's input.
+		Input protoreflect.MessageDescriptor
+		// The Output message of the command. This is nil if the plugin does not describe the command's output.
+		Output protoreflect.MessageDescriptor
+	}
+
+	// The CommandFilter type is a function that selects the commands returned by Manager.Commands.
+	CommandFilter func(command ManagedCommand) bool
+
+	// The messageDescriber interface describes a Client that can describe the messages used by its commands, such as a
+	// *Plugin.
+	messageDescriber interface {
+		Describe(ctx context.Context) ([]CommandDescriptor, error)
+	}
+)
+
+// QualifiedName returns the name of the command qualified with the name of its plugin, such as "backup/snapshot", as
+// accepted by Manager.Exec.
+func (mc ManagedCommand) QualifiedName() string {
+	return mc.Plugin + CommandSeparator + mc.Name
+}
+
+// CommandsMatching is a CommandFilter that selects commands whose qualified name or description contains the query,
+// ignoring case.
+func CommandsMatching(query string) CommandFilter {
+	query = strings.ToLower(query)
+	return func(command ManagedCommand) bool {
+		return strings.Contains(strings.ToLower(command.QualifiedName()), query) ||
+			strings.Contains(strings.ToLower(command.Description), query)
+	}
+}
+
+// CommandsFrom is a CommandFilter that selects commands provided by the named plugins.
+func CommandsFrom(plugins ...string) CommandFilter {
+	return func(command ManagedCommand) bool {
+		for _, name := range plugins {
+			if command.Plugin == name {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// NonDeprecatedCommands is a CommandFilter that selects commands that have not been deprecated.
+func NonDeprecatedCommands() CommandFilter {
+	return func(command ManagedCommand) bool {
+		return !command.Deprecated
+	}
+}
+
+// Commands returns the commands provided by every plugin within the Manager that satisfy all the provided filters.
+// Commands are ordered by the order their plugins were added, then by the order each plugin lists them.
+//
+// The input and output messages of each command are obtained using Plugin.Describe, which starts plugins that use
+// the WithLazyStart option. Messages are omitted for plugins that cannot describe them, such as those that cannot be
+// reached or are not a *Plugin, in which case the remaining information is still returned.
+func (m *Manager) Commands(ctx context.Context, filters ...CommandFilter) []ManagedCommand {
+	commands := make([]ManagedCommand, 0)
+	for _, p := range m.Plugins() {
+		descriptors := make(map[string]CommandDescriptor)
+		if d, ok := p.(messageDescriber); ok {
+			described, _ := d.Describe(ctx)
+			for _, descriptor := range described {
+				descriptors[descriptor.Name] = descriptor
+			}
+		}
+
+		for _, info := range p.Commands() {
+			command := ManagedCommand{
+				CommandInfo: info,
+				Plugin:      p.Name(),
+				Input:       descriptors[info.Name].Input,
+				Output:      descriptors[info.Name].Output,
+			}
+
+			if matches(command, filters) {
+				commands = append(commands, command)
+			}
+		}
+	}
+
+	return commands
+}
+
+func matches(command ManagedCommand, filters []CommandFilter) bool {
+	for _, filter := range filters {
+		if !filter(command) {
+			return false
+		}
+	}
+
+	return true
+}
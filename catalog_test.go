@@ -0,0 +1,120 @@
+package plugin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestManager_Commands(t *testing.T) {
+	t.Parallel()
+
+	backup := plugintest.New(t, plugin.Config{
+		Name: "backup",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *emptypb.Empty]{
+				Use:         "snapshot",
+				Description: "Takes a snapshot of a volume.",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*emptypb.Empty, error) {
+					return &emptypb.Empty{}, nil
+				},
+			},
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{
+				Use:         "prune",
+				Description: "Removes old snapshots.",
+				Deprecated:  true,
+				Run: func(ctx context.Context, input *emptypb.Empty) (*emptypb.Empty, error) {
+					return &emptypb.Empty{}, nil
+				},
+			},
+		},
+	})
+
+	fake := plugintest.NewFake("archive", "v1.0.0").
+		Handle("upload", func(ctx context.Context, input proto.Message) (proto.Message, error) {
+			return &emptypb.Empty{}, nil
+		})
+
+	manager := plugin.NewManager()
+	require.NoError(t, manager.Add(backup))
+	require.NoError(t, manager.Add(fake))
+
+	names := func(commands []plugin.ManagedCommand) []string {
+		qualified := make([]string, len(commands))
+		for i, command := range commands {
+			qualified[i] = command.QualifiedName()
+		}
+
+		return qualified
+	}
+
+	t.Run("returns commands from every plugin", func(t *testing.T) {
+		t.Parallel()
+
+		commands := manager.Commands(t.Context())
+		assert.EqualValues(t, []string{"backup/snapshot", "backup/prune", "archive/upload"}, names(commands))
+
+		snapshot := commands[0]
+		assert.EqualValues(t, "backup", snapshot.Plugin)
+		assert.EqualValues(t, "Takes a snapshot of a volume.", snapshot.Description)
+		require.NotNil(t, snapshot.Input)
+		assert.EqualValues(t, "google.protobuf.StringValue", snapshot.Input.FullName())
+		require.NotNil(t, snapshot.Output)
+		assert.EqualValues(t, "google.protobuf.Empty", snapshot.Output.FullName())
+
+		// Fakes cannot describe their messages.
+		assert.Nil(t, commands[2].Input)
+		assert.Nil(t, commands[2].Output)
+	})
+
+	tt := []struct {
+		Name     string
+		Filters  []plugin.CommandFilter
+		Expected []string
+	}{
+		{
+			Name:     "searches command names",
+			Filters:  []plugin.CommandFilter{plugin.CommandsMatching("UPLOAD")},
+			Expected: []string{"archive/upload"},
+		},
+		{
+			Name:     "searches command descriptions",
+			Filters:  []plugin.CommandFilter{plugin.CommandsMatching("snapshot")},
+			Expected: []string{"backup/snapshot", "backup/prune"},
+		},
+		{
+			Name:     "filters by plugin",
+			Filters:  []plugin.CommandFilter{plugin.CommandsFrom("archive")},
+			Expected: []string{"archive/upload"},
+		},
+		{
+			Name: "combines filters",
+			Filters: []plugin.CommandFilter{
+				plugin.CommandsFrom("backup"),
+				plugin.NonDeprecatedCommands(),
+			},
+			Expected: []string{"backup/snapshot"},
+		},
+		{
+			Name:     "returns no commands when nothing matches",
+			Filters:  []plugin.CommandFilter{plugin.CommandsMatching("restore")},
+			Expected: []string{},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.EqualValues(t, tc.Expected, names(manager.Commands(t.Context(), tc.Filters...)))
+		})
+	}
+}
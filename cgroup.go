@@ -0,0 +1,199 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+type (
+	// The Cgroup type describes a cgroup v2 hierarchy in which each plugin process is placed into a cgroup of its
+	// own, limiting the memory and CPU it may consume. Unlike ResourceLimits, memory limits apply to the resident
+	// memory of the process and any processes it starts. Zero values leave the corresponding limit unset.
+	Cgroup struct {
+		// The path of the parent cgroup within the cgroup v2 filesystem, such as "/sys/fs/cgroup/plugins". It must
+		// be delegated to the host application and contain no processes of its own. Any controllers required by the
+		// limits are enabled within its cgroup.subtree_control file.
+		Parent string
+		// The maximum amount of memory, in bytes, the plugin may use (memory.max). Once exceeded, the plugin is
+		// killed by the kernel.
+		Memory uint64
+		// The maximum number of CPUs the plugin may use, such as 0.5 for half of a single CPU (cpu.max).
+		CPU float64
+		// The maximum number of processes and threads the plugin may have (pids.max).
+		PIDs uint64
+	}
+
+	// The CgroupStats type describes the resources used by the processes of a plugin placed into cgroups using
+	// WithCgroup.
+	CgroupStats struct {
+		// The memory, in bytes, currently used by the plugin processes (memory.current). This is zero if the memory
+		// controller is not enabled.
+		Memory uint64
+		// The CPU time consumed by the plugin processes since they were started (cpu.stat).
+		CPUTime time.Duration
+	}
+
+	// cgroup is the cgroup of a single plugin process.
+	cgroup struct {
+		path string
+		dir  *os.File
+	}
+)
+
+// ErrCgroupUnsupported is the error given by Use when the WithCgroup option is used on a platform other than Linux.
+var ErrCgroupUnsupported = errors.New("cgroups are not supported on this platform")
+
+const cgroupPeriod = 100000
+
+// create a cgroup within the parent for a single plugin process, applying the limits.
+func (c Cgroup) create(name string) (*cgroup, error) {
+	var controllers []string
+	limits := make(map[string]string)
+	if c.Memory > 0 {
+		controllers = append(controllers, "+memory")
+		limits["memory.max"] = strconv.FormatUint(c.Memory, 10)
+	}
+
+	if c.CPU > 0 {
+		controllers = append(controllers, "+cpu")
+		limits["cpu.max"] = fmt.Sprintf("%d %d", max(1000, int64(c.CPU*cgroupPeriod)), cgroupPeriod)
+	}
+
+	if c.PIDs > 0 {
+		controllers = append(controllers, "+pids")
+		limits["pids.max"] = strconv.FormatUint(c.PIDs, 10)
+	}
+
+	if len(controllers) > 0 {
+		control := filepath.Join(c.Parent, "cgroup.subtree_control")
+		if err := os.WriteFile(control, []byte(strings.Join(controllers, " ")), 0); err != nil {
+			return nil, fmt.Errorf("failed to enable cgroup controllers: %w", err)
+		}
+	}
+
+	cg := &cgroup{path: filepath.Join(c.Parent, name)}
+	if err := os.Mkdir(cg.path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create cgroup: %w", err)
+	}
+
+	for file, limit := range limits {
+		if err := os.WriteFile(filepath.Join(cg.path, file), []byte(limit), 0); err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to set %s: %w", file, err), cg.remove())
+		}
+	}
+
+	dir, err := os.Open(cg.path)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to open cgroup: %w", err), cg.remove())
+	}
+
+	cg.dir = dir
+	return cg, nil
+}
+
+// started closes the cgroup directory once the process has been placed within it.
+func (cg *cgroup) started() {
+	if cg.dir != nil {
+		_ = cg.dir.Close()
+		cg.dir = nil
+	}
+}
+
+// remove kills any processes remaining within the cgroup, such as those started by the plugin, and removes it.
+func (cg *cgroup) remove() error {
+	cg.started()
+
+	// Kernels without cgroup.kill leave any remaining processes to be killed by other means.
+	_ = os.WriteFile(filepath.Join(cg.path, "cgroup.kill"), []byte("1"), 0)
+
+	// Killed processes are removed from the cgroup asynchronously, so it may briefly remain busy.
+	var err error
+	for range 100 {
+		if err = os.Remove(cg.path); err == nil || errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		if !errors.Is(err, syscall.EBUSY) {
+			break
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return fmt.Errorf("failed to remove cgroup %q: %w", cg.path, err)
+}
+
+// usage returns the resources used by the processes within the cgroup.
+func (cg *cgroup) usage() (CgroupStats, error) {
+	var stats CgroupStats
+
+	memory, err := os.ReadFile(filepath.Join(cg.path, "memory.current"))
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return CgroupStats{}, err
+	default:
+		if stats.Memory, err = strconv.ParseUint(string(bytes.TrimSpace(memory)), 10, 64); err != nil {
+			return CgroupStats{}, fmt.Errorf("invalid memory.current: %w", err)
+		}
+	}
+
+	cpu, err := os.ReadFile(filepath.Join(cg.path, "cpu.stat"))
+	if err != nil {
+		return CgroupStats{}, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(cpu))
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "usage_usec ")
+		if !ok {
+			continue
+		}
+
+		usec, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return CgroupStats{}, fmt.Errorf("invalid cpu.stat: %w", err)
+		}
+
+		stats.CPUTime = time.Duration(usec) * time.Microsecond
+	}
+
+	return stats, nil
+}
+
+// cgroupStats returns the total resources used by the running processes of the plugin.
+func (p *Plugin) cgroupStats() CgroupStats {
+	p.mu.RLock()
+	pl := p.pool
+	p.mu.RUnlock()
+
+	var total CgroupStats
+	if pl == nil {
+		return total
+	}
+
+	for _, proc := range pl.processes {
+		if proc == nil || proc.cgroup == nil {
+			continue
+		}
+
+		// Processes that have exited may have had their cgroup removed.
+		usage, err := proc.cgroup.usage()
+		if err != nil {
+			continue
+		}
+
+		total.Memory += usage.Memory
+		total.CPUTime += usage.CPUTime
+	}
+
+	return total
+}
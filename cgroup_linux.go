@@ -0,0 +1,20 @@
+//go:build linux
+
+package plugin
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const cgroupSupported = true
+
+// attach places the command within the cgroup as it is started, so that it is never able to run outside of it.
+func (cg *cgroup) attach(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(cg.dir.Fd())
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package plugin
+
+import (
+	"os/exec"
+)
+
+const cgroupSupported = false
+
+func (*cgroup) attach(*exec.Cmd) {}
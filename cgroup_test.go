@@ -0,0 +1,108 @@
+//go:build linux
+
+package plugin_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestUse_WithCgroup(t *testing.T) {
+	parent := cgroupParent(t)
+
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithCgroup(plugin.Cgroup{Parent: parent}))
+	require.NoError(t, err)
+
+	output := &wrapperspb.Int64Value{}
+	require.NoError(t, p.Exec(t.Context(), "pid", &emptypb.Empty{}, output))
+
+	cgroups, err := filepath.Glob(filepath.Join(parent, "test_plugin-*"))
+	require.NoError(t, err)
+	require.Len(t, cgroups, 1)
+
+	procs, err := os.ReadFile(filepath.Join(cgroups[0], "cgroup.procs"))
+	require.NoError(t, err)
+	assert.Contains(t, strings.Fields(string(procs)), strconv.FormatInt(output.GetValue(), 10))
+
+	stats := p.Stats()
+	require.NotNil(t, stats.Cgroup)
+	assert.Positive(t, stats.Cgroup.CPUTime)
+
+	require.NoError(t, p.Close())
+	assert.NoDirExists(t, cgroups[0])
+}
+
+func TestUse_WithCgroup_Limits(t *testing.T) {
+	parent := cgroupParent(t)
+
+	controllers, err := os.ReadFile(filepath.Join(parent, "cgroup.controllers"))
+	require.NoError(t, err)
+	for _, controller := range []string{"memory", "cpu", "pids"} {
+		if !strings.Contains(string(controllers), controller) {
+			t.Skipf("the %s controller is not available", controller)
+		}
+	}
+
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithCgroup(plugin.Cgroup{
+		Parent: parent,
+		Memory: 256 << 20,
+		CPU:    0.5,
+		PIDs:   64,
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	cgroups, err := filepath.Glob(filepath.Join(parent, "test_plugin-*"))
+	require.NoError(t, err)
+	require.Len(t, cgroups, 1)
+
+	for file, expected := range map[string]string{"memory.max": "268435456", "cpu.max": "50000 100000", "pids.max": "64"} {
+		limit, err := os.ReadFile(filepath.Join(cgroups[0], file))
+		require.NoError(t, err)
+		assert.Equal(t, expected, strings.TrimSpace(string(limit)))
+	}
+
+	stats := p.Stats()
+	require.NotNil(t, stats.Cgroup)
+	assert.Positive(t, stats.Cgroup.Memory)
+}
+
+// cgroupParent creates a cgroup for the plugins started by a test, skipping the test if cgroup v2 is unavailable.
+func cgroupParent(t *testing.T) string {
+	t.Helper()
+
+	var root string
+	for _, path := range []string{"/sys/fs/cgroup", "/sys/fs/cgroup/unified"} {
+		if _, err := os.Stat(filepath.Join(path, "cgroup.controllers")); err == nil {
+			root = path
+			break
+		}
+	}
+
+	if root == "" {
+		t.Skip("cgroup v2 is not available")
+	}
+
+	parent, err := os.MkdirTemp(root, "plugin-test-")
+	if err != nil {
+		t.Skipf("cannot create cgroups: %v", err)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, os.Remove(parent))
+	})
+
+	return parent
+}
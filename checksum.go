@@ -0,0 +1,105 @@
+package plugin
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrChecksumMismatch is the error returned by Use when the SHA-256 digest of a plugin binary does not match the
+// digest provided via WithChecksum or WithChecksumFile. It is also returned by Plugin.SendFile and Plugin.ReceiveFile
+// when a file is corrupted in transit.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// WithChecksum is a UseOption that verifies the SHA-256 digest of the plugin binary matches the provided
+// hex-encoded digest before it is started. Use returns ErrChecksumMismatch if the digests do not match.
+func WithChecksum(digest string) UseOption {
+	return func(o *useOptions) {
+		o.checksum = digest
+	}
+}
+
+// WithChecksumFile is a UseOption that verifies the SHA-256 digest of the plugin binary matches the digest listed for
+// it within the provided checksums file before it is started. The file is expected to be in the format produced by
+// the sha256sum utility, where each line contains a hex-encoded digest followed by a file name. Use returns
+// ErrChecksumMismatch if the file does not list the plugin binary or if the digests do not match.
+func WithChecksumFile(path string) UseOption {
+	return func(o *useOptions) {
+		o.checksumFile = path
+	}
+}
+
+func verifyChecksum(path string, options useOptions) error {
+	expected := options.checksum
+	if options.checksumFile != "" {
+		digest, err := readChecksumFile(options.checksumFile, filepath.Base(path))
+		if err != nil {
+			return err
+		}
+
+		expected = digest
+	}
+
+	if expected == "" {
+		return nil
+	}
+
+	actual, err := checksum(path)
+	if err != nil {
+		return fmt.Errorf("failed to compute checksum of %q: %w", path, err)
+	}
+
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("%w: expected %q, got %q", ErrChecksumMismatch, expected, actual)
+	}
+
+	return nil
+}
+
+func checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func readChecksumFile(path, name string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open checksum file %q: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+
+		// Binary mode entries are prefixed with an asterisk by sha256sum.
+		if strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], nil
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read checksum file %q: %w", path, err)
+	}
+
+	return "", fmt.Errorf("%w: no checksum for %q in %q", ErrChecksumMismatch, name, path)
+}
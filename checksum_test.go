@@ -0,0 +1,83 @@
+package plugin_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestUse_WithChecksum(t *testing.T) {
+	binary, err := os.ReadFile("./test_plugin")
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(binary)
+	digest := hex.EncodeToString(sum[:])
+	other := hex.EncodeToString(make([]byte, sha256.Size))
+
+	checksums := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "checksums.txt")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+
+	tt := []struct {
+		Name        string
+		Option      func(t *testing.T) plugin.UseOption
+		ExpectError error
+	}{
+		{
+			Name: "starts with matching digest",
+			Option: func(t *testing.T) plugin.UseOption {
+				return plugin.WithChecksum(digest)
+			},
+		},
+		{
+			Name: "fails with mismatched digest",
+			Option: func(t *testing.T) plugin.UseOption {
+				return plugin.WithChecksum(other)
+			},
+			ExpectError: plugin.ErrChecksumMismatch,
+		},
+		{
+			Name: "starts with matching checksum file",
+			Option: func(t *testing.T) plugin.UseOption {
+				return plugin.WithChecksumFile(checksums(t, fmt.Sprintf("%s  other_plugin\n%s *test_plugin\n", other, digest)))
+			},
+		},
+		{
+			Name: "fails with mismatched checksum file",
+			Option: func(t *testing.T) plugin.UseOption {
+				return plugin.WithChecksumFile(checksums(t, fmt.Sprintf("%s  test_plugin\n", other)))
+			},
+			ExpectError: plugin.ErrChecksumMismatch,
+		},
+		{
+			Name: "fails when checksum file does not list the plugin",
+			Option: func(t *testing.T) plugin.UseOption {
+				return plugin.WithChecksumFile(checksums(t, fmt.Sprintf("%s  other_plugin\n", digest)))
+			},
+			ExpectError: plugin.ErrChecksumMismatch,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			p, err := plugin.Use(t.Context(), "./test_plugin", tc.Option(t))
+			if tc.ExpectError != nil {
+				assert.ErrorIs(t, err, tc.ExpectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NoError(t, p.Close())
+		})
+	}
+}
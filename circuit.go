@@ -0,0 +1,114 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// The CircuitBreaker type describes when calls to a degraded plugin should fail fast rather than being sent to
+	// it. When provided to Use via the WithCircuitBreaker option, the circuit opens after a number of consecutive
+	// failed calls to Plugin.Exec. While open, calls fail immediately with ErrCircuitOpen. Once the cooldown has
+	// elapsed, a single probe call is sent to the plugin. If it succeeds the circuit closes, otherwise it opens again.
+	CircuitBreaker struct {
+		// The number of consecutive failures after which the circuit opens. Defaults to 5.
+		Threshold int
+		// The Cooldown is the time the circuit stays open before a probe call is allowed. Defaults to 30 seconds.
+		Cooldown time.Duration
+		// IsFailure, if set, determines whether an error returned by Plugin.Exec counts as a failure. By default, all
+		// errors count as failures other than those caused by the cancellation of the caller's context.
+		IsFailure func(err error) bool
+	}
+
+	circuitBreaker struct {
+		config CircuitBreaker
+		clock  Clock
+
+		mu       sync.Mutex
+		failures int
+		openedAt time.Time
+		probing  bool
+	}
+)
+
+// ErrCircuitOpen is the error returned by Plugin.Exec when the circuit breaker set using WithCircuitBreaker is open
+// and the call was not sent to the plugin.
+var ErrCircuitOpen = errors.New("circuit open")
+
+const (
+	defaultCircuitThreshold = 5
+	defaultCircuitCooldown  = 30 * time.Second
+)
+
+// WithCircuitBreaker is a UseOption that protects the host application from a degraded plugin by failing calls to
+// Plugin.Exec fast with ErrCircuitOpen after the plugin has failed repeatedly.
+func WithCircuitBreaker(breaker CircuitBreaker) UseOption {
+	return func(o *useOptions) {
+		o.circuitBreaker = &breaker
+	}
+}
+
+func newCircuitBreaker(config CircuitBreaker, clock Clock) *circuitBreaker {
+	if config.Threshold <= 0 {
+		config.Threshold = defaultCircuitThreshold
+	}
+
+	if config.Cooldown <= 0 {
+		config.Cooldown = defaultCircuitCooldown
+	}
+
+	return &circuitBreaker{
+		config: config,
+		clock:  clock,
+	}
+}
+
+// allow returns ErrCircuitOpen if a call to the named plugin should not be made. Otherwise, it reports whether the call
+// is a probe of an open circuit, and the outcome of the call must be reported using done.
+func (cb *circuitBreaker) allow(name string) (probe bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.failures < cb.config.Threshold {
+		return false, nil
+	}
+
+	if cb.probing || cb.clock.Now().Sub(cb.openedAt) < cb.config.Cooldown {
+		return false, fmt.Errorf("%w: plugin %q has failed %d consecutive calls", ErrCircuitOpen, name, cb.failures)
+	}
+
+	cb.probing = true
+	return true, nil
+}
+
+func (cb *circuitBreaker) done(ctx context.Context, probe bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if probe {
+		cb.probing = false
+	}
+
+	switch {
+	case err == nil:
+		cb.failures = 0
+	case !cb.isFailure(ctx, err):
+		return
+	default:
+		cb.failures++
+		if cb.failures >= cb.config.Threshold {
+			cb.openedAt = cb.clock.Now()
+		}
+	}
+}
+
+func (cb *circuitBreaker) isFailure(ctx context.Context, err error) bool {
+	if cb.config.IsFailure != nil {
+		return cb.config.IsFailure(err)
+	}
+
+	return ctx.Err() == nil
+}
@@ -0,0 +1,79 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestUse_WithCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var (
+		calls   atomic.Int64
+		failing atomic.Bool
+	)
+
+	failing.Store(true)
+	clock := NewManualClock()
+	p := plugintest.New(t, plugin.Config{
+		Name: "circuit",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{
+				Use: "flaky",
+				Run: func(ctx context.Context, input *emptypb.Empty) (*emptypb.Empty, error) {
+					calls.Add(1)
+					if failing.Load() {
+						return nil, errors.New("degraded")
+					}
+
+					return &emptypb.Empty{}, nil
+				},
+			},
+		},
+	}, plugin.WithClock(clock), plugin.WithCircuitBreaker(plugin.CircuitBreaker{
+		Threshold: 3,
+		Cooldown:  time.Minute,
+	}))
+
+	exec := func() error {
+		return p.Exec(t.Context(), "flaky", &emptypb.Empty{}, &emptypb.Empty{})
+	}
+
+	for range 3 {
+		var e *plugin.Error
+		require.ErrorAs(t, exec(), &e)
+	}
+
+	t.Run("fails fast once open", func(t *testing.T) {
+		require.ErrorIs(t, exec(), plugin.ErrCircuitOpen)
+		assert.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("opens again if the probe fails", func(t *testing.T) {
+		clock.Advance(time.Minute)
+
+		var e *plugin.Error
+		require.ErrorAs(t, exec(), &e)
+		require.ErrorIs(t, exec(), plugin.ErrCircuitOpen)
+		assert.EqualValues(t, 4, calls.Load())
+	})
+
+	t.Run("closes once the probe succeeds", func(t *testing.T) {
+		failing.Store(false)
+		clock.Advance(time.Minute)
+
+		require.NoError(t, exec())
+		require.NoError(t, exec())
+		assert.EqualValues(t, 6, calls.Load())
+	})
+}
@@ -0,0 +1,28 @@
+package plugin
+
+import (
+	"time"
+)
+
+type (
+	// The Clock interface describes types that provide the current time and the ability to wait for durations to
+	// elapse. It is used for all time-based behaviour of a Plugin, such as waiting for it to become ready and
+	// waiting between retries. Host applications can provide their own Clock implementation using WithClock, which
+	// is primarily useful for testing.
+	Clock interface {
+		// Now returns the current time.
+		Now() time.Time
+		// After waits for the duration to elapse and then sends the current time on the returned channel.
+		After(d time.Duration) <-chan time.Time
+	}
+
+	systemClock struct{}
+)
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
@@ -0,0 +1,152 @@
+package plugin_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+type (
+	ManualClock struct {
+		mu      sync.Mutex
+		now     time.Time
+		waiters []manualWaiter
+	}
+
+	manualWaiter struct {
+		at time.Time
+		ch chan time.Time
+	}
+)
+
+func NewManualClock() *ManualClock {
+	return &ManualClock{now: time.Unix(0, 0)}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+
+	c.waiters = append(c.waiters, manualWaiter{at: c.now.Add(d), ch: ch})
+	return ch
+}
+
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+
+	waiters := make([]manualWaiter, 0, len(c.waiters))
+	for _, waiter := range c.waiters {
+		if waiter.at.After(c.now) {
+			waiters = append(waiters, waiter)
+			continue
+		}
+
+		waiter.ch <- c.now
+	}
+
+	c.waiters = waiters
+}
+
+// AdvanceUntil advances the clock by the given duration every millisecond until the provided channel is closed.
+func (c *ManualClock) AdvanceUntil(d time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			c.Advance(d)
+		}
+	}
+}
+
+func TestUse_WithClock(t *testing.T) {
+	t.Run("error if plugin does not create socket", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sleep_plugin")
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexec sleep 60\n"), 0o755))
+
+		clock := NewManualClock()
+		done := make(chan struct{})
+
+		var err error
+		go func() {
+			defer close(done)
+			_, err = plugin.Use(t.Context(), path, plugin.WithClock(clock))
+		}()
+
+		clock.AdvanceUntil(time.Second, done)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plugin.ErrStartupTimeout))
+	})
+
+	t.Run("retries use clock", func(t *testing.T) {
+		clock := NewManualClock()
+		policy := &CountingRetryPolicy{Max: 3, Delay: time.Hour}
+
+		p := useWithClock(t, clock, plugin.WithRetryPolicy(policy))
+
+		var err error
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			err = p.Exec(t.Context(), "pingpong", wrapperspb.String("pung"), &wrapperspb.StringValue{})
+		}()
+
+		clock.AdvanceUntil(time.Hour, done)
+		require.Error(t, err)
+		assert.EqualValues(t, 3, p.Stats().Commands["pingpong"].Calls)
+	})
+}
+
+// useWithClock starts the test plugin using the provided clock, advancing it in small increments until the plugin
+// has started.
+func useWithClock(t *testing.T, clock *ManualClock, opts ...plugin.UseOption) *plugin.Plugin {
+	t.Helper()
+
+	var (
+		p   *plugin.Plugin
+		err error
+	)
+
+	started := make(chan struct{})
+	go func() {
+		defer close(started)
+		p, err = plugin.Use(t.Context(), "./test_plugin", append(opts, plugin.WithClock(clock))...)
+	}()
+
+	clock.AdvanceUntil(10*time.Millisecond, started)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	return p
+}
@@ -0,0 +1,55 @@
+// Package main provides a command-line tool that writes canonical wire-level fixtures describing the plugin protocol
+// to a directory. Authors of plugin implementations in languages other than Go can test their implementations
+// against these fixtures.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/davidsbond/plugin/internal/conformance"
+)
+
+func main() {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "conformance",
+		Short: "Generates plugin protocol conformance fixtures",
+		Long:  "Generates canonical wire-level fixtures for the plugin protocol.\n\nThese include binary and JSON encoded requests and responses, the handshake performed by host applications when starting a plugin and the gRPC status codes plugins must return in error scenarios.",
+		CompletionOptions: cobra.CompletionOptions{
+			DisableDefaultCmd: true,
+		},
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		Args:          cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fixtures, err := conformance.Generate()
+			if err != nil {
+				return err
+			}
+
+			if err = os.MkdirAll(output, 0o755); err != nil {
+				return err
+			}
+
+			for _, fixture := range fixtures {
+				if err = os.WriteFile(filepath.Join(output, fixture.Name), fixture.Data, 0o644); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", ".", "The directory to write fixtures to")
+
+	if err := cmd.Execute(); err != nil {
+		fmt.Printf("failed to generate fixtures: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The Codec interface describes types that encode the input and output of commands, allowing formats other than
+	// the protobuf binary format to be exchanged with plugins using the same messages. Host applications provide
+	// codecs using WithCodecs and plugins using Config.Codecs. Each command advertises the codecs it supports, and the
+	// host application uses the first of those it also supports.
+	Codec interface {
+		// Name returns the name of the codec, which must be the same within the host application and plugin.
+		Name() string
+		// Marshal encodes the message.
+		Marshal(message proto.Message) ([]byte, error)
+		// Unmarshal decodes the data into the message.
+		Unmarshal(data []byte, message proto.Message) error
+	}
+)
+
+var (
+	// ProtoCodec is the Codec that uses the protobuf binary format. It is always supported and is used unless a
+	// command advertises otherwise.
+	ProtoCodec Codec = plugin.ProtoCodec{}
+
+	// JSONCodec is the Codec that uses the protobuf JSON format. It is always supported.
+	JSONCodec Codec = plugin.JSONCodec{}
+)
+
+// ErrUnsupportedCodec is the error returned by Plugin.Exec when the host application supports none of the codecs
+// advertised by a command.
+var ErrUnsupportedCodec = errors.New("unsupported codec")
+
+// codec returns the Codec used to execute the named command, or nil if ProtoCodec is used.
+func (p *Plugin) codec(name string) (Codec, error) {
+	for _, command := range p.Commands() {
+		if command.Name != name || len(command.Codecs) == 0 {
+			continue
+		}
+
+		for _, codecName := range command.Codecs {
+			if codecName == ProtoCodec.Name() {
+				return nil, nil
+			}
+
+			if codecName == JSONCodec.Name() {
+				return JSONCodec, nil
+			}
+
+			for _, codec := range p.options.codecs {
+				if codec.Name() == codecName {
+					return codec, nil
+				}
+			}
+		}
+
+		return nil, fmt.Errorf("%w: command %q supports %q", ErrUnsupportedCodec, name, command.Codecs)
+	}
+
+	return nil, nil
+}
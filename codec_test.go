@@ -0,0 +1,56 @@
+package plugin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+type (
+	// customCodec is a Codec that uses the protobuf binary format under another name.
+	customCodec struct{}
+)
+
+func (customCodec) Name() string {
+	return "custom"
+}
+
+func (customCodec) Marshal(message proto.Message) ([]byte, error) {
+	return plugin.ProtoCodec.Marshal(message)
+}
+
+func (customCodec) Unmarshal(data []byte, message proto.Message) error {
+	return plugin.ProtoCodec.Unmarshal(data, message)
+}
+
+func TestPlugin_Commands_Codecs(t *testing.T) {
+	t.Parallel()
+
+	p := plugintest.New(t, plugin.Config{
+		Name: "codecs",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "echo",
+				Run: func(_ context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					return input, nil
+				},
+			},
+		},
+		Codecs: []plugin.Codec{customCodec{}},
+	}, plugin.WithCodecs(customCodec{}))
+
+	commands := p.Commands()
+	require.Len(t, commands, 1)
+	assert.Equal(t, []string{"proto", "json", "custom"}, commands[0].Codecs)
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, p.Exec(t.Context(), "echo", wrapperspb.String("hello"), output))
+	assert.Equal(t, "hello", output.GetValue())
+}
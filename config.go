@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// validName matches plugin names that are safe to use within file names, such as that of the plugin binary and its
+// UNIX domain socket.
+var validName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+
+// validate checks that the Config can be used to run a plugin, returning an error matching ErrInvalidConfig that
+// describes every problem found.
+func (c Config) validate() error {
+	var errs []error
+	switch {
+	case c.Name == "":
+		errs = append(errs, errors.New("plugin name is empty"))
+	case !validName.MatchString(c.Name):
+		errs = append(errs, fmt.Errorf("plugin name %q must only contain letters, digits, '.', '_' and '-', and must not start with a symbol", c.Name))
+	}
+
+	names := make(map[string]struct{}, len(c.Commands))
+	for i, command := range c.Commands {
+		if command == nil {
+			errs = append(errs, fmt.Errorf("command %d is nil", i))
+			continue
+		}
+
+		name := command.Name()
+		if name == "" {
+			errs = append(errs, fmt.Errorf("command %d has no name", i))
+			continue
+		}
+
+		if strings.Contains(name, CommandSeparator) {
+			errs = append(errs, fmt.Errorf("command name %q must not contain %q", name, CommandSeparator))
+		}
+
+		if _, ok := names[name]; ok {
+			errs = append(errs, fmt.Errorf("command %q is defined more than once", name))
+		}
+		names[name] = struct{}{}
+
+		if v, ok := command.(validator); ok {
+			if err := v.validate(); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	if c.OnConfigChange != nil && c.Configure == nil {
+		errs = append(errs, errors.New("OnConfigChange requires Configure to be set"))
+	}
+
+	if c.MinHostVersion != "" {
+		if _, err := semver.NewVersion(c.MinHostVersion); err != nil {
+			errs = append(errs, fmt.Errorf("minimum host version %q is invalid: %w", c.MinHostVersion, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, errors.Join(errs...))
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package plugin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestServe_InvalidConfig(t *testing.T) {
+	t.Parallel()
+
+	echo := func(_ context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+		return input, nil
+	}
+
+	tt := []struct {
+		Name     string
+		Config   plugin.Config
+		Expected string
+	}{
+		{
+			Name:     "empty plugin name",
+			Config:   plugin.Config{},
+			Expected: "invalid config: plugin name is empty",
+		},
+		{
+			Name:     "unsafe plugin name",
+			Config:   plugin.Config{Name: "../plugin"},
+			Expected: `invalid config: plugin name "../plugin" must only contain letters, digits, '.', '_' and '-', and must not start with a symbol`,
+		},
+		{
+			Name: "duplicate commands",
+			Config: plugin.Config{
+				Name: "test",
+				Commands: []plugin.CommandHandler{
+					&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{Use: "echo", Run: echo},
+					&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{Use: "echo", Run: echo},
+				},
+			},
+			Expected: `invalid config: command "echo" is defined more than once`,
+		},
+		{
+			Name: "command containing the separator",
+			Config: plugin.Config{
+				Name: "test",
+				Commands: []plugin.CommandHandler{
+					&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{Use: "echo/reverse", Run: echo},
+				},
+			},
+			Expected: `invalid config: command name "echo/reverse" must not contain "/"`,
+		},
+		{
+			Name: "unnamed command",
+			Config: plugin.Config{
+				Name: "test",
+				Commands: []plugin.CommandHandler{
+					&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{Run: echo},
+				},
+			},
+			Expected: "invalid config: command 0 has no name",
+		},
+		{
+			Name: "nil command",
+			Config: plugin.Config{
+				Name:     "test",
+				Commands: []plugin.CommandHandler{nil},
+			},
+			Expected: "invalid config: command 0 is nil",
+		},
+		{
+			Name: "missing run functions",
+			Config: plugin.Config{
+				Name: "test",
+				Commands: []plugin.CommandHandler{
+					&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{Use: "sync"},
+					&plugin.AsyncCommand[*wrapperspb.StringValue, *wrapperspb.StringValue]{Use: "async"},
+					&plugin.RawCommand{Use: "raw"},
+				},
+			},
+			Expected: "invalid config: command \"sync\" has no Run function\ncommand \"async\" has no Run function\ncommand \"raw\" has no Run function",
+		},
+		{
+			Name: "config change without configure",
+			Config: plugin.Config{
+				Name:           "test",
+				OnConfigChange: func(context.Context, *anypb.Any) error { return nil },
+			},
+			Expected: "invalid config: OnConfigChange requires Configure to be set",
+		},
+		{
+			Name:     "invalid minimum host version",
+			Config:   plugin.Config{Name: "test", MinHostVersion: "latest"},
+			Expected: `invalid config: minimum host version "latest" is invalid: invalid semantic version`,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			tc.Config.Socket = "unused.sock"
+
+			err := plugin.Serve(t.Context(), tc.Config)
+			assert.ErrorIs(t, err, plugin.ErrInvalidConfig)
+			assert.EqualError(t, err, tc.Expected)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+// The configurer interface describes a Client whose configuration can be changed by Manager.Configure, such as a
+// *Plugin.
+type configurer interface {
+	Configure(ctx context.Context, configuration proto.Message) error
+}
+
+// ErrConfigurationUnsupported is the error returned by Use and Connect when configuration is provided using
+// WithConfiguration to a plugin that does not accept it, as it does not set Config.Configure. It is also returned by
+// Plugin.Configure and Manager.Configure for plugins that do not set Config.OnConfigChange.
+var ErrConfigurationUnsupported = plugin.ErrConfigurationUnsupported
+
+// WithConfiguration is a UseOption that provides configuration to the plugin, such as credentials or endpoints, that
+// does not fit within its arguments. The configuration is given to the Config.Configure function of each plugin
+// process once it has started, before any of its commands are executed. Commonly, the configuration is a message
+// specific to the plugin, or a structpb.Struct for plugins that accept arbitrary settings.
+func WithConfiguration(configuration proto.Message) UseOption {
+	return func(o *useOptions) {
+		o.configuration = configuration
+	}
+}
+
+// Configuration returns a function for use as Config.Configure that decodes the configuration provided by the host
+// application into a new instance of the configuration type before calling fn. If the host application does not
+// provide any configuration, fn is given an empty instance. Configuration of any other type is rejected.
+func Configuration[T proto.Message](fn func(ctx context.Context, configuration T) error) func(ctx context.Context, configuration *anypb.Any) error {
+	return func(ctx context.Context, configuration *anypb.Any) error {
+		message := newMessage[T]()
+		if configuration == nil {
+			return fn(ctx, message)
+		}
+
+		if !configuration.MessageIs(message) {
+			return fmt.Errorf("expected configuration of type %q, got %q", message.ProtoReflect().Descriptor().FullName(), configuration.GetTypeUrl())
+		}
+
+		if err := configuration.UnmarshalTo(message); err != nil {
+			return err
+		}
+
+		return fn(ctx, message)
+	}
+}
+
+// configure provides the configuration given via WithConfiguration to a newly started plugin process. Plugins that
+// accept configuration are always configured, so that they know when the host application provides none.
+func (p *Plugin) configure(ctx context.Context, proc *process, info plugin.Info) error {
+	if !info.Configurable {
+		if p.configuration != nil {
+			return fmt.Errorf("%w: plugin %q", ErrConfigurationUnsupported, p.name)
+		}
+
+		return nil
+	}
+
+	configuration, err := p.encodeConfiguration(p.configuration)
+	if err != nil {
+		return err
+	}
+
+	if err = proc.client.Configure(ctx, configuration); err != nil {
+		return fmt.Errorf("failed to configure plugin %q: %w", p.name, err)
+	}
+
+	return nil
+}
+
+func (p *Plugin) encodeConfiguration(configuration proto.Message) (*anypb.Any, error) {
+	if configuration == nil {
+		return nil, nil
+	}
+
+	encoded, err := anypb.New(configuration)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration for plugin %q: %w", p.name, err)
+	}
+
+	return encoded, nil
+}
+
+// Configure pushes new configuration to the running plugin, replacing that given via WithConfiguration or a previous
+// call to Configure. Each process of the plugin applies the configuration using its Config.OnConfigChange function,
+// and Configure returns once every process has done so, at which point the configuration is in use. Processes started
+// later, such as after a crash or upgrade, are configured using it. Plugins that are not running are configured once
+// started.
+//
+// Returns ErrConfigurationUnsupported if the plugin does not accept changes to its configuration. If a process rejects
+// the configuration, an error is returned and processes started later continue to use the previous configuration,
+// though processes that accepted it continue to use it.
+func (p *Plugin) Configure(ctx context.Context, configuration proto.Message) error {
+	encoded, err := p.encodeConfiguration(configuration)
+	if err != nil {
+		return err
+	}
+
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+
+	// The configuration is replaced before it is pushed, so that processes started in the meantime also use it.
+	p.mu.Lock()
+	pl, info, closed, previous := p.pool, p.info, p.closed, p.configuration
+	switch {
+	case closed:
+		p.mu.Unlock()
+		return fmt.Errorf("%w: %q", ErrClosed, p.name)
+	case pl != nil && !info.Configurable:
+		p.mu.Unlock()
+		return fmt.Errorf("%w: plugin %q", ErrConfigurationUnsupported, p.name)
+	}
+
+	p.configuration = configuration
+	p.mu.Unlock()
+
+	if pl == nil {
+		return nil
+	}
+
+	var errs []error
+	for _, proc := range pl.processes {
+		// Processes being closed, such as when the plugin is stopped due to inactivity, no longer need configuring.
+		if err = proc.client.Configure(ctx, encoded); err != nil && !proc.closing.Load() {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	p.configuration = previous
+	p.mu.Unlock()
+
+	return fmt.Errorf("failed to configure plugin %q: %w", p.name, errors.Join(errs...))
+}
+
+// Configure pushes new configuration to the named plugin, as described by Plugin.Configure. Returns ErrNoPlugin if no
+// plugin with the given name has been added to the Manager, and ErrConfigurationUnsupported if the plugin cannot be
+// configured.
+func (m *Manager) Configure(ctx context.Context, name string, configuration proto.Message) error {
+	p, ok := m.Get(name)
+	if !ok {
+		return fmt.Errorf("%w: no plugin named %q", ErrNoPlugin, name)
+	}
+
+	c, ok := p.(configurer)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrConfigurationUnsupported, name)
+	}
+
+	return c.Configure(ctx, configuration)
+}
@@ -0,0 +1,215 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestWithConfiguration(t *testing.T) {
+	t.Parallel()
+
+	var endpoint string
+	config := plugin.Config{
+		Name: "configured",
+		Configure: plugin.Configuration(func(_ context.Context, configuration *wrapperspb.StringValue) error {
+			if configuration.GetValue() == "invalid" {
+				return errors.New("invalid endpoint")
+			}
+
+			endpoint = configuration.GetValue()
+			return nil
+		}),
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*emptypb.Empty, *wrapperspb.StringValue]{
+				Use: "endpoint",
+				Run: func(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error) {
+					return wrapperspb.String(endpoint), nil
+				},
+			},
+		},
+	}
+
+	tt := []struct {
+		Name          string
+		Configuration proto.Message
+		Expected      string
+	}{
+		{
+			Name:          "provides configuration to plugin",
+			Configuration: wrapperspb.String("https://example.com"),
+			Expected:      "https://example.com",
+		},
+		{
+			Name: "configures plugin without configuration",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var options []plugin.UseOption
+			if tc.Configuration != nil {
+				options = append(options, plugin.WithConfiguration(tc.Configuration))
+			}
+
+			p := plugintest.New(t, config, options...)
+
+			output := &wrapperspb.StringValue{}
+			require.NoError(t, p.Exec(t.Context(), "endpoint", &emptypb.Empty{}, output))
+			assert.EqualValues(t, tc.Expected, output.GetValue())
+		})
+	}
+}
+
+func TestWithConfiguration_Errors(t *testing.T) {
+	plugin.Register(plugin.Config{
+		Name: "configured_plugin",
+		Configure: plugin.Configuration(func(_ context.Context, configuration *wrapperspb.StringValue) error {
+			if configuration.GetValue() == "" {
+				return errors.New("missing endpoint")
+			}
+
+			return nil
+		}),
+	})
+
+	plugin.Register(plugin.Config{
+		Name: "unconfigured_plugin",
+	})
+
+	tt := []struct {
+		Name          string
+		Plugin        string
+		Configuration proto.Message
+		ExpectedErr   error
+	}{
+		{
+			Name:          "plugin rejects configuration",
+			Plugin:        "configured_plugin",
+			Configuration: wrapperspb.String(""),
+		},
+		{
+			Name:          "plugin rejects configuration of another type",
+			Plugin:        "configured_plugin",
+			Configuration: wrapperspb.Int64(1),
+		},
+		{
+			Name:   "plugin rejects missing configuration",
+			Plugin: "configured_plugin",
+		},
+		{
+			Name:          "plugin does not accept configuration",
+			Plugin:        "unconfigured_plugin",
+			Configuration: wrapperspb.String("https://example.com"),
+			ExpectedErr:   plugin.ErrConfigurationUnsupported,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var options []plugin.UseOption
+			if tc.Configuration != nil {
+				options = append(options, plugin.WithConfiguration(tc.Configuration))
+			}
+
+			_, err := plugin.Use(t.Context(), tc.Plugin, options...)
+			require.Error(t, err)
+			if tc.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tc.ExpectedErr)
+			}
+		})
+	}
+}
+
+func TestPlugin_Configure(t *testing.T) {
+	t.Parallel()
+
+	newConfig := func(onConfigChange bool) plugin.Config {
+		var endpoint atomic.Value
+		configure := plugin.Configuration(func(_ context.Context, configuration *wrapperspb.StringValue) error {
+			if configuration.GetValue() == "invalid" {
+				return errors.New("invalid endpoint")
+			}
+
+			endpoint.Store(configuration.GetValue())
+			return nil
+		})
+
+		config := plugin.Config{
+			Name:      "configured",
+			Configure: configure,
+			Commands: []plugin.CommandHandler{
+				&plugin.Command[*emptypb.Empty, *wrapperspb.StringValue]{
+					Use: "endpoint",
+					Run: func(context.Context, *emptypb.Empty) (*wrapperspb.StringValue, error) {
+						return wrapperspb.String(endpoint.Load().(string)), nil
+					},
+				},
+			},
+		}
+
+		if onConfigChange {
+			config.OnConfigChange = configure
+		}
+
+		return config
+	}
+
+	endpoint := func(t *testing.T, p *plugin.Plugin) string {
+		t.Helper()
+
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "endpoint", &emptypb.Empty{}, output))
+		return output.GetValue()
+	}
+
+	t.Run("running plugins use new configuration", func(t *testing.T) {
+		p := plugintest.New(t, newConfig(true), plugin.WithConfiguration(wrapperspb.String("a")), plugin.WithInstances(2))
+		assert.EqualValues(t, "a", endpoint(t, p))
+
+		require.NoError(t, p.Configure(t.Context(), wrapperspb.String("b")))
+		for range 2 {
+			assert.EqualValues(t, "b", endpoint(t, p))
+		}
+
+		assert.Error(t, p.Configure(t.Context(), wrapperspb.String("invalid")))
+		assert.EqualValues(t, "b", endpoint(t, p))
+	})
+
+	t.Run("plugins started later use new configuration", func(t *testing.T) {
+		p := plugintest.New(t, newConfig(true), plugin.WithConfiguration(wrapperspb.String("a")), plugin.WithLazyStart())
+
+		require.NoError(t, p.Configure(t.Context(), wrapperspb.String("b")))
+		assert.EqualValues(t, "b", endpoint(t, p))
+	})
+
+	t.Run("plugins without OnConfigChange reject new configuration", func(t *testing.T) {
+		p := plugintest.New(t, newConfig(false), plugin.WithConfiguration(wrapperspb.String("a")))
+
+		assert.ErrorIs(t, p.Configure(t.Context(), wrapperspb.String("b")), plugin.ErrConfigurationUnsupported)
+		assert.EqualValues(t, "a", endpoint(t, p))
+	})
+
+	t.Run("manager configures named plugin", func(t *testing.T) {
+		p := plugintest.New(t, newConfig(true), plugin.WithConfiguration(wrapperspb.String("a")))
+
+		manager := plugin.NewManager()
+		require.NoError(t, manager.Add(p))
+
+		require.NoError(t, manager.Configure(t.Context(), "configured", wrapperspb.String("b")))
+		assert.EqualValues(t, "b", endpoint(t, p))
+
+		err := manager.Configure(t.Context(), "missing", wrapperspb.String("b"))
+		assert.ErrorIs(t, err, plugin.ErrNoPlugin)
+	})
+}
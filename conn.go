@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+type (
+	// conn is a grpc.ClientConnInterface that sends each call to one of the plugin's processes, chosen in the same
+	// way as for Plugin.Exec.
+	conn struct {
+		plugin *Plugin
+	}
+
+	// heldStream is a grpc.ClientStream that keeps its process in use until the stream has ended.
+	heldStream struct {
+		grpc.ClientStream
+		once     sync.Once
+		release  func()
+		finished chan struct{}
+	}
+)
+
+// Conn returns a gRPC connection to the plugin, which can be used to create clients for any additional services
+// registered using Config.RegisterServices, or to call the plugin API directly, such as to perform custom health
+// checks. This allows plugins to expose strongly-typed APIs where the Any-based Plugin.Exec is too generic.
+// Interceptors can be attached to the connection using WithDialOptions. If the plugin was used with WithInstances,
+// each call is sent to one of its processes, which are started if they are not running. Calls made using the
+// connection are not retried, observed or subject to any CircuitBreaker.
+func (p *Plugin) Conn() grpc.ClientConnInterface {
+	return &conn{plugin: p}
+}
+
+func (c *conn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	proc, release, err := c.plugin.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+	return proc.client.Conn().Invoke(ctx, method, args, reply, opts...)
+}
+
+func (c *conn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	proc, release, err := c.plugin.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := proc.client.Conn().NewStream(ctx, desc, method, opts...)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	held := &heldStream{ClientStream: stream, release: release, finished: make(chan struct{})}
+
+	// Streams end once RecvMsg returns an error, or once their context is cancelled.
+	go func() {
+		select {
+		case <-ctx.Done():
+			held.done()
+		case <-held.finished:
+		}
+	}()
+
+	return held, nil
+}
+
+func (s *heldStream) RecvMsg(m any) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.done()
+	}
+
+	return err
+}
+
+func (s *heldStream) done() {
+	s.once.Do(func() {
+		close(s.finished)
+		s.release()
+	})
+}
@@ -0,0 +1,93 @@
+package plugin_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestPlugin_Conn(t *testing.T) {
+	t.Parallel()
+
+	server := health.NewServer()
+	server.SetServingStatus("custom", healthpb.HealthCheckResponse_SERVING)
+
+	p := plugintest.New(t, plugin.Config{
+		Name: "services",
+		RegisterServices: func(s grpc.ServiceRegistrar) {
+			healthpb.RegisterHealthServer(s, server)
+		},
+	})
+
+	client := healthpb.NewHealthClient(p.Conn())
+
+	t.Run("calls unary methods of custom services", func(t *testing.T) {
+		response, err := client.Check(t.Context(), &healthpb.HealthCheckRequest{Service: "custom"})
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, response.GetStatus())
+	})
+
+	t.Run("calls streaming methods of custom services", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		defer cancel()
+
+		stream, err := client.Watch(ctx, &healthpb.HealthCheckRequest{Service: "custom"})
+		require.NoError(t, err)
+
+		response, err := stream.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, healthpb.HealthCheckResponse_SERVING, response.GetStatus())
+	})
+}
+
+func TestUse_WithDialOptions(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		methods []string
+	)
+
+	interceptor := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		mu.Lock()
+		methods = append(methods, method)
+		mu.Unlock()
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+
+	p := plugintest.New(t, plugin.Config{
+		Name: "intercepted",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{
+				Use: "noop",
+				Run: func(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+					return &emptypb.Empty{}, nil
+				},
+			},
+		},
+		RegisterServices: func(s grpc.ServiceRegistrar) {
+			healthpb.RegisterHealthServer(s, health.NewServer())
+		},
+	}, plugin.WithDialOptions(grpc.WithChainUnaryInterceptor(interceptor)))
+
+	require.NoError(t, p.Exec(t.Context(), "noop", &emptypb.Empty{}, &emptypb.Empty{}))
+
+	_, err := healthpb.NewHealthClient(p.Conn()).Check(t.Context(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Contains(t, methods, "/plugin.PluginService/Execute")
+	assert.Contains(t, methods, "/grpc.health.v1.Health/Check")
+}
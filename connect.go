@@ -0,0 +1,100 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+// Connect to a plugin that has already been started by another process, such as an orchestrator that manages plugin
+// processes itself, rather than executing its binary. The address is either the path to the plugin's UNIX domain
+// socket, optionally prefixed with "unix://", or a TCP address of the form "host:port". Once connected, the plugin is
+// queried for its name, version and available commands.
+//
+// Plugins authenticate their host application using the token within their PLUGIN_TOKEN environment variable, the
+// same token must be provided using WithToken. Plugins started without a token accept calls from any host.
+//
+// Options relating to the plugin process or binary, such as WithChecksum or WithEnv, have no effect, and the
+// WithLazyStart option is ignored as the name of the plugin is not known until it has been connected to. Memory can
+// only be shared using WithSharedMemory with plugins connected to via their UNIX domain socket.
+//
+// If successful, it is up to the caller to eventually call Plugin.Close when they no longer require use of the plugin.
+// Closing the Plugin closes the connection to the plugin, but does not stop it unless its process was identified using
+// WithPID. Plugins using Config.VerifyPeer only accept connections from the process that started them.
+func Connect(ctx context.Context, address string, opts ...UseOption) (*Plugin, error) {
+	return use(ctx, &Plugin{address: address}, opts...)
+}
+
+// startConnection connects to the plugin at the address given to Connect. The returned process represents the
+// connection, exiting only when it is closed.
+func (p *Plugin) startConnection(ctx context.Context) (*process, plugin.Info, error) {
+	proc := &process{
+		command:     &exec.Cmd{},
+		clock:       p.options.clock,
+		gracePeriod: p.options.shutdownGracePeriod,
+		exited:      make(chan struct{}),
+		logger:      p.diagnostics(),
+		stderr:      newTailBuffer(stderrTailSize),
+		token:       p.options.token,
+	}
+
+	proc.stop = sync.OnceFunc(func() {
+		close(proc.exited)
+	})
+
+	proc.logger.Debug("connecting to plugin", "address", p.address)
+	options := p.clientOptions(p.options.token)
+
+	target, unix := strings.CutPrefix(p.address, "unix://")
+	if _, _, err := net.SplitHostPort(target); !unix && err == nil && !strings.Contains(target, "/") {
+		options = append(options, plugin.WithDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "tcp", address)
+		}))
+
+		// The address is given to the dialer as the endpoint of the target, which follows an empty authority.
+		target = "/" + target
+	} else {
+		proc.socket = target
+		proc.memory = sharedMemorySocketPath(target)
+	}
+
+	var err error
+	proc.client, err = plugin.NewClient(target, options...)
+	if err != nil {
+		return nil, plugin.Info{}, fmt.Errorf("failed to dial plugin at %q: %w", p.address, err)
+	}
+
+	info, err := proc.client.Stat(ctx)
+	if err != nil {
+		return nil, plugin.Info{}, errors.Join(fmt.Errorf("failed to connect to plugin at %q: %w", p.address, err), proc.close())
+	}
+
+	// The name is only known once the plugin has first been connected to, which happens before Connect returns. A
+	// plugin connected to again, such as after an idle timeout, must be the same plugin.
+	switch {
+	case p.name == "":
+		p.name = info.Name
+	case info.Name != p.name:
+		return nil, plugin.Info{}, errors.Join(fmt.Errorf("%w: expected %q, got %q", ErrUnexpectedName, p.name, info.Name), proc.close())
+	}
+
+	if err = checkVersion(p.name, info.Version, p.options.versionConstraint); err != nil {
+		return nil, plugin.Info{}, errors.Join(err, proc.close())
+	}
+
+	// The process is only attached to once connected, so that a plugin that cannot be used is not stopped.
+	if p.options.pid > 0 {
+		if err = p.attach(ctx, proc); err != nil {
+			return nil, plugin.Info{}, errors.Join(fmt.Errorf("failed to attach to plugin at %q: %w", p.address, err), proc.close())
+		}
+	}
+
+	return proc, info, nil
+}
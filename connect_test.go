@@ -0,0 +1,224 @@
+package plugin_test
+
+import (
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestConnect(t *testing.T) {
+	t.Parallel()
+
+	// The plugin is started as an orchestrator would, rather than by Use.
+	directory := t.TempDir()
+	cmd := exec.Command("./test_plugin", "--socket-dir", directory, "external")
+	cmd.Env = append(os.Environ(), "PLUGIN_TOKEN=secret")
+	require.NoError(t, cmd.Start())
+	t.Cleanup(func() {
+		assert.NoError(t, cmd.Process.Kill())
+		_ = cmd.Wait()
+	})
+
+	socket := filepath.Join(directory, "external.sock")
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(socket)
+		return err == nil
+	}, time.Second*5, time.Millisecond*10)
+
+	// The plugin is also made available over TCP, via a proxy to its socket.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, listener.Close())
+	})
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			upstream, err := net.Dial("unix", socket)
+			if err != nil {
+				_ = conn.Close()
+				continue
+			}
+
+			go func() {
+				_, _ = io.Copy(upstream, conn)
+				_ = upstream.Close()
+			}()
+			go func() {
+				_, _ = io.Copy(conn, upstream)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	tt := []struct {
+		Name    string
+		Address string
+	}{
+		{
+			Name:    "connects to a socket path",
+			Address: socket,
+		},
+		{
+			Name:    "connects to a unix address",
+			Address: "unix://" + socket,
+		},
+		{
+			Name:    "connects to a tcp address",
+			Address: listener.Addr().String(),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			p, err := plugin.Connect(t.Context(), tc.Address, plugin.WithToken("secret"))
+			require.NoError(t, err)
+
+			assert.EqualValues(t, "test_plugin", p.Name())
+			assert.EqualValues(t, "v1.2.3", p.Version())
+
+			output := &wrapperspb.StringValue{}
+			require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+			assert.EqualValues(t, "pong", output.GetValue())
+
+			// Closing the Plugin leaves the plugin process running.
+			require.NoError(t, p.Close())
+			assert.NoError(t, cmd.Process.Signal(syscall.Signal(0)))
+		})
+	}
+
+	t.Run("error for an invalid token", func(t *testing.T) {
+		_, err := plugin.Connect(t.Context(), socket, plugin.WithToken("invalid"))
+		assert.EqualValues(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestConnect_WithPID(t *testing.T) {
+	t.Parallel()
+
+	t.Run("stops the attached process when closed", func(t *testing.T) {
+		directory := t.TempDir()
+		cmd := exec.Command("./test_plugin", "--socket-dir", directory, "attached")
+		require.NoError(t, cmd.Start())
+
+		// The process is reaped as soon as it exits, as it would be once reparented after the host application exits.
+		exited := make(chan struct{})
+		go func() {
+			_ = cmd.Wait()
+			close(exited)
+		}()
+
+		socket := filepath.Join(directory, "attached.sock")
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socket)
+			return err == nil
+		}, time.Second*5, time.Millisecond*10)
+
+		p, err := plugin.Connect(t.Context(), socket, plugin.WithPID(cmd.Process.Pid))
+		require.NoError(t, err)
+		require.Len(t, p.Processes(), 1)
+		assert.EqualValues(t, plugin.ProcessInfo{PID: cmd.Process.Pid, Socket: socket}, p.Processes()[0])
+
+		require.NoError(t, p.Close())
+		<-exited
+		require.Len(t, p.Exits(), 1)
+		assert.EqualValues(t, cmd.Process.Pid, p.Exits()[0].PID)
+		assert.True(t, cmd.ProcessState.Success())
+	})
+
+	t.Run("attaches to processes started by Use", func(t *testing.T) {
+		started, err := plugin.Use(t.Context(), "./test_plugin")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, started.Close())
+		})
+
+		processes := started.Processes()
+		require.Len(t, processes, 1)
+
+		p, err := plugin.Connect(t.Context(), processes[0].Socket,
+			plugin.WithToken(processes[0].Token),
+			plugin.WithPID(processes[0].PID),
+		)
+		require.NoError(t, err)
+
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+		assert.EqualValues(t, "pong", output.GetValue())
+
+		require.NoError(t, p.Close())
+		require.Eventually(t, func() bool {
+			return len(started.Exits()) == 1
+		}, time.Second*5, time.Millisecond*10)
+	})
+
+	t.Run("error if the process is not that of the plugin", func(t *testing.T) {
+		directory := t.TempDir()
+		cmd := exec.Command("./test_plugin", "--socket-dir", directory, "reused")
+		require.NoError(t, cmd.Start())
+		t.Cleanup(func() {
+			assert.NoError(t, cmd.Process.Kill())
+			_ = cmd.Wait()
+		})
+
+		socket := filepath.Join(directory, "reused.sock")
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socket)
+			return err == nil
+		}, time.Second*5, time.Millisecond*10)
+
+		// Another process standing in for one that has reused the process identifier of the plugin.
+		other := exec.Command("sleep", "10")
+		require.NoError(t, other.Start())
+		t.Cleanup(func() {
+			assert.NoError(t, other.Process.Kill())
+			_ = other.Wait()
+		})
+
+		_, err := plugin.Connect(t.Context(), socket, plugin.WithPID(other.Process.Pid))
+		assert.ErrorIs(t, err, plugin.ErrUnexpectedPID)
+
+		// The other process is left running.
+		assert.NoError(t, other.Process.Signal(syscall.Signal(0)))
+	})
+
+	t.Run("error if the process does not exist", func(t *testing.T) {
+		directory := t.TempDir()
+		cmd := exec.Command("./test_plugin", "--socket-dir", directory, "exited")
+		require.NoError(t, cmd.Start())
+		t.Cleanup(func() {
+			assert.NoError(t, cmd.Process.Kill())
+			_ = cmd.Wait()
+		})
+
+		socket := filepath.Join(directory, "exited.sock")
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socket)
+			return err == nil
+		}, time.Second*5, time.Millisecond*10)
+
+		finished := exec.Command("true")
+		require.NoError(t, finished.Run())
+
+		_, err := plugin.Connect(t.Context(), socket, plugin.WithPID(finished.Process.Pid))
+		assert.ErrorIs(t, err, os.ErrProcessDone)
+	})
+}
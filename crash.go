@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// The CrashError type is the error returned by Plugin.Exec when the plugin process exits unexpectedly while
+	// executing a command. It matches ErrPluginCrashed when using errors.Is. Once a crash has been detected, the
+	// plugin is started again the next time it is required.
+	CrashError struct {
+		// The name of the plugin that crashed.
+		Plugin string
+		// The ExitStatus of the plugin process.
+		Status ExitStatus
+		// The final output written by the plugin process to stderr, limited to the last few kilobytes.
+		Stderr string
+	}
+)
+
+// ErrPluginCrashed is the error given when a plugin process exits unexpectedly while executing a command. The error is
+// always a *CrashError that can be obtained using errors.As.
+var ErrPluginCrashed = errors.New("plugin crashed")
+
+// crashDetectionTimeout is how long to wait for a plugin process to exit after a call fails because the plugin was
+// unavailable. The connection is usually closed before the exit of the process is observed.
+const crashDetectionTimeout = 100 * time.Millisecond
+
+// Error returns a description of how the plugin process exited.
+func (e *CrashError) Error() string {
+	return fmt.Sprintf("%s: plugin %q %s", ErrPluginCrashed, e.Plugin, e.Status)
+}
+
+// Unwrap returns ErrPluginCrashed.
+func (e *CrashError) Unwrap() error {
+	return ErrPluginCrashed
+}
+
+// GRPCStatus returns a status with the codes.Unavailable code, so that a RetryPolicy or Manager.ExecAny treat a
+// crashed plugin in the same way as one that cannot be reached.
+func (e *CrashError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.Error())
+}
+
+// detectCrash returns a *CrashError if the provided error was caused by the plugin process exiting unexpectedly,
+// otherwise it returns the error unchanged. When a crash is detected, the running plugin processes are discarded so
+// that they are started again the next time they are required.
+func (p *Plugin) detectCrash(proc *process, err error) error {
+	if status.Code(err) != codes.Unavailable {
+		return err
+	}
+
+	select {
+	case <-proc.exited:
+	case <-p.options.clock.After(crashDetectionTimeout):
+		return err
+	}
+
+	if proc.closing.Load() {
+		return err
+	}
+
+	p.discard(proc)
+	return &CrashError{
+		Plugin: p.name,
+		Status: proc.status,
+		Stderr: proc.stderr.String(),
+	}
+}
+
+// discard stops the running plugin processes if they include the provided process.
+func (p *Plugin) discard(proc *process) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pool == nil || !slices.Contains(p.pool.processes, proc) {
+		return
+	}
+
+	pl := p.pool
+	p.pool = nil
+	go func() {
+		_ = pl.close()
+	}()
+}
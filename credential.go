@@ -0,0 +1,42 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+type (
+	// The Credential type describes the user and groups a plugin process is run as, allowing untrusted plugins to be
+	// run using an unprivileged account.
+	Credential struct {
+		// The user identifier the plugin process is run as.
+		UID int
+		// The group identifier the plugin process is run as.
+		GID int
+		// Any supplementary group identifiers given to the plugin process. If empty, the process has no
+		// supplementary groups.
+		Groups []int
+	}
+)
+
+// ErrCredentialUnsupported is the error given by Use when the WithCredential option is used on a platform that does
+// not support running processes as a different user.
+var ErrCredentialUnsupported = errors.New("running plugins as a different user is not supported on this platform")
+
+// socketDirectory creates a directory within the parent directory, owned by the user and group of the Credential,
+// for a plugin process running as that user to create its sockets within. Only the plugin user and the host
+// application, which must be privileged in order to start processes as another user, can access the directory.
+func (c Credential) socketDirectory(parent, id string) (string, error) {
+	directory := filepath.Join(parent, id)
+	if err := os.Mkdir(directory, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if err := os.Chown(directory, c.UID, c.GID); err != nil {
+		return "", errors.Join(fmt.Errorf("failed to change owner of socket directory: %w", err), os.Remove(directory))
+	}
+
+	return directory, nil
+}
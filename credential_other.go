@@ -0,0 +1,11 @@
+//go:build !unix
+
+package plugin
+
+import (
+	"os/exec"
+)
+
+const credentialSupported = false
+
+func (Credential) apply(*exec.Cmd) {}
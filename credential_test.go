@@ -0,0 +1,72 @@
+//go:build linux
+
+package plugin_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestUse_WithCredential(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("running plugins as another user requires root")
+	}
+
+	const nobody = 65534
+
+	// The plugin user cannot access the module directory or those given by t.TempDir, so the binary is copied
+	// somewhere it can be executed.
+	binary, err := os.ReadFile("./test_plugin")
+	require.NoError(t, err)
+
+	directory := publicTempDir(t)
+	path := filepath.Join(directory, "test_plugin")
+	require.NoError(t, os.WriteFile(path, binary, 0o755))
+
+	socketDirectory := publicTempDir(t)
+	p, err := plugin.Use(t.Context(), path,
+		plugin.WithCredential(plugin.Credential{UID: nobody, GID: nobody}),
+		plugin.WithSocketDirectory(socketDirectory),
+	)
+	require.NoError(t, err)
+
+	output := &wrapperspb.Int64Value{}
+	require.NoError(t, p.Exec(t.Context(), "pid", &emptypb.Empty{}, output))
+
+	info, err := os.Stat(filepath.Join("/proc", strconv.FormatInt(output.GetValue(), 10)))
+	require.NoError(t, err)
+
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	require.True(t, ok)
+	assert.EqualValues(t, nobody, stat.Uid)
+	assert.EqualValues(t, nobody, stat.Gid)
+
+	require.NoError(t, p.Close())
+
+	entries, err := os.ReadDir(socketDirectory)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "socket directory should be removed once the plugin exits")
+}
+
+func publicTempDir(t *testing.T) string {
+	t.Helper()
+
+	directory, err := os.MkdirTemp("", "plugin-test-")
+	require.NoError(t, err)
+	require.NoError(t, os.Chmod(directory, 0o755))
+	t.Cleanup(func() {
+		assert.NoError(t, os.RemoveAll(directory))
+	})
+
+	return directory
+}
@@ -0,0 +1,28 @@
+//go:build unix
+
+package plugin
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+const credentialSupported = true
+
+// apply sets the Credential used to start the command.
+func (c Credential) apply(cmd *exec.Cmd) {
+	groups := make([]uint32, len(c.Groups))
+	for i, group := range c.Groups {
+		groups[i] = uint32(group)
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    uint32(c.UID),
+		Gid:    uint32(c.GID),
+		Groups: groups,
+	}
+}
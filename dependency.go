@@ -0,0 +1,165 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+)
+
+type (
+	// The Definition type describes a plugin to be started by Manager.Start.
+	Definition struct {
+		// The Path to the plugin binary. The base of the path is used as the name of the plugin.
+		Path string
+		// The names of any plugins that must be running before this plugin is started. Dependencies may be other
+		// plugins provided to the same call to Manager.Start or plugins that have already been added to the Manager.
+		DependsOn []string
+		// The Priority of the plugin when selecting plugins via Manager.ExecAny. See WithPriority for more details.
+		Priority int
+		// Any UseOptions to apply when starting the plugin.
+		Options []UseOption
+	}
+
+	// The FailurePolicy type determines how Manager.Start behaves when a plugin fails to start.
+	FailurePolicy int
+)
+
+const (
+	// FailAll is a FailurePolicy that closes all plugins started by Manager.Start as soon as any plugin fails to
+	// start.
+	FailAll FailurePolicy = iota
+	// SkipDependents is a FailurePolicy that continues to start plugins when one fails to start, skipping only those
+	// that depend on it, directly or transitively.
+	SkipDependents
+)
+
+var (
+	// ErrUnknownDependency is the error returned by Manager.Start when a plugin depends on another plugin that is
+	// neither defined nor already managed.
+	ErrUnknownDependency = errors.New("unknown dependency")
+
+	// ErrDependencyCycle is the error returned by Manager.Start when the dependencies between plugins form a cycle.
+	ErrDependencyCycle = errors.New("dependency cycle")
+
+	// ErrDependencyFailed is the error returned by Manager.Start when using the SkipDependents FailurePolicy for
+	// each plugin that was not started because one of its dependencies failed to start.
+	ErrDependencyFailed = errors.New("dependency failed")
+)
+
+func (d Definition) name() string {
+	return filepath.Base(d.Path)
+}
+
+// Start the plugins described by the provided definitions and add them to the Manager. Plugins are started in order
+// of their dependencies, such that a plugin is only started once all the plugins it depends on are running. Plugins
+// whose dependencies are satisfied at the same time are started in the order they are defined.
+//
+// Returns ErrUnknownDependency if a plugin depends on one that is neither defined nor already managed, and
+// ErrDependencyCycle if the dependencies contain a cycle. In both cases no plugins are started. The provided
+// FailurePolicy determines behaviour when a plugin fails to start.
+func (m *Manager) Start(ctx context.Context, policy FailurePolicy, definitions ...Definition) error {
+	order, err := m.sortDefinitions(definitions)
+	if err != nil {
+		return err
+	}
+
+	var (
+		errs    error
+		started []*Plugin
+		failed  = make(map[string]bool)
+	)
+
+	for _, definition := range order {
+		name := definition.name()
+
+		dependency := slices.IndexFunc(definition.DependsOn, func(dependency string) bool {
+			return failed[dependency]
+		})
+
+		if dependency >= 0 {
+			failed[name] = true
+			errs = errors.Join(errs, fmt.Errorf("%w: plugin %q depends on %q", ErrDependencyFailed, name, definition.DependsOn[dependency]))
+			continue
+		}
+
+		p, err := m.start(ctx, definition)
+		if err == nil {
+			started = append(started, p)
+			continue
+		}
+
+		err = fmt.Errorf("failed to start plugin %q: %w", name, err)
+		if policy == FailAll {
+			for _, p = range slices.Backward(started) {
+				m.remove(p)
+				err = errors.Join(err, p.Close())
+			}
+
+			return err
+		}
+
+		failed[name] = true
+		errs = errors.Join(errs, err)
+	}
+
+	return errs
+}
+
+func (m *Manager) start(ctx context.Context, definition Definition) (*Plugin, error) {
+	p, err := Use(ctx, definition.Path, definition.Options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = m.Add(p, WithPriority(definition.Priority)); err != nil {
+		return nil, errors.Join(err, p.Close())
+	}
+
+	return p, nil
+}
+
+func (m *Manager) sortDefinitions(definitions []Definition) ([]Definition, error) {
+	defined := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		defined[definition.name()] = true
+	}
+
+	for _, definition := range definitions {
+		for _, dependency := range definition.DependsOn {
+			if defined[dependency] {
+				continue
+			}
+
+			if _, ok := m.Get(dependency); ok {
+				continue
+			}
+
+			return nil, fmt.Errorf("%w: plugin %q depends on %q", ErrUnknownDependency, definition.name(), dependency)
+		}
+	}
+
+	order := make([]Definition, 0, len(definitions))
+	visited := make(map[string]bool, len(definitions))
+	for len(order) < len(definitions) {
+		ready := slices.IndexFunc(definitions, func(definition Definition) bool {
+			if visited[definition.name()] {
+				return false
+			}
+
+			return !slices.ContainsFunc(definition.DependsOn, func(dependency string) bool {
+				return defined[dependency] && !visited[dependency]
+			})
+		})
+
+		if ready < 0 {
+			return nil, ErrDependencyCycle
+		}
+
+		visited[definitions[ready].name()] = true
+		order = append(order, definitions[ready])
+	}
+
+	return order, nil
+}
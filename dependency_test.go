@@ -0,0 +1,89 @@
+package plugin_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestManager_Start(t *testing.T) {
+	t.Run("starts plugins in dependency order", func(t *testing.T) {
+		manager := plugin.NewManager()
+		t.Cleanup(func() {
+			assert.NoError(t, manager.Close())
+		})
+
+		err := manager.Start(t.Context(), plugin.FailAll,
+			plugin.Definition{Path: copyPlugin(t, "c_plugin"), DependsOn: []string{"b_plugin"}},
+			plugin.Definition{Path: copyPlugin(t, "b_plugin"), DependsOn: []string{"a_plugin"}},
+			plugin.Definition{Path: copyPlugin(t, "a_plugin")},
+		)
+		require.NoError(t, err)
+
+		names := make([]string, 0)
+		for _, p := range manager.Plugins() {
+			names = append(names, p.Name())
+		}
+
+		assert.Equal(t, []string{"a_plugin", "b_plugin", "c_plugin"}, names)
+	})
+
+	t.Run("error if dependency is unknown", func(t *testing.T) {
+		manager := plugin.NewManager()
+		err := manager.Start(t.Context(), plugin.FailAll,
+			plugin.Definition{Path: "./test_plugin", DependsOn: []string{"unknown"}},
+		)
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plugin.ErrUnknownDependency))
+		assert.Empty(t, manager.Plugins())
+	})
+
+	t.Run("error if dependencies form a cycle", func(t *testing.T) {
+		manager := plugin.NewManager()
+		err := manager.Start(t.Context(), plugin.FailAll,
+			plugin.Definition{Path: "./a_plugin", DependsOn: []string{"b_plugin"}},
+			plugin.Definition{Path: "./b_plugin", DependsOn: []string{"a_plugin"}},
+		)
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plugin.ErrDependencyCycle))
+		assert.Empty(t, manager.Plugins())
+	})
+
+	t.Run("closes started plugins on failure", func(t *testing.T) {
+		manager := plugin.NewManager()
+		err := manager.Start(t.Context(), plugin.FailAll,
+			plugin.Definition{Path: "./test_plugin"},
+			plugin.Definition{Path: filepath.Join(t.TempDir(), "missing_plugin"), DependsOn: []string{"test_plugin"}},
+		)
+
+		require.Error(t, err)
+		assert.Empty(t, manager.Plugins())
+	})
+
+	t.Run("skips dependents on failure", func(t *testing.T) {
+		manager := plugin.NewManager()
+		t.Cleanup(func() {
+			assert.NoError(t, manager.Close())
+		})
+
+		err := manager.Start(t.Context(), plugin.SkipDependents,
+			plugin.Definition{Path: filepath.Join(t.TempDir(), "missing_plugin")},
+			plugin.Definition{Path: copyPlugin(t, "dependent_plugin"), DependsOn: []string{"missing_plugin"}},
+			plugin.Definition{Path: "./test_plugin"},
+		)
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plugin.ErrDependencyFailed))
+
+		if assert.Len(t, manager.Plugins(), 1) {
+			assert.EqualValues(t, "test_plugin", manager.Plugins()[0].Name())
+		}
+	})
+}
@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// typeURLPrefix is the prefix used for the type URLs of messages packed into the protobuf Any type.
+const typeURLPrefix = "type.googleapis.com/"
+
+type (
+	description struct {
+		Name     string               `json:"name"`
+		Version  string               `json:"version"`
+		Commands []commandDescription `json:"commands"`
+	}
+
+	commandDescription struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+		Example     string `json:"example,omitempty"`
+		Deprecated  bool   `json:"deprecated,omitempty"`
+		Idempotent  bool   `json:"idempotent,omitempty"`
+		Input       string `json:"input,omitempty"`
+		Output      string `json:"output,omitempty"`
+	}
+
+	// The CommandDescriptor type describes the messages used by a command, as returned by Plugin.Describe. The
+	// descriptors can be used to construct and validate messages without compiling the plugin's protobuf definitions,
+	// for example using the dynamicpb package.
+	CommandDescriptor struct {
+		// The Name of the command.
+		Name string
+		// The Input message of the command. This is nil if the plugin does not describe the command's input.
+		Input protoreflect.MessageDescriptor
+		// The Output message of the command. This is nil if the plugin does not describe the command's output.
+		Output protoreflect.MessageDescriptor
+	}
+)
+
+// Describe queries the plugin for the input and output messages of each command it provides.
+func (p *Plugin) Describe(ctx context.Context) ([]CommandDescriptor, error) {
+	proc, release, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer release()
+
+	commands, err := proc.client.Describe(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	descriptors := make([]CommandDescriptor, 0, len(commands))
+	for _, command := range commands {
+		descriptors = append(descriptors, CommandDescriptor{
+			Name:   command.Name,
+			Input:  command.Input,
+			Output: command.Output,
+		})
+	}
+
+	return descriptors, nil
+}
+
+func describeCommand(config Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe",
+		Short: "Describes the plugin as JSON",
+		Long:  "Prints the plugin's name, version and the commands it provides, including the type URLs of their input and output messages, as JSON. The plugin is not started.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d := description{
+				Name:     config.Name,
+				Version:  getPluginVersion(config),
+				Commands: make([]commandDescription, 0, len(config.Commands)),
+			}
+
+			for _, command := range config.Commands {
+				described := describe(command)
+				d.Commands = append(d.Commands, commandDescription{
+					Name:        described.Name,
+					Description: described.Description,
+					Example:     described.Example,
+					Deprecated:  described.Deprecated,
+					Idempotent:  described.Idempotent,
+					Input:       typeURL(described.Input),
+					Output:      typeURL(described.Output),
+				})
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(d)
+		},
+	}
+}
+
+func typeURL(descriptor protoreflect.MessageDescriptor) string {
+	if descriptor == nil {
+		return ""
+	}
+
+	return typeURLPrefix + string(descriptor.FullName())
+}
+
+// newMessage returns a new, empty instance of the message type T.
+func newMessage[T protoreflect.ProtoMessage]() T {
+	var message T
+	return message.ProtoReflect().New().Interface().(T)
+}
+
+// messageDescriptor returns the descriptor of the message type T.
+func messageDescriptor[T protoreflect.ProtoMessage]() protoreflect.MessageDescriptor {
+	var message T
+	return message.ProtoReflect().Descriptor()
+}
@@ -0,0 +1,45 @@
+package plugin
+
+import (
+	"log/slog"
+	"os"
+)
+
+// WithDiagnosticLogger is a UseOption that sets the logger the package writes its own diagnostics to, describing the
+// lifecycle of the plugin's processes, such as when they are started, dialled, retried, stopped and exit. Records are
+// logged at the debug level, and at the info or warn level for events that usually need attention, such as a process
+// exiting unexpectedly. By default, diagnostics are discarded. This is unrelated to WithLogger, which receives the
+// logs written by the plugin itself.
+func WithDiagnosticLogger(logger *slog.Logger) UseOption {
+	return func(o *useOptions) {
+		o.diagnostics = logger
+	}
+}
+
+// diagnostics returns the logger given via WithDiagnosticLogger, with a "plugin" attribute naming the plugin.
+func (p *Plugin) diagnostics() *slog.Logger {
+	return diagnosticLogger(p.options.diagnostics, p.name)
+}
+
+// diagnostics returns the logger given by Config.DiagnosticLogger, with a "plugin" attribute naming the plugin.
+func (c Config) diagnostics() *slog.Logger {
+	return diagnosticLogger(c.DiagnosticLogger, c.Name)
+}
+
+// requestLogger returns the logger that commands are logged to when Config.LogRequests is true. Unlike other
+// diagnostics, these are written to stderr when no logger is set, as they have been explicitly requested.
+func (c Config) requestLogger() *slog.Logger {
+	if c.DiagnosticLogger == nil {
+		return slog.New(slog.NewTextHandler(os.Stderr, nil)).With("plugin", c.Name)
+	}
+
+	return c.diagnostics()
+}
+
+func diagnosticLogger(logger *slog.Logger, name string) *slog.Logger {
+	if logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+
+	return logger.With("plugin", name)
+}
@@ -0,0 +1,164 @@
+package plugin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+type (
+	recordBuffer struct {
+		mu  sync.Mutex
+		buf bytes.Buffer
+	}
+)
+
+func (b *recordBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *recordBuffer) bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return bytes.Clone(b.buf.Bytes())
+}
+
+// messages returns the messages of the records written to the buffer, in the order they were logged.
+func (b *recordBuffer) messages(t *testing.T) []string {
+	t.Helper()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var messages []string
+	decoder := json.NewDecoder(bytes.NewReader(b.buf.Bytes()))
+	for decoder.More() {
+		var record struct {
+			Message string `json:"msg"`
+			Plugin  string `json:"plugin"`
+		}
+
+		require.NoError(t, decoder.Decode(&record))
+		assert.NotEmpty(t, record.Plugin)
+		messages = append(messages, record.Message)
+	}
+
+	return messages
+}
+
+func newRecordLogger(buf *recordBuffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestWithDiagnosticLogger(t *testing.T) {
+	t.Parallel()
+
+	records := &recordBuffer{}
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithDiagnosticLogger(newRecordLogger(records)))
+	require.NoError(t, err)
+	require.NoError(t, p.Close())
+
+	messages := records.messages(t)
+	for _, expected := range []string{
+		"starting plugin process",
+		"started plugin process",
+		"dialing plugin",
+		"plugin socket ready",
+		"completed handshake",
+		"stopping plugin process",
+		"plugin process exited",
+	} {
+		assert.Contains(t, messages, expected)
+	}
+
+	assert.NotContains(t, messages, "plugin process exited unexpectedly")
+}
+
+func TestConfig_DiagnosticLogger(t *testing.T) {
+	t.Parallel()
+
+	records := &recordBuffer{}
+	p := plugintest.New(t, plugin.Config{
+		Name:             "diagnostics",
+		DiagnosticLogger: newRecordLogger(records),
+	})
+	require.NoError(t, p.Close())
+
+	messages := records.messages(t)
+	assert.Contains(t, messages, "plugin listening")
+	assert.Contains(t, messages, "plugin shutting down")
+}
+
+func TestConfig_LogRequests(t *testing.T) {
+	t.Parallel()
+
+	records := &recordBuffer{}
+	p := plugintest.New(t, plugin.Config{
+		Name:             "request_log",
+		DiagnosticLogger: newRecordLogger(records),
+		LogRequests:      true,
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "echo",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					if input.GetValue() == "fail" {
+						return nil, status.Error(codes.InvalidArgument, "invalid input")
+					}
+
+					return input, nil
+				},
+			},
+		},
+	})
+
+	require.NoError(t, p.Exec(t.Context(), "echo", wrapperspb.String("hello"), &wrapperspb.StringValue{}))
+	require.Error(t, p.Exec(t.Context(), "echo", wrapperspb.String("fail"), &wrapperspb.StringValue{}))
+
+	type Record struct {
+		Message      string `json:"msg"`
+		Level        string `json:"level"`
+		Command      string `json:"command"`
+		Code         string `json:"code"`
+		RequestSize  int    `json:"request_size"`
+		ResponseSize int    `json:"response_size"`
+		Error        string `json:"error"`
+	}
+
+	var logged []Record
+	for _, line := range bytes.Split(bytes.TrimSpace(records.bytes()), []byte("\n")) {
+		var record Record
+		require.NoError(t, json.Unmarshal(line, &record))
+		if record.Message == "executed command" {
+			logged = append(logged, record)
+		}
+	}
+
+	require.Len(t, logged, 2)
+
+	assert.EqualValues(t, "INFO", logged[0].Level)
+	assert.EqualValues(t, "echo", logged[0].Command)
+	assert.EqualValues(t, codes.OK.String(), logged[0].Code)
+	assert.Positive(t, logged[0].RequestSize)
+	assert.Positive(t, logged[0].ResponseSize)
+
+	assert.EqualValues(t, "WARN", logged[1].Level)
+	assert.EqualValues(t, codes.InvalidArgument.String(), logged[1].Code)
+	assert.EqualValues(t, "invalid input", logged[1].Error)
+	assert.Zero(t, logged[1].ResponseSize)
+}
@@ -0,0 +1,302 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The Egress type describes how the host application makes HTTP requests on behalf of a plugin, as given to
+	// WithEgress. Plugins make requests using the client returned by HTTPClientFromContext.
+	Egress struct {
+		// The Client used to make requests. Defaults to http.DefaultClient. Redirects are only followed to hosts
+		// within AllowedHosts, and requests redirected elsewhere fail with ErrEgressDenied.
+		Client *http.Client
+		// The AllowedHosts the plugin can make requests to, such as "api.example.com". Hosts beginning with "*." allow
+		// requests to any subdomain, and "*" allows requests to any host. Requests to other hosts are denied.
+		AllowedHosts []string
+		// Modify, if set, is called with each allowed request before it is made, such as to add credentials that the
+		// plugin should not hold. Returning an error denies the request.
+		Modify func(plugin string, request *http.Request) error
+		// Record, if set, is called once each request made by the plugin has completed or been denied, such as to
+		// audit the requests made by plugins.
+		Record func(record EgressRecord)
+	}
+
+	// The EgressRecord type describes an HTTP request made by a plugin via the host application, as given to
+	// Egress.Record.
+	EgressRecord struct {
+		// The name of the Plugin that made the request.
+		Plugin string
+		// The HTTP Method of the request.
+		Method string
+		// The URL of the request.
+		URL string
+		// The StatusCode of the response. This is zero if the request was denied or failed.
+		StatusCode int
+		// The number of bytes within the body of the response.
+		ResponseSize int
+		// How long the request took.
+		Duration time.Duration
+		// The Err describing why the request was denied or failed, if it was.
+		Err error
+	}
+
+	// The brokerTransport type is an http.RoundTripper that makes requests via the host application.
+	brokerTransport struct {
+		broker *plugin.Broker
+	}
+)
+
+var (
+	// ErrEgressDenied is the error returned when the host application does not allow an HTTP request made by a plugin,
+	// either as its host is not within Egress.AllowedHosts or due to Egress.Modify.
+	ErrEgressDenied = errors.New("egress denied")
+
+	// ErrNoEgress is the error returned by requests made using the client returned by HTTPClientFromContext when the
+	// host application has not allowed the plugin to make HTTP requests using WithEgress.
+	ErrNoEgress = errors.New("egress unavailable")
+)
+
+// WithEgress is a UseOption that allows the plugin to make HTTP requests via the host application, using the client
+// returned by HTTPClientFromContext. This allows the host application to restrict the hosts the plugin can reach,
+// add credentials to requests and record the requests made, which is typically combined with WithSandbox to prevent
+// the plugin from accessing the network itself. Request and response bodies are limited by the maximum message size.
+func WithEgress(egress Egress) UseOption {
+	return func(o *useOptions) {
+		o.egress = &egress
+	}
+}
+
+// HTTPClientFromContext returns an HTTP client that makes requests via the host application, within the context given
+// to a command or to Config.Configure. Requests fail with ErrNoEgress unless the host application allows egress using
+// WithEgress, and with ErrEgressDenied if the host application does not allow them.
+func HTTPClientFromContext(ctx context.Context) *http.Client {
+	return &http.Client{
+		Transport: brokerTransport{broker: plugin.BrokerFromContext(ctx)},
+	}
+}
+
+// RoundTrip makes the request via the host application.
+func (t brokerTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	var body []byte
+	if request.Body != nil {
+		var err error
+		body, err = io.ReadAll(request.Body)
+		_ = request.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	input, err := anypb.New(&pb.RoundTripRequest{
+		Method:  request.Method,
+		Url:     request.URL.String(),
+		Headers: encodeHeader(request.Header),
+		Body:    body,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := t.broker.Call(request.Context(), pb.HostService_RoundTrip_FullMethodName, input)
+	switch {
+	case errors.Is(err, plugin.ErrNoBroker), status.Code(err) == codes.Unimplemented:
+		return nil, fmt.Errorf("%w: %w", ErrNoEgress, err)
+	case status.Code(err) == codes.PermissionDenied:
+		return nil, fmt.Errorf("%w: %s", ErrEgressDenied, status.Convert(err).Message())
+	case err != nil:
+		return nil, err
+	}
+
+	response := &pb.RoundTripResponse{}
+	if err = output.UnmarshalTo(response); err != nil {
+		return nil, err
+	}
+
+	code := int(response.GetStatusCode())
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        decodeHeader(response.GetHeaders()),
+		Body:          io.NopCloser(bytes.NewReader(response.GetBody())),
+		ContentLength: int64(len(response.GetBody())),
+		Request:       request,
+	}, nil
+}
+
+// egressHandler returns the function that handles HTTP requests made by the plugin via HTTPClientFromContext, making
+// them as described by the Egress given via WithEgress.
+func (p *Plugin) egressHandler() func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+	egress := *p.options.egress
+	egress.Client = allowedRedirects(egress.Client, egress.AllowedHosts)
+
+	return func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+		request := &pb.RoundTripRequest{}
+		if err := input.UnmarshalTo(request); err != nil {
+			return nil, err
+		}
+
+		started := p.options.clock.Now()
+		record := EgressRecord{
+			Plugin: p.name,
+			Method: request.GetMethod(),
+			URL:    request.GetUrl(),
+		}
+
+		response, err := p.roundTrip(ctx, egress, request, &record)
+		if egress.Record != nil {
+			record.Duration = p.options.clock.Now().Sub(started)
+			record.Err = err
+			egress.Record(record)
+		}
+
+		if err != nil {
+			if errors.Is(err, ErrEgressDenied) {
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+
+		return anypb.New(response)
+	}
+}
+
+func (p *Plugin) roundTrip(ctx context.Context, egress Egress, r *pb.RoundTripRequest, record *EgressRecord) (*pb.RoundTripResponse, error) {
+	target, err := url.Parse(r.GetUrl())
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkEgress(egress.AllowedHosts, target); err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, r.GetMethod(), target.String(), bytes.NewReader(r.GetBody()))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header = decodeHeader(r.GetHeaders())
+	if egress.Modify != nil {
+		if err = egress.Modify(p.name, request); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrEgressDenied, err)
+		}
+	}
+
+	response, err := egress.Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	// The response is sent to the plugin as a single message, so cannot be larger than the maximum message size.
+	body, err := io.ReadAll(io.LimitReader(response.Body, int64(p.options.maxMessageSize)+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(body) > p.options.maxMessageSize {
+		return nil, fmt.Errorf("response body exceeds the maximum message size of %d bytes", p.options.maxMessageSize)
+	}
+
+	record.StatusCode = response.StatusCode
+	record.ResponseSize = len(body)
+
+	return &pb.RoundTripResponse{
+		StatusCode: int32(response.StatusCode),
+		Headers:    encodeHeader(response.Header),
+		Body:       body,
+	}, nil
+}
+
+// allowedRedirects returns a copy of the client that only follows redirects to the allowed hosts, so that an allowed
+// host cannot redirect the plugin to one that is not allowed. Redirects are otherwise followed as the client would.
+func allowedRedirects(client *http.Client, allowed []string) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	checked := *client
+	checked.CheckRedirect = func(request *http.Request, via []*http.Request) error {
+		if err := checkEgress(allowed, request.URL); err != nil {
+			return fmt.Errorf("redirect denied: %w", err)
+		}
+
+		if client.CheckRedirect != nil {
+			return client.CheckRedirect(request, via)
+		}
+
+		// As with the default policy of http.Client, stop after 10 consecutive redirects.
+		if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+
+		return nil
+	}
+
+	return &checked
+}
+
+func checkEgress(allowed []string, target *url.URL) error {
+	if target.Scheme != "http" && target.Scheme != "https" {
+		return fmt.Errorf("%w: unsupported scheme %q", ErrEgressDenied, target.Scheme)
+	}
+
+	if !allowedHost(allowed, target.Hostname()) {
+		return fmt.Errorf("%w: host %q is not allowed", ErrEgressDenied, target.Hostname())
+	}
+
+	return nil
+}
+
+func allowedHost(allowed []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		switch {
+		case pattern == "*", pattern == host:
+			return true
+		case strings.HasPrefix(pattern, "*.") && strings.HasSuffix(host, pattern[1:]):
+			return true
+		}
+	}
+
+	return false
+}
+
+func encodeHeader(header http.Header) map[string]*pb.HTTPHeader {
+	encoded := make(map[string]*pb.HTTPHeader, len(header))
+	for name, values := range header {
+		encoded[name] = &pb.HTTPHeader{Values: values}
+	}
+
+	return encoded
+}
+
+func decodeHeader(header map[string]*pb.HTTPHeader) http.Header {
+	decoded := make(http.Header, len(header))
+	for name, values := range header {
+		decoded[http.CanonicalHeaderKey(name)] = values.GetValues()
+	}
+
+	return decoded
+}
@@ -0,0 +1,156 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestWithEgress(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			// The server is also reachable as localhost, which is not an allowed host.
+			_, port, _ := net.SplitHostPort(r.Host)
+			http.Redirect(w, r, "http://localhost:"+port+"/allowed", http.StatusFound)
+			return
+		case "/redirect-allowed":
+			http.Redirect(w, r, "/allowed", http.StatusFound)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("X-Method", r.Method)
+		_, _ = io.WriteString(w, r.Header.Get("Authorization")+":"+string(body))
+	}))
+	t.Cleanup(server.Close)
+
+	config := plugin.Config{
+		Name: "egress",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "post",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					response, err := plugin.HTTPClientFromContext(ctx).Post(input.GetValue(), "text/plain", strings.NewReader("body"))
+					if err != nil {
+						return nil, err
+					}
+					defer response.Body.Close()
+
+					body, err := io.ReadAll(response.Body)
+					if err != nil {
+						return nil, err
+					}
+
+					return wrapperspb.String(response.Header.Get("X-Method") + " " + string(body)), nil
+				},
+			},
+		},
+	}
+
+	var (
+		mu      sync.Mutex
+		records []plugin.EgressRecord
+	)
+
+	egress := plugin.Egress{
+		AllowedHosts: []string{"127.0.0.1"},
+		Modify: func(name string, request *http.Request) error {
+			if request.URL.Path == "/forbidden" {
+				return errors.New("forbidden path")
+			}
+
+			request.Header.Set("Authorization", "token-for-"+name)
+			return nil
+		},
+		Record: func(record plugin.EgressRecord) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			records = append(records, record)
+		},
+	}
+
+	t.Run("makes requests via host", func(t *testing.T) {
+		p := plugintest.New(t, config, plugin.WithEgress(egress))
+
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "post", wrapperspb.String(server.URL+"/allowed"), output))
+		assert.EqualValues(t, "POST token-for-egress:body", output.GetValue())
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		require.NotEmpty(t, records)
+		record := records[len(records)-1]
+		assert.EqualValues(t, "egress", record.Plugin)
+		assert.EqualValues(t, http.MethodPost, record.Method)
+		assert.EqualValues(t, server.URL+"/allowed", record.URL)
+		assert.EqualValues(t, http.StatusOK, record.StatusCode)
+		assert.EqualValues(t, len("token-for-egress:body"), record.ResponseSize)
+		assert.NoError(t, record.Err)
+	})
+
+	t.Run("follows redirects to allowed hosts", func(t *testing.T) {
+		p := plugintest.New(t, config, plugin.WithEgress(egress))
+
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "post", wrapperspb.String(server.URL+"/redirect-allowed"), output))
+		assert.EqualValues(t, "GET token-for-egress:", output.GetValue())
+	})
+
+	tt := []struct {
+		Name     string
+		Options  []plugin.UseOption
+		URL      string
+		Expected string
+	}{
+		{
+			Name:     "denies hosts that are not allowed",
+			Options:  []plugin.UseOption{plugin.WithEgress(egress)},
+			URL:      "http://example.com",
+			Expected: plugin.ErrEgressDenied.Error(),
+		},
+		{
+			Name:     "denies requests rejected by host",
+			Options:  []plugin.UseOption{plugin.WithEgress(egress)},
+			URL:      server.URL + "/forbidden",
+			Expected: plugin.ErrEgressDenied.Error(),
+		},
+		{
+			Name:     "denies redirects to hosts that are not allowed",
+			Options:  []plugin.UseOption{plugin.WithEgress(egress)},
+			URL:      server.URL + "/redirect",
+			Expected: plugin.ErrEgressDenied.Error(),
+		},
+		{
+			Name:     "fails without egress",
+			URL:      server.URL,
+			Expected: plugin.ErrNoEgress.Error(),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			p := plugintest.New(t, config, tc.Options...)
+
+			err := p.Exec(t.Context(), "post", wrapperspb.String(tc.URL), &wrapperspb.StringValue{})
+			assert.ErrorContains(t, err, tc.Expected)
+		})
+	}
+}
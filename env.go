@@ -0,0 +1,47 @@
+package plugin
+
+import (
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+// inheritedEnv returns the environment variables a plugin process inherits from the host application, limited to
+// those given to WithInheritEnv if used.
+func (o useOptions) inheritedEnv() []string {
+	var env []string
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if o.restrictEnv && !slices.Contains(o.inheritEnv, name) {
+			continue
+		}
+
+		env = append(env, variable)
+	}
+
+	return env
+}
+
+// pluginEnv returns the environment variables set for a plugin process authenticated using the token, which are
+// those given to WithEnv followed by the token, so that it cannot be overridden.
+func (o useOptions) pluginEnv(token string) []string {
+	return append(slices.Clone(o.env), plugin.TokenEnvironmentVariable+"="+token)
+}
+
+// ConfigFromEnv returns the environment variables of the plugin process whose names begin with the given prefix,
+// keyed by their names with the prefix removed. This allows plugins to read configuration provided by the host
+// application using WithEnv, such as a prefix of "MY_PLUGIN_" for the variable "MY_PLUGIN_ENDPOINT" being returned
+// under the key "ENDPOINT". Returns an empty map if there are no matching variables.
+func ConfigFromEnv(prefix string) map[string]string {
+	config := make(map[string]string)
+	for _, variable := range os.Environ() {
+		name, value, _ := strings.Cut(variable, "=")
+		if key, ok := strings.CutPrefix(name, prefix); ok && key != "" {
+			config[key] = value
+		}
+	}
+
+	return config
+}
@@ -0,0 +1,23 @@
+package plugin_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("PLUGIN_TEST_ENDPOINT", "localhost:8080")
+	t.Setenv("PLUGIN_TEST_DEBUG", "")
+	t.Setenv("PLUGIN_TEST_", "ignored")
+	t.Setenv("OTHER_TEST_ENDPOINT", "ignored")
+
+	assert.Equal(t, map[string]string{
+		"ENDPOINT": "localhost:8080",
+		"DEBUG":    "",
+	}, plugin.ConfigFromEnv("PLUGIN_TEST_"))
+
+	assert.Empty(t, plugin.ConfigFromEnv("UNKNOWN_PREFIX_"))
+}
@@ -0,0 +1,238 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The Error type is an error that carries structured details across the plugin boundary. Command handlers can
+	// return an *Error to provide the host application with proto-encoded details describing a failure, such as the
+	// types provided by the errdetails package. When a command fails, Plugin.Exec returns an *Error containing the
+	// message, code and details provided by the plugin, which can be obtained using errors.As.
+	Error struct {
+		// The Code describing the class of error. When unset, codes.Internal is used.
+		Code codes.Code
+		// The Message describing the error.
+		Message string
+		// Any Details describing the error. Details whose types are not known to the host application are provided
+		// as *anypb.Any.
+		Details []proto.Message
+	}
+
+	// The ExecError type is returned by Plugin.Exec when a command fails, naming the plugin and command that produced
+	// the error so that hosts using more than one plugin can tell where it came from. The error it wraps, such as an
+	// *Error, ErrUnknownCommand or context.DeadlineExceeded, can still be inspected using errors.Is, errors.As and
+	// status.Code.
+	ExecError struct {
+		// The name of the Plugin that executed the command.
+		Plugin string
+		// The name of the Command that failed.
+		Command string
+		// The Err returned when executing the command.
+		Err error
+	}
+
+	// statusError is an error that does not originate from a command, such as its context being cancelled, which
+	// keeps the gRPC status code describing it so that it can be inspected using status.Code.
+	statusError struct {
+		code codes.Code
+		err  error
+	}
+)
+
+// NewError returns a new *Error containing the provided message and details, using the codes.Internal code. The
+// Code field can be set to describe a different class of error, such as codes.InvalidArgument.
+func NewError(message string, details ...proto.Message) *Error {
+	return &Error{
+		Code:    codes.Internal,
+		Message: message,
+		Details: details,
+	}
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// GRPCStatus converts the Error into a gRPC status, allowing its details to be transmitted from the plugin to the
+// host application. Details that cannot be encoded are omitted.
+func (e *Error) GRPCStatus() *status.Status {
+	code := e.Code
+	if code == codes.OK {
+		code = codes.Internal
+	}
+
+	st := &spb.Status{
+		Code:    int32(code),
+		Message: e.Message,
+	}
+
+	for _, detail := range e.Details {
+		a, err := anypb.New(detail)
+		if err != nil {
+			continue
+		}
+
+		st.Details = append(st.Details, a)
+	}
+
+	return status.FromProto(st)
+}
+
+func convertError(ctx context.Context, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if ctx.Err() != nil {
+		return contextError(ctx, name)
+	}
+
+	if errors.Is(err, ErrPluginCrashed) {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	// The plugin may observe the deadline shortly before the host does, in which case the deadline is reported in
+	// the same way as if it had been observed by the host.
+	if _, ok := ctx.Deadline(); ok && st.Code() == codes.DeadlineExceeded {
+		return deadlineExceeded(name)
+	}
+
+	// The command may be cancelled via Plugin.Cancel without the host's context being cancelled.
+	if st.Code() == codes.Canceled {
+		return &statusError{code: codes.Canceled, err: context.Canceled}
+	}
+
+	if isUnknownCommand(st) {
+		return &statusError{code: codes.NotFound, err: fmt.Errorf("%w: %q", ErrUnknownCommand, name)}
+	}
+
+	// Transport failures are not produced by the command, so they are returned as-is, allowing callers to inspect
+	// them using status.Code.
+	if st.Code() == codes.Unavailable {
+		return err
+	}
+
+	e := &Error{
+		Code:    st.Code(),
+		Message: st.Message(),
+	}
+
+	for _, detail := range st.Proto().GetDetails() {
+		message, err := detail.UnmarshalNew()
+		if err != nil {
+			e.Details = append(e.Details, detail)
+			continue
+		}
+
+		e.Details = append(e.Details, message)
+	}
+
+	return e
+}
+
+// contextError returns the error describing why the context used to execute the named command was cancelled.
+func contextError(ctx context.Context, name string) error {
+	if name != "" && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return deadlineExceeded(name)
+	}
+
+	return &statusError{code: status.FromContextError(ctx.Err()).Code(), err: ctx.Err()}
+}
+
+func deadlineExceeded(name string) error {
+	return &statusError{
+		code: codes.DeadlineExceeded,
+		err:  fmt.Errorf("%w: command %q did not complete before its deadline", context.DeadlineExceeded, name),
+	}
+}
+
+// Error returns the error message, prefixed with the names of the plugin and command.
+func (e *ExecError) Error() string {
+	return fmt.Sprintf("plugin %q: command %q: %v", e.Plugin, e.Command, e.Err)
+}
+
+// Unwrap returns the error returned when executing the command.
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// Error returns the error message.
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error, so that it can be inspected using errors.Is, such as context.Canceled.
+func (e *statusError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus returns the gRPC status describing the error.
+func (e *statusError) GRPCStatus() *status.Status {
+	return status.New(e.code, e.err.Error())
+}
+
+// invalidInputType returns the error describing an input to the named command that is not of the expected message
+// type. It uses the codes.InvalidArgument code, with an errdetails.ErrorInfo detail describing the expected and
+// received types, so that host applications can distinguish it from a failure of the command itself.
+func invalidInputType(name string, expected proto.Message, input *anypb.Any) error {
+	want := string(expected.ProtoReflect().Descriptor().FullName())
+	got := string(input.MessageName())
+
+	message := fmt.Sprintf("invalid input type for command %q: expected %q, got %q", name, want, got)
+	st, err := status.New(codes.InvalidArgument, message).WithDetails(&errdetails.ErrorInfo{
+		Reason: plugin.ReasonInvalidInputType,
+		Domain: plugin.ErrorDomain,
+		Metadata: map[string]string{
+			"expected": want,
+			"received": got,
+		},
+	})
+	if err != nil {
+		return status.Error(codes.InvalidArgument, message)
+	}
+
+	return st.Err()
+}
+
+// isUnknownCommand returns true if the status describes a command that does not exist. Plugins identify these using an
+// errdetails.ErrorInfo detail, though those that predate it return codes.NotFound without one, so NotFound statuses
+// that carry no ErrorInfo are also treated as an unknown command.
+func isUnknownCommand(st *status.Status) bool {
+	if st.Code() != codes.NotFound {
+		return false
+	}
+
+	described := false
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok {
+			continue
+		}
+
+		if info.GetDomain() == plugin.ErrorDomain && info.GetReason() == plugin.ReasonUnknownCommand {
+			return true
+		}
+
+		described = true
+	}
+
+	return !described
+}
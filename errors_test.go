@@ -0,0 +1,197 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	internal "github.com/davidsbond/plugin/internal/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestPlugin_Exec_StatusCodes(t *testing.T) {
+	t.Parallel()
+
+	p := plugintest.New(t, plugin.Config{
+		Name: "status",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "fail",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					switch input.GetValue() {
+					case "invalid":
+						return nil, status.Error(codes.InvalidArgument, "invalid input")
+					case "error":
+						return nil, errors.New("something went wrong")
+					default:
+						<-ctx.Done()
+						return nil, ctx.Err()
+					}
+				},
+			},
+		},
+	})
+
+	tt := []struct {
+		Name     string
+		Command  string
+		Input    string
+		Options  []plugin.ExecOption
+		Expected codes.Code
+		Is       error
+	}{
+		{
+			Name:     "status returned by command",
+			Command:  "fail",
+			Input:    "invalid",
+			Expected: codes.InvalidArgument,
+		},
+		{
+			Name:     "error returned by command",
+			Command:  "fail",
+			Input:    "error",
+			Expected: codes.Internal,
+		},
+		{
+			Name:     "deadline exceeded",
+			Command:  "fail",
+			Input:    "wait",
+			Options:  []plugin.ExecOption{plugin.WithTimeout(50 * time.Millisecond)},
+			Expected: codes.DeadlineExceeded,
+			Is:       context.DeadlineExceeded,
+		},
+		{
+			Name:     "unknown command",
+			Command:  "unknown",
+			Expected: codes.NotFound,
+			Is:       plugin.ErrUnknownCommand,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			err := p.Exec(t.Context(), tc.Command, wrapperspb.String(tc.Input), &wrapperspb.StringValue{}, tc.Options...)
+			require.Error(t, err)
+
+			assert.EqualValues(t, tc.Expected, status.Code(err))
+			if tc.Is != nil {
+				assert.ErrorIs(t, err, tc.Is)
+			}
+		})
+	}
+
+	t.Run("context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		err := p.Exec(ctx, "fail", wrapperspb.String("wait"), &wrapperspb.StringValue{})
+		assert.EqualValues(t, codes.Canceled, status.Code(err))
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+type (
+	// legacyAPI is a plugin API that reports unknown commands using codes.NotFound without an errdetails.ErrorInfo
+	// detail, like those of older plugins.
+	legacyAPI struct {
+		*internal.API
+	}
+)
+
+func (legacyAPI) Execute(_ context.Context, request *pb.ExecuteRequest) (*pb.ExecuteResponse, error) {
+	return nil, status.Errorf(codes.NotFound, "command %q does not exist", request.GetName())
+}
+
+func TestPlugin_Exec_UnknownCommand(t *testing.T) {
+	t.Parallel()
+
+	t.Run("legacy plugin without error details", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "legacy.sock")
+		listener, err := net.Listen("unix", socket)
+		require.NoError(t, err)
+
+		server := grpc.NewServer()
+		pb.RegisterPluginServiceServer(server, legacyAPI{API: internal.NewAPI(internal.Info{Name: "legacy"}, nil)})
+
+		go server.Serve(listener)
+		t.Cleanup(server.Stop)
+
+		p, err := plugin.Connect(t.Context(), socket)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, p.Close())
+		})
+
+		err = p.Exec(t.Context(), "unknown", wrapperspb.String("input"), &wrapperspb.StringValue{})
+		assert.ErrorIs(t, err, plugin.ErrUnknownCommand)
+		assert.EqualValues(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("not found error described by command", func(t *testing.T) {
+		p := plugintest.New(t, plugin.Config{
+			Name: "lookup",
+			Commands: []plugin.CommandHandler{
+				&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+					Use: "find",
+					Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+						st, err := status.New(codes.NotFound, "user not found").WithDetails(&errdetails.ErrorInfo{
+							Domain: "example.com",
+							Reason: "USER_NOT_FOUND",
+						})
+						if err != nil {
+							return nil, err
+						}
+
+						return nil, st.Err()
+					},
+				},
+			},
+		})
+
+		err := p.Exec(t.Context(), "find", wrapperspb.String("input"), &wrapperspb.StringValue{})
+		assert.NotErrorIs(t, err, plugin.ErrUnknownCommand)
+		assert.EqualValues(t, codes.NotFound, status.Code(err))
+	})
+}
+
+func TestPlugin_Exec_ExecError(t *testing.T) {
+	t.Parallel()
+
+	p := plugintest.New(t, plugin.Config{
+		Name: "wrapped",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "fail",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					return nil, plugin.NewError("invalid input")
+				},
+			},
+		},
+	})
+
+	err := p.Exec(t.Context(), "fail", wrapperspb.String("input"), &wrapperspb.StringValue{})
+	assert.EqualError(t, err, `plugin "wrapped": command "fail": invalid input`)
+
+	var execErr *plugin.ExecError
+	require.True(t, errors.As(err, &execErr))
+	assert.EqualValues(t, "wrapped", execErr.Plugin)
+	assert.EqualValues(t, "fail", execErr.Command)
+
+	var pluginErr *plugin.Error
+	require.True(t, errors.As(err, &pluginErr))
+	assert.EqualValues(t, "invalid input", pluginErr.Message)
+	assert.EqualValues(t, codes.Internal, status.Code(err))
+}
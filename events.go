@@ -0,0 +1,127 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type (
+	// The Event type is a single event published by a plugin, received via Plugin.Events.
+	Event struct {
+		// The name of the plugin that published the event.
+		Plugin string
+		// The Payload of the event. Use Event.UnmarshalTo to decode it into the expected message.
+		Payload *anypb.Any
+		// When the event was published by the plugin.
+		Time time.Time
+	}
+
+	// The EventSource type is a function that publishes events to the host application using the provided
+	// PublishFunc. It is set via the Config.Events field and runs for the lifetime of the plugin, until the provided
+	// context is cancelled. If it returns an error, the plugin exits.
+	EventSource func(ctx context.Context, publish PublishFunc) error
+
+	// The PublishFunc type is a function used by an EventSource to publish an event to the host application. It
+	// blocks until the event has been received by all subscribers, or the context is cancelled. Events published
+	// while the host application is not subscribed are discarded.
+	PublishFunc func(ctx context.Context, event proto.Message) error
+)
+
+const (
+	minResubscribeInterval = 10 * time.Millisecond
+	maxResubscribeInterval = time.Second
+)
+
+// UnmarshalTo decodes the event payload into the provided message. Returns an error if the payload is of a different
+// type.
+func (e Event) UnmarshalTo(m proto.Message) error {
+	return e.Payload.UnmarshalTo(m)
+}
+
+// Events returns a channel on which events published by the plugin are received. The plugin is started if it is not
+// already running, and is not stopped due to inactivity while subscribed. If the plugin restarts, such as after a
+// crash, the subscription is resumed automatically, though events published in the meantime are lost. The channel is
+// closed once the context is cancelled or the plugin is closed. The channel must be drained, as the plugin blocks
+// while publishing until events are received.
+func (p *Plugin) Events(ctx context.Context) <-chan Event {
+	events := make(chan Event)
+	go p.subscribe(ctx, events)
+	return events
+}
+
+func (p *Plugin) subscribe(ctx context.Context, events chan<- Event) {
+	defer close(events)
+
+	release := p.hold()
+	defer release()
+
+	interval := minResubscribeInterval
+	for {
+		pl, err := p.start(ctx)
+		switch {
+		case errors.Is(err, ErrClosed) || ctx.Err() != nil:
+			return
+		case err == nil:
+			if p.subscribePool(ctx, pl, events) {
+				interval = minResubscribeInterval
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.options.clock.After(interval):
+			interval = min(interval*2, maxResubscribeInterval)
+		}
+	}
+}
+
+// subscribePool forwards events from every process within the pool until all of their subscriptions have ended.
+// Returns true if any events were received.
+func (p *Plugin) subscribePool(ctx context.Context, pl *pool, events chan<- Event) bool {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		received bool
+	)
+
+	for _, proc := range pl.processes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_ = proc.client.Subscribe(ctx, func(payload *anypb.Any, at time.Time) {
+				mu.Lock()
+				received = true
+				mu.Unlock()
+
+				select {
+				case <-ctx.Done():
+				case events <- Event{Plugin: p.name, Payload: payload, Time: at}:
+				}
+			})
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			// A subscription ending because the process exited unexpectedly is treated as a crash, so that the
+			// plugin is started again.
+			select {
+			case <-proc.exited:
+				if !proc.closing.Load() {
+					p.discard(proc)
+				}
+			case <-p.options.clock.After(crashDetectionTimeout):
+			}
+		}()
+	}
+
+	wg.Wait()
+	return received
+}
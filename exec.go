@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func execCommand(config Config) *cobra.Command {
+	var input string
+
+	cmd := &cobra.Command{
+		Use:   "exec [command]",
+		Short: "Executes a command directly",
+		Long:  "Executes a command directly, without starting the gRPC server. The command input is read as protobuf JSON from the --input flag if set, otherwise from stdin. The command output is printed as protobuf JSON.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var handler CommandHandler
+			for _, command := range config.Commands {
+				if command.Name() == args[0] {
+					handler = command
+					break
+				}
+			}
+
+			if handler == nil {
+				return fmt.Errorf("%w: %q", ErrUnknownCommand, args[0])
+			}
+
+			described := describe(handler)
+			if described.Input == nil {
+				return fmt.Errorf("%w: %q", ErrUndescribedCommand, args[0])
+			}
+
+			data := []byte(input)
+			if !cmd.Flags().Changed("input") {
+				var err error
+				if data, err = io.ReadAll(cmd.InOrStdin()); err != nil {
+					return err
+				}
+			}
+
+			// The concrete input type is registered by the plugin binary itself, so it is used rather than a dynamic
+			// message to match the type expected by the command.
+			messageType, err := protoregistry.GlobalTypes.FindMessageByName(described.Input.FullName())
+			if err != nil {
+				return err
+			}
+
+			in := messageType.New().Interface()
+			if err = protojson.Unmarshal(data, in); err != nil {
+				return fmt.Errorf("%w for command %q: %w", ErrInvalidJSON, args[0], err)
+			}
+
+			packed, err := anypb.New(in)
+			if err != nil {
+				return err
+			}
+
+			result, err := handler.Execute(cmd.Context(), packed)
+			if err != nil {
+				return err
+			}
+
+			out, err := result.UnmarshalNew()
+			if err != nil {
+				return err
+			}
+
+			output, err := protojson.MarshalOptions{Multiline: true}.Marshal(out)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(output))
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&input, "input", "", "The command input, encoded as protobuf JSON")
+
+	return cmd
+}
@@ -0,0 +1,74 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"syscall"
+	"time"
+)
+
+type (
+	// The ExitStatus type describes how a plugin process exited.
+	ExitStatus struct {
+		// The process identifier of the plugin process.
+		PID int
+		// The exit code of the process. This is -1 if the process was terminated by a signal, or if it was attached to
+		// using WithPID rather than started by the host application.
+		Code int
+		// The Signal that terminated the process, if any.
+		Signal os.Signal
+		// The time at which the process was found to have exited.
+		Time time.Time
+	}
+)
+
+// maxExits is the number of exit statuses retained by each Plugin.
+const maxExits = 32
+
+// Success returns true if the process exited with a zero exit code.
+func (s ExitStatus) Success() bool {
+	return s.Code == 0 && s.Signal == nil
+}
+
+// String describes how the process exited.
+func (s ExitStatus) String() string {
+	if s.Signal != nil {
+		return fmt.Sprintf("terminated by signal %s", s.Signal)
+	}
+
+	return fmt.Sprintf("exited with code %d", s.Code)
+}
+
+func newExitStatus(state *os.ProcessState, now time.Time) ExitStatus {
+	status := ExitStatus{
+		PID:  state.Pid(),
+		Code: state.ExitCode(),
+		Time: now,
+	}
+
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		status.Signal = ws.Signal()
+	}
+
+	return status
+}
+
+// Exits returns the exit statuses of plugin processes that have exited, oldest first. Processes exit when the Plugin
+// is closed or stopped due to inactivity, or unexpectedly. Only the most recent exits are retained.
+func (p *Plugin) Exits() []ExitStatus {
+	p.exitsMu.Lock()
+	defer p.exitsMu.Unlock()
+
+	return slices.Clone(p.exits)
+}
+
+func (p *Plugin) recordExit(status ExitStatus) {
+	p.exitsMu.Lock()
+	defer p.exitsMu.Unlock()
+
+	p.exits = append(p.exits, status)
+	if len(p.exits) > maxExits {
+		p.exits = slices.Delete(p.exits, 0, len(p.exits)-maxExits)
+	}
+}
@@ -0,0 +1,124 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rs/xid"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+var (
+	// ErrUnknownFile is the error returned by Plugin.ReceiveFile and OpenFile when a file does not exist.
+	ErrUnknownFile = plugin.ErrUnknownFile
+
+	// ErrNoFiles is the error returned by OpenFile and CreateFile when they are not provided the context given to a
+	// command.
+	ErrNoFiles = plugin.ErrNoFiles
+)
+
+// SendFile streams the contents of the reader to the plugin, returning an identifier that commands can use to open it
+// via OpenFile. This allows plugins to process files provided by the host application without sharing filesystem
+// paths. The file is sent in chunks and verified against its checksum once received, failing with
+// ErrChecksumMismatch if it was corrupted.
+//
+// Files remain available for the lifetime of the plugin process and are lost if it exits, including when it is
+// stopped by WithIdleTimeout. If the plugin was used with WithInstances, each instance receives a copy of the file.
+func (p *Plugin) SendFile(ctx context.Context, r io.Reader) (string, error) {
+	release := p.hold()
+	defer release()
+
+	pl, err := p.start(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	id := xid.New().String()
+
+	// Every process is given its own copy of the file, as commands may be executed by any of them.
+	group, groupCtx := errgroup.WithContext(ctx)
+	writers := make([]*io.PipeWriter, len(pl.processes))
+	destinations := make([]io.Writer, len(pl.processes))
+	for i, proc := range pl.processes {
+		reader, writer := io.Pipe()
+		writers[i], destinations[i] = writer, writer
+
+		group.Go(func() error {
+			_, err := proc.client.SendFile(groupCtx, id, reader)
+			reader.CloseWithError(err)
+			return err
+		})
+	}
+
+	_, copyErr := io.Copy(io.MultiWriter(destinations...), r)
+	for _, writer := range writers {
+		writer.CloseWithError(copyErr)
+	}
+
+	if err = group.Wait(); err != nil {
+		return "", fileError(ctx, id, err)
+	}
+
+	if copyErr != nil {
+		return "", copyErr
+	}
+
+	return id, nil
+}
+
+// ReceiveFile writes the contents of the file created by a command using CreateFile to the writer. Returns
+// ErrUnknownFile if the file does not exist, or ErrChecksumMismatch if the file received does not match its
+// checksum. As the file is written as it is received, the writer may have been partially written to when an error is
+// returned.
+func (p *Plugin) ReceiveFile(ctx context.Context, id string, w io.Writer) error {
+	release := p.hold()
+	defer release()
+
+	pl, err := p.start(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The file exists only within the process that executed the command that created it.
+	for _, proc := range pl.processes {
+		_, err = proc.client.ReceiveFile(ctx, id, w)
+		if status.Code(err) != codes.NotFound {
+			break
+		}
+	}
+
+	return fileError(ctx, id, err)
+}
+
+// OpenFile opens the file with the given identifier, sent by the host application using Plugin.SendFile, for
+// reading. The provided context must be that given to the command. Returns ErrUnknownFile if the file does not
+// exist.
+func OpenFile(ctx context.Context, id string) (*os.File, error) {
+	return plugin.OpenFile(ctx, id)
+}
+
+// CreateFile creates a new file, returning an identifier that the host application can use to receive it via
+// Plugin.ReceiveFile. Commands typically return the identifier within their output. The provided context must be
+// that given to the command, and the file must be closed before the command returns.
+func CreateFile(ctx context.Context) (string, *os.File, error) {
+	return plugin.CreateFile(ctx)
+}
+
+func fileError(ctx context.Context, id string, err error) error {
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.NotFound:
+		return fmt.Errorf("%w: %q", ErrUnknownFile, id)
+	case codes.DataLoss:
+		return fmt.Errorf("%w: %s", ErrChecksumMismatch, status.Convert(err).Message())
+	default:
+		return convertError(ctx, "", err)
+	}
+}
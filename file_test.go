@@ -0,0 +1,88 @@
+package plugin_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestPlugin_SendFile(t *testing.T) {
+	t.Parallel()
+
+	config := plugin.Config{
+		Name: "files",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "upper",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					in, err := plugin.OpenFile(ctx, input.GetValue())
+					if err != nil {
+						return nil, err
+					}
+
+					defer in.Close()
+
+					contents, err := io.ReadAll(in)
+					if err != nil {
+						return nil, err
+					}
+
+					id, out, err := plugin.CreateFile(ctx)
+					if err != nil {
+						return nil, err
+					}
+
+					defer out.Close()
+
+					if _, err = out.Write(bytes.ToUpper(contents)); err != nil {
+						return nil, err
+					}
+
+					return wrapperspb.String(id), nil
+				},
+			},
+		},
+	}
+
+	contents := strings.Repeat("plugin", 1<<16)
+
+	for _, instances := range []int{1, 2} {
+		p := plugintest.New(t, config, plugin.WithInstances(instances))
+
+		id, err := p.SendFile(t.Context(), strings.NewReader(contents))
+		require.NoError(t, err)
+
+		// Each call may be executed by either instance, so the file must be available to both.
+		for range instances {
+			output := &wrapperspb.StringValue{}
+			require.NoError(t, p.Exec(t.Context(), "upper", wrapperspb.String(id), output))
+
+			received := &bytes.Buffer{}
+			require.NoError(t, p.ReceiveFile(t.Context(), output.GetValue(), received))
+			assert.Equal(t, strings.ToUpper(contents), received.String())
+		}
+	}
+
+	t.Run("error if file does not exist", func(t *testing.T) {
+		p := plugintest.New(t, config)
+
+		require.ErrorIs(t, p.ReceiveFile(t.Context(), "unknown", io.Discard), plugin.ErrUnknownFile)
+
+		err := p.Exec(t.Context(), "upper", wrapperspb.String("unknown"), &wrapperspb.StringValue{})
+		assert.ErrorContains(t, err, `unknown file: "unknown"`)
+	})
+
+	t.Run("error outside of commands", func(t *testing.T) {
+		_, err := plugin.OpenFile(t.Context(), "unknown")
+		assert.ErrorIs(t, err, plugin.ErrNoFiles)
+	})
+}
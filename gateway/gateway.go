@@ -0,0 +1,175 @@
+// Package gateway provides an HTTP handler that exposes the plugins within a plugin.Manager over HTTP, using JSON
+// request and response bodies. Commands are executed by sending a POST request to /plugins/{name}/commands/{command},
+// where the request body is the command input encoded as protobuf JSON. The response body contains the command output,
+// encoded in the same way.
+//
+// Errors are returned as a JSON object containing an "error" field, with a status code describing the failure. For
+// example, unknown plugins and commands result in a 404 status code, while input that does not match the command's
+// input message results in a 400 status code. Errors returned by commands are mapped from the gRPC status code set by
+// the plugin, so a command returning codes.InvalidArgument also results in a 400 status code.
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/davidsbond/plugin"
+)
+
+type (
+	// The Handler type is an http.Handler that executes plugin commands.
+	Handler struct {
+		manager     *plugin.Manager
+		mux         *http.ServeMux
+		maxBodySize int64
+	}
+
+	// The Option type is a function that modifies the behaviour of the Handler.
+	Option func(h *Handler)
+
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+
+	jsonExecutor interface {
+		ExecJSON(ctx context.Context, name string, input []byte) ([]byte, error)
+	}
+)
+
+// DefaultMaxBodySize is the maximum size, in bytes, of a request body accepted by the Handler unless set otherwise
+// using WithMaxBodySize.
+const DefaultMaxBodySize = 4 << 20
+
+// NewHandler returns a new instance of the Handler type that executes commands provided by plugins within the Manager.
+// Plugins added to the Manager after the Handler is created are also exposed.
+func NewHandler(manager *plugin.Manager, options ...Option) *Handler {
+	h := &Handler{
+		manager:     manager,
+		mux:         http.NewServeMux(),
+		maxBodySize: DefaultMaxBodySize,
+	}
+
+	for _, option := range options {
+		option(h)
+	}
+
+	h.mux.HandleFunc("POST /plugins/{name}/commands/{command}", h.exec)
+
+	return h
+}
+
+// WithMaxBodySize is an Option that sets the maximum size, in bytes, of a request body. Requests with larger bodies
+// are rejected with a 413 status code. Defaults to DefaultMaxBodySize.
+func WithMaxBodySize(size int64) Option {
+	return func(h *Handler) {
+		h.maxBodySize = size
+	}
+}
+
+// ServeHTTP handles an HTTP request.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *Handler) exec(w http.ResponseWriter, r *http.Request) {
+	p, ok := h.manager.Get(r.PathValue("name"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("plugin not found"))
+		return
+	}
+
+	input, err := io.ReadAll(http.MaxBytesReader(w, r.Body, h.maxBodySize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, err)
+			return
+		}
+
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// Only plugins that can describe their commands, such as a *plugin.Plugin, can convert JSON to and from the
+	// command's messages.
+	executor, ok := p.(jsonExecutor)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, errors.New("plugin does not support JSON"))
+		return
+	}
+
+	output, err := executor.ExecJSON(r.Context(), r.PathValue("command"), input)
+	if err != nil {
+		writeError(w, statusCode(err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(output)
+}
+
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, plugin.ErrUnknownCommand):
+		return http.StatusNotFound
+	case errors.Is(err, plugin.ErrInvalidJSON):
+		return http.StatusBadRequest
+	case errors.Is(err, plugin.ErrRequestTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, plugin.ErrUndescribedCommand):
+		return http.StatusNotImplemented
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, plugin.ErrClosed), errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	default:
+		// Errors returned by commands, such as a *plugin.Error, carry the gRPC status code chosen by the plugin.
+		return httpStatus(status.Code(err))
+	}
+}
+
+// httpStatus maps a gRPC status code to its closest HTTP equivalent, following the mapping used by the gRPC gateway
+// project.
+func httpStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(errorResponse{Error: err.Error()})
+}
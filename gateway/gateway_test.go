@@ -0,0 +1,112 @@
+package gateway_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/gateway"
+)
+
+func TestHandler(t *testing.T) {
+	p, err := plugin.Use(t.Context(), "../test_plugin")
+	require.NoError(t, err)
+
+	manager := plugin.NewManager()
+	require.NoError(t, manager.Add(p))
+	t.Cleanup(func() {
+		assert.NoError(t, manager.Close())
+	})
+
+	server := httptest.NewServer(gateway.NewHandler(manager, gateway.WithMaxBodySize(64)))
+	t.Cleanup(server.Close)
+
+	tt := []struct {
+		Name         string
+		Method       string
+		Path         string
+		Body         string
+		ExpectedCode int
+		ExpectedBody string
+	}{
+		{
+			Name:         "executes command",
+			Method:       http.MethodPost,
+			Path:         "/plugins/test_plugin/commands/pingpong",
+			Body:         `"ping"`,
+			ExpectedCode: http.StatusOK,
+			ExpectedBody: `"pong"`,
+		},
+		{
+			Name:         "error if plugin does not exist",
+			Method:       http.MethodPost,
+			Path:         "/plugins/unknown/commands/pingpong",
+			Body:         `"ping"`,
+			ExpectedCode: http.StatusNotFound,
+			ExpectedBody: `{"error": "plugin not found"}`,
+		},
+		{
+			Name:         "error if command does not exist",
+			Method:       http.MethodPost,
+			Path:         "/plugins/test_plugin/commands/unknown",
+			Body:         `{}`,
+			ExpectedCode: http.StatusNotFound,
+			ExpectedBody: `{"error": "unknown command: \"unknown\""}`,
+		},
+		{
+			Name:         "error if input is invalid",
+			Method:       http.MethodPost,
+			Path:         "/plugins/test_plugin/commands/pingpong",
+			Body:         `{"value": "ping"}`,
+			ExpectedCode: http.StatusBadRequest,
+		},
+		{
+			Name:         "error if command fails",
+			Method:       http.MethodPost,
+			Path:         "/plugins/test_plugin/commands/pingpong",
+			Body:         `"pung"`,
+			ExpectedCode: http.StatusBadRequest,
+			ExpectedBody: `{"error": "plugin \"test_plugin\": command \"pingpong\": invalid input \"pung\", expected \"ping\" or \"pong\""}`,
+		},
+		{
+			Name:         "error if body is too large",
+			Method:       http.MethodPost,
+			Path:         "/plugins/test_plugin/commands/pingpong",
+			Body:         `"` + strings.Repeat("a", 64) + `"`,
+			ExpectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			Name:         "error if method is not allowed",
+			Method:       http.MethodGet,
+			Path:         "/plugins/test_plugin/commands/pingpong",
+			ExpectedCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			request, err := http.NewRequestWithContext(t.Context(), tc.Method, server.URL+tc.Path, strings.NewReader(tc.Body))
+			require.NoError(t, err)
+
+			response, err := server.Client().Do(request)
+			require.NoError(t, err)
+			defer response.Body.Close()
+
+			assert.EqualValues(t, tc.ExpectedCode, response.StatusCode)
+			if tc.ExpectedBody == "" {
+				return
+			}
+
+			var body strings.Builder
+			_, err = io.Copy(&body, response.Body)
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.ExpectedBody, body.String())
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package goplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+type (
+	// The Broker type allows the host application and a go-plugin plugin to serve additional gRPC services to one
+	// another, identified by a numeric id, as go-plugin's GRPCBroker does. It is typically used to pass a client for a
+	// host service to the plugin, by serving the service using AcceptAndServe and passing its id within a request.
+	Broker struct {
+		conn    *grpc.ClientConn
+		ctx     context.Context
+		cancel  context.CancelFunc
+		nextID  atomic.Uint32
+		send    chan connInfo
+		done    chan struct{}
+		err     error
+		mu      sync.Mutex
+		infos   map[uint32]chan connInfo
+		servers []*grpc.Server
+		dir     string
+	}
+
+	// The connInfo type describes where a brokered service can be dialed, matching go-plugin's ConnInfo message.
+	connInfo struct {
+		serviceID uint32
+		network   string
+		address   string
+	}
+
+	// The frame type contains an encoded protobuf message. It is used for go-plugin's own services so that their
+	// message types need not be registered by this package, which would conflict with go-plugin itself when both are
+	// linked into the same binary.
+	frame struct {
+		data []byte
+	}
+
+	// The frameCodec type is an encoding.Codec that passes frames to and from gRPC unmodified.
+	frameCodec struct{}
+)
+
+const (
+	startStreamMethod = "/plugin.GRPCBroker/StartStream"
+	shutdownMethod    = "/plugin.GRPCController/Shutdown"
+)
+
+// ErrBrokerClosed is the error given by Broker.Dial when the broker's stream to the plugin ends before the plugin
+// serves the requested service.
+var ErrBrokerClosed = errors.New("broker closed")
+
+func newBroker(conn *grpc.ClientConn) *Broker {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	b := &Broker{
+		conn:   conn,
+		ctx:    ctx,
+		cancel: cancel,
+		send:   make(chan connInfo),
+		done:   make(chan struct{}),
+		infos:  make(map[uint32]chan connInfo),
+	}
+
+	go b.run()
+	return b
+}
+
+// run maintains the broker's stream with the plugin, sending the details of services served by the host application
+// and receiving those of services served by the plugin.
+func (b *Broker) run() {
+	defer close(b.done)
+
+	desc := &grpc.StreamDesc{ClientStreams: true, ServerStreams: true}
+	stream, err := b.conn.NewStream(b.ctx, desc, startStreamMethod, grpc.ForceCodec(frameCodec{}))
+	if err != nil {
+		b.err = err
+		return
+	}
+
+	received := make(chan error, 1)
+	go func() {
+		for {
+			f := &frame{}
+			if err := stream.RecvMsg(f); err != nil {
+				received <- err
+				return
+			}
+
+			info, err := decodeConnInfo(f.data)
+			if err != nil {
+				received <- err
+				return
+			}
+
+			// Each id is used for a single service, so only the first details received for it are kept.
+			select {
+			case b.conns(info.serviceID) <- info:
+			default:
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case err = <-received:
+			b.err = err
+			return
+		case info := <-b.send:
+			if err = stream.SendMsg(&frame{data: encodeConnInfo(info)}); err != nil {
+				b.err = err
+				return
+			}
+		}
+	}
+}
+
+// conns returns the channel on which the details of the service with the given id are delivered.
+func (b *Broker) conns(id uint32) chan connInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch, ok := b.infos[id]
+	if !ok {
+		ch = make(chan connInfo, 1)
+		b.infos[id] = ch
+	}
+
+	return ch
+}
+
+// NextID returns an id that is not yet in use by the host application, to be used with AcceptAndServe.
+func (b *Broker) NextID() uint32 {
+	return b.nextID.Add(1)
+}
+
+// AcceptAndServe serves the gRPC services added by the register function under the given id, which the plugin can
+// dial using its own GRPCBroker. Unlike go-plugin, it does not block while the services are served. They are stopped
+// when the plugin is closed.
+func (b *Broker) AcceptAndServe(id uint32, register func(registrar grpc.ServiceRegistrar)) error {
+	b.mu.Lock()
+	if b.dir == "" {
+		dir, err := os.MkdirTemp("", "goplugin-")
+		if err != nil {
+			b.mu.Unlock()
+			return fmt.Errorf("failed to create socket directory: %w", err)
+		}
+
+		b.dir = dir
+	}
+
+	socket := filepath.Join(b.dir, strconv.FormatUint(uint64(id), 10)+".sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		b.mu.Unlock()
+		return fmt.Errorf("failed to listen on %q: %w", socket, err)
+	}
+
+	server := grpc.NewServer()
+	register(server)
+	b.servers = append(b.servers, server)
+	b.mu.Unlock()
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+
+	select {
+	case b.send <- connInfo{serviceID: id, network: "unix", address: socket}:
+		return nil
+	case <-b.done:
+		return fmt.Errorf("%w: %w", ErrBrokerClosed, b.err)
+	}
+}
+
+// Dial the service served by the plugin under the given id, waiting until the plugin has begun serving it. Returns
+// ErrBrokerClosed if the plugin does not serve the service before the broker's stream ends. It is up to the caller to
+// close the returned connection.
+func (b *Broker) Dial(ctx context.Context, id uint32) (*grpc.ClientConn, error) {
+	var info connInfo
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-b.done:
+		return nil, fmt.Errorf("%w: %w", ErrBrokerClosed, b.err)
+	case info = <-b.conns(id):
+	}
+
+	address, err := target(info.network, info.address)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+}
+
+func (b *Broker) close() error {
+	b.cancel()
+	<-b.done
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, server := range b.servers {
+		server.Stop()
+	}
+
+	if b.dir == "" {
+		return nil
+	}
+
+	return os.RemoveAll(b.dir)
+}
+
+func encodeConnInfo(info connInfo) []byte {
+	var data []byte
+	data = protowire.AppendTag(data, 1, protowire.VarintType)
+	data = protowire.AppendVarint(data, uint64(info.serviceID))
+	data = protowire.AppendTag(data, 2, protowire.BytesType)
+	data = protowire.AppendString(data, info.network)
+	data = protowire.AppendTag(data, 3, protowire.BytesType)
+	data = protowire.AppendString(data, info.address)
+
+	return data
+}
+
+func decodeConnInfo(data []byte) (connInfo, error) {
+	var info connInfo
+	for len(data) > 0 {
+		number, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return info, protowire.ParseError(n)
+		}
+
+		data = data[n:]
+		switch {
+		case number == 1 && typ == protowire.VarintType:
+			var value uint64
+			value, n = protowire.ConsumeVarint(data)
+			info.serviceID = uint32(value)
+		case number == 2 && typ == protowire.BytesType:
+			info.network, n = protowire.ConsumeString(data)
+		case number == 3 && typ == protowire.BytesType:
+			info.address, n = protowire.ConsumeString(data)
+		default:
+			// Other fields, such as the knock used by go-plugin's multiplexing, are not used.
+			n = protowire.ConsumeFieldValue(number, typ, data)
+		}
+
+		if n < 0 {
+			return info, protowire.ParseError(n)
+		}
+
+		data = data[n:]
+	}
+
+	return info, nil
+}
+
+// Name returns "proto", as frames contain messages encoded using the protobuf binary format.
+func (frameCodec) Name() string {
+	return "proto"
+}
+
+// Marshal returns the contents of the frame.
+func (frameCodec) Marshal(v any) ([]byte, error) {
+	f, ok := v.(*frame)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+
+	return f.data, nil
+}
+
+// Unmarshal sets the contents of the frame.
+func (frameCodec) Unmarshal(data []byte, v any) error {
+	f, ok := v.(*frame)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+
+	f.data = append([]byte(nil), data...)
+	return nil
+}
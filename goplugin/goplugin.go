@@ -0,0 +1,407 @@
+// Package goplugin provides an adapter that drives plugins built using github.com/hashicorp/go-plugin through the
+// plugin.Client interface, allowing them to be managed alongside plugins built using this module while they are
+// migrated. Plugins are started and stopped as go-plugin would, using its handshake and magic cookie, and must use
+// its gRPC protocol.
+//
+// As go-plugin plugins serve arbitrary gRPC services rather than commands, each command is mapped to the full name of
+// the gRPC method that implements it, such as "/proto.KV/Get", using WithCommand. The command input and output must be
+// the request and response messages of the method. Services that would otherwise be accessed using go-plugin's
+// GRPCBroker are available via Plugin.Broker.
+package goplugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/davidsbond/plugin"
+)
+
+type (
+	// The Handshake type contains the values a go-plugin plugin expects from its host application, matching the
+	// plugin's go-plugin HandshakeConfig.
+	Handshake struct {
+		// The application protocol version of the plugin.
+		ProtocolVersion uint
+		// The name of the environment variable containing the magic cookie.
+		MagicCookieKey string
+		// The value of the magic cookie.
+		MagicCookieValue string
+	}
+
+	// The Option type is a function that modifies how a go-plugin plugin is started and communicated with.
+	Option func(o *options)
+
+	options struct {
+		commands         map[string]string
+		protocolVersions []uint
+		startTimeout     time.Duration
+		gracePeriod      time.Duration
+		stderr           io.Writer
+	}
+
+	// The Plugin type represents a running go-plugin plugin. It implements the plugin.Client interface, so can be
+	// added to a plugin.Manager.
+	Plugin struct {
+		name     string
+		path     string
+		version  uint
+		options  options
+		command  *exec.Cmd
+		conn     *grpc.ClientConn
+		broker   *Broker
+		exited   chan struct{}
+		stderr   *tailWriter
+		closeErr error
+		close    sync.Once
+	}
+)
+
+var (
+	// ErrHandshakeFailed is the error given by Use when a plugin does not complete the go-plugin handshake, such as
+	// when it exits with an incorrect magic cookie or does not support any of the requested protocol versions.
+	ErrHandshakeFailed = errors.New("handshake failed")
+
+	// ErrUnsupportedProtocol is the error given by Use when a plugin uses go-plugin's net/rpc protocol rather than
+	// gRPC.
+	ErrUnsupportedProtocol = errors.New("unsupported protocol")
+)
+
+const (
+	// DefaultStartTimeout is the time Use waits for a plugin to complete the handshake, unless set otherwise using
+	// WithStartTimeout. It matches the default used by go-plugin.
+	DefaultStartTimeout = time.Minute
+
+	// DefaultShutdownGracePeriod is the time Plugin.Close waits for a plugin to exit before killing it, unless set
+	// otherwise using WithShutdownGracePeriod.
+	DefaultShutdownGracePeriod = 2 * time.Second
+
+	// coreProtocolVersion is the version of go-plugin's handshake protocol.
+	coreProtocolVersion = "1"
+
+	// stderrTailSize is the amount of stderr output retained from a plugin to describe why it failed to start.
+	stderrTailSize = 4096
+)
+
+// WithCommand is an Option that maps the named command to the full name of the gRPC method that implements it, such
+// as "/proto.KV/Get".
+func WithCommand(name, method string) Option {
+	return func(o *options) {
+		o.commands[name] = method
+	}
+}
+
+// WithProtocolVersions is an Option that allows the plugin to negotiate any of the provided application protocol
+// versions, in addition to that given within the Handshake, matching go-plugin's VersionedPlugins. The negotiated
+// version is available via Plugin.ProtocolVersion.
+func WithProtocolVersions(versions ...uint) Option {
+	return func(o *options) {
+		o.protocolVersions = append(o.protocolVersions, versions...)
+	}
+}
+
+// WithStartTimeout is an Option that sets how long Use waits for the plugin to complete the handshake. Defaults to
+// DefaultStartTimeout.
+func WithStartTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.startTimeout = timeout
+	}
+}
+
+// WithShutdownGracePeriod is an Option that sets how long Plugin.Close waits for the plugin to exit after asking it to
+// shut down. Once elapsed, the process is killed. Defaults to DefaultShutdownGracePeriod.
+func WithShutdownGracePeriod(gracePeriod time.Duration) Option {
+	return func(o *options) {
+		o.gracePeriod = gracePeriod
+	}
+}
+
+// WithStderr is an Option that writes the stderr output of the plugin, which go-plugin plugins typically use for
+// logging, to the provided writer. By default, it is discarded.
+func WithStderr(w io.Writer) Option {
+	return func(o *options) {
+		o.stderr = w
+	}
+}
+
+// Use the go-plugin plugin at the given path. The plugin binary is executed with the magic cookie and protocol
+// versions within its environment, and Use waits for it to write its connection details to stdout. Returns
+// ErrHandshakeFailed if the plugin exits or does not complete the handshake within the start timeout, and
+// ErrUnsupportedProtocol if the plugin does not use gRPC.
+//
+// If successful, it is up to the caller to eventually call Plugin.Close when they no longer require use of the plugin.
+func Use(ctx context.Context, path string, handshake Handshake, opts ...Option) (*Plugin, error) {
+	o := options{
+		commands:         make(map[string]string),
+		protocolVersions: []uint{handshake.ProtocolVersion},
+		startTimeout:     DefaultStartTimeout,
+		gracePeriod:      DefaultShutdownGracePeriod,
+		stderr:           io.Discard,
+	}
+
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	versions := make([]string, len(o.protocolVersions))
+	for i, version := range o.protocolVersions {
+		versions[i] = strconv.FormatUint(uint64(version), 10)
+	}
+
+	p := &Plugin{
+		name:    filepath.Base(path),
+		path:    path,
+		options: o,
+		exited:  make(chan struct{}),
+		stderr:  &tailWriter{w: o.stderr, size: stderrTailSize},
+	}
+
+	p.command = exec.Command(path)
+	p.command.Env = append(os.Environ(),
+		handshake.MagicCookieKey+"="+handshake.MagicCookieValue,
+		"PLUGIN_PROTOCOL_VERSIONS="+strings.Join(versions, ","),
+		"PLUGIN_MIN_PORT=10000",
+		"PLUGIN_MAX_PORT=25000",
+	)
+	p.command.Stderr = p.stderr
+
+	stdout, err := p.command.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = p.command.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin at %q: %w", path, err)
+	}
+
+	// The process is always waited on so that it is reaped once it exits, however it exits.
+	go func() {
+		_ = p.command.Wait()
+		close(p.exited)
+	}()
+
+	address, err := p.handshake(ctx, bufio.NewReader(stdout))
+	if err != nil {
+		return nil, errors.Join(err, p.kill())
+	}
+
+	p.conn, err = grpc.NewClient(address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to dial plugin %q: %w", p.name, err), p.kill())
+	}
+
+	p.broker = newBroker(p.conn)
+	return p, nil
+}
+
+// handshake reads the connection details written to stdout by the plugin, returning the gRPC target used to dial it.
+// Any further output is discarded, as plugins are free to write to stdout once started.
+func (p *Plugin) handshake(ctx context.Context, stdout *bufio.Reader) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+
+	lines := make(chan result, 1)
+	go func() {
+		line, err := stdout.ReadString('\n')
+		lines <- result{line: line, err: err}
+		_, _ = io.Copy(io.Discard, stdout)
+	}()
+
+	timer := time.NewTimer(p.options.startTimeout)
+	defer timer.Stop()
+
+	var line string
+	select {
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-timer.C:
+		return "", fmt.Errorf("%w: plugin %q did not complete the handshake within %s", ErrHandshakeFailed, p.name, p.options.startTimeout)
+	case r := <-lines:
+		if r.err != nil {
+			// The plugin exited without writing its connection details, waiting for it ensures its stderr is complete.
+			<-p.exited
+			return "", fmt.Errorf("%w: plugin %q exited: %s", ErrHandshakeFailed, p.name, strings.TrimSpace(p.stderr.String()))
+		}
+
+		line = r.line
+	}
+
+	// The connection details are of the form CORE-VERSION|APP-VERSION|NETWORK|ADDRESS|PROTOCOL.
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) < 4 {
+		return "", fmt.Errorf("%w: plugin %q gave unexpected connection details %q", ErrHandshakeFailed, p.name, line)
+	}
+
+	if parts[0] != coreProtocolVersion {
+		return "", fmt.Errorf("%w: plugin %q uses unsupported core protocol version %s", ErrHandshakeFailed, p.name, parts[0])
+	}
+
+	version, err := strconv.ParseUint(parts[1], 10, 0)
+	if err != nil || !slices.Contains(p.options.protocolVersions, uint(version)) {
+		return "", fmt.Errorf("%w: plugin %q uses unsupported protocol version %s", ErrHandshakeFailed, p.name, parts[1])
+	}
+
+	p.version = uint(version)
+
+	// Plugins that predate the gRPC protocol do not include it within their connection details.
+	protocol := "netrpc"
+	if len(parts) > 4 {
+		protocol = parts[4]
+	}
+
+	if protocol != "grpc" {
+		return "", fmt.Errorf("%w: plugin %q uses the %s protocol", ErrUnsupportedProtocol, p.name, protocol)
+	}
+
+	return target(parts[2], parts[3])
+}
+
+// target returns the gRPC target for the network address.
+func target(network, address string) (string, error) {
+	switch network {
+	case "unix":
+		return "unix://" + address, nil
+	case "tcp":
+		return "passthrough:///" + address, nil
+	default:
+		return "", fmt.Errorf("%w: unsupported network %q", ErrHandshakeFailed, network)
+	}
+}
+
+// Name returns the name of the plugin, which is the base of the path given to Use.
+func (p *Plugin) Name() string {
+	return p.name
+}
+
+// Version returns the negotiated application protocol version, as go-plugin plugins do not describe their own
+// version.
+func (p *Plugin) Version() string {
+	return strconv.FormatUint(uint64(p.version), 10)
+}
+
+// ProtocolVersion returns the negotiated application protocol version.
+func (p *Plugin) ProtocolVersion() uint {
+	return p.version
+}
+
+// Commands returns the commands mapped to gRPC methods using WithCommand, sorted by name.
+func (p *Plugin) Commands() []plugin.CommandInfo {
+	names := slices.Sorted(maps.Keys(p.options.commands))
+
+	commands := make([]plugin.CommandInfo, len(names))
+	for i, name := range names {
+		commands[i] = plugin.CommandInfo{Name: name}
+	}
+
+	return commands
+}
+
+// HasCommand returns true if the named command has been mapped to a gRPC method using WithCommand.
+func (p *Plugin) HasCommand(name string) bool {
+	_, ok := p.options.commands[name]
+	return ok
+}
+
+// Exec invokes the gRPC method mapped to the named command, unmarshalling its response into the provided output
+// parameter. Returns plugin.ErrUnknownCommand if the command has not been mapped using WithCommand. Errors returned by
+// the plugin are returned as their gRPC status. The ExecOption values accepted by plugin.Plugin are not supported and
+// are ignored.
+func (p *Plugin) Exec(ctx context.Context, name string, input proto.Message, output proto.Message, _ ...plugin.ExecOption) error {
+	method, ok := p.options.commands[name]
+	if !ok {
+		return fmt.Errorf("%w: %q", plugin.ErrUnknownCommand, name)
+	}
+
+	return p.conn.Invoke(ctx, method, input, output)
+}
+
+// Conn returns the connection to the plugin, which can be used with the gRPC clients generated for the services it
+// provides.
+func (p *Plugin) Conn() grpc.ClientConnInterface {
+	return p.conn
+}
+
+// Broker returns the Broker used to serve and dial additional gRPC services between the host application and the
+// plugin, as go-plugin's GRPCBroker would.
+func (p *Plugin) Broker() *Broker {
+	return p.broker
+}
+
+// Close the plugin. The plugin is asked to shut down via go-plugin's GRPCController service, and is killed if it has
+// not exited within the shutdown grace period.
+func (p *Plugin) Close() error {
+	p.close.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), p.options.gracePeriod)
+		defer cancel()
+
+		// Plugins that do not provide the controller service are sent a SIGTERM signal instead.
+		if err := p.conn.Invoke(ctx, shutdownMethod, &frame{}, &frame{}, grpc.ForceCodec(frameCodec{})); err != nil {
+			_ = p.command.Process.Signal(syscall.SIGTERM)
+		}
+
+		var errs []error
+		select {
+		case <-p.exited:
+		case <-ctx.Done():
+			errs = append(errs, p.kill())
+		}
+
+		errs = append(errs, p.broker.close(), p.conn.Close())
+		p.closeErr = errors.Join(errs...)
+	})
+
+	return p.closeErr
+}
+
+func (p *Plugin) kill() error {
+	if err := p.command.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+
+	<-p.exited
+	return nil
+}
+
+// The tailWriter type is an io.Writer that passes writes to another writer, retaining only the most recent output.
+type tailWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	size int
+	data []byte
+}
+
+func (tw *tailWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	tw.data = append(tw.data, p...)
+	if len(tw.data) > tw.size {
+		tw.data = tw.data[len(tw.data)-tw.size:]
+	}
+	tw.mu.Unlock()
+
+	return tw.w.Write(p)
+}
+
+func (tw *tailWriter) String() string {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	return string(tw.data)
+}
@@ -0,0 +1,168 @@
+package goplugin_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/goplugin"
+)
+
+var handshake = goplugin.Handshake{
+	ProtocolVersion:  2,
+	MagicCookieKey:   "LEGACY_PLUGIN",
+	MagicCookieValue: "hello",
+}
+
+func TestUse(t *testing.T) {
+	t.Parallel()
+
+	stderr := &strings.Builder{}
+	p, err := goplugin.Use(t.Context(), "../legacy_plugin", handshake,
+		goplugin.WithCommand("check", "/grpc.health.v1.Health/Check"),
+		goplugin.WithStderr(stderr),
+	)
+	require.NoError(t, err)
+
+	var _ plugin.Client = p
+
+	t.Run("describes the plugin", func(t *testing.T) {
+		assert.EqualValues(t, "legacy_plugin", p.Name())
+		assert.EqualValues(t, "2", p.Version())
+		assert.EqualValues(t, []plugin.CommandInfo{{Name: "check"}}, p.Commands())
+		assert.True(t, p.HasCommand("check"))
+		assert.False(t, p.HasCommand("unknown"))
+	})
+
+	t.Run("executes mapped commands", func(t *testing.T) {
+		output := &grpc_health_v1.HealthCheckResponse{}
+		require.NoError(t, p.Exec(t.Context(), "check", &grpc_health_v1.HealthCheckRequest{}, output))
+		assert.EqualValues(t, grpc_health_v1.HealthCheckResponse_SERVING, output.GetStatus())
+	})
+
+	t.Run("error for unknown commands", func(t *testing.T) {
+		err := p.Exec(t.Context(), "unknown", &grpc_health_v1.HealthCheckRequest{}, &grpc_health_v1.HealthCheckResponse{})
+		assert.ErrorIs(t, err, plugin.ErrUnknownCommand)
+	})
+
+	t.Run("serves host services to the plugin", func(t *testing.T) {
+		broker := p.Broker()
+		id := broker.NextID()
+		require.NoError(t, broker.AcceptAndServe(id, func(registrar grpc.ServiceRegistrar) {
+			grpc_health_v1.RegisterHealthServer(registrar, health.NewServer())
+		}))
+
+		output := &grpc_health_v1.HealthCheckResponse{}
+		require.NoError(t, p.Exec(t.Context(), "check", &grpc_health_v1.HealthCheckRequest{Service: "host"}, output))
+		assert.EqualValues(t, grpc_health_v1.HealthCheckResponse_SERVING, output.GetStatus())
+	})
+
+	t.Run("dials services served by the plugin", func(t *testing.T) {
+		conn, err := p.Broker().Dial(t.Context(), 2)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, conn.Close())
+		})
+
+		output, err := grpc_health_v1.NewHealthClient(conn).Check(t.Context(), &grpc_health_v1.HealthCheckRequest{Service: "brokered"})
+		require.NoError(t, err)
+		assert.EqualValues(t, grpc_health_v1.HealthCheckResponse_SERVING, output.GetStatus())
+	})
+
+	t.Run("shuts down the plugin", func(t *testing.T) {
+		start := time.Now()
+		require.NoError(t, p.Close())
+		assert.Less(t, time.Since(start), goplugin.DefaultShutdownGracePeriod)
+		assert.Contains(t, stderr.String(), "plugin started")
+
+		err := p.Exec(t.Context(), "check", &grpc_health_v1.HealthCheckRequest{}, &grpc_health_v1.HealthCheckResponse{})
+		assert.Error(t, err)
+	})
+}
+
+func TestUse_Errors(t *testing.T) {
+	t.Parallel()
+
+	binary, err := filepath.Abs("../legacy_plugin")
+	require.NoError(t, err)
+
+	// script returns the path to a script that executes the shell command.
+	script := func(t *testing.T, command string) string {
+		path := filepath.Join(t.TempDir(), "legacy_plugin")
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+command+"\n"), 0o755))
+		return path
+	}
+
+	tt := []struct {
+		Name      string
+		Path      string
+		Handshake goplugin.Handshake
+		Options   []goplugin.Option
+		Expected  error
+		Message   string
+	}{
+		{
+			Name:      "error for an incorrect magic cookie",
+			Path:      binary,
+			Handshake: goplugin.Handshake{ProtocolVersion: 2, MagicCookieKey: "LEGACY_PLUGIN", MagicCookieValue: "goodbye"},
+			Expected:  goplugin.ErrHandshakeFailed,
+			Message:   "not meant to be executed directly",
+		},
+		{
+			Name:      "error for an unsupported protocol version",
+			Path:      binary,
+			Handshake: goplugin.Handshake{ProtocolVersion: 1, MagicCookieKey: "LEGACY_PLUGIN", MagicCookieValue: "hello"},
+			Expected:  goplugin.ErrHandshakeFailed,
+			Message:   "protocol version 2 is not supported",
+		},
+		{
+			Name:      "error for plugins using net/rpc",
+			Path:      script(t, "LEGACY_PLUGIN_PROTOCOL=netrpc exec "+binary),
+			Handshake: handshake,
+			Expected:  goplugin.ErrUnsupportedProtocol,
+		},
+		{
+			Name:      "error if the handshake times out",
+			Path:      script(t, "exec sleep 10"),
+			Handshake: handshake,
+			Options:   []goplugin.Option{goplugin.WithStartTimeout(100 * time.Millisecond)},
+			Expected:  goplugin.ErrHandshakeFailed,
+			Message:   "did not complete the handshake",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := goplugin.Use(t.Context(), tc.Path, tc.Handshake, tc.Options...)
+			require.ErrorIs(t, err, tc.Expected)
+			assert.Contains(t, err.Error(), tc.Message)
+		})
+	}
+
+	t.Run("negotiates additional protocol versions", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := goplugin.Use(t.Context(), binary, goplugin.Handshake{
+			ProtocolVersion:  1,
+			MagicCookieKey:   "LEGACY_PLUGIN",
+			MagicCookieValue: "hello",
+		}, goplugin.WithProtocolVersions(2))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, p.Close())
+		})
+
+		assert.EqualValues(t, 2, p.ProtocolVersion())
+	})
+}
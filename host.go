@@ -0,0 +1,43 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The Host type describes the host application driving a plugin, as identified using WithHost and returned by
+	// HostFromContext.
+	Host struct {
+		// The Name of the host application.
+		Name string
+		// The Version of the host application.
+		Version string
+	}
+)
+
+// ErrIncompatibleHost is the error returned by Use and Connect when the plugin requires a newer version of the host
+// application, as given by Config.MinHostVersion, than the one provided using WithHost.
+var ErrIncompatibleHost = plugin.ErrIncompatibleHost
+
+// WithHost is a UseOption that identifies the host application to the plugin using its name and semantic version.
+// Plugins obtain them using HostFromContext, and those that set Config.MinHostVersion refuse to be used by older host
+// applications, or by host applications that do not identify themselves, in which case ErrIncompatibleHost is
+// returned.
+func WithHost(name, version string) UseOption {
+	return func(o *useOptions) {
+		o.host = plugin.Host{Name: name, Version: version}
+	}
+}
+
+// HostFromContext returns the host application driving the plugin, within the context given to a command. Returns
+// false if the host application has not identified itself using WithHost.
+func HostFromContext(ctx context.Context) (Host, bool) {
+	host, ok := plugin.HostFromContext(ctx)
+	if !ok {
+		return Host{}, false
+	}
+
+	return Host{Name: host.Name, Version: host.Version}, true
+}
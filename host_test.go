@@ -0,0 +1,80 @@
+package plugin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestWithHost(t *testing.T) {
+	plugin.Register(plugin.Config{
+		Name:           "host_plugin",
+		Version:        "v1.0.0",
+		MinHostVersion: "v2.0.0",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*emptypb.Empty, *wrapperspb.StringValue]{
+				Use: "host",
+				Run: func(ctx context.Context, _ *emptypb.Empty) (*wrapperspb.StringValue, error) {
+					host, ok := plugin.HostFromContext(ctx)
+					if !ok {
+						return wrapperspb.String(""), nil
+					}
+
+					return wrapperspb.String(host.Name + "@" + host.Version), nil
+				},
+			},
+		},
+	})
+
+	tt := []struct {
+		Name        string
+		Options     []plugin.UseOption
+		Expected    string
+		ExpectedErr error
+	}{
+		{
+			Name:     "provides host to commands",
+			Options:  []plugin.UseOption{plugin.WithHost("host", "v2.1.0")},
+			Expected: "host@v2.1.0",
+		},
+		{
+			Name:        "rejects older host",
+			Options:     []plugin.UseOption{plugin.WithHost("host", "v1.9.0")},
+			ExpectedErr: plugin.ErrIncompatibleHost,
+		},
+		{
+			Name:        "rejects host with invalid version",
+			Options:     []plugin.UseOption{plugin.WithHost("host", "latest")},
+			ExpectedErr: plugin.ErrIncompatibleHost,
+		},
+		{
+			Name:        "rejects unidentified host",
+			ExpectedErr: plugin.ErrIncompatibleHost,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			p, err := plugin.Use(t.Context(), "host_plugin", tc.Options...)
+			if tc.ExpectedErr != nil {
+				assert.ErrorIs(t, err, tc.ExpectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, p.Close())
+			})
+
+			output := &wrapperspb.StringValue{}
+			require.NoError(t, p.Exec(t.Context(), "host", &emptypb.Empty{}, output))
+			assert.EqualValues(t, tc.Expected, output.GetValue())
+		})
+	}
+}
@@ -0,0 +1,418 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The SharedDirectory type describes a directory of the host application that is shared with a plugin, as given to
+	// WithSharedDirectory.
+	SharedDirectory struct {
+		// The Path of the directory.
+		Path string
+		// ReadOnly prevents the plugin from writing to files within the directory.
+		ReadOnly bool
+	}
+
+	// The HostFS type is an fs.FS implementation that provides access to the directory shared with the plugin by its
+	// host application, as returned by FilesystemFromContext. Names are slash-separated paths relative to the shared
+	// directory, as described by fs.ValidPath.
+	HostFS struct {
+		ctx    context.Context
+		broker *plugin.Broker
+	}
+
+	hostFile struct {
+		fs     *HostFS
+		name   string
+		info   fs.FileInfo
+		offset int64
+		read   int
+	}
+
+	hostFileInfo struct {
+		info *pb.FileInfo
+	}
+)
+
+// ErrNoSharedDirectory is the error returned by the methods of HostFS when the host application has not shared a
+// directory with the plugin using WithSharedDirectory.
+var ErrNoSharedDirectory = errors.New("no shared directory")
+
+// hostFSChunkSize is the maximum number of bytes read or written by a single call to the host application.
+const hostFSChunkSize = plugin.DefaultChunkSize
+
+var (
+	_ fs.ReadDirFS  = (*HostFS)(nil)
+	_ fs.ReadFileFS = (*HostFS)(nil)
+	_ fs.StatFS     = (*HostFS)(nil)
+)
+
+// WithSharedDirectory is a UseOption that shares a directory of the host application with the plugin, which plugins
+// access using FilesystemFromContext. The plugin can only access files within the directory, regardless of the
+// filesystem or permissions of the plugin process, so that plugins within sandboxes or containers can exchange files
+// with the host application.
+func WithSharedDirectory(directory SharedDirectory) UseOption {
+	return func(o *useOptions) {
+		o.sharedDirectory = &directory
+	}
+}
+
+// FilesystemFromContext returns the filesystem providing access to the directory shared with the plugin by its host
+// application, within the context given to a command or to Config.Configure. Calls to the host application made by
+// the filesystem are cancelled with the context. If the host application has not shared a directory, its methods
+// return ErrNoSharedDirectory.
+func FilesystemFromContext(ctx context.Context) *HostFS {
+	return &HostFS{ctx: ctx, broker: plugin.BrokerFromContext(ctx)}
+}
+
+// Open the named file for reading.
+func (f *HostFS) Open(name string) (fs.File, error) {
+	info, err := f.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &hostFile{fs: f, name: name, info: info}, nil
+}
+
+// Stat returns a description of the named file.
+func (f *HostFS) Stat(name string) (fs.FileInfo, error) {
+	response := &pb.StatFileResponse{}
+	if err := f.call("stat", name, pb.HostService_StatFile_FullMethodName, &pb.StatFileRequest{Path: name}, response); err != nil {
+		return nil, err
+	}
+
+	return hostFileInfo{info: response.GetInfo()}, nil
+}
+
+// ReadDir reads the named directory, returning its entries sorted by name.
+func (f *HostFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	response := &pb.ReadDirResponse{}
+	if err := f.call("readdir", name, pb.HostService_ReadDir_FullMethodName, &pb.ReadDirRequest{Path: name}, response); err != nil {
+		return nil, err
+	}
+
+	entries := make([]fs.DirEntry, 0, len(response.GetEntries()))
+	for _, entry := range response.GetEntries() {
+		entries = append(entries, fs.FileInfoToDirEntry(hostFileInfo{info: entry}))
+	}
+
+	return entries, nil
+}
+
+// ReadFile reads the named file, returning its contents.
+func (f *HostFS) ReadFile(name string) ([]byte, error) {
+	var data []byte
+	for {
+		chunk, eof, err := f.readAt(name, int64(len(data)), hostFSChunkSize)
+		if err != nil {
+			return nil, err
+		}
+
+		data = append(data, chunk...)
+		if eof {
+			return data, nil
+		}
+	}
+}
+
+// WriteFile writes data to the named file, creating it if necessary and replacing its contents otherwise. Returns an
+// error wrapping fs.ErrPermission if the shared directory is read-only.
+func (f *HostFS) WriteFile(name string, data []byte) error {
+	for offset := 0; offset == 0 || offset < len(data); offset += hostFSChunkSize {
+		request := &pb.WriteFileRequest{
+			Path:   name,
+			Data:   data[offset:min(offset+hostFSChunkSize, len(data))],
+			Append: offset > 0,
+		}
+
+		if err := f.call("write", name, pb.HostService_WriteFile_FullMethodName, request, &pb.WriteFileResponse{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *HostFS) readAt(name string, offset int64, length int) ([]byte, bool, error) {
+	response := &pb.ReadFileResponse{}
+	request := &pb.ReadFileRequest{Path: name, Offset: offset, Length: int64(length)}
+	if err := f.call("read", name, pb.HostService_ReadFile_FullMethodName, request, response); err != nil {
+		return nil, false, err
+	}
+
+	return response.GetData(), response.GetEof(), nil
+}
+
+// call the named method of the host application, returning errors as fs.PathError values.
+func (f *HostFS) call(op, name, method string, input, output proto.Message) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: op, Path: name, Err: fs.ErrInvalid}
+	}
+
+	encoded, err := anypb.New(input)
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: err}
+	}
+
+	result, err := f.broker.Call(f.ctx, method, encoded)
+	if err == nil {
+		err = result.UnmarshalTo(output)
+	}
+
+	if err != nil {
+		return &fs.PathError{Op: op, Path: name, Err: fsError(err)}
+	}
+
+	return nil
+}
+
+// fsError converts errors returned by the host application into their equivalent fs package errors.
+func fsError(err error) error {
+	if errors.Is(err, plugin.ErrNoBroker) {
+		return ErrNoSharedDirectory
+	}
+
+	switch status.Code(err) {
+	case codes.Unimplemented:
+		return ErrNoSharedDirectory
+	case codes.NotFound:
+		return fs.ErrNotExist
+	case codes.PermissionDenied:
+		return fs.ErrPermission
+	case codes.AlreadyExists:
+		return fs.ErrExist
+	default:
+		return err
+	}
+}
+
+// Stat returns a description of the file.
+func (hf *hostFile) Stat() (fs.FileInfo, error) {
+	return hf.info, nil
+}
+
+// Read reads up to len(p) bytes from the file.
+func (hf *hostFile) Read(p []byte) (int, error) {
+	if hf.info.IsDir() {
+		return 0, &fs.PathError{Op: "read", Path: hf.name, Err: errors.New("is a directory")}
+	}
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	data, eof, err := hf.fs.readAt(hf.name, hf.offset, min(len(p), hostFSChunkSize))
+	if err != nil {
+		return 0, err
+	}
+
+	n := copy(p, data)
+	hf.offset += int64(n)
+	if n == 0 && eof {
+		return 0, io.EOF
+	}
+
+	return n, nil
+}
+
+// ReadDir reads the contents of the directory, returning at most n entries if n is positive, as described by
+// fs.ReadDirFile.
+func (hf *hostFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries, err := hf.fs.ReadDir(hf.name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries = entries[min(hf.read, len(entries)):]
+	if n > 0 {
+		if len(entries) == 0 {
+			return nil, io.EOF
+		}
+
+		entries = entries[:min(n, len(entries))]
+	}
+
+	hf.read += len(entries)
+	return entries, nil
+}
+
+// Close the file.
+func (hf *hostFile) Close() error {
+	return nil
+}
+
+// Name returns the name of the file.
+func (fi hostFileInfo) Name() string {
+	return fi.info.GetName()
+}
+
+// Size returns the size of the file in bytes.
+func (fi hostFileInfo) Size() int64 {
+	return fi.info.GetSize()
+}
+
+// Mode returns the mode of the file.
+func (fi hostFileInfo) Mode() fs.FileMode {
+	return fs.FileMode(fi.info.GetMode())
+}
+
+// ModTime returns the time the file was last modified.
+func (fi hostFileInfo) ModTime() time.Time {
+	return fi.info.GetModified().AsTime()
+}
+
+// IsDir returns true if the file is a directory.
+func (fi hostFileInfo) IsDir() bool {
+	return fi.Mode().IsDir()
+}
+
+// Sys returns nil, as the underlying data source is the host application.
+func (fi hostFileInfo) Sys() any {
+	return nil
+}
+
+// sharedDirectoryHandlers returns the functions that handle calls made by the plugin via FilesystemFromContext, which
+// access the directory given via WithSharedDirectory.
+func (p *Plugin) sharedDirectoryHandlers() plugin.HostHandlers {
+	directory := *p.options.sharedDirectory
+
+	// The largest read is bounded by the maximum message size, leaving room for the rest of the response.
+	maxRead := int64(max(p.options.maxMessageSize/2, 1))
+
+	return plugin.HostHandlers{
+		pb.HostService_StatFile_FullMethodName: sharedDirectoryHandler(directory.Path, func(root *os.Root, request *pb.StatFileRequest) (*pb.StatFileResponse, error) {
+			info, err := root.Stat(filepath.FromSlash(request.GetPath()))
+			if err != nil {
+				return nil, err
+			}
+
+			return &pb.StatFileResponse{Info: fileInfo(info)}, nil
+		}),
+		pb.HostService_ReadDir_FullMethodName: sharedDirectoryHandler(directory.Path, func(root *os.Root, request *pb.ReadDirRequest) (*pb.ReadDirResponse, error) {
+			entries, err := fs.ReadDir(root.FS(), request.GetPath())
+			if err != nil {
+				return nil, err
+			}
+
+			response := &pb.ReadDirResponse{}
+			for _, entry := range entries {
+				info, err := entry.Info()
+				if err != nil {
+					return nil, err
+				}
+
+				response.Entries = append(response.Entries, fileInfo(info))
+			}
+
+			return response, nil
+		}),
+		pb.HostService_ReadFile_FullMethodName: sharedDirectoryHandler(directory.Path, func(root *os.Root, request *pb.ReadFileRequest) (*pb.ReadFileResponse, error) {
+			file, err := root.Open(filepath.FromSlash(request.GetPath()))
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+
+			data := make([]byte, min(max(request.GetLength(), 0), maxRead))
+			n, err := file.ReadAt(data, request.GetOffset())
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+
+			return &pb.ReadFileResponse{Data: data[:n], Eof: errors.Is(err, io.EOF)}, nil
+		}),
+		pb.HostService_WriteFile_FullMethodName: sharedDirectoryHandler(directory.Path, func(root *os.Root, request *pb.WriteFileRequest) (*pb.WriteFileResponse, error) {
+			if directory.ReadOnly {
+				return nil, fs.ErrPermission
+			}
+
+			flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+			if request.GetAppend() {
+				flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+			}
+
+			file, err := root.OpenFile(filepath.FromSlash(request.GetPath()), flags, 0o644)
+			if err != nil {
+				return nil, err
+			}
+
+			if _, err = file.Write(request.GetData()); err != nil {
+				return nil, errors.Join(err, file.Close())
+			}
+
+			return &pb.WriteFileResponse{}, file.Close()
+		}),
+	}
+}
+
+// sharedDirectoryHandler returns a function that handles a call to one of the filesystem methods of the HostService,
+// given the shared directory opened as an os.Root so that paths cannot escape it.
+func sharedDirectoryHandler[Request interface {
+	proto.Message
+	GetPath() string
+}, Response proto.Message](directory string, fn func(root *os.Root, request Request) (Response, error)) func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+	return func(_ context.Context, input *anypb.Any) (*anypb.Any, error) {
+		request := newMessage[Request]()
+		if err := input.UnmarshalTo(request); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		// Paths such as "../file" would be outside the shared directory.
+		if !fs.ValidPath(request.GetPath()) {
+			return nil, status.Errorf(codes.PermissionDenied, "path %q is outside the shared directory", request.GetPath())
+		}
+
+		root, err := os.OpenRoot(directory)
+		if err != nil {
+			return nil, status.Errorf(codes.Unavailable, "failed to open shared directory: %v", err)
+		}
+		defer root.Close()
+
+		response, err := fn(root, request)
+		if err != nil {
+			return nil, sharedDirectoryError(err)
+		}
+
+		return anypb.New(response)
+	}
+}
+
+func sharedDirectoryError(err error) error {
+	code := codes.Internal
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		code = codes.NotFound
+	case errors.Is(err, fs.ErrPermission):
+		code = codes.PermissionDenied
+	case errors.Is(err, fs.ErrExist):
+		code = codes.AlreadyExists
+	}
+
+	return status.Error(code, err.Error())
+}
+
+func fileInfo(info fs.FileInfo) *pb.FileInfo {
+	return &pb.FileInfo{
+		Name:     info.Name(),
+		Size:     info.Size(),
+		Mode:     uint32(info.Mode()),
+		Modified: timestamppb.New(info.ModTime()),
+	}
+}
@@ -0,0 +1,176 @@
+package plugin_test
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestWithSharedDirectory(t *testing.T) {
+	t.Parallel()
+
+	type (
+		Input  = wrapperspb.StringValue
+		Output = wrapperspb.StringValue
+	)
+
+	config := plugin.Config{
+		Name: "shared_directory",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*Input, *Output]{
+				Use: "read",
+				Run: func(ctx context.Context, input *Input) (*Output, error) {
+					file, err := plugin.FilesystemFromContext(ctx).Open(input.GetValue())
+					if err != nil {
+						return nil, err
+					}
+					defer file.Close()
+
+					data, err := io.ReadAll(file)
+					if err != nil {
+						return nil, err
+					}
+
+					return wrapperspb.String(string(data)), nil
+				},
+			},
+			&plugin.Command[*Input, *Output]{
+				Use: "write",
+				Run: func(ctx context.Context, input *Input) (*Output, error) {
+					name, data, _ := strings.Cut(input.GetValue(), "=")
+					if err := plugin.FilesystemFromContext(ctx).WriteFile(name, []byte(data)); err != nil {
+						return nil, err
+					}
+
+					return wrapperspb.String(name), nil
+				},
+			},
+			&plugin.Command[*Input, *Output]{
+				Use: "list",
+				Run: func(ctx context.Context, input *Input) (*Output, error) {
+					var names []string
+					err := fs.WalkDir(plugin.FilesystemFromContext(ctx), input.GetValue(), func(path string, _ fs.DirEntry, err error) error {
+						names = append(names, path)
+						return err
+					})
+					if err != nil {
+						return nil, err
+					}
+
+					return wrapperspb.String(strings.Join(names, ",")), nil
+				},
+			},
+		},
+	}
+
+	directory := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(directory, "nested"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "nested", "file.txt"), []byte("hello"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(directory, "large.txt"), []byte(strings.Repeat("a", 256*1024)), 0o644))
+
+	writable := t.TempDir()
+
+	tt := []struct {
+		Name     string
+		Options  []plugin.UseOption
+		Command  string
+		Input    string
+		Expected string
+		Err      string
+		Check    func(t *testing.T)
+	}{
+		{
+			Name:     "reads files",
+			Options:  []plugin.UseOption{plugin.WithSharedDirectory(plugin.SharedDirectory{Path: directory, ReadOnly: true})},
+			Command:  "read",
+			Input:    "nested/file.txt",
+			Expected: "hello",
+		},
+		{
+			Name:     "reads large files",
+			Options:  []plugin.UseOption{plugin.WithSharedDirectory(plugin.SharedDirectory{Path: directory, ReadOnly: true})},
+			Command:  "read",
+			Input:    "large.txt",
+			Expected: strings.Repeat("a", 256*1024),
+		},
+		{
+			Name:     "lists directories",
+			Options:  []plugin.UseOption{plugin.WithSharedDirectory(plugin.SharedDirectory{Path: directory, ReadOnly: true})},
+			Command:  "list",
+			Input:    ".",
+			Expected: ".,large.txt,nested,nested/file.txt",
+		},
+		{
+			Name:     "writes files",
+			Options:  []plugin.UseOption{plugin.WithSharedDirectory(plugin.SharedDirectory{Path: writable})},
+			Command:  "write",
+			Input:    "output.txt=written",
+			Expected: "output.txt",
+			Check: func(t *testing.T) {
+				data, err := os.ReadFile(filepath.Join(writable, "output.txt"))
+				require.NoError(t, err)
+				assert.EqualValues(t, "written", string(data))
+			},
+		},
+		{
+			Name:    "fails to write to read-only directory",
+			Options: []plugin.UseOption{plugin.WithSharedDirectory(plugin.SharedDirectory{Path: directory, ReadOnly: true})},
+			Command: "write",
+			Input:   "output.txt=written",
+			Err:     fs.ErrPermission.Error(),
+			Check: func(t *testing.T) {
+				assert.NoFileExists(t, filepath.Join(directory, "output.txt"))
+			},
+		},
+		{
+			Name:    "fails to read missing files",
+			Options: []plugin.UseOption{plugin.WithSharedDirectory(plugin.SharedDirectory{Path: directory, ReadOnly: true})},
+			Command: "read",
+			Input:   "missing.txt",
+			Err:     fs.ErrNotExist.Error(),
+		},
+		{
+			Name:    "fails to read outside shared directory",
+			Options: []plugin.UseOption{plugin.WithSharedDirectory(plugin.SharedDirectory{Path: filepath.Join(directory, "nested"), ReadOnly: true})},
+			Command: "read",
+			Input:   "../large.txt",
+			Err:     fs.ErrInvalid.Error(),
+		},
+		{
+			Name:    "fails without shared directory",
+			Command: "read",
+			Input:   "nested/file.txt",
+			Err:     plugin.ErrNoSharedDirectory.Error(),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			p := plugintest.New(t, config, tc.Options...)
+
+			output := &Output{}
+			err := p.Exec(t.Context(), tc.Command, wrapperspb.String(tc.Input), output)
+			if tc.Err != "" {
+				assert.ErrorContains(t, err, tc.Err)
+			} else {
+				require.NoError(t, err)
+				assert.EqualValues(t, tc.Expected, output.GetValue())
+			}
+
+			if tc.Check != nil {
+				tc.Check(t)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+
+	"github.com/rs/xid"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/davidsbond/plugin/internal/inprocess"
+	"github.com/davidsbond/plugin/internal/plugin"
+	"github.com/davidsbond/plugin/internal/shm"
+)
+
+// inProcessBufferSize is the size, in bytes, of the in-memory buffer used by connections to in-process plugins.
+const inProcessBufferSize = 1 << 20
+
+func init() {
+	inprocess.Use = useInProcess
+}
+
+// useInProcess serves the commands within the provided Config from within the host application, returning a Plugin
+// that communicates with them via gRPC over an in-memory connection. No binary is executed and no UNIX domain socket
+// is created, but calls are otherwise handled exactly as they would be by a plugin started via Use. It is used for
+// plugins registered using Register, and by the plugintest package to test plugins and host applications without
+// building plugin binaries.
+//
+// Options relating to the plugin binary, such as WithChecksum, have no effect. The Config.VerifyPeer field is ignored,
+// as there is no peer process to verify.
+func useInProcess(ctx context.Context, config Config, opts ...UseOption) (*Plugin, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	return use(ctx, &Plugin{name: config.Name, config: &config}, opts...)
+}
+
+func (p *Plugin) startInProcess(ctx context.Context) (*process, plugin.Info, error) {
+	listener := bufconn.Listen(inProcessBufferSize)
+	token := plugin.NewToken()
+
+	// Shared memory is passed between processes as file descriptors, which cannot be sent over an in-memory
+	// connection, so a UNIX domain socket is still used for it.
+	var memoryListener net.Listener
+	memorySocket := sharedMemorySocketPath(socketPath(p.options.socketDirectory, xid.New().String()))
+	if p.config.SharedMemory && shm.Supported {
+		var err error
+		if memoryListener, err = listen(*p.config, memorySocket); err != nil {
+			return nil, plugin.Info{}, fmt.Errorf("failed to start plugin %q: %w", p.name, err)
+		}
+	}
+
+	serveCtx, stop := context.WithCancel(context.Background())
+	proc := &process{
+		command:     &exec.Cmd{},
+		clock:       p.options.clock,
+		gracePeriod: p.options.shutdownGracePeriod,
+		exited:      make(chan struct{}),
+		logger:      p.diagnostics(),
+		stderr:      newTailBuffer(stderrTailSize),
+		stop:        stop,
+		token:       token,
+		memory:      memorySocket,
+	}
+
+	go func() {
+		err := serve(serveCtx, *p.config, listener, memoryListener, getPluginVersion(*p.config), token)
+		proc.status = ExitStatus{Time: p.options.clock.Now()}
+		if err != nil {
+			proc.status.Code = 1
+			_, _ = fmt.Fprintln(proc.stderr, err)
+		}
+
+		p.recordExit(proc.status)
+		close(proc.exited)
+	}()
+
+	dialer := plugin.WithDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	})
+
+	var err error
+	proc.client, err = plugin.NewClient("/"+p.name, append(p.clientOptions(token), dialer)...)
+	if err != nil {
+		return nil, plugin.Info{}, errors.Join(fmt.Errorf("failed to dial plugin %q: %w", p.name, err), proc.close())
+	}
+
+	info, err := proc.client.Stat(ctx)
+	if err != nil {
+		return nil, plugin.Info{}, errors.Join(fmt.Errorf("failed to start plugin %q: %w", p.name, err), proc.close())
+	}
+
+	if err = checkVersion(p.name, info.Version, p.options.versionConstraint); err != nil {
+		return nil, plugin.Info{}, errors.Join(err, proc.close())
+	}
+
+	return proc, info, nil
+}
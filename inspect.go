@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"context"
+	"time"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The ProcessDetails type describes a running plugin process and the environment it was built for, as reported
+	// by the process itself. It can be used by host applications to determine exactly what is running without
+	// inspecting the machine the plugin runs on.
+	ProcessDetails struct {
+		// The process identifier of the plugin process. For plugins served in-process, this is that of the host
+		// application.
+		PID int
+		// The time the process started.
+		StartedAt time.Time
+		// The time elapsed since the process started, as measured by the process.
+		Uptime time.Duration
+		// The operating system the plugin was built for, as given by runtime.GOOS.
+		OS string
+		// The architecture the plugin was built for, as given by runtime.GOARCH.
+		Arch string
+		// The version of the runtime or toolchain the plugin was built with, as given by runtime.Version for plugins
+		// written in Go.
+		RuntimeVersion string
+		// The version of the plugin protocol implemented by the plugin, which can be compared with ProtocolVersion.
+		ProtocolVersion int
+		// The version control revision the plugin was built from, as recorded by the Go toolchain. Empty if the
+		// plugin was built without version control information.
+		VCSRevision string
+		// The time of the version control revision the plugin was built from, if known.
+		VCSTime time.Time
+		// Whether the plugin was built from a working tree containing uncommitted changes.
+		VCSModified bool
+	}
+)
+
+// ProtocolVersion is the version of the plugin protocol implemented by this package.
+const ProtocolVersion = plugin.ProtocolVersion
+
+// Inspect queries each running plugin process for details of itself, such as its process identifier, uptime and the
+// platform and toolchain it was built for. When the plugin is running more than one instance, as set using
+// WithInstances, the details of each instance are returned. Plugins that predate these details are described by an
+// empty ProcessDetails.
+func (p *Plugin) Inspect(ctx context.Context) ([]ProcessDetails, error) {
+	release := p.hold()
+	defer release()
+
+	pl, err := p.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]ProcessDetails, 0, len(pl.processes))
+	for _, proc := range pl.processes {
+		process, err := proc.client.Process(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		details = append(details, ProcessDetails(process))
+	}
+
+	return details, nil
+}
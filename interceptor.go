@@ -0,0 +1,62 @@
+package plugin
+
+import (
+	"context"
+	"slices"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	// The ExecInvoker type is a function that executes a command, as given to an ExecInterceptor. It either calls the
+	// next ExecInterceptor within the chain, or executes the command.
+	ExecInvoker func(ctx context.Context, command string, input proto.Message, output proto.Message, opts ...ExecOption) error
+
+	// The ExecInterceptor type is a function that wraps the execution of commands, in the same way as a gRPC client
+	// interceptor, so that reusable logic can be applied to every call to Plugin.Exec or Manager.Exec. It is given the
+	// name of the plugin executing the command, and is responsible for calling invoke to execute it, optionally with
+	// a modified context, input or options. Returning without calling invoke prevents the command from being
+	// executed, such as when the output has been cached or the caller is not authorized.
+	ExecInterceptor func(ctx context.Context, plugin string, command string, input proto.Message, output proto.Message, invoke ExecInvoker, opts ...ExecOption) error
+)
+
+// WithExecInterceptors is a UseOption that wraps every call to Plugin.Exec with the given interceptors. The first
+// interceptor is the outermost, so is called first and returns last. This option can be provided more than once, with
+// interceptors added by later options being called after those added by earlier ones.
+func WithExecInterceptors(interceptors ...ExecInterceptor) UseOption {
+	return func(o *useOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// Intercept wraps every call made to a plugin by Manager.Exec and Manager.ExecAny with the given interceptors, in the
+// same way as WithExecInterceptors. The interceptors are called after any added by previous calls to Intercept, and
+// before any given to the plugin itself using WithExecInterceptors. When Manager.ExecAny tries more than one plugin,
+// the interceptors are called for each plugin tried.
+func (m *Manager) Intercept(interceptors ...ExecInterceptor) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.interceptors = append(m.interceptors, interceptors...)
+}
+
+// invoker returns the ExecInvoker that calls Exec on the plugin, via the interceptors added using Manager.Intercept.
+func (m *Manager) invoker(p Client) ExecInvoker {
+	m.mu.RLock()
+	interceptors := slices.Clone(m.interceptors)
+	m.mu.RUnlock()
+
+	return chainInterceptors(interceptors, p.Name(), p.Exec)
+}
+
+// chainInterceptors returns an ExecInvoker that calls each of the interceptors in turn, followed by invoke.
+func chainInterceptors(interceptors []ExecInterceptor, plugin string, invoke ExecInvoker) ExecInvoker {
+	for _, interceptor := range slices.Backward(interceptors) {
+		next := invoke
+		invoke = func(ctx context.Context, command string, input proto.Message, output proto.Message, opts ...ExecOption) error {
+			return interceptor(ctx, plugin, command, input, output, next, opts...)
+		}
+	}
+
+	return invoke
+}
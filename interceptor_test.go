@@ -0,0 +1,129 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestWithExecInterceptors(t *testing.T) {
+	t.Parallel()
+
+	config := plugin.Config{
+		Name: "intercepted",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "whoami",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					return wrapperspb.String(input.GetValue() + " " + plugin.MetadataFromContext(ctx)["tenant"]), nil
+				},
+			},
+		},
+	}
+
+	var (
+		mu    sync.Mutex
+		calls []string
+	)
+
+	record := func(name string) plugin.ExecInterceptor {
+		return func(ctx context.Context, p string, command string, input, output proto.Message, invoke plugin.ExecInvoker, opts ...plugin.ExecOption) error {
+			mu.Lock()
+			calls = append(calls, name+":"+p+"/"+command)
+			mu.Unlock()
+
+			return invoke(ctx, command, input, output, opts...)
+		}
+	}
+
+	tenant := func(ctx context.Context, _ string, command string, input, output proto.Message, invoke plugin.ExecInvoker, opts ...plugin.ExecOption) error {
+		return invoke(plugin.ContextWithMetadata(ctx, map[string]string{"tenant": "acme"}), command, input, output, opts...)
+	}
+
+	deny := func(ctx context.Context, _ string, command string, input, output proto.Message, invoke plugin.ExecInvoker, opts ...plugin.ExecOption) error {
+		if input.(*wrapperspb.StringValue).GetValue() == "mallory" {
+			return errors.New("unauthorized")
+		}
+
+		return invoke(ctx, command, input, output, opts...)
+	}
+
+	p := plugintest.New(t, config,
+		plugin.WithExecInterceptors(record("first"), deny),
+		plugin.WithExecInterceptors(tenant, record("second")),
+	)
+
+	t.Run("calls interceptors in order", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "whoami", wrapperspb.String("alice"), output))
+		assert.EqualValues(t, "alice acme", output.GetValue())
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.EqualValues(t, []string{"first:intercepted/whoami", "second:intercepted/whoami"}, calls)
+	})
+
+	t.Run("interceptors prevent calls", func(t *testing.T) {
+		err := p.Exec(t.Context(), "whoami", wrapperspb.String("mallory"), &wrapperspb.StringValue{})
+		assert.EqualError(t, err, `plugin "intercepted": command "whoami": unauthorized`)
+	})
+}
+
+func TestManager_Intercept(t *testing.T) {
+	t.Parallel()
+
+	echo := func(_ context.Context, input proto.Message) (proto.Message, error) {
+		return input, nil
+	}
+
+	manager := plugin.NewManager()
+	require.NoError(t, manager.Add(plugintest.NewFake("backup", "v1.0.0").Handle("snapshot", echo)))
+	require.NoError(t, manager.Add(plugintest.NewFake("archive", "v1.0.0").Handle("archive", echo)))
+
+	var (
+		mu      sync.Mutex
+		plugins []string
+	)
+
+	cache := map[string]string{"archive": "cached"}
+	manager.Intercept(
+		func(ctx context.Context, p string, command string, input, output proto.Message, invoke plugin.ExecInvoker, opts ...plugin.ExecOption) error {
+			mu.Lock()
+			plugins = append(plugins, p)
+			mu.Unlock()
+
+			return invoke(ctx, command, input, output, opts...)
+		},
+		func(ctx context.Context, _ string, command string, input, output proto.Message, invoke plugin.ExecInvoker, opts ...plugin.ExecOption) error {
+			if cached, ok := cache[command]; ok {
+				proto.Merge(output, wrapperspb.String(cached))
+				return nil
+			}
+
+			return invoke(ctx, command, input, output, opts...)
+		},
+	)
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, manager.Exec(t.Context(), "backup/snapshot", wrapperspb.String("input"), output))
+	assert.EqualValues(t, "input", output.GetValue())
+
+	output = &wrapperspb.StringValue{}
+	require.NoError(t, manager.ExecAny(t.Context(), "archive", wrapperspb.String("input"), output))
+	assert.EqualValues(t, "cached", output.GetValue())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.EqualValues(t, []string{"backup", "archive"}, plugins)
+}
@@ -0,0 +1,839 @@
+// Package conformance generates canonical wire-level fixtures describing the plugin protocol. These fixtures can be
+// used by authors of plugin implementations in languages other than Go to verify that their implementations remain
+// interoperable with this package as the protocol evolves.
+package conformance
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The Fixture type is a single named file containing canonical protocol data.
+	Fixture struct {
+		// The Name of the fixture, used as its file name.
+		Name string
+		// The Data contained within the fixture.
+		Data []byte
+	}
+
+	// The Handshake type describes how a host application starts a plugin and the first request it makes.
+	Handshake struct {
+		// The arguments passed to the plugin binary, with placeholders in angle brackets.
+		Args []string `json:"args"`
+		// The environment variables set for the plugin process, with placeholders in angle brackets.
+		Env map[string]string `json:"env"`
+		// The path of the UNIX domain socket the plugin must listen on, with placeholders in angle brackets.
+		Socket string `json:"socket"`
+		// The gRPC metadata sent with every request. Plugins must reject requests without it using the
+		// UNAUTHENTICATED status code.
+		Metadata map[string]string `json:"metadata"`
+		// The requests made by the host application once the socket accepts connections, in order.
+		Requests []Exchange `json:"requests"`
+	}
+
+	// The Exchange type describes a single RPC made by a host application to a plugin.
+	Exchange struct {
+		// The full gRPC method name.
+		Method string `json:"method"`
+		// The request message, encoded as protobuf JSON.
+		Request json.RawMessage `json:"request"`
+		// The expected response message, encoded as protobuf JSON.
+		Response json.RawMessage `json:"response"`
+	}
+
+	// The ErrorMapping type describes the gRPC status a plugin must return in a given error scenario.
+	ErrorMapping struct {
+		// A description of the scenario.
+		Scenario string `json:"scenario"`
+		// The full gRPC method name of the RPC that fails.
+		Method string `json:"method"`
+		// The gRPC status returned by the plugin, encoded as protobuf JSON.
+		Status json.RawMessage `json:"status"`
+	}
+
+	namedMessage struct {
+		Name    string
+		Message proto.Message
+	}
+
+	// subscribeStream is a server stream used to capture the events sent by the Subscribe RPC.
+	subscribeStream struct {
+		grpc.ServerStream
+
+		ctx    context.Context
+		events chan *pb.SubscribeResponse
+	}
+
+	// sendFileStream is a client stream used to provide the chunks received by the SendFile RPC.
+	sendFileStream struct {
+		grpc.ServerStream
+
+		ctx      context.Context
+		chunks   []*pb.FileChunk
+		next     int
+		response *pb.SendFileResponse
+	}
+
+	// receiveFileStream is a server stream used to capture the chunks sent by the ReceiveFile RPC.
+	receiveFileStream struct {
+		grpc.ServerStream
+
+		ctx    context.Context
+		chunks []*pb.FileChunk
+	}
+
+	// executeStream is a bidirectional stream used to capture the chunks sent by the ExecuteStream RPC.
+	executeStream struct {
+		grpc.ServerStream
+
+		ctx       context.Context
+		requests  []*pb.ExecuteChunk
+		responses []*pb.ExecuteChunk
+	}
+)
+
+const (
+	commandName     = "pingpong"
+	pluginName      = "example"
+	pluginVersion   = "v1.0.0"
+	executeMethod   = "/plugin.PluginService/Execute"
+	statMethod      = "/plugin.PluginService/Stat"
+	submitJobMethod = "/plugin.PluginService/SubmitJob"
+	getJobMethod    = "/plugin.PluginService/GetJob"
+	cancelJobMethod = "/plugin.PluginService/CancelJob"
+	cancelMethod    = "/plugin.PluginService/Cancel"
+	subscribeMethod = "/plugin.PluginService/Subscribe"
+	socketIDHolder  = "<id>"
+	tokenHolder     = "<token>"
+
+	// Job and request identifiers are generated at random, so fixed values are used to keep the fixtures
+	// deterministic.
+	jobID     = "d0fixturejob0000000g"
+	requestID = "d0fixturerequest000g"
+	fileID    = "d0fixturefile00000g0"
+)
+
+// fixedTime replaces all timestamps within the fixtures, which would otherwise vary between runs.
+var fixedTime = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Generate all fixtures. The output is deterministic, such that the same version of this package always produces
+// identical fixtures.
+func Generate() ([]Fixture, error) {
+	ctx := context.Background()
+
+	api := plugin.NewAPI(plugin.Info{
+		Name:    pluginName,
+		Version: pluginVersion,
+		Commands: []plugin.Command{
+			{
+				Name:        commandName,
+				Description: "Responds to ping with pong, and to pong with ping.",
+				Example:     `"ping"`,
+				Input:       (&wrapperspb.StringValue{}).ProtoReflect().Descriptor(),
+				Output:      (&wrapperspb.StringValue{}).ProtoReflect().Descriptor(),
+			},
+		},
+	}, plugin.CommandHandlers{
+		commandName: pingPong,
+	}, plugin.WithGracePeriod(0))
+	defer api.Close()
+
+	input, err := anypb.New(wrapperspb.String("ping"))
+	if err != nil {
+		return nil, err
+	}
+
+	executeRequest := &pb.ExecuteRequest{Name: commandName, Input: input}
+	executeResponse, err := api.Execute(ctx, executeRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	statRequest := &pb.StatRequest{}
+	statResponse, err := api.Stat(ctx, statRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	describeRequest := &pb.DescribeRequest{}
+	describeResponse, err := api.Describe(ctx, describeRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	statsRequest := &pb.StatsRequest{}
+	statsResponse, err := api.Stats(ctx, statsRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Resource usage varies between calls, so fixed values are used to keep the fixtures deterministic.
+	statResponse.Usage = &pb.ResourceUsage{
+		HeapBytes:     1024,
+		SysBytes:      4096,
+		Goroutines:    8,
+		UserCpuTime:   durationpb.New(time.Second),
+		SystemCpuTime: durationpb.New(time.Millisecond),
+	}
+
+	// The secret key is generated each time the plugin starts, so a fixed key is used for the same reason.
+	statResponse.SecretKey = bytes.Repeat([]byte{0x09}, 32)
+
+	// As are the details of the process serving the plugin.
+	statResponse.Process = &pb.ProcessDetails{
+		Pid:             1234,
+		StartedAt:       timestamppb.New(time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)),
+		Uptime:          durationpb.New(time.Minute),
+		Os:              "linux",
+		Arch:            "amd64",
+		RuntimeVersion:  "go1.25.0",
+		ProtocolVersion: plugin.ProtocolVersion,
+		VcsRevision:     "0123456789abcdef0123456789abcdef01234567",
+		VcsTime:         timestamppb.New(time.Date(2023, time.December, 31, 12, 0, 0, 0, time.UTC)),
+	}
+
+	// As is the time taken to execute the command, and so the latency recorded for it.
+	executeResponse.Duration = durationpb.New(time.Millisecond)
+	for _, command := range statsResponse.GetCommands() {
+		command.LatencyBuckets = make([]uint64, len(plugin.LatencyBuckets)+1)
+		command.LatencyBuckets[0] = command.GetCalls()
+		command.MaxLatency = durationpb.New(time.Millisecond)
+	}
+
+	messages := []namedMessage{
+		{Name: "execute_request", Message: executeRequest},
+		{Name: "execute_response", Message: executeResponse},
+		{Name: "stat_request", Message: statRequest},
+		{Name: "stat_response", Message: statResponse},
+		{Name: "describe_request", Message: describeRequest},
+		{Name: "describe_response", Message: describeResponse},
+		{Name: "stats_request", Message: statsRequest},
+		{Name: "stats_response", Message: statsResponse},
+	}
+
+	for _, generate := range []func(context.Context, *plugin.API) ([]namedMessage, error){generateJobs, generateCancel, generateSubscribe, generateExecuteStream, generateFiles} {
+		generated, err := generate(ctx, api)
+		if err != nil {
+			return nil, err
+		}
+
+		messages = append(messages, generated...)
+	}
+
+	fixtures := make([]Fixture, 0)
+	for _, message := range messages {
+		binary, err := proto.MarshalOptions{Deterministic: true}.Marshal(message.Message)
+		if err != nil {
+			return nil, err
+		}
+
+		text, err := encodeJSON(message.Message)
+		if err != nil {
+			return nil, err
+		}
+
+		indented, err := encodeIndented(text)
+		if err != nil {
+			return nil, err
+		}
+
+		fixtures = append(fixtures,
+			Fixture{Name: message.Name + ".binpb", Data: binary},
+			Fixture{Name: message.Name + ".json", Data: indented},
+		)
+	}
+
+	handshake, err := generateHandshake(statRequest, statResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	mappings, err := generateErrorMappings(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+
+	fixtures = append(fixtures,
+		Fixture{Name: "handshake.json", Data: handshake},
+		Fixture{Name: "errors.json", Data: mappings},
+	)
+
+	return fixtures, nil
+}
+
+func pingPong(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+	value := &wrapperspb.StringValue{}
+	if err := input.UnmarshalTo(value); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	switch value.GetValue() {
+	case "ping":
+		return anypb.New(wrapperspb.String("pong"))
+	case "pong":
+		return anypb.New(wrapperspb.String("ping"))
+	case "fail":
+		return nil, errors.New("command failed")
+	case "wait":
+		<-ctx.Done()
+		return nil, ctx.Err()
+	default:
+		st, err := status.New(codes.InvalidArgument, `expected "ping" or "pong"`).WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: "value", Description: `must be "ping" or "pong"`},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return nil, st.Err()
+	}
+}
+
+// generateJobs submits a job, waits for it to complete and then cancels it. Cancelling a completed job has no effect,
+// so the exchange is the same as for a running job.
+func generateJobs(ctx context.Context, api *plugin.API) ([]namedMessage, error) {
+	submitRequest := &pb.SubmitJobRequest{Name: commandName, Input: mustAny(wrapperspb.String("ping"))}
+	submitResponse, err := api.SubmitJob(ctx, submitRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	getRequest := &pb.GetJobRequest{Id: submitResponse.GetId()}
+	getResponse, err := waitForJob(ctx, api, getRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelRequest := &pb.CancelJobRequest{Id: submitResponse.GetId()}
+	cancelResponse, err := api.CancelJob(ctx, cancelRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	submitResponse.Id = jobID
+	getRequest.Id = jobID
+	cancelRequest.Id = jobID
+	getResponse.Job.Id = jobID
+	getResponse.Job.CreatedAt = timestamppb.New(fixedTime)
+	getResponse.Job.CompletedAt = timestamppb.New(fixedTime.Add(time.Second))
+
+	return []namedMessage{
+		{Name: "submit_job_request", Message: submitRequest},
+		{Name: "submit_job_response", Message: submitResponse},
+		{Name: "get_job_request", Message: getRequest},
+		{Name: "get_job_response", Message: getResponse},
+		{Name: "cancel_job_request", Message: cancelRequest},
+		{Name: "cancel_job_response", Message: cancelResponse},
+	}, nil
+}
+
+// waitForJob polls the job until it is no longer running, returning its terminal state.
+func waitForJob(ctx context.Context, api *plugin.API, request *pb.GetJobRequest) (*pb.GetJobResponse, error) {
+	for {
+		response, err := api.GetJob(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		if response.GetJob().GetState() != pb.JobState_JOB_STATE_RUNNING {
+			return response, nil
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// generateCancel cancels an in-flight call to Execute using its request identifier.
+func generateCancel(ctx context.Context, api *plugin.API) ([]namedMessage, error) {
+	executed, err := executeInFlight(ctx, api, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	cancelRequest := &pb.CancelRequest{RequestId: requestID}
+	cancelResponse, err := api.Cancel(ctx, cancelRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = <-executed; status.Code(err) != codes.Canceled {
+		return nil, errors.New("cancelled request did not return codes.Canceled")
+	}
+
+	return []namedMessage{
+		{Name: "cancel_request", Message: cancelRequest},
+		{Name: "cancel_response", Message: cancelResponse},
+	}, nil
+}
+
+// executeInFlight begins a call to Execute using the given request identifier that waits until it is cancelled. It
+// returns once the call is in-flight, providing a channel that receives the error returned by the call.
+func executeInFlight(ctx context.Context, api *plugin.API, id string) (<-chan error, error) {
+	request := &pb.ExecuteRequest{Name: commandName, Input: mustAny(wrapperspb.String("wait")), RequestId: id}
+	probe := &pb.ExecuteRequest{Name: commandName, Input: mustAny(wrapperspb.String("ping")), RequestId: id}
+
+	for {
+		executed := make(chan error, 1)
+		go func() {
+			_, err := api.Execute(ctx, request)
+			executed <- err
+		}()
+
+		// A request is in-flight once a probe using the same identifier is rejected. The probe does not wait, so it
+		// completes immediately if the request is not yet in-flight. If the request is rejected by the probe
+		// instead, it is made again.
+		for {
+			_, err := api.Execute(ctx, probe)
+			if status.Code(err) == codes.AlreadyExists {
+				return executed, nil
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			select {
+			case err = <-executed:
+				if status.Code(err) != codes.AlreadyExists {
+					return nil, errors.New("in-flight request returned before it was cancelled")
+				}
+			case <-time.After(time.Millisecond):
+				continue
+			}
+
+			break
+		}
+	}
+}
+
+// generateSubscribe publishes an event to a subscriber, capturing the message sent on the stream.
+func generateSubscribe(ctx context.Context, api *plugin.API) ([]namedMessage, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	request := &pb.SubscribeRequest{}
+	stream := &subscribeStream{ctx: ctx, events: make(chan *pb.SubscribeResponse, 1)}
+
+	subscribed := make(chan error, 1)
+	go func() {
+		subscribed <- api.Subscribe(request, stream)
+	}()
+
+	// Events published before the subscription is registered are discarded, so publishing is repeated until the
+	// event is received.
+	var response *pb.SubscribeResponse
+	for response == nil {
+		if err := api.Publish(ctx, mustAny(wrapperspb.Int64(1))); err != nil {
+			return nil, err
+		}
+
+		select {
+		case response = <-stream.events:
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	if err := <-subscribed; err != nil {
+		return nil, err
+	}
+
+	response.Time = timestamppb.New(fixedTime)
+
+	return []namedMessage{
+		{Name: "subscribe_request", Message: request},
+		{Name: "subscribe_response", Message: response},
+	}, nil
+}
+
+func (s *subscribeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *subscribeStream) Send(response *pb.SubscribeResponse) error {
+	s.events <- response
+	return nil
+}
+
+func generateExecuteStream(ctx context.Context, api *plugin.API) ([]namedMessage, error) {
+	input, err := proto.MarshalOptions{Deterministic: true}.Marshal(mustAny(wrapperspb.String("ping")))
+	if err != nil {
+		return nil, err
+	}
+
+	request := &pb.ExecuteChunk{Name: commandName, ChunkSize: 1 << 20, Data: input}
+	stream := &executeStream{ctx: ctx, requests: []*pb.ExecuteChunk{request}}
+	if err = api.ExecuteStream(stream); err != nil {
+		return nil, err
+	}
+
+	if len(stream.responses) != 1 {
+		return nil, errors.New("expected a single response chunk")
+	}
+
+	// The time taken to execute the command varies between calls, so a fixed value is used.
+	stream.responses[0].Duration = durationpb.New(time.Millisecond)
+
+	return []namedMessage{
+		{Name: "execute_stream_request", Message: request},
+		{Name: "execute_stream_response", Message: stream.responses[0]},
+	}, nil
+}
+
+func (s *executeStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *executeStream) Recv() (*pb.ExecuteChunk, error) {
+	if len(s.requests) == 0 {
+		return nil, io.EOF
+	}
+
+	request := s.requests[0]
+	s.requests = s.requests[1:]
+	return request, nil
+}
+
+func (s *executeStream) Send(response *pb.ExecuteChunk) error {
+	s.responses = append(s.responses, response)
+	return nil
+}
+
+func generateFiles(ctx context.Context, api *plugin.API) ([]namedMessage, error) {
+	contents := []byte("contents")
+	checksum := sha256.Sum256(contents)
+
+	send := &sendFileStream{ctx: ctx, chunks: []*pb.FileChunk{
+		{Id: fileID, Data: contents},
+		{Sha256: hex.EncodeToString(checksum[:])},
+	}}
+
+	if err := api.SendFile(send); err != nil {
+		return nil, err
+	}
+
+	request := &pb.ReceiveFileRequest{Id: fileID}
+	receive := &receiveFileStream{ctx: ctx}
+	if err := api.ReceiveFile(request, receive); err != nil {
+		return nil, err
+	}
+
+	if len(receive.chunks) != 2 {
+		return nil, errors.New("expected a data chunk and a checksum chunk")
+	}
+
+	return []namedMessage{
+		{Name: "send_file_request", Message: send.chunks[0]},
+		{Name: "send_file_checksum", Message: send.chunks[1]},
+		{Name: "send_file_response", Message: send.response},
+		{Name: "receive_file_request", Message: request},
+		{Name: "receive_file_response", Message: receive.chunks[0]},
+		{Name: "receive_file_checksum", Message: receive.chunks[1]},
+	}, nil
+}
+
+func (s *sendFileStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *sendFileStream) Recv() (*pb.FileChunk, error) {
+	if s.next == len(s.chunks) {
+		return nil, io.EOF
+	}
+
+	s.next++
+	return s.chunks[s.next-1], nil
+}
+
+func (s *sendFileStream) SendAndClose(response *pb.SendFileResponse) error {
+	s.response = response
+	return nil
+}
+
+func (s *receiveFileStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *receiveFileStream) Send(chunk *pb.FileChunk) error {
+	s.chunks = append(s.chunks, proto.CloneOf(chunk))
+	return nil
+}
+
+func generateHandshake(request *pb.StatRequest, response *pb.StatResponse) ([]byte, error) {
+	req, err := encodeJSON(request)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := encodeJSON(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return encodeIndented(Handshake{
+		Args:     []string{"<path>", "--socket-dir", "<directory>", socketIDHolder},
+		Env:      map[string]string{plugin.TokenEnvironmentVariable: tokenHolder},
+		Socket:   "<directory>/" + socketIDHolder + ".sock",
+		Metadata: map[string]string{plugin.TokenMetadataKey: tokenHolder},
+		Requests: []Exchange{{Method: statMethod, Request: req, Response: res}},
+	})
+}
+
+func generateErrorMappings(ctx context.Context, api *plugin.API) ([]byte, error) {
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	expired, cancel := context.WithDeadline(ctx, time.Unix(0, 0))
+	defer cancel()
+
+	execute := func(ctx context.Context, request *pb.ExecuteRequest) func() error {
+		return func() error {
+			_, err := api.Execute(ctx, request)
+			return err
+		}
+	}
+
+	// Jobs report their errors in the same structure as a gRPC status rather than failing the RPC.
+	jobError := func(input string, cancelJob bool) func() error {
+		return func() error {
+			response, err := api.SubmitJob(ctx, &pb.SubmitJobRequest{Name: commandName, Input: mustAny(wrapperspb.String(input))})
+			if err != nil {
+				return err
+			}
+
+			if cancelJob {
+				if _, err = api.CancelJob(ctx, &pb.CancelJobRequest{Id: response.GetId()}); err != nil {
+					return err
+				}
+			}
+
+			job, err := waitForJob(ctx, api, &pb.GetJobRequest{Id: response.GetId()})
+			if err != nil {
+				return err
+			}
+
+			e := job.GetJob().GetError()
+			if e == nil {
+				return nil
+			}
+
+			return status.FromProto(&spb.Status{Code: e.GetCode(), Message: e.GetMessage(), Details: e.GetDetails()}).Err()
+		}
+	}
+
+	scenarios := []struct {
+		Scenario string
+		Method   string
+		Call     func() error
+	}{
+		{
+			Scenario: "command name is empty",
+			Method:   executeMethod,
+			Call:     execute(ctx, &pb.ExecuteRequest{}),
+		},
+		{
+			Scenario: "command does not exist",
+			Method:   executeMethod,
+			Call:     execute(ctx, &pb.ExecuteRequest{Name: "unknown"}),
+		},
+		{
+			Scenario: "command returns an error",
+			Method:   executeMethod,
+			Call:     execute(ctx, &pb.ExecuteRequest{Name: commandName, Input: mustAny(wrapperspb.String("fail"))}),
+		},
+		{
+			Scenario: "command returns an error with a status",
+			Method:   executeMethod,
+			Call:     execute(ctx, &pb.ExecuteRequest{Name: commandName, Input: mustAny(wrapperspb.String("pung"))}),
+		},
+		{
+			Scenario: "request is cancelled",
+			Method:   executeMethod,
+			Call:     execute(cancelled, &pb.ExecuteRequest{Name: commandName, Input: mustAny(wrapperspb.String("wait"))}),
+		},
+		{
+			Scenario: "request deadline is exceeded",
+			Method:   executeMethod,
+			Call:     execute(expired, &pb.ExecuteRequest{Name: commandName, Input: mustAny(wrapperspb.String("wait"))}),
+		},
+		{
+			Scenario: "request identifier is already in-flight",
+			Method:   executeMethod,
+			Call: func() error {
+				executed, err := executeInFlight(ctx, api, requestID)
+				if err != nil {
+					return err
+				}
+
+				_, err = api.Execute(ctx, &pb.ExecuteRequest{Name: commandName, Input: mustAny(wrapperspb.String("ping")), RequestId: requestID})
+				if _, cancelErr := api.Cancel(ctx, &pb.CancelRequest{RequestId: requestID}); cancelErr != nil {
+					return cancelErr
+				}
+
+				<-executed
+				return err
+			},
+		},
+		{
+			Scenario: "request to cancel is not in-flight",
+			Method:   cancelMethod,
+			Call: func() error {
+				_, err := api.Cancel(ctx, &pb.CancelRequest{RequestId: requestID})
+				return err
+			},
+		},
+		{
+			Scenario: "stream is opened without the token",
+			Method:   subscribeMethod,
+			Call: func() error {
+				return subscribeWithoutToken(ctx, api)
+			},
+		},
+		{
+			Scenario: "job command name is empty",
+			Method:   submitJobMethod,
+			Call: func() error {
+				_, err := api.SubmitJob(ctx, &pb.SubmitJobRequest{})
+				return err
+			},
+		},
+		{
+			Scenario: "job command does not exist",
+			Method:   submitJobMethod,
+			Call: func() error {
+				_, err := api.SubmitJob(ctx, &pb.SubmitJobRequest{Name: "unknown"})
+				return err
+			},
+		},
+		{
+			Scenario: "job does not exist",
+			Method:   getJobMethod,
+			Call: func() error {
+				_, err := api.GetJob(ctx, &pb.GetJobRequest{Id: jobID})
+				return err
+			},
+		},
+		{
+			Scenario: "job to cancel does not exist",
+			Method:   cancelJobMethod,
+			Call: func() error {
+				_, err := api.CancelJob(ctx, &pb.CancelJobRequest{Id: jobID})
+				return err
+			},
+		},
+		{
+			Scenario: "job command returns an error with a status",
+			Method:   getJobMethod,
+			Call:     jobError("pung", false),
+		},
+		{
+			Scenario: "job is cancelled",
+			Method:   getJobMethod,
+			Call:     jobError("wait", true),
+		},
+	}
+
+	mappings := make([]ErrorMapping, 0, len(scenarios))
+	for _, scenario := range scenarios {
+		err := scenario.Call()
+		if err == nil {
+			return nil, errors.New("scenario " + scenario.Scenario + " did not return an error")
+		}
+
+		encoded, err := encodeJSON(status.Convert(err).Proto())
+		if err != nil {
+			return nil, err
+		}
+
+		mappings = append(mappings, ErrorMapping{Scenario: scenario.Scenario, Method: scenario.Method, Status: encoded})
+	}
+
+	return encodeIndented(mappings)
+}
+
+// subscribeWithoutToken serves the API with token verification enabled, as plugins do, and subscribes to it without
+// providing the token.
+func subscribeWithoutToken(ctx context.Context, api *plugin.API) error {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(plugin.TokenServerOptions(tokenHolder)...)
+	api.Register(server)
+
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	client, err := plugin.NewClient("/"+pluginName, plugin.WithDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	return client.Subscribe(ctx, func(*anypb.Any, time.Time) {})
+}
+
+func mustAny(message proto.Message) *anypb.Any {
+	a, err := anypb.New(message)
+	if err != nil {
+		panic(err)
+	}
+
+	return a
+}
+
+// encodeJSON encodes the message as protobuf JSON. The output of protojson is deliberately unstable, so it is
+// compacted to produce a canonical form.
+func encodeJSON(message proto.Message) (json.RawMessage, error) {
+	data, err := protojson.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = json.Compact(buf, data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeIndented(v any) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,31 @@
+package conformance_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/davidsbond/plugin/internal/conformance"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	fixtures, err := conformance.Generate()
+	require.NoError(t, err)
+
+	again, err := conformance.Generate()
+	require.NoError(t, err)
+	assert.Equal(t, fixtures, again)
+
+	for _, fixture := range fixtures {
+		t.Run(fixture.Name, func(t *testing.T) {
+			expected, err := os.ReadFile(filepath.Join("..", "..", "testdata", "conformance", fixture.Name))
+			require.NoError(t, err, "fixtures are out of date, run make generate")
+			assert.Equal(t, string(expected), string(fixture.Data), "fixtures are out of date, run make generate")
+		})
+	}
+}
@@ -1,6 +1,6 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
-// 	protoc-gen-go v1.36.6
+// 	protoc-gen-go v1.36.8
 // 	protoc        (unknown)
 // source: proto/plugin/plugin.proto
 
@@ -11,7 +11,11 @@ package plugin
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
 	anypb "google.golang.org/protobuf/types/known/anypb"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -24,9 +28,74 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// The JobState enumeration describes the lifecycle of a job.
+type JobState int32
+
+const (
+	// The state is unknown.
+	JobState_JOB_STATE_UNSPECIFIED JobState = 0
+	// The command is executing.
+	JobState_JOB_STATE_RUNNING JobState = 1
+	// The command completed successfully and its output is available.
+	JobState_JOB_STATE_SUCCEEDED JobState = 2
+	// The command returned an error.
+	JobState_JOB_STATE_FAILED JobState = 3
+	// The job was cancelled before the command completed.
+	JobState_JOB_STATE_CANCELLED JobState = 4
+)
+
+// Enum value maps for JobState.
+var (
+	JobState_name = map[int32]string{
+		0: "JOB_STATE_UNSPECIFIED",
+		1: "JOB_STATE_RUNNING",
+		2: "JOB_STATE_SUCCEEDED",
+		3: "JOB_STATE_FAILED",
+		4: "JOB_STATE_CANCELLED",
+	}
+	JobState_value = map[string]int32{
+		"JOB_STATE_UNSPECIFIED": 0,
+		"JOB_STATE_RUNNING":     1,
+		"JOB_STATE_SUCCEEDED":   2,
+		"JOB_STATE_FAILED":      3,
+		"JOB_STATE_CANCELLED":   4,
+	}
+)
+
+func (x JobState) Enum() *JobState {
+	p := new(JobState)
+	*p = x
+	return p
+}
+
+func (x JobState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobState) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_plugin_plugin_proto_enumTypes[0].Descriptor()
+}
+
+func (JobState) Type() protoreflect.EnumType {
+	return &file_proto_plugin_plugin_proto_enumTypes[0]
+}
+
+func (x JobState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobState.Descriptor instead.
+func (JobState) EnumDescriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{0}
+}
+
 // The StatRequest type contains fields used by the Stat RPC.
 type StatRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the host application, if it has identified itself.
+	HostName string `protobuf:"bytes,1,opt,name=host_name,json=hostName,proto3" json:"host_name,omitempty"`
+	// The version of the host application, if it has identified itself.
+	HostVersion   string `protobuf:"bytes,2,opt,name=host_version,json=hostVersion,proto3" json:"host_version,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -61,6 +130,20 @@ func (*StatRequest) Descriptor() ([]byte, []int) {
 	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{0}
 }
 
+func (x *StatRequest) GetHostName() string {
+	if x != nil {
+		return x.HostName
+	}
+	return ""
+}
+
+func (x *StatRequest) GetHostVersion() string {
+	if x != nil {
+		return x.HostVersion
+	}
+	return ""
+}
+
 // The StatResponse type describes plugin metadata returned by the Stat RPC.
 type StatResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
@@ -69,7 +152,21 @@ type StatResponse struct {
 	// The version of the plugin.
 	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
 	// The commands the plugin supports.
-	Commands      []string `protobuf:"bytes,3,rep,name=commands,proto3" json:"commands,omitempty"`
+	Commands []string `protobuf:"bytes,3,rep,name=commands,proto3" json:"commands,omitempty"`
+	// The resources currently used by the plugin process.
+	Usage *ResourceUsage `protobuf:"bytes,4,opt,name=usage,proto3" json:"usage,omitempty"`
+	// Descriptions of the commands the plugin supports, in the same order as the commands field.
+	CommandInfo []*CommandInfo `protobuf:"bytes,5,rep,name=command_info,json=commandInfo,proto3" json:"command_info,omitempty"`
+	// Whether the plugin has enforced the sandbox profile given to it by the host application.
+	Sandboxed bool `protobuf:"varint,6,opt,name=sandboxed,proto3" json:"sandboxed,omitempty"`
+	// Whether the plugin accepts configuration via the Configure RPC, in which case its commands cannot be executed
+	// until it has been configured.
+	Configurable bool `protobuf:"varint,7,opt,name=configurable,proto3" json:"configurable,omitempty"`
+	// An ephemeral X25519 public key, generated when the plugin starts, that secrets given to SetSecrets are sealed to.
+	// Plugins that do not provide a key receive secrets unsealed.
+	SecretKey []byte `protobuf:"bytes,8,opt,name=secret_key,json=secretKey,proto3" json:"secret_key,omitempty"`
+	// Details of the process serving the plugin.
+	Process       *ProcessDetails `protobuf:"bytes,9,opt,name=process,proto3" json:"process,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -125,20 +222,366 @@ func (x *StatResponse) GetCommands() []string {
 	return nil
 }
 
+func (x *StatResponse) GetUsage() *ResourceUsage {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+func (x *StatResponse) GetCommandInfo() []*CommandInfo {
+	if x != nil {
+		return x.CommandInfo
+	}
+	return nil
+}
+
+func (x *StatResponse) GetSandboxed() bool {
+	if x != nil {
+		return x.Sandboxed
+	}
+	return false
+}
+
+func (x *StatResponse) GetConfigurable() bool {
+	if x != nil {
+		return x.Configurable
+	}
+	return false
+}
+
+func (x *StatResponse) GetSecretKey() []byte {
+	if x != nil {
+		return x.SecretKey
+	}
+	return nil
+}
+
+func (x *StatResponse) GetProcess() *ProcessDetails {
+	if x != nil {
+		return x.Process
+	}
+	return nil
+}
+
+// The ProcessDetails type describes the process serving a plugin and the environment it was built for.
+type ProcessDetails struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The process identifier.
+	Pid int64 `protobuf:"varint,1,opt,name=pid,proto3" json:"pid,omitempty"`
+	// The time the process started.
+	StartedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	// The time elapsed since the process started, as measured by the process.
+	Uptime *durationpb.Duration `protobuf:"bytes,3,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	// The operating system the plugin was built for, as given by GOOS.
+	Os string `protobuf:"bytes,4,opt,name=os,proto3" json:"os,omitempty"`
+	// The architecture the plugin was built for, as given by GOARCH.
+	Arch string `protobuf:"bytes,5,opt,name=arch,proto3" json:"arch,omitempty"`
+	// The version of the runtime or toolchain the plugin was built with, such as the Go version.
+	RuntimeVersion string `protobuf:"bytes,6,opt,name=runtime_version,json=runtimeVersion,proto3" json:"runtime_version,omitempty"`
+	// The version of the plugin protocol implemented by the plugin.
+	ProtocolVersion uint32 `protobuf:"varint,7,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// The version control revision the plugin was built from, if known.
+	VcsRevision string `protobuf:"bytes,8,opt,name=vcs_revision,json=vcsRevision,proto3" json:"vcs_revision,omitempty"`
+	// The time of the version control revision the plugin was built from, if known.
+	VcsTime *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=vcs_time,json=vcsTime,proto3" json:"vcs_time,omitempty"`
+	// Whether the plugin was built from a working tree containing uncommitted changes.
+	VcsModified   bool `protobuf:"varint,10,opt,name=vcs_modified,json=vcsModified,proto3" json:"vcs_modified,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessDetails) Reset() {
+	*x = ProcessDetails{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessDetails) ProtoMessage() {}
+
+func (x *ProcessDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessDetails.ProtoReflect.Descriptor instead.
+func (*ProcessDetails) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ProcessDetails) GetPid() int64 {
+	if x != nil {
+		return x.Pid
+	}
+	return 0
+}
+
+func (x *ProcessDetails) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *ProcessDetails) GetUptime() *durationpb.Duration {
+	if x != nil {
+		return x.Uptime
+	}
+	return nil
+}
+
+func (x *ProcessDetails) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *ProcessDetails) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+func (x *ProcessDetails) GetRuntimeVersion() string {
+	if x != nil {
+		return x.RuntimeVersion
+	}
+	return ""
+}
+
+func (x *ProcessDetails) GetProtocolVersion() uint32 {
+	if x != nil {
+		return x.ProtocolVersion
+	}
+	return 0
+}
+
+func (x *ProcessDetails) GetVcsRevision() string {
+	if x != nil {
+		return x.VcsRevision
+	}
+	return ""
+}
+
+func (x *ProcessDetails) GetVcsTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.VcsTime
+	}
+	return nil
+}
+
+func (x *ProcessDetails) GetVcsModified() bool {
+	if x != nil {
+		return x.VcsModified
+	}
+	return false
+}
+
+// The CommandInfo type describes a single command supported by the plugin.
+type CommandInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the command.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// A human-readable description of what the command does.
+	Description string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// Hints on how the command should be used, such as an example input.
+	Example string `protobuf:"bytes,3,opt,name=example,proto3" json:"example,omitempty"`
+	// Whether the command is deprecated and should no longer be used.
+	Deprecated bool `protobuf:"varint,4,opt,name=deprecated,proto3" json:"deprecated,omitempty"`
+	// Whether the command is idempotent, meaning it can safely be executed again after a transient failure.
+	Idempotent bool `protobuf:"varint,5,opt,name=idempotent,proto3" json:"idempotent,omitempty"`
+	// The names of the codecs the command supports for encoding its input and output, in order of preference. The
+	// codec used by a call to Execute is given by the "plugin-codec" metadata key. When empty, only the "proto" codec,
+	// the protobuf binary format, is supported.
+	Codecs        []string `protobuf:"bytes,6,rep,name=codecs,proto3" json:"codecs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandInfo) Reset() {
+	*x = CommandInfo{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandInfo) ProtoMessage() {}
+
+func (x *CommandInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandInfo.ProtoReflect.Descriptor instead.
+func (*CommandInfo) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CommandInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CommandInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CommandInfo) GetExample() string {
+	if x != nil {
+		return x.Example
+	}
+	return ""
+}
+
+func (x *CommandInfo) GetDeprecated() bool {
+	if x != nil {
+		return x.Deprecated
+	}
+	return false
+}
+
+func (x *CommandInfo) GetIdempotent() bool {
+	if x != nil {
+		return x.Idempotent
+	}
+	return false
+}
+
+func (x *CommandInfo) GetCodecs() []string {
+	if x != nil {
+		return x.Codecs
+	}
+	return nil
+}
+
+// The ResourceUsage type describes the resources consumed by a plugin process at the time of a request.
+type ResourceUsage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The number of bytes of allocated heap objects.
+	HeapBytes uint64 `protobuf:"varint,1,opt,name=heap_bytes,json=heapBytes,proto3" json:"heap_bytes,omitempty"`
+	// The total number of bytes of memory obtained from the operating system.
+	SysBytes uint64 `protobuf:"varint,2,opt,name=sys_bytes,json=sysBytes,proto3" json:"sys_bytes,omitempty"`
+	// The number of goroutines that currently exist.
+	Goroutines int64 `protobuf:"varint,3,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
+	// The total time spent executing in user mode.
+	UserCpuTime *durationpb.Duration `protobuf:"bytes,4,opt,name=user_cpu_time,json=userCpuTime,proto3" json:"user_cpu_time,omitempty"`
+	// The total time spent executing in kernel mode.
+	SystemCpuTime *durationpb.Duration `protobuf:"bytes,5,opt,name=system_cpu_time,json=systemCpuTime,proto3" json:"system_cpu_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceUsage) Reset() {
+	*x = ResourceUsage{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceUsage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceUsage) ProtoMessage() {}
+
+func (x *ResourceUsage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceUsage.ProtoReflect.Descriptor instead.
+func (*ResourceUsage) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ResourceUsage) GetHeapBytes() uint64 {
+	if x != nil {
+		return x.HeapBytes
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetSysBytes() uint64 {
+	if x != nil {
+		return x.SysBytes
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetGoroutines() int64 {
+	if x != nil {
+		return x.Goroutines
+	}
+	return 0
+}
+
+func (x *ResourceUsage) GetUserCpuTime() *durationpb.Duration {
+	if x != nil {
+		return x.UserCpuTime
+	}
+	return nil
+}
+
+func (x *ResourceUsage) GetSystemCpuTime() *durationpb.Duration {
+	if x != nil {
+		return x.SystemCpuTime
+	}
+	return nil
+}
+
 // The ExecuteRequest type contains fields used by the Execute RPC.
 type ExecuteRequest struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The name of the command to execute.
 	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	// The input used by the command.
-	Input         *anypb.Any `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	Input *anypb.Any `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	// An optional identifier for the request, unique among in-flight requests, used to cancel it via the Cancel RPC.
+	RequestId     string `protobuf:"bytes,3,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ExecuteRequest) Reset() {
 	*x = ExecuteRequest{}
-	mi := &file_proto_plugin_plugin_proto_msgTypes[2]
+	mi := &file_proto_plugin_plugin_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -150,7 +593,7 @@ func (x *ExecuteRequest) String() string {
 func (*ExecuteRequest) ProtoMessage() {}
 
 func (x *ExecuteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_plugin_plugin_proto_msgTypes[2]
+	mi := &file_proto_plugin_plugin_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -163,7 +606,7 @@ func (x *ExecuteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecuteRequest.ProtoReflect.Descriptor instead.
 func (*ExecuteRequest) Descriptor() ([]byte, []int) {
-	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{2}
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *ExecuteRequest) GetName() string {
@@ -180,18 +623,29 @@ func (x *ExecuteRequest) GetInput() *anypb.Any {
 	return nil
 }
 
+func (x *ExecuteRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
 // The ExecuteResponse type contains the results of a successful command execution.
 type ExecuteResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// The command output.
-	Output        *anypb.Any `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	Output *anypb.Any `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+	// Any non-fatal warnings reported by the command while producing its output.
+	Warnings []string `protobuf:"bytes,2,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// The time the plugin spent executing the command.
+	Duration      *durationpb.Duration `protobuf:"bytes,3,opt,name=duration,proto3" json:"duration,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *ExecuteResponse) Reset() {
 	*x = ExecuteResponse{}
-	mi := &file_proto_plugin_plugin_proto_msgTypes[3]
+	mi := &file_proto_plugin_plugin_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -203,7 +657,7 @@ func (x *ExecuteResponse) String() string {
 func (*ExecuteResponse) ProtoMessage() {}
 
 func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_plugin_plugin_proto_msgTypes[3]
+	mi := &file_proto_plugin_plugin_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -216,7 +670,7 @@ func (x *ExecuteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ExecuteResponse.ProtoReflect.Descriptor instead.
 func (*ExecuteResponse) Descriptor() ([]byte, []int) {
-	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{3}
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *ExecuteResponse) GetOutput() *anypb.Any {
@@ -226,24 +680,2669 @@ func (x *ExecuteResponse) GetOutput() *anypb.Any {
 	return nil
 }
 
-var File_proto_plugin_plugin_proto protoreflect.FileDescriptor
-
+func (x *ExecuteResponse) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *ExecuteResponse) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+// The ExecuteChunk type contains part of a request or response of the ExecuteStream RPC. The data of all chunks sent
+// in one direction, concatenated in order, is the encoded google.protobuf.Any input or output of the command.
+type ExecuteChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the command to execute. Only set on the first chunk of a request.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// An optional identifier for the request, used in the same way as that of an ExecuteRequest. Only set on the first
+	// chunk of a request.
+	RequestId string `protobuf:"bytes,2,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	// The maximum size, in bytes, of the data within each chunk of the response. Only set on the first chunk of a
+	// request. When unset, the plugin chooses its own chunk size.
+	ChunkSize int64 `protobuf:"varint,3,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	// The next part of the encoded input or output.
+	Data []byte `protobuf:"bytes,4,opt,name=data,proto3" json:"data,omitempty"`
+	// Any non-fatal warnings reported by the command while producing its output. Only set on the last chunk of a
+	// response.
+	Warnings []string `protobuf:"bytes,5,rep,name=warnings,proto3" json:"warnings,omitempty"`
+	// The time the plugin spent executing the command. Only set on the last chunk of a response.
+	Duration      *durationpb.Duration `protobuf:"bytes,6,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteChunk) Reset() {
+	*x = ExecuteChunk{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteChunk) ProtoMessage() {}
+
+func (x *ExecuteChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteChunk.ProtoReflect.Descriptor instead.
+func (*ExecuteChunk) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ExecuteChunk) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ExecuteChunk) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ExecuteChunk) GetChunkSize() int64 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *ExecuteChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ExecuteChunk) GetWarnings() []string {
+	if x != nil {
+		return x.Warnings
+	}
+	return nil
+}
+
+func (x *ExecuteChunk) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+// The CancelRequest type contains fields used by the Cancel RPC.
+type CancelRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The identifier of the request to cancel.
+	RequestId     string `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelRequest) Reset() {
+	*x = CancelRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRequest) ProtoMessage() {}
+
+func (x *CancelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRequest.ProtoReflect.Descriptor instead.
+func (*CancelRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CancelRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+// The CancelResponse type is returned by the Cancel RPC once a request has been cancelled.
+type CancelResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelResponse) Reset() {
+	*x = CancelResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelResponse) ProtoMessage() {}
+
+func (x *CancelResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelResponse.ProtoReflect.Descriptor instead.
+func (*CancelResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{9}
+}
+
+// The SubscribeRequest type contains fields used by the Subscribe RPC.
+type SubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{10}
+}
+
+// The SubscribeResponse type contains a single event published by the plugin.
+type SubscribeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The event.
+	Event *anypb.Any `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	// When the event was published.
+	Time          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeResponse) Reset() {
+	*x = SubscribeResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeResponse) ProtoMessage() {}
+
+func (x *SubscribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *SubscribeResponse) GetEvent() *anypb.Any {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *SubscribeResponse) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+// The DescribeRequest type contains fields used by the Describe RPC.
+type DescribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeRequest) Reset() {
+	*x = DescribeRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeRequest) ProtoMessage() {}
+
+func (x *DescribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeRequest.ProtoReflect.Descriptor instead.
+func (*DescribeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{12}
+}
+
+// The DescribeResponse type describes the messages used by each command.
+type DescribeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The messages used by each command.
+	Commands []*CommandDescriptor `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	// The files defining the messages used by all commands, including their dependencies. Files are ordered such that
+	// each appears after its dependencies.
+	Files         *descriptorpb.FileDescriptorSet `protobuf:"bytes,2,opt,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DescribeResponse) Reset() {
+	*x = DescribeResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DescribeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DescribeResponse) ProtoMessage() {}
+
+func (x *DescribeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DescribeResponse.ProtoReflect.Descriptor instead.
+func (*DescribeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DescribeResponse) GetCommands() []*CommandDescriptor {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+func (x *DescribeResponse) GetFiles() *descriptorpb.FileDescriptorSet {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+// The CommandDescriptor type describes the messages used by a single command.
+type CommandDescriptor struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the command.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The fully-qualified name of the command's input message, if known.
+	InputType string `protobuf:"bytes,2,opt,name=input_type,json=inputType,proto3" json:"input_type,omitempty"`
+	// The fully-qualified name of the command's output message, if known.
+	OutputType    string `protobuf:"bytes,3,opt,name=output_type,json=outputType,proto3" json:"output_type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandDescriptor) Reset() {
+	*x = CommandDescriptor{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandDescriptor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandDescriptor) ProtoMessage() {}
+
+func (x *CommandDescriptor) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandDescriptor.ProtoReflect.Descriptor instead.
+func (*CommandDescriptor) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CommandDescriptor) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CommandDescriptor) GetInputType() string {
+	if x != nil {
+		return x.InputType
+	}
+	return ""
+}
+
+func (x *CommandDescriptor) GetOutputType() string {
+	if x != nil {
+		return x.OutputType
+	}
+	return ""
+}
+
+// The StatsRequest type contains fields used by the Stats RPC.
+type StatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{15}
+}
+
+// The StatsResponse type describes the calls made to each command.
+type StatsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Statistics for each command that has been called, ordered by name.
+	Commands      []*CommandStats `protobuf:"bytes,1,rep,name=commands,proto3" json:"commands,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse) ProtoMessage() {}
+
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StatsResponse) GetCommands() []*CommandStats {
+	if x != nil {
+		return x.Commands
+	}
+	return nil
+}
+
+// The CommandStats type describes the calls made to a single command, whether via Execute, ExecuteStream or
+// SubmitJob.
+type CommandStats struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the command.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The number of times the command has been called.
+	Calls uint64 `protobuf:"varint,2,opt,name=calls,proto3" json:"calls,omitempty"`
+	// The number of calls that returned an error, including those that were cancelled or exceeded their deadline.
+	Errors uint64 `protobuf:"varint,3,opt,name=errors,proto3" json:"errors,omitempty"`
+	// The number of calls whose latency fell within each latency bucket. Buckets are bounded by 1ms, 2.5ms, 5ms, 10ms,
+	// 25ms, 50ms, 100ms, 250ms, 500ms, 1s, 2.5s, 5s and 10s, with each counting calls slower than the previous bound
+	// and no slower than its own. The final bucket counts calls slower than 10s.
+	LatencyBuckets []uint64 `protobuf:"varint,4,rep,packed,name=latency_buckets,json=latencyBuckets,proto3" json:"latency_buckets,omitempty"`
+	// The latency of the slowest call.
+	MaxLatency    *durationpb.Duration `protobuf:"bytes,5,opt,name=max_latency,json=maxLatency,proto3" json:"max_latency,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandStats) Reset() {
+	*x = CommandStats{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandStats) ProtoMessage() {}
+
+func (x *CommandStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandStats.ProtoReflect.Descriptor instead.
+func (*CommandStats) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CommandStats) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CommandStats) GetCalls() uint64 {
+	if x != nil {
+		return x.Calls
+	}
+	return 0
+}
+
+func (x *CommandStats) GetErrors() uint64 {
+	if x != nil {
+		return x.Errors
+	}
+	return 0
+}
+
+func (x *CommandStats) GetLatencyBuckets() []uint64 {
+	if x != nil {
+		return x.LatencyBuckets
+	}
+	return nil
+}
+
+func (x *CommandStats) GetMaxLatency() *durationpb.Duration {
+	if x != nil {
+		return x.MaxLatency
+	}
+	return nil
+}
+
+// The SubmitJobRequest type contains fields used by the SubmitJob RPC.
+type SubmitJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the command to execute.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The input used by the command.
+	Input         *anypb.Any `protobuf:"bytes,2,opt,name=input,proto3" json:"input,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitJobRequest) Reset() {
+	*x = SubmitJobRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitJobRequest) ProtoMessage() {}
+
+func (x *SubmitJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitJobRequest.ProtoReflect.Descriptor instead.
+func (*SubmitJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SubmitJobRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SubmitJobRequest) GetInput() *anypb.Any {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+// The SubmitJobResponse type contains the identifier of a submitted job.
+type SubmitJobResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The unique identifier of the job.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitJobResponse) Reset() {
+	*x = SubmitJobResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitJobResponse) ProtoMessage() {}
+
+func (x *SubmitJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitJobResponse.ProtoReflect.Descriptor instead.
+func (*SubmitJobResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *SubmitJobResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// The GetJobRequest type contains fields used by the GetJob RPC.
+type GetJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The unique identifier of the job.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// The GetJobResponse type contains the current state of a job.
+type GetJobResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The job.
+	Job           *Job `protobuf:"bytes,1,opt,name=job,proto3" json:"job,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobResponse) Reset() {
+	*x = GetJobResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobResponse) ProtoMessage() {}
+
+func (x *GetJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobResponse.ProtoReflect.Descriptor instead.
+func (*GetJobResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *GetJobResponse) GetJob() *Job {
+	if x != nil {
+		return x.Job
+	}
+	return nil
+}
+
+// The CancelJobRequest type contains fields used by the CancelJob RPC.
+type CancelJobRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The unique identifier of the job.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobRequest) Reset() {
+	*x = CancelJobRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobRequest) ProtoMessage() {}
+
+func (x *CancelJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobRequest.ProtoReflect.Descriptor instead.
+func (*CancelJobRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CancelJobRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// The CancelJobResponse type is returned by the CancelJob RPC once a job has been asked to stop.
+type CancelJobResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelJobResponse) Reset() {
+	*x = CancelJobResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelJobResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelJobResponse) ProtoMessage() {}
+
+func (x *CancelJobResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelJobResponse.ProtoReflect.Descriptor instead.
+func (*CancelJobResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{23}
+}
+
+// The Job type describes a command executing in the background.
+type Job struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The unique identifier of the job.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The name of the command being executed.
+	Name string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// The current state of the job.
+	State JobState `protobuf:"varint,3,opt,name=state,proto3,enum=plugin.JobState" json:"state,omitempty"`
+	// The progress reported by the command, between zero and one.
+	Progress float64 `protobuf:"fixed64,4,opt,name=progress,proto3" json:"progress,omitempty"`
+	// The most recent message reported by the command alongside its progress.
+	Message string `protobuf:"bytes,5,opt,name=message,proto3" json:"message,omitempty"`
+	// The command output, set once the job has succeeded.
+	Output *anypb.Any `protobuf:"bytes,6,opt,name=output,proto3" json:"output,omitempty"`
+	// The error returned by the command, set once the job has failed or been cancelled.
+	Error *JobError `protobuf:"bytes,7,opt,name=error,proto3" json:"error,omitempty"`
+	// When the job was submitted.
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// When the job reached a terminal state.
+	CompletedAt   *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Job) GetState() JobState {
+	if x != nil {
+		return x.State
+	}
+	return JobState_JOB_STATE_UNSPECIFIED
+}
+
+func (x *Job) GetProgress() float64 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *Job) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Job) GetOutput() *anypb.Any {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *Job) GetError() *JobError {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+// The JobError type describes the error returned by a job. It has the same structure as the google.rpc.Status type.
+type JobError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The gRPC status code.
+	Code int32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	// The error message.
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// Any details describing the error.
+	Details       []*anypb.Any `protobuf:"bytes,3,rep,name=details,proto3" json:"details,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JobError) Reset() {
+	*x = JobError{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *JobError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*JobError) ProtoMessage() {}
+
+func (x *JobError) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use JobError.ProtoReflect.Descriptor instead.
+func (*JobError) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *JobError) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *JobError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *JobError) GetDetails() []*anypb.Any {
+	if x != nil {
+		return x.Details
+	}
+	return nil
+}
+
+// The FileChunk type contains part of a file sent using the SendFile or ReceiveFile RPCs.
+type FileChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The identifier of the file. Only set on the first chunk sent using SendFile.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The next part of the file's contents.
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// The hex-encoded SHA-256 checksum of the complete file. Only set on the final chunk, which contains no data.
+	Sha256        string `protobuf:"bytes,3,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileChunk) Reset() {
+	*x = FileChunk{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileChunk) ProtoMessage() {}
+
+func (x *FileChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileChunk.ProtoReflect.Descriptor instead.
+func (*FileChunk) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *FileChunk) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *FileChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *FileChunk) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+// The SendFileResponse type describes a file stored using the SendFile RPC.
+type SendFileResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The size of the file in bytes.
+	Size          int64 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SendFileResponse) Reset() {
+	*x = SendFileResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SendFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SendFileResponse) ProtoMessage() {}
+
+func (x *SendFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SendFileResponse.ProtoReflect.Descriptor instead.
+func (*SendFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SendFileResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// The ReceiveFileRequest type contains fields used by the ReceiveFile RPC.
+type ReceiveFileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The identifier of the file to receive.
+	Id            string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReceiveFileRequest) Reset() {
+	*x = ReceiveFileRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReceiveFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReceiveFileRequest) ProtoMessage() {}
+
+func (x *ReceiveFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReceiveFileRequest.ProtoReflect.Descriptor instead.
+func (*ReceiveFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ReceiveFileRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// The RawMessage type is the input and output of commands that exchange raw bytes rather than protobuf messages. It is
+// packed into the Any type in the same way as the messages of any other command.
+type RawMessage struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The media type describing the data, such as "application/json".
+	ContentType string `protobuf:"bytes,1,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// The raw data.
+	Data          []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RawMessage) Reset() {
+	*x = RawMessage{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RawMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RawMessage) ProtoMessage() {}
+
+func (x *RawMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RawMessage.ProtoReflect.Descriptor instead.
+func (*RawMessage) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *RawMessage) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *RawMessage) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// The ConfigureRequest type contains fields used by the Configure RPC.
+type ConfigureRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The configuration of the plugin, if the host application provides any.
+	Configuration *anypb.Any `protobuf:"bytes,1,opt,name=configuration,proto3" json:"configuration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureRequest) Reset() {
+	*x = ConfigureRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureRequest) ProtoMessage() {}
+
+func (x *ConfigureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureRequest.ProtoReflect.Descriptor instead.
+func (*ConfigureRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ConfigureRequest) GetConfiguration() *anypb.Any {
+	if x != nil {
+		return x.Configuration
+	}
+	return nil
+}
+
+// The ConfigureResponse type is returned by the Configure RPC once the plugin has applied its configuration.
+type ConfigureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureResponse) Reset() {
+	*x = ConfigureResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureResponse) ProtoMessage() {}
+
+func (x *ConfigureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureResponse.ProtoReflect.Descriptor instead.
+func (*ConfigureResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{31}
+}
+
+// The SetSecretsRequest type contains fields used by the SetSecrets RPC.
+type SetSecretsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The secrets, keyed by name.
+	Secrets map[string][]byte `protobuf:"bytes,1,rep,name=secrets,proto3" json:"secrets,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// An ephemeral X25519 public key generated by the host application. When set, each secret is sealed using
+	// AES-256-GCM, with a key derived using HKDF-SHA256 from the X25519 shared secret of this key and the secret key
+	// returned by Stat, and consists of a 12 byte nonce followed by the ciphertext. The name of each secret is used as
+	// additional authenticated data.
+	PublicKey     []byte `protobuf:"bytes,2,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSecretsRequest) Reset() {
+	*x = SetSecretsRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSecretsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSecretsRequest) ProtoMessage() {}
+
+func (x *SetSecretsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSecretsRequest.ProtoReflect.Descriptor instead.
+func (*SetSecretsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *SetSecretsRequest) GetSecrets() map[string][]byte {
+	if x != nil {
+		return x.Secrets
+	}
+	return nil
+}
+
+func (x *SetSecretsRequest) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+// The SetSecretsResponse type is returned by the SetSecrets RPC once the plugin holds the secrets.
+type SetSecretsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetSecretsResponse) Reset() {
+	*x = SetSecretsResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetSecretsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetSecretsResponse) ProtoMessage() {}
+
+func (x *SetSecretsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetSecretsResponse.ProtoReflect.Descriptor instead.
+func (*SetSecretsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{33}
+}
+
+// The HostCall type describes a call made by the plugin to a service provided by the host application.
+type HostCall struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The identifier of the call, which is unique within the stream.
+	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The full name of the method being called, such as "/plugin.HostService/Log".
+	Method string `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	// The input of the method.
+	Input         *anypb.Any `protobuf:"bytes,3,opt,name=input,proto3" json:"input,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostCall) Reset() {
+	*x = HostCall{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostCall) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostCall) ProtoMessage() {}
+
+func (x *HostCall) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostCall.ProtoReflect.Descriptor instead.
+func (*HostCall) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *HostCall) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *HostCall) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *HostCall) GetInput() *anypb.Any {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+// The HostResult type contains the result of a call made by the plugin to a service provided by the host
+// application.
+type HostResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The identifier of the call the result is for.
+	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// The output of the method, if it succeeded.
+	Output *anypb.Any `protobuf:"bytes,2,opt,name=output,proto3" json:"output,omitempty"`
+	// The gRPC status code describing why the method failed, if it did.
+	Code uint32 `protobuf:"varint,3,opt,name=code,proto3" json:"code,omitempty"`
+	// A message describing why the method failed, if it did.
+	Message       string `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HostResult) Reset() {
+	*x = HostResult{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HostResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HostResult) ProtoMessage() {}
+
+func (x *HostResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HostResult.ProtoReflect.Descriptor instead.
+func (*HostResult) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *HostResult) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *HostResult) GetOutput() *anypb.Any {
+	if x != nil {
+		return x.Output
+	}
+	return nil
+}
+
+func (x *HostResult) GetCode() uint32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *HostResult) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// The LogRequest type contains fields used by the HostService.Log method.
+type LogRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The time the record was created.
+	Time *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	// The level of the record, using the values of the log/slog package. For example, 0 is INFO and 8 is ERROR.
+	Level int64 `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+	// The log message.
+	Message string `protobuf:"bytes,3,opt,name=message,proto3" json:"message,omitempty"`
+	// The attributes of the record, in order. The keys of attributes within groups are prefixed with the name of each
+	// group, separated by a period.
+	Attrs         []*LogAttr `protobuf:"bytes,4,rep,name=attrs,proto3" json:"attrs,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogRequest) Reset() {
+	*x = LogRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogRequest) ProtoMessage() {}
+
+func (x *LogRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogRequest.ProtoReflect.Descriptor instead.
+func (*LogRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *LogRequest) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *LogRequest) GetLevel() int64 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *LogRequest) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *LogRequest) GetAttrs() []*LogAttr {
+	if x != nil {
+		return x.Attrs
+	}
+	return nil
+}
+
+// The LogAttr type describes a single attribute of a log record.
+type LogAttr struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The key of the attribute.
+	Key string `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// The value of the attribute.
+	Value         *structpb.Value `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogAttr) Reset() {
+	*x = LogAttr{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogAttr) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogAttr) ProtoMessage() {}
+
+func (x *LogAttr) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogAttr.ProtoReflect.Descriptor instead.
+func (*LogAttr) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *LogAttr) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *LogAttr) GetValue() *structpb.Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+// The LogResponse type is returned by the HostService.Log method once the record has been logged.
+type LogResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LogResponse) Reset() {
+	*x = LogResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LogResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LogResponse) ProtoMessage() {}
+
+func (x *LogResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LogResponse.ProtoReflect.Descriptor instead.
+func (*LogResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{38}
+}
+
+// The RoundTripRequest type describes an HTTP request made via the HostService.RoundTrip method.
+type RoundTripRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The HTTP method of the request, such as "GET".
+	Method string `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	// The absolute URL of the request.
+	Url string `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`
+	// The headers of the request, keyed by their canonical name.
+	Headers map[string]*HTTPHeader `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The body of the request.
+	Body          []byte `protobuf:"bytes,4,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoundTripRequest) Reset() {
+	*x = RoundTripRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoundTripRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundTripRequest) ProtoMessage() {}
+
+func (x *RoundTripRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundTripRequest.ProtoReflect.Descriptor instead.
+func (*RoundTripRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *RoundTripRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *RoundTripRequest) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *RoundTripRequest) GetHeaders() map[string]*HTTPHeader {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *RoundTripRequest) GetBody() []byte {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+// The RoundTripResponse type describes the HTTP response returned by the HostService.RoundTrip method.
+type RoundTripResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The HTTP status code of the response.
+	StatusCode int32 `protobuf:"varint,1,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	// The headers of the response, keyed by their canonical name.
+	Headers map[string]*HTTPHeader `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	// The body of the response.
+	Body          []byte `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RoundTripResponse) Reset() {
+	*x = RoundTripResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RoundTripResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RoundTripResponse) ProtoMessage() {}
+
+func (x *RoundTripResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RoundTripResponse.ProtoReflect.Descriptor instead.
+func (*RoundTripResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *RoundTripResponse) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *RoundTripResponse) GetHeaders() map[string]*HTTPHeader {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *RoundTripResponse) GetBody() []byte {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+// The HTTPHeader type contains the values of a single HTTP header.
+type HTTPHeader struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The values of the header, in order.
+	Values        []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HTTPHeader) Reset() {
+	*x = HTTPHeader{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HTTPHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HTTPHeader) ProtoMessage() {}
+
+func (x *HTTPHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HTTPHeader.ProtoReflect.Descriptor instead.
+func (*HTTPHeader) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *HTTPHeader) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// The FileInfo type describes a file within the directory shared with the plugin.
+type FileInfo struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The name of the file, without its directory.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// The size of the file in bytes.
+	Size int64 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	// The mode of the file, using the values of Go's io/fs.FileMode.
+	Mode uint32 `protobuf:"varint,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	// The time the file was last modified.
+	Modified      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=modified,proto3" json:"modified,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FileInfo) Reset() {
+	*x = FileInfo{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FileInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FileInfo) ProtoMessage() {}
+
+func (x *FileInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FileInfo.ProtoReflect.Descriptor instead.
+func (*FileInfo) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *FileInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *FileInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FileInfo) GetMode() uint32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *FileInfo) GetModified() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Modified
+	}
+	return nil
+}
+
+// The StatFileRequest type contains fields used by the HostService.StatFile method.
+type StatFileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The path of the file.
+	Path          string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatFileRequest) Reset() {
+	*x = StatFileRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatFileRequest) ProtoMessage() {}
+
+func (x *StatFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatFileRequest.ProtoReflect.Descriptor instead.
+func (*StatFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *StatFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+// The StatFileResponse type is returned by the HostService.StatFile method.
+type StatFileResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The description of the file.
+	Info          *FileInfo `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatFileResponse) Reset() {
+	*x = StatFileResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatFileResponse) ProtoMessage() {}
+
+func (x *StatFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatFileResponse.ProtoReflect.Descriptor instead.
+func (*StatFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *StatFileResponse) GetInfo() *FileInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+// The ReadDirRequest type contains fields used by the HostService.ReadDir method.
+type ReadDirRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The path of the directory.
+	Path          string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadDirRequest) Reset() {
+	*x = ReadDirRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadDirRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDirRequest) ProtoMessage() {}
+
+func (x *ReadDirRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDirRequest.ProtoReflect.Descriptor instead.
+func (*ReadDirRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *ReadDirRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+// The ReadDirResponse type is returned by the HostService.ReadDir method.
+type ReadDirResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The contents of the directory.
+	Entries       []*FileInfo `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadDirResponse) Reset() {
+	*x = ReadDirResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadDirResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadDirResponse) ProtoMessage() {}
+
+func (x *ReadDirResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadDirResponse.ProtoReflect.Descriptor instead.
+func (*ReadDirResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *ReadDirResponse) GetEntries() []*FileInfo {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+// The ReadFileRequest type contains fields used by the HostService.ReadFile method.
+type ReadFileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The path of the file.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The offset to read from.
+	Offset int64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// The maximum number of bytes to read.
+	Length        int64 `protobuf:"varint,3,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadFileRequest) Reset() {
+	*x = ReadFileRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadFileRequest) ProtoMessage() {}
+
+func (x *ReadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadFileRequest.ProtoReflect.Descriptor instead.
+func (*ReadFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ReadFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *ReadFileRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *ReadFileRequest) GetLength() int64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+// The ReadFileResponse type is returned by the HostService.ReadFile method.
+type ReadFileResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The data read from the file.
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// Whether the end of the file was reached.
+	Eof           bool `protobuf:"varint,2,opt,name=eof,proto3" json:"eof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadFileResponse) Reset() {
+	*x = ReadFileResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadFileResponse) ProtoMessage() {}
+
+func (x *ReadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadFileResponse.ProtoReflect.Descriptor instead.
+func (*ReadFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ReadFileResponse) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ReadFileResponse) GetEof() bool {
+	if x != nil {
+		return x.Eof
+	}
+	return false
+}
+
+// The WriteFileRequest type contains fields used by the HostService.WriteFile method.
+type WriteFileRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The path of the file.
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// The data to write.
+	Data []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// Whether the data is appended to the file, rather than replacing its contents.
+	Append        bool `protobuf:"varint,3,opt,name=append,proto3" json:"append,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteFileRequest) Reset() {
+	*x = WriteFileRequest{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteFileRequest) ProtoMessage() {}
+
+func (x *WriteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteFileRequest.ProtoReflect.Descriptor instead.
+func (*WriteFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *WriteFileRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *WriteFileRequest) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *WriteFileRequest) GetAppend() bool {
+	if x != nil {
+		return x.Append
+	}
+	return false
+}
+
+// The WriteFileResponse type is returned by the HostService.WriteFile method once the data has been written.
+type WriteFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WriteFileResponse) Reset() {
+	*x = WriteFileResponse{}
+	mi := &file_proto_plugin_plugin_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WriteFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WriteFileResponse) ProtoMessage() {}
+
+func (x *WriteFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_plugin_plugin_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WriteFileResponse.ProtoReflect.Descriptor instead.
+func (*WriteFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_plugin_plugin_proto_rawDescGZIP(), []int{50}
+}
+
+var File_proto_plugin_plugin_proto protoreflect.FileDescriptor
+
 const file_proto_plugin_plugin_proto_rawDesc = "" +
 	"\n" +
-	"\x19proto/plugin/plugin.proto\x12\x06plugin\x1a\x19google/protobuf/any.proto\"\r\n" +
-	"\vStatRequest\"X\n" +
+	"\x19proto/plugin/plugin.proto\x12\x06plugin\x1a\x19google/protobuf/any.proto\x1a google/protobuf/descriptor.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"M\n" +
+	"\vStatRequest\x12\x1b\n" +
+	"\thost_name\x18\x01 \x01(\tR\bhostName\x12!\n" +
+	"\fhost_version\x18\x02 \x01(\tR\vhostVersion\"\xd0\x02\n" +
 	"\fStatResponse\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
 	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1a\n" +
-	"\bcommands\x18\x03 \x03(\tR\bcommands\"P\n" +
+	"\bcommands\x18\x03 \x03(\tR\bcommands\x12+\n" +
+	"\x05usage\x18\x04 \x01(\v2\x15.plugin.ResourceUsageR\x05usage\x126\n" +
+	"\fcommand_info\x18\x05 \x03(\v2\x13.plugin.CommandInfoR\vcommandInfo\x12\x1c\n" +
+	"\tsandboxed\x18\x06 \x01(\bR\tsandboxed\x12\"\n" +
+	"\fconfigurable\x18\a \x01(\bR\fconfigurable\x12\x1d\n" +
+	"\n" +
+	"secret_key\x18\b \x01(\fR\tsecretKey\x120\n" +
+	"\aprocess\x18\t \x01(\v2\x16.plugin.ProcessDetailsR\aprocess\"\x85\x03\n" +
+	"\x0eProcessDetails\x12\x10\n" +
+	"\x03pid\x18\x01 \x01(\x03R\x03pid\x129\n" +
+	"\n" +
+	"started_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x121\n" +
+	"\x06uptime\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x06uptime\x12\x0e\n" +
+	"\x02os\x18\x04 \x01(\tR\x02os\x12\x12\n" +
+	"\x04arch\x18\x05 \x01(\tR\x04arch\x12'\n" +
+	"\x0fruntime_version\x18\x06 \x01(\tR\x0eruntimeVersion\x12)\n" +
+	"\x10protocol_version\x18\a \x01(\rR\x0fprotocolVersion\x12!\n" +
+	"\fvcs_revision\x18\b \x01(\tR\vvcsRevision\x125\n" +
+	"\bvcs_time\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\avcsTime\x12!\n" +
+	"\fvcs_modified\x18\n" +
+	" \x01(\bR\vvcsModified\"\xb5\x01\n" +
+	"\vCommandInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x18\n" +
+	"\aexample\x18\x03 \x01(\tR\aexample\x12\x1e\n" +
+	"\n" +
+	"deprecated\x18\x04 \x01(\bR\n" +
+	"deprecated\x12\x1e\n" +
+	"\n" +
+	"idempotent\x18\x05 \x01(\bR\n" +
+	"idempotent\x12\x16\n" +
+	"\x06codecs\x18\x06 \x03(\tR\x06codecs\"\xed\x01\n" +
+	"\rResourceUsage\x12\x1d\n" +
+	"\n" +
+	"heap_bytes\x18\x01 \x01(\x04R\theapBytes\x12\x1b\n" +
+	"\tsys_bytes\x18\x02 \x01(\x04R\bsysBytes\x12\x1e\n" +
+	"\n" +
+	"goroutines\x18\x03 \x01(\x03R\n" +
+	"goroutines\x12=\n" +
+	"\ruser_cpu_time\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\vuserCpuTime\x12A\n" +
+	"\x0fsystem_cpu_time\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\rsystemCpuTime\"o\n" +
 	"\x0eExecuteRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12*\n" +
-	"\x05input\x18\x02 \x01(\v2\x14.google.protobuf.AnyR\x05input\"?\n" +
+	"\x05input\x18\x02 \x01(\v2\x14.google.protobuf.AnyR\x05input\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x03 \x01(\tR\trequestId\"\x92\x01\n" +
 	"\x0fExecuteResponse\x12,\n" +
-	"\x06output\x18\x01 \x01(\v2\x14.google.protobuf.AnyR\x06output2~\n" +
+	"\x06output\x18\x01 \x01(\v2\x14.google.protobuf.AnyR\x06output\x12\x1a\n" +
+	"\bwarnings\x18\x02 \x03(\tR\bwarnings\x125\n" +
+	"\bduration\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\bduration\"\xc7\x01\n" +
+	"\fExecuteChunk\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x02 \x01(\tR\trequestId\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x03 \x01(\x03R\tchunkSize\x12\x12\n" +
+	"\x04data\x18\x04 \x01(\fR\x04data\x12\x1a\n" +
+	"\bwarnings\x18\x05 \x03(\tR\bwarnings\x125\n" +
+	"\bduration\x18\x06 \x01(\v2\x19.google.protobuf.DurationR\bduration\".\n" +
+	"\rCancelRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\"\x10\n" +
+	"\x0eCancelResponse\"\x12\n" +
+	"\x10SubscribeRequest\"o\n" +
+	"\x11SubscribeResponse\x12*\n" +
+	"\x05event\x18\x01 \x01(\v2\x14.google.protobuf.AnyR\x05event\x12.\n" +
+	"\x04time\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\"\x11\n" +
+	"\x0fDescribeRequest\"\x83\x01\n" +
+	"\x10DescribeResponse\x125\n" +
+	"\bcommands\x18\x01 \x03(\v2\x19.plugin.CommandDescriptorR\bcommands\x128\n" +
+	"\x05files\x18\x02 \x01(\v2\".google.protobuf.FileDescriptorSetR\x05files\"g\n" +
+	"\x11CommandDescriptor\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"input_type\x18\x02 \x01(\tR\tinputType\x12\x1f\n" +
+	"\voutput_type\x18\x03 \x01(\tR\n" +
+	"outputType\"\x0e\n" +
+	"\fStatsRequest\"A\n" +
+	"\rStatsResponse\x120\n" +
+	"\bcommands\x18\x01 \x03(\v2\x14.plugin.CommandStatsR\bcommands\"\xb5\x01\n" +
+	"\fCommandStats\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05calls\x18\x02 \x01(\x04R\x05calls\x12\x16\n" +
+	"\x06errors\x18\x03 \x01(\x04R\x06errors\x12'\n" +
+	"\x0flatency_buckets\x18\x04 \x03(\x04R\x0elatencyBuckets\x12:\n" +
+	"\vmax_latency\x18\x05 \x01(\v2\x19.google.protobuf.DurationR\n" +
+	"maxLatency\"R\n" +
+	"\x10SubmitJobRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12*\n" +
+	"\x05input\x18\x02 \x01(\v2\x14.google.protobuf.AnyR\x05input\"#\n" +
+	"\x11SubmitJobResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x1f\n" +
+	"\rGetJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"/\n" +
+	"\x0eGetJobResponse\x12\x1d\n" +
+	"\x03job\x18\x01 \x01(\v2\v.plugin.JobR\x03job\"\"\n" +
+	"\x10CancelJobRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"\x13\n" +
+	"\x11CancelJobResponse\"\xd7\x02\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12&\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x10.plugin.JobStateR\x05state\x12\x1a\n" +
+	"\bprogress\x18\x04 \x01(\x01R\bprogress\x12\x18\n" +
+	"\amessage\x18\x05 \x01(\tR\amessage\x12,\n" +
+	"\x06output\x18\x06 \x01(\v2\x14.google.protobuf.AnyR\x06output\x12&\n" +
+	"\x05error\x18\a \x01(\v2\x10.plugin.JobErrorR\x05error\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12=\n" +
+	"\fcompleted_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\"h\n" +
+	"\bJobError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12.\n" +
+	"\adetails\x18\x03 \x03(\v2\x14.google.protobuf.AnyR\adetails\"G\n" +
+	"\tFileChunk\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x16\n" +
+	"\x06sha256\x18\x03 \x01(\tR\x06sha256\"&\n" +
+	"\x10SendFileResponse\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\"$\n" +
+	"\x12ReceiveFileRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"C\n" +
+	"\n" +
+	"RawMessage\x12!\n" +
+	"\fcontent_type\x18\x01 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"N\n" +
+	"\x10ConfigureRequest\x12:\n" +
+	"\rconfiguration\x18\x01 \x01(\v2\x14.google.protobuf.AnyR\rconfiguration\"\x13\n" +
+	"\x11ConfigureResponse\"\xb0\x01\n" +
+	"\x11SetSecretsRequest\x12@\n" +
+	"\asecrets\x18\x01 \x03(\v2&.plugin.SetSecretsRequest.SecretsEntryR\asecrets\x12\x1d\n" +
+	"\n" +
+	"public_key\x18\x02 \x01(\fR\tpublicKey\x1a:\n" +
+	"\fSecretsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\fR\x05value:\x028\x01\"\x14\n" +
+	"\x12SetSecretsResponse\"^\n" +
+	"\bHostCall\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12*\n" +
+	"\x05input\x18\x03 \x01(\v2\x14.google.protobuf.AnyR\x05input\"x\n" +
+	"\n" +
+	"HostResult\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12,\n" +
+	"\x06output\x18\x02 \x01(\v2\x14.google.protobuf.AnyR\x06output\x12\x12\n" +
+	"\x04code\x18\x03 \x01(\rR\x04code\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"\x93\x01\n" +
+	"\n" +
+	"LogRequest\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\x03R\x05level\x12\x18\n" +
+	"\amessage\x18\x03 \x01(\tR\amessage\x12%\n" +
+	"\x05attrs\x18\x04 \x03(\v2\x0f.plugin.LogAttrR\x05attrs\"I\n" +
+	"\aLogAttr\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value\"\r\n" +
+	"\vLogResponse\"\xe1\x01\n" +
+	"\x10RoundTripRequest\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12?\n" +
+	"\aheaders\x18\x03 \x03(\v2%.plugin.RoundTripRequest.HeadersEntryR\aheaders\x12\x12\n" +
+	"\x04body\x18\x04 \x01(\fR\x04body\x1aN\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.plugin.HTTPHeaderR\x05value:\x028\x01\"\xda\x01\n" +
+	"\x11RoundTripResponse\x12\x1f\n" +
+	"\vstatus_code\x18\x01 \x01(\x05R\n" +
+	"statusCode\x12@\n" +
+	"\aheaders\x18\x02 \x03(\v2&.plugin.RoundTripResponse.HeadersEntryR\aheaders\x12\x12\n" +
+	"\x04body\x18\x03 \x01(\fR\x04body\x1aN\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12(\n" +
+	"\x05value\x18\x02 \x01(\v2\x12.plugin.HTTPHeaderR\x05value:\x028\x01\"$\n" +
+	"\n" +
+	"HTTPHeader\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\"~\n" +
+	"\bFileInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\rR\x04mode\x126\n" +
+	"\bmodified\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bmodified\"%\n" +
+	"\x0fStatFileRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"8\n" +
+	"\x10StatFileResponse\x12$\n" +
+	"\x04info\x18\x01 \x01(\v2\x10.plugin.FileInfoR\x04info\"$\n" +
+	"\x0eReadDirRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\"=\n" +
+	"\x0fReadDirResponse\x12*\n" +
+	"\aentries\x18\x01 \x03(\v2\x10.plugin.FileInfoR\aentries\"U\n" +
+	"\x0fReadFileRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x03R\x06offset\x12\x16\n" +
+	"\x06length\x18\x03 \x01(\x03R\x06length\"8\n" +
+	"\x10ReadFileResponse\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x10\n" +
+	"\x03eof\x18\x02 \x01(\bR\x03eof\"R\n" +
+	"\x10WriteFileRequest\x12\x12\n" +
+	"\x04path\x18\x01 \x01(\tR\x04path\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\x12\x16\n" +
+	"\x06append\x18\x03 \x01(\bR\x06append\"\x13\n" +
+	"\x11WriteFileResponse*\x84\x01\n" +
+	"\bJobState\x12\x19\n" +
+	"\x15JOB_STATE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11JOB_STATE_RUNNING\x10\x01\x12\x17\n" +
+	"\x13JOB_STATE_SUCCEEDED\x10\x02\x12\x14\n" +
+	"\x10JOB_STATE_FAILED\x10\x03\x12\x17\n" +
+	"\x13JOB_STATE_CANCELLED\x10\x042\xa4\a\n" +
 	"\rPluginService\x121\n" +
 	"\x04Stat\x12\x13.plugin.StatRequest\x1a\x14.plugin.StatResponse\x12:\n" +
-	"\aExecute\x12\x16.plugin.ExecuteRequest\x1a\x17.plugin.ExecuteResponseB>Z<github.com/davidsbond/plugin/internal/generated/proto/pluginb\x06proto3"
+	"\aExecute\x12\x16.plugin.ExecuteRequest\x1a\x17.plugin.ExecuteResponse\x12?\n" +
+	"\rExecuteStream\x12\x14.plugin.ExecuteChunk\x1a\x14.plugin.ExecuteChunk(\x010\x01\x12@\n" +
+	"\tSubmitJob\x12\x18.plugin.SubmitJobRequest\x1a\x19.plugin.SubmitJobResponse\x127\n" +
+	"\x06GetJob\x12\x15.plugin.GetJobRequest\x1a\x16.plugin.GetJobResponse\x12@\n" +
+	"\tCancelJob\x12\x18.plugin.CancelJobRequest\x1a\x19.plugin.CancelJobResponse\x127\n" +
+	"\x06Cancel\x12\x15.plugin.CancelRequest\x1a\x16.plugin.CancelResponse\x12B\n" +
+	"\tSubscribe\x12\x18.plugin.SubscribeRequest\x1a\x19.plugin.SubscribeResponse0\x01\x12=\n" +
+	"\bDescribe\x12\x17.plugin.DescribeRequest\x1a\x18.plugin.DescribeResponse\x124\n" +
+	"\x05Stats\x12\x14.plugin.StatsRequest\x1a\x15.plugin.StatsResponse\x129\n" +
+	"\bSendFile\x12\x11.plugin.FileChunk\x1a\x18.plugin.SendFileResponse(\x01\x12>\n" +
+	"\vReceiveFile\x12\x1a.plugin.ReceiveFileRequest\x1a\x11.plugin.FileChunk0\x01\x12@\n" +
+	"\tConfigure\x12\x18.plugin.ConfigureRequest\x1a\x19.plugin.ConfigureResponse\x12C\n" +
+	"\n" +
+	"SetSecrets\x12\x19.plugin.SetSecretsRequest\x1a\x1a.plugin.SetSecretsResponse\x122\n" +
+	"\x06Broker\x12\x12.plugin.HostResult\x1a\x10.plugin.HostCall(\x010\x012\xfb\x02\n" +
+	"\vHostService\x12.\n" +
+	"\x03Log\x12\x12.plugin.LogRequest\x1a\x13.plugin.LogResponse\x12@\n" +
+	"\tRoundTrip\x12\x18.plugin.RoundTripRequest\x1a\x19.plugin.RoundTripResponse\x12=\n" +
+	"\bStatFile\x12\x17.plugin.StatFileRequest\x1a\x18.plugin.StatFileResponse\x12:\n" +
+	"\aReadDir\x12\x16.plugin.ReadDirRequest\x1a\x17.plugin.ReadDirResponse\x12=\n" +
+	"\bReadFile\x12\x17.plugin.ReadFileRequest\x1a\x18.plugin.ReadFileResponse\x12@\n" +
+	"\tWriteFile\x12\x18.plugin.WriteFileRequest\x1a\x19.plugin.WriteFileResponseB>Z<github.com/davidsbond/plugin/internal/generated/proto/pluginb\x06proto3"
 
 var (
 	file_proto_plugin_plugin_proto_rawDescOnce sync.Once
@@ -257,26 +3356,158 @@ func file_proto_plugin_plugin_proto_rawDescGZIP() []byte {
 	return file_proto_plugin_plugin_proto_rawDescData
 }
 
-var file_proto_plugin_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_proto_plugin_plugin_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_plugin_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 54)
 var file_proto_plugin_plugin_proto_goTypes = []any{
-	(*StatRequest)(nil),     // 0: plugin.StatRequest
-	(*StatResponse)(nil),    // 1: plugin.StatResponse
-	(*ExecuteRequest)(nil),  // 2: plugin.ExecuteRequest
-	(*ExecuteResponse)(nil), // 3: plugin.ExecuteResponse
-	(*anypb.Any)(nil),       // 4: google.protobuf.Any
+	(JobState)(0),                          // 0: plugin.JobState
+	(*StatRequest)(nil),                    // 1: plugin.StatRequest
+	(*StatResponse)(nil),                   // 2: plugin.StatResponse
+	(*ProcessDetails)(nil),                 // 3: plugin.ProcessDetails
+	(*CommandInfo)(nil),                    // 4: plugin.CommandInfo
+	(*ResourceUsage)(nil),                  // 5: plugin.ResourceUsage
+	(*ExecuteRequest)(nil),                 // 6: plugin.ExecuteRequest
+	(*ExecuteResponse)(nil),                // 7: plugin.ExecuteResponse
+	(*ExecuteChunk)(nil),                   // 8: plugin.ExecuteChunk
+	(*CancelRequest)(nil),                  // 9: plugin.CancelRequest
+	(*CancelResponse)(nil),                 // 10: plugin.CancelResponse
+	(*SubscribeRequest)(nil),               // 11: plugin.SubscribeRequest
+	(*SubscribeResponse)(nil),              // 12: plugin.SubscribeResponse
+	(*DescribeRequest)(nil),                // 13: plugin.DescribeRequest
+	(*DescribeResponse)(nil),               // 14: plugin.DescribeResponse
+	(*CommandDescriptor)(nil),              // 15: plugin.CommandDescriptor
+	(*StatsRequest)(nil),                   // 16: plugin.StatsRequest
+	(*StatsResponse)(nil),                  // 17: plugin.StatsResponse
+	(*CommandStats)(nil),                   // 18: plugin.CommandStats
+	(*SubmitJobRequest)(nil),               // 19: plugin.SubmitJobRequest
+	(*SubmitJobResponse)(nil),              // 20: plugin.SubmitJobResponse
+	(*GetJobRequest)(nil),                  // 21: plugin.GetJobRequest
+	(*GetJobResponse)(nil),                 // 22: plugin.GetJobResponse
+	(*CancelJobRequest)(nil),               // 23: plugin.CancelJobRequest
+	(*CancelJobResponse)(nil),              // 24: plugin.CancelJobResponse
+	(*Job)(nil),                            // 25: plugin.Job
+	(*JobError)(nil),                       // 26: plugin.JobError
+	(*FileChunk)(nil),                      // 27: plugin.FileChunk
+	(*SendFileResponse)(nil),               // 28: plugin.SendFileResponse
+	(*ReceiveFileRequest)(nil),             // 29: plugin.ReceiveFileRequest
+	(*RawMessage)(nil),                     // 30: plugin.RawMessage
+	(*ConfigureRequest)(nil),               // 31: plugin.ConfigureRequest
+	(*ConfigureResponse)(nil),              // 32: plugin.ConfigureResponse
+	(*SetSecretsRequest)(nil),              // 33: plugin.SetSecretsRequest
+	(*SetSecretsResponse)(nil),             // 34: plugin.SetSecretsResponse
+	(*HostCall)(nil),                       // 35: plugin.HostCall
+	(*HostResult)(nil),                     // 36: plugin.HostResult
+	(*LogRequest)(nil),                     // 37: plugin.LogRequest
+	(*LogAttr)(nil),                        // 38: plugin.LogAttr
+	(*LogResponse)(nil),                    // 39: plugin.LogResponse
+	(*RoundTripRequest)(nil),               // 40: plugin.RoundTripRequest
+	(*RoundTripResponse)(nil),              // 41: plugin.RoundTripResponse
+	(*HTTPHeader)(nil),                     // 42: plugin.HTTPHeader
+	(*FileInfo)(nil),                       // 43: plugin.FileInfo
+	(*StatFileRequest)(nil),                // 44: plugin.StatFileRequest
+	(*StatFileResponse)(nil),               // 45: plugin.StatFileResponse
+	(*ReadDirRequest)(nil),                 // 46: plugin.ReadDirRequest
+	(*ReadDirResponse)(nil),                // 47: plugin.ReadDirResponse
+	(*ReadFileRequest)(nil),                // 48: plugin.ReadFileRequest
+	(*ReadFileResponse)(nil),               // 49: plugin.ReadFileResponse
+	(*WriteFileRequest)(nil),               // 50: plugin.WriteFileRequest
+	(*WriteFileResponse)(nil),              // 51: plugin.WriteFileResponse
+	nil,                                    // 52: plugin.SetSecretsRequest.SecretsEntry
+	nil,                                    // 53: plugin.RoundTripRequest.HeadersEntry
+	nil,                                    // 54: plugin.RoundTripResponse.HeadersEntry
+	(*timestamppb.Timestamp)(nil),          // 55: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),            // 56: google.protobuf.Duration
+	(*anypb.Any)(nil),                      // 57: google.protobuf.Any
+	(*descriptorpb.FileDescriptorSet)(nil), // 58: google.protobuf.FileDescriptorSet
+	(*structpb.Value)(nil),                 // 59: google.protobuf.Value
 }
 var file_proto_plugin_plugin_proto_depIdxs = []int32{
-	4, // 0: plugin.ExecuteRequest.input:type_name -> google.protobuf.Any
-	4, // 1: plugin.ExecuteResponse.output:type_name -> google.protobuf.Any
-	0, // 2: plugin.PluginService.Stat:input_type -> plugin.StatRequest
-	2, // 3: plugin.PluginService.Execute:input_type -> plugin.ExecuteRequest
-	1, // 4: plugin.PluginService.Stat:output_type -> plugin.StatResponse
-	3, // 5: plugin.PluginService.Execute:output_type -> plugin.ExecuteResponse
-	4, // [4:6] is the sub-list for method output_type
-	2, // [2:4] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	5,  // 0: plugin.StatResponse.usage:type_name -> plugin.ResourceUsage
+	4,  // 1: plugin.StatResponse.command_info:type_name -> plugin.CommandInfo
+	3,  // 2: plugin.StatResponse.process:type_name -> plugin.ProcessDetails
+	55, // 3: plugin.ProcessDetails.started_at:type_name -> google.protobuf.Timestamp
+	56, // 4: plugin.ProcessDetails.uptime:type_name -> google.protobuf.Duration
+	55, // 5: plugin.ProcessDetails.vcs_time:type_name -> google.protobuf.Timestamp
+	56, // 6: plugin.ResourceUsage.user_cpu_time:type_name -> google.protobuf.Duration
+	56, // 7: plugin.ResourceUsage.system_cpu_time:type_name -> google.protobuf.Duration
+	57, // 8: plugin.ExecuteRequest.input:type_name -> google.protobuf.Any
+	57, // 9: plugin.ExecuteResponse.output:type_name -> google.protobuf.Any
+	56, // 10: plugin.ExecuteResponse.duration:type_name -> google.protobuf.Duration
+	56, // 11: plugin.ExecuteChunk.duration:type_name -> google.protobuf.Duration
+	57, // 12: plugin.SubscribeResponse.event:type_name -> google.protobuf.Any
+	55, // 13: plugin.SubscribeResponse.time:type_name -> google.protobuf.Timestamp
+	15, // 14: plugin.DescribeResponse.commands:type_name -> plugin.CommandDescriptor
+	58, // 15: plugin.DescribeResponse.files:type_name -> google.protobuf.FileDescriptorSet
+	18, // 16: plugin.StatsResponse.commands:type_name -> plugin.CommandStats
+	56, // 17: plugin.CommandStats.max_latency:type_name -> google.protobuf.Duration
+	57, // 18: plugin.SubmitJobRequest.input:type_name -> google.protobuf.Any
+	25, // 19: plugin.GetJobResponse.job:type_name -> plugin.Job
+	0,  // 20: plugin.Job.state:type_name -> plugin.JobState
+	57, // 21: plugin.Job.output:type_name -> google.protobuf.Any
+	26, // 22: plugin.Job.error:type_name -> plugin.JobError
+	55, // 23: plugin.Job.created_at:type_name -> google.protobuf.Timestamp
+	55, // 24: plugin.Job.completed_at:type_name -> google.protobuf.Timestamp
+	57, // 25: plugin.JobError.details:type_name -> google.protobuf.Any
+	57, // 26: plugin.ConfigureRequest.configuration:type_name -> google.protobuf.Any
+	52, // 27: plugin.SetSecretsRequest.secrets:type_name -> plugin.SetSecretsRequest.SecretsEntry
+	57, // 28: plugin.HostCall.input:type_name -> google.protobuf.Any
+	57, // 29: plugin.HostResult.output:type_name -> google.protobuf.Any
+	55, // 30: plugin.LogRequest.time:type_name -> google.protobuf.Timestamp
+	38, // 31: plugin.LogRequest.attrs:type_name -> plugin.LogAttr
+	59, // 32: plugin.LogAttr.value:type_name -> google.protobuf.Value
+	53, // 33: plugin.RoundTripRequest.headers:type_name -> plugin.RoundTripRequest.HeadersEntry
+	54, // 34: plugin.RoundTripResponse.headers:type_name -> plugin.RoundTripResponse.HeadersEntry
+	55, // 35: plugin.FileInfo.modified:type_name -> google.protobuf.Timestamp
+	43, // 36: plugin.StatFileResponse.info:type_name -> plugin.FileInfo
+	43, // 37: plugin.ReadDirResponse.entries:type_name -> plugin.FileInfo
+	42, // 38: plugin.RoundTripRequest.HeadersEntry.value:type_name -> plugin.HTTPHeader
+	42, // 39: plugin.RoundTripResponse.HeadersEntry.value:type_name -> plugin.HTTPHeader
+	1,  // 40: plugin.PluginService.Stat:input_type -> plugin.StatRequest
+	6,  // 41: plugin.PluginService.Execute:input_type -> plugin.ExecuteRequest
+	8,  // 42: plugin.PluginService.ExecuteStream:input_type -> plugin.ExecuteChunk
+	19, // 43: plugin.PluginService.SubmitJob:input_type -> plugin.SubmitJobRequest
+	21, // 44: plugin.PluginService.GetJob:input_type -> plugin.GetJobRequest
+	23, // 45: plugin.PluginService.CancelJob:input_type -> plugin.CancelJobRequest
+	9,  // 46: plugin.PluginService.Cancel:input_type -> plugin.CancelRequest
+	11, // 47: plugin.PluginService.Subscribe:input_type -> plugin.SubscribeRequest
+	13, // 48: plugin.PluginService.Describe:input_type -> plugin.DescribeRequest
+	16, // 49: plugin.PluginService.Stats:input_type -> plugin.StatsRequest
+	27, // 50: plugin.PluginService.SendFile:input_type -> plugin.FileChunk
+	29, // 51: plugin.PluginService.ReceiveFile:input_type -> plugin.ReceiveFileRequest
+	31, // 52: plugin.PluginService.Configure:input_type -> plugin.ConfigureRequest
+	33, // 53: plugin.PluginService.SetSecrets:input_type -> plugin.SetSecretsRequest
+	36, // 54: plugin.PluginService.Broker:input_type -> plugin.HostResult
+	37, // 55: plugin.HostService.Log:input_type -> plugin.LogRequest
+	40, // 56: plugin.HostService.RoundTrip:input_type -> plugin.RoundTripRequest
+	44, // 57: plugin.HostService.StatFile:input_type -> plugin.StatFileRequest
+	46, // 58: plugin.HostService.ReadDir:input_type -> plugin.ReadDirRequest
+	48, // 59: plugin.HostService.ReadFile:input_type -> plugin.ReadFileRequest
+	50, // 60: plugin.HostService.WriteFile:input_type -> plugin.WriteFileRequest
+	2,  // 61: plugin.PluginService.Stat:output_type -> plugin.StatResponse
+	7,  // 62: plugin.PluginService.Execute:output_type -> plugin.ExecuteResponse
+	8,  // 63: plugin.PluginService.ExecuteStream:output_type -> plugin.ExecuteChunk
+	20, // 64: plugin.PluginService.SubmitJob:output_type -> plugin.SubmitJobResponse
+	22, // 65: plugin.PluginService.GetJob:output_type -> plugin.GetJobResponse
+	24, // 66: plugin.PluginService.CancelJob:output_type -> plugin.CancelJobResponse
+	10, // 67: plugin.PluginService.Cancel:output_type -> plugin.CancelResponse
+	12, // 68: plugin.PluginService.Subscribe:output_type -> plugin.SubscribeResponse
+	14, // 69: plugin.PluginService.Describe:output_type -> plugin.DescribeResponse
+	17, // 70: plugin.PluginService.Stats:output_type -> plugin.StatsResponse
+	28, // 71: plugin.PluginService.SendFile:output_type -> plugin.SendFileResponse
+	27, // 72: plugin.PluginService.ReceiveFile:output_type -> plugin.FileChunk
+	32, // 73: plugin.PluginService.Configure:output_type -> plugin.ConfigureResponse
+	34, // 74: plugin.PluginService.SetSecrets:output_type -> plugin.SetSecretsResponse
+	35, // 75: plugin.PluginService.Broker:output_type -> plugin.HostCall
+	39, // 76: plugin.HostService.Log:output_type -> plugin.LogResponse
+	41, // 77: plugin.HostService.RoundTrip:output_type -> plugin.RoundTripResponse
+	45, // 78: plugin.HostService.StatFile:output_type -> plugin.StatFileResponse
+	47, // 79: plugin.HostService.ReadDir:output_type -> plugin.ReadDirResponse
+	49, // 80: plugin.HostService.ReadFile:output_type -> plugin.ReadFileResponse
+	51, // 81: plugin.HostService.WriteFile:output_type -> plugin.WriteFileResponse
+	61, // [61:82] is the sub-list for method output_type
+	40, // [40:61] is the sub-list for method input_type
+	40, // [40:40] is the sub-list for extension type_name
+	40, // [40:40] is the sub-list for extension extendee
+	0,  // [0:40] is the sub-list for field type_name
 }
 
 func init() { file_proto_plugin_plugin_proto_init() }
@@ -289,13 +3520,14 @@ func file_proto_plugin_plugin_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_plugin_plugin_proto_rawDesc), len(file_proto_plugin_plugin_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      1,
+			NumMessages:   54,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_proto_plugin_plugin_proto_goTypes,
 		DependencyIndexes: file_proto_plugin_plugin_proto_depIdxs,
+		EnumInfos:         file_proto_plugin_plugin_proto_enumTypes,
 		MessageInfos:      file_proto_plugin_plugin_proto_msgTypes,
 	}.Build()
 	File_proto_plugin_plugin_proto = out.File
@@ -21,8 +21,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	PluginService_Stat_FullMethodName    = "/plugin.PluginService/Stat"
-	PluginService_Execute_FullMethodName = "/plugin.PluginService/Execute"
+	PluginService_Stat_FullMethodName          = "/plugin.PluginService/Stat"
+	PluginService_Execute_FullMethodName       = "/plugin.PluginService/Execute"
+	PluginService_ExecuteStream_FullMethodName = "/plugin.PluginService/ExecuteStream"
+	PluginService_SubmitJob_FullMethodName     = "/plugin.PluginService/SubmitJob"
+	PluginService_GetJob_FullMethodName        = "/plugin.PluginService/GetJob"
+	PluginService_CancelJob_FullMethodName     = "/plugin.PluginService/CancelJob"
+	PluginService_Cancel_FullMethodName        = "/plugin.PluginService/Cancel"
+	PluginService_Subscribe_FullMethodName     = "/plugin.PluginService/Subscribe"
+	PluginService_Describe_FullMethodName      = "/plugin.PluginService/Describe"
+	PluginService_Stats_FullMethodName         = "/plugin.PluginService/Stats"
+	PluginService_SendFile_FullMethodName      = "/plugin.PluginService/SendFile"
+	PluginService_ReceiveFile_FullMethodName   = "/plugin.PluginService/ReceiveFile"
+	PluginService_Configure_FullMethodName     = "/plugin.PluginService/Configure"
+	PluginService_SetSecrets_FullMethodName    = "/plugin.PluginService/SetSecrets"
+	PluginService_Broker_FullMethodName        = "/plugin.PluginService/Broker"
 )
 
 // PluginServiceClient is the client API for PluginService service.
@@ -33,8 +46,57 @@ const (
 type PluginServiceClient interface {
 	// Stat returns metadata about the plugin.
 	Stat(ctx context.Context, in *StatRequest, opts ...grpc.CallOption) (*StatResponse, error)
-	// Execute a plugin command. Should return a NOT_FOUND code if the specified command does not exist.
+	// Execute a plugin command. Should return a NOT_FOUND code with a google.rpc.ErrorInfo detail using the "plugin"
+	// domain and "UNKNOWN_COMMAND" reason if the specified command does not exist.
 	Execute(ctx context.Context, in *ExecuteRequest, opts ...grpc.CallOption) (*ExecuteResponse, error)
+	// ExecuteStream executes a plugin command whose input or output may be too large to send as a single message. The
+	// client sends the input as a sequence of chunks, the first of which names the command, then closes its side of
+	// the stream. The plugin responds with the output as a sequence of chunks. Should return the same errors as Execute.
+	ExecuteStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecuteChunk, ExecuteChunk], error)
+	// SubmitJob starts executing a plugin command in the background, returning an identifier used to track its
+	// progress. Should return the same errors as Execute if the specified command does not exist.
+	SubmitJob(ctx context.Context, in *SubmitJobRequest, opts ...grpc.CallOption) (*SubmitJobResponse, error)
+	// GetJob returns the current state of a job. Should return a NOT_FOUND code if the job does not exist.
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*GetJobResponse, error)
+	// CancelJob cancels a running job. Should return a NOT_FOUND code if the job does not exist. Cancelling a job
+	// that has already completed has no effect.
+	CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*CancelJobResponse, error)
+	// Cancel an in-flight call to Execute using the request identifier it was given. Should return a NOT_FOUND code if
+	// no call with the identifier is in-flight.
+	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error)
+	// Subscribe to events published by the plugin. Events are streamed until the request is cancelled or the plugin
+	// exits.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SubscribeResponse], error)
+	// Describe the input and output messages of each command, alongside the descriptors of the files that define
+	// them, so that messages can be constructed without access to the plugin's protobuf definitions.
+	Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*DescribeResponse, error)
+	// Stats returns statistics describing the calls made to each command since the plugin started, such as how often
+	// they have been executed, how often they have failed and how long they have taken.
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// SendFile stores a file provided by the host application within the plugin, so that commands can read it using
+	// the identifier given in the first chunk. The final chunk contains the checksum of the file. Should return a
+	// DATA_LOSS code if the checksum does not match the data received.
+	SendFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[FileChunk, SendFileResponse], error)
+	// ReceiveFile streams a file created by a command to the host application, ending with a chunk that contains the
+	// checksum of the file. Should return a NOT_FOUND code if the file does not exist.
+	ReceiveFile(ctx context.Context, in *ReceiveFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error)
+	// Configure the plugin using configuration provided by the host application, such as credentials or endpoints.
+	// Host applications call Configure after Stat for plugins that report themselves as configurable, before executing
+	// any commands. Should return an UNIMPLEMENTED code if the plugin does not accept configuration, and an
+	// INVALID_ARGUMENT code if it rejects the configuration.
+	Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*ConfigureResponse, error)
+	// SetSecrets replaces the secrets held by the plugin, such as credentials, which are kept in memory rather than
+	// being passed as arguments or configuration. Host applications call SetSecrets after Stat, before Configure.
+	// Secrets are sealed to the secret key returned by Stat when the plugin provides one. Should return an
+	// UNIMPLEMENTED code if the plugin does not accept secrets, and an INVALID_ARGUMENT code if a sealed secret cannot
+	// be opened.
+	SetSecrets(ctx context.Context, in *SetSecretsRequest, opts ...grpc.CallOption) (*SetSecretsResponse, error)
+	// Broker opens a stream through which the plugin calls services provided by the host application, such as those
+	// described by the HostService. Host applications open the stream after Stat, before Configure, and only if they
+	// provide any services. The plugin sends its response headers once it is ready to make calls, and the host
+	// application sends a result for each call, in any order. Should return an ALREADY_EXISTS code if a stream
+	// is already open.
+	Broker(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[HostResult, HostCall], error)
 }
 
 type pluginServiceClient struct {
@@ -65,6 +127,163 @@ func (c *pluginServiceClient) Execute(ctx context.Context, in *ExecuteRequest, o
 	return out, nil
 }
 
+func (c *pluginServiceClient) ExecuteStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ExecuteChunk, ExecuteChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PluginService_ServiceDesc.Streams[0], PluginService_ExecuteStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExecuteChunk, ExecuteChunk]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PluginService_ExecuteStreamClient = grpc.BidiStreamingClient[ExecuteChunk, ExecuteChunk]
+
+func (c *pluginServiceClient) SubmitJob(ctx context.Context, in *SubmitJobRequest, opts ...grpc.CallOption) (*SubmitJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitJobResponse)
+	err := c.cc.Invoke(ctx, PluginService_SubmitJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*GetJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetJobResponse)
+	err := c.cc.Invoke(ctx, PluginService_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) CancelJob(ctx context.Context, in *CancelJobRequest, opts ...grpc.CallOption) (*CancelJobResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelJobResponse)
+	err := c.cc.Invoke(ctx, PluginService_CancelJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelResponse)
+	err := c.cc.Invoke(ctx, PluginService_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SubscribeResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PluginService_ServiceDesc.Streams[1], PluginService_Subscribe_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, SubscribeResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PluginService_SubscribeClient = grpc.ServerStreamingClient[SubscribeResponse]
+
+func (c *pluginServiceClient) Describe(ctx context.Context, in *DescribeRequest, opts ...grpc.CallOption) (*DescribeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DescribeResponse)
+	err := c.cc.Invoke(ctx, PluginService_Describe_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatsResponse)
+	err := c.cc.Invoke(ctx, PluginService_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) SendFile(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[FileChunk, SendFileResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PluginService_ServiceDesc.Streams[2], PluginService_SendFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FileChunk, SendFileResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PluginService_SendFileClient = grpc.ClientStreamingClient[FileChunk, SendFileResponse]
+
+func (c *pluginServiceClient) ReceiveFile(ctx context.Context, in *ReceiveFileRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FileChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PluginService_ServiceDesc.Streams[3], PluginService_ReceiveFile_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReceiveFileRequest, FileChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PluginService_ReceiveFileClient = grpc.ServerStreamingClient[FileChunk]
+
+func (c *pluginServiceClient) Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*ConfigureResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfigureResponse)
+	err := c.cc.Invoke(ctx, PluginService_Configure_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) SetSecrets(ctx context.Context, in *SetSecretsRequest, opts ...grpc.CallOption) (*SetSecretsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetSecretsResponse)
+	err := c.cc.Invoke(ctx, PluginService_SetSecrets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginServiceClient) Broker(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[HostResult, HostCall], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &PluginService_ServiceDesc.Streams[4], PluginService_Broker_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[HostResult, HostCall]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PluginService_BrokerClient = grpc.BidiStreamingClient[HostResult, HostCall]
+
 // PluginServiceServer is the server API for PluginService service.
 // All implementations must embed UnimplementedPluginServiceServer
 // for forward compatibility.
@@ -73,8 +292,57 @@ func (c *pluginServiceClient) Execute(ctx context.Context, in *ExecuteRequest, o
 type PluginServiceServer interface {
 	// Stat returns metadata about the plugin.
 	Stat(context.Context, *StatRequest) (*StatResponse, error)
-	// Execute a plugin command. Should return a NOT_FOUND code if the specified command does not exist.
+	// Execute a plugin command. Should return a NOT_FOUND code with a google.rpc.ErrorInfo detail using the "plugin"
+	// domain and "UNKNOWN_COMMAND" reason if the specified command does not exist.
 	Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error)
+	// ExecuteStream executes a plugin command whose input or output may be too large to send as a single message. The
+	// client sends the input as a sequence of chunks, the first of which names the command, then closes its side of
+	// the stream. The plugin responds with the output as a sequence of chunks. Should return the same errors as Execute.
+	ExecuteStream(grpc.BidiStreamingServer[ExecuteChunk, ExecuteChunk]) error
+	// SubmitJob starts executing a plugin command in the background, returning an identifier used to track its
+	// progress. Should return the same errors as Execute if the specified command does not exist.
+	SubmitJob(context.Context, *SubmitJobRequest) (*SubmitJobResponse, error)
+	// GetJob returns the current state of a job. Should return a NOT_FOUND code if the job does not exist.
+	GetJob(context.Context, *GetJobRequest) (*GetJobResponse, error)
+	// CancelJob cancels a running job. Should return a NOT_FOUND code if the job does not exist. Cancelling a job
+	// that has already completed has no effect.
+	CancelJob(context.Context, *CancelJobRequest) (*CancelJobResponse, error)
+	// Cancel an in-flight call to Execute using the request identifier it was given. Should return a NOT_FOUND code if
+	// no call with the identifier is in-flight.
+	Cancel(context.Context, *CancelRequest) (*CancelResponse, error)
+	// Subscribe to events published by the plugin. Events are streamed until the request is cancelled or the plugin
+	// exits.
+	Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[SubscribeResponse]) error
+	// Describe the input and output messages of each command, alongside the descriptors of the files that define
+	// them, so that messages can be constructed without access to the plugin's protobuf definitions.
+	Describe(context.Context, *DescribeRequest) (*DescribeResponse, error)
+	// Stats returns statistics describing the calls made to each command since the plugin started, such as how often
+	// they have been executed, how often they have failed and how long they have taken.
+	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	// SendFile stores a file provided by the host application within the plugin, so that commands can read it using
+	// the identifier given in the first chunk. The final chunk contains the checksum of the file. Should return a
+	// DATA_LOSS code if the checksum does not match the data received.
+	SendFile(grpc.ClientStreamingServer[FileChunk, SendFileResponse]) error
+	// ReceiveFile streams a file created by a command to the host application, ending with a chunk that contains the
+	// checksum of the file. Should return a NOT_FOUND code if the file does not exist.
+	ReceiveFile(*ReceiveFileRequest, grpc.ServerStreamingServer[FileChunk]) error
+	// Configure the plugin using configuration provided by the host application, such as credentials or endpoints.
+	// Host applications call Configure after Stat for plugins that report themselves as configurable, before executing
+	// any commands. Should return an UNIMPLEMENTED code if the plugin does not accept configuration, and an
+	// INVALID_ARGUMENT code if it rejects the configuration.
+	Configure(context.Context, *ConfigureRequest) (*ConfigureResponse, error)
+	// SetSecrets replaces the secrets held by the plugin, such as credentials, which are kept in memory rather than
+	// being passed as arguments or configuration. Host applications call SetSecrets after Stat, before Configure.
+	// Secrets are sealed to the secret key returned by Stat when the plugin provides one. Should return an
+	// UNIMPLEMENTED code if the plugin does not accept secrets, and an INVALID_ARGUMENT code if a sealed secret cannot
+	// be opened.
+	SetSecrets(context.Context, *SetSecretsRequest) (*SetSecretsResponse, error)
+	// Broker opens a stream through which the plugin calls services provided by the host application, such as those
+	// described by the HostService. Host applications open the stream after Stat, before Configure, and only if they
+	// provide any services. The plugin sends its response headers once it is ready to make calls, and the host
+	// application sends a result for each call, in any order. Should return an ALREADY_EXISTS code if a stream
+	// is already open.
+	Broker(grpc.BidiStreamingServer[HostResult, HostCall]) error
 	mustEmbedUnimplementedPluginServiceServer()
 }
 
@@ -91,6 +359,45 @@ func (UnimplementedPluginServiceServer) Stat(context.Context, *StatRequest) (*St
 func (UnimplementedPluginServiceServer) Execute(context.Context, *ExecuteRequest) (*ExecuteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Execute not implemented")
 }
+func (UnimplementedPluginServiceServer) ExecuteStream(grpc.BidiStreamingServer[ExecuteChunk, ExecuteChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ExecuteStream not implemented")
+}
+func (UnimplementedPluginServiceServer) SubmitJob(context.Context, *SubmitJobRequest) (*SubmitJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitJob not implemented")
+}
+func (UnimplementedPluginServiceServer) GetJob(context.Context, *GetJobRequest) (*GetJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedPluginServiceServer) CancelJob(context.Context, *CancelJobRequest) (*CancelJobResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelJob not implemented")
+}
+func (UnimplementedPluginServiceServer) Cancel(context.Context, *CancelRequest) (*CancelResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedPluginServiceServer) Subscribe(*SubscribeRequest, grpc.ServerStreamingServer[SubscribeResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedPluginServiceServer) Describe(context.Context, *DescribeRequest) (*DescribeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Describe not implemented")
+}
+func (UnimplementedPluginServiceServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (UnimplementedPluginServiceServer) SendFile(grpc.ClientStreamingServer[FileChunk, SendFileResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method SendFile not implemented")
+}
+func (UnimplementedPluginServiceServer) ReceiveFile(*ReceiveFileRequest, grpc.ServerStreamingServer[FileChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method ReceiveFile not implemented")
+}
+func (UnimplementedPluginServiceServer) Configure(context.Context, *ConfigureRequest) (*ConfigureResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Configure not implemented")
+}
+func (UnimplementedPluginServiceServer) SetSecrets(context.Context, *SetSecretsRequest) (*SetSecretsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSecrets not implemented")
+}
+func (UnimplementedPluginServiceServer) Broker(grpc.BidiStreamingServer[HostResult, HostCall]) error {
+	return status.Errorf(codes.Unimplemented, "method Broker not implemented")
+}
 func (UnimplementedPluginServiceServer) mustEmbedUnimplementedPluginServiceServer() {}
 func (UnimplementedPluginServiceServer) testEmbeddedByValue()                       {}
 
@@ -148,6 +455,193 @@ func _PluginService_Execute_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PluginService_ExecuteStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PluginServiceServer).ExecuteStream(&grpc.GenericServerStream[ExecuteChunk, ExecuteChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PluginService_ExecuteStreamServer = grpc.BidiStreamingServer[ExecuteChunk, ExecuteChunk]
+
+func _PluginService_SubmitJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).SubmitJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_SubmitJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).SubmitJob(ctx, req.(*SubmitJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_CancelJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).CancelJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_CancelJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).CancelJob(ctx, req.(*CancelJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).Cancel(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PluginServiceServer).Subscribe(m, &grpc.GenericServerStream[SubscribeRequest, SubscribeResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PluginService_SubscribeServer = grpc.ServerStreamingServer[SubscribeResponse]
+
+func _PluginService_Describe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DescribeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).Describe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_Describe_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).Describe(ctx, req.(*DescribeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_SendFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PluginServiceServer).SendFile(&grpc.GenericServerStream[FileChunk, SendFileResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PluginService_SendFileServer = grpc.ClientStreamingServer[FileChunk, SendFileResponse]
+
+func _PluginService_ReceiveFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ReceiveFileRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PluginServiceServer).ReceiveFile(m, &grpc.GenericServerStream[ReceiveFileRequest, FileChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PluginService_ReceiveFileServer = grpc.ServerStreamingServer[FileChunk]
+
+func _PluginService_Configure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).Configure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_Configure_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).Configure(ctx, req.(*ConfigureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_SetSecrets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetSecretsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServiceServer).SetSecrets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PluginService_SetSecrets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServiceServer).SetSecrets(ctx, req.(*SetSecretsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PluginService_Broker_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(PluginServiceServer).Broker(&grpc.GenericServerStream[HostResult, HostCall]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type PluginService_BrokerServer = grpc.BidiStreamingServer[HostResult, HostCall]
+
 // PluginService_ServiceDesc is the grpc.ServiceDesc for PluginService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -163,6 +657,388 @@ var PluginService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Execute",
 			Handler:    _PluginService_Execute_Handler,
 		},
+		{
+			MethodName: "SubmitJob",
+			Handler:    _PluginService_SubmitJob_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _PluginService_GetJob_Handler,
+		},
+		{
+			MethodName: "CancelJob",
+			Handler:    _PluginService_CancelJob_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _PluginService_Cancel_Handler,
+		},
+		{
+			MethodName: "Describe",
+			Handler:    _PluginService_Describe_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _PluginService_Stats_Handler,
+		},
+		{
+			MethodName: "Configure",
+			Handler:    _PluginService_Configure_Handler,
+		},
+		{
+			MethodName: "SetSecrets",
+			Handler:    _PluginService_SetSecrets_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExecuteStream",
+			Handler:       _PluginService_ExecuteStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _PluginService_Subscribe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SendFile",
+			Handler:       _PluginService_SendFile_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ReceiveFile",
+			Handler:       _PluginService_ReceiveFile_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Broker",
+			Handler:       _PluginService_Broker_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "proto/plugin/plugin.proto",
+}
+
+const (
+	HostService_Log_FullMethodName       = "/plugin.HostService/Log"
+	HostService_RoundTrip_FullMethodName = "/plugin.HostService/RoundTrip"
+	HostService_StatFile_FullMethodName  = "/plugin.HostService/StatFile"
+	HostService_ReadDir_FullMethodName   = "/plugin.HostService/ReadDir"
+	HostService_ReadFile_FullMethodName  = "/plugin.HostService/ReadFile"
+	HostService_WriteFile_FullMethodName = "/plugin.HostService/WriteFile"
+)
+
+// HostServiceClient is the client API for HostService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// The HostService describes services that host applications provide to plugins. Its methods are not served via gRPC,
+// instead plugins call them via the Broker stream using their full method name, such as "/plugin.HostService/Log".
+type HostServiceClient interface {
+	// Log a record within the logs of the host application.
+	Log(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (*LogResponse, error)
+	// RoundTrip makes an HTTP request on behalf of the plugin, returning its response. Should return a
+	// PERMISSION_DENIED code if the host application does not allow the request.
+	RoundTrip(ctx context.Context, in *RoundTripRequest, opts ...grpc.CallOption) (*RoundTripResponse, error)
+	// StatFile describes a file within the directory shared with the plugin. Paths are slash-separated and relative to
+	// the shared directory. Should return a NOT_FOUND code if the file does not exist, and a PERMISSION_DENIED code if
+	// the path is outside the shared directory.
+	StatFile(ctx context.Context, in *StatFileRequest, opts ...grpc.CallOption) (*StatFileResponse, error)
+	// ReadDir lists the contents of a directory within the directory shared with the plugin, in order of their names.
+	// Should return the same errors as StatFile.
+	ReadDir(ctx context.Context, in *ReadDirRequest, opts ...grpc.CallOption) (*ReadDirResponse, error)
+	// ReadFile reads part of a file within the directory shared with the plugin. The host application may return less
+	// data than requested. Should return the same errors as StatFile.
+	ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (*ReadFileResponse, error)
+	// WriteFile writes to a file within the directory shared with the plugin, creating it if it does not exist. Should
+	// return the same errors as StatFile, with a PERMISSION_DENIED code if the directory is read-only.
+	WriteFile(ctx context.Context, in *WriteFileRequest, opts ...grpc.CallOption) (*WriteFileResponse, error)
+}
+
+type hostServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHostServiceClient(cc grpc.ClientConnInterface) HostServiceClient {
+	return &hostServiceClient{cc}
+}
+
+func (c *hostServiceClient) Log(ctx context.Context, in *LogRequest, opts ...grpc.CallOption) (*LogResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogResponse)
+	err := c.cc.Invoke(ctx, HostService_Log_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) RoundTrip(ctx context.Context, in *RoundTripRequest, opts ...grpc.CallOption) (*RoundTripResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RoundTripResponse)
+	err := c.cc.Invoke(ctx, HostService_RoundTrip_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) StatFile(ctx context.Context, in *StatFileRequest, opts ...grpc.CallOption) (*StatFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(StatFileResponse)
+	err := c.cc.Invoke(ctx, HostService_StatFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) ReadDir(ctx context.Context, in *ReadDirRequest, opts ...grpc.CallOption) (*ReadDirResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadDirResponse)
+	err := c.cc.Invoke(ctx, HostService_ReadDir_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) ReadFile(ctx context.Context, in *ReadFileRequest, opts ...grpc.CallOption) (*ReadFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReadFileResponse)
+	err := c.cc.Invoke(ctx, HostService_ReadFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *hostServiceClient) WriteFile(ctx context.Context, in *WriteFileRequest, opts ...grpc.CallOption) (*WriteFileResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WriteFileResponse)
+	err := c.cc.Invoke(ctx, HostService_WriteFile_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HostServiceServer is the server API for HostService service.
+// All implementations must embed UnimplementedHostServiceServer
+// for forward compatibility.
+//
+// The HostService describes services that host applications provide to plugins. Its methods are not served via gRPC,
+// instead plugins call them via the Broker stream using their full method name, such as "/plugin.HostService/Log".
+type HostServiceServer interface {
+	// Log a record within the logs of the host application.
+	Log(context.Context, *LogRequest) (*LogResponse, error)
+	// RoundTrip makes an HTTP request on behalf of the plugin, returning its response. Should return a
+	// PERMISSION_DENIED code if the host application does not allow the request.
+	RoundTrip(context.Context, *RoundTripRequest) (*RoundTripResponse, error)
+	// StatFile describes a file within the directory shared with the plugin. Paths are slash-separated and relative to
+	// the shared directory. Should return a NOT_FOUND code if the file does not exist, and a PERMISSION_DENIED code if
+	// the path is outside the shared directory.
+	StatFile(context.Context, *StatFileRequest) (*StatFileResponse, error)
+	// ReadDir lists the contents of a directory within the directory shared with the plugin, in order of their names.
+	// Should return the same errors as StatFile.
+	ReadDir(context.Context, *ReadDirRequest) (*ReadDirResponse, error)
+	// ReadFile reads part of a file within the directory shared with the plugin. The host application may return less
+	// data than requested. Should return the same errors as StatFile.
+	ReadFile(context.Context, *ReadFileRequest) (*ReadFileResponse, error)
+	// WriteFile writes to a file within the directory shared with the plugin, creating it if it does not exist. Should
+	// return the same errors as StatFile, with a PERMISSION_DENIED code if the directory is read-only.
+	WriteFile(context.Context, *WriteFileRequest) (*WriteFileResponse, error)
+	mustEmbedUnimplementedHostServiceServer()
+}
+
+// UnimplementedHostServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHostServiceServer struct{}
+
+func (UnimplementedHostServiceServer) Log(context.Context, *LogRequest) (*LogResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Log not implemented")
+}
+func (UnimplementedHostServiceServer) RoundTrip(context.Context, *RoundTripRequest) (*RoundTripResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RoundTrip not implemented")
+}
+func (UnimplementedHostServiceServer) StatFile(context.Context, *StatFileRequest) (*StatFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StatFile not implemented")
+}
+func (UnimplementedHostServiceServer) ReadDir(context.Context, *ReadDirRequest) (*ReadDirResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadDir not implemented")
+}
+func (UnimplementedHostServiceServer) ReadFile(context.Context, *ReadFileRequest) (*ReadFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReadFile not implemented")
+}
+func (UnimplementedHostServiceServer) WriteFile(context.Context, *WriteFileRequest) (*WriteFileResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WriteFile not implemented")
+}
+func (UnimplementedHostServiceServer) mustEmbedUnimplementedHostServiceServer() {}
+func (UnimplementedHostServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeHostServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HostServiceServer will
+// result in compilation errors.
+type UnsafeHostServiceServer interface {
+	mustEmbedUnimplementedHostServiceServer()
+}
+
+func RegisterHostServiceServer(s grpc.ServiceRegistrar, srv HostServiceServer) {
+	// If the following call pancis, it indicates UnimplementedHostServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&HostService_ServiceDesc, srv)
+}
+
+func _HostService_Log_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).Log(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_Log_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).Log(ctx, req.(*LogRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_RoundTrip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RoundTripRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).RoundTrip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_RoundTrip_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).RoundTrip(ctx, req.(*RoundTripRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_StatFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).StatFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_StatFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).StatFile(ctx, req.(*StatFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_ReadDir_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadDirRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).ReadDir(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_ReadDir_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).ReadDir(ctx, req.(*ReadDirRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_ReadFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReadFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).ReadFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_ReadFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).ReadFile(ctx, req.(*ReadFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HostService_WriteFile_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WriteFileRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HostServiceServer).WriteFile(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HostService_WriteFile_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HostServiceServer).WriteFile(ctx, req.(*WriteFileRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HostService_ServiceDesc is the grpc.ServiceDesc for HostService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HostService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "plugin.HostService",
+	HandlerType: (*HostServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Log",
+			Handler:    _HostService_Log_Handler,
+		},
+		{
+			MethodName: "RoundTrip",
+			Handler:    _HostService_RoundTrip_Handler,
+		},
+		{
+			MethodName: "StatFile",
+			Handler:    _HostService_StatFile_Handler,
+		},
+		{
+			MethodName: "ReadDir",
+			Handler:    _HostService_ReadDir_Handler,
+		},
+		{
+			MethodName: "ReadFile",
+			Handler:    _HostService_ReadFile_Handler,
+		},
+		{
+			MethodName: "WriteFile",
+			Handler:    _HostService_WriteFile_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/plugin/plugin.proto",
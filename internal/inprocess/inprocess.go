@@ -0,0 +1,11 @@
+// Package inprocess allows packages within this module, such as plugintest, to serve plugins from within the host
+// application without the plugin package exporting this as part of its public API.
+package inprocess
+
+// Use is set by the plugin package when it is initialised. It has the signature:
+//
+//	func(ctx context.Context, config plugin.Config, opts ...plugin.UseOption) (*plugin.Plugin, error)
+//
+// It is declared using the any type because this package cannot import the plugin package without creating an import
+// cycle.
+var Use any
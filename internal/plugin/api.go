@@ -2,23 +2,60 @@ package plugin
 
 import (
 	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	"github.com/davidsbond/plugin/internal/shm"
 )
 
 type (
 	// The API type implements the plugin API, exposing plugin information and command execution.
 	API struct {
 		plugin.UnimplementedPluginServiceServer
-		info     Info
-		handlers CommandHandlers
+		info        Info
+		handlers    CommandHandlers
+		gracePeriod time.Duration
+		jobs        jobs
+		calls       calls
+		events      events
+		files       files
+		memory      *shm.Store
+		codecs      map[string]Codec
+		codecNames  []string
+
+		minHostVersion string
+		host           atomic.Pointer[Host]
+
+		configureMu    sync.Mutex
+		configure      ConfigureFunc
+		onConfigChange ConfigureFunc
+		configured     atomic.Bool
+
+		secretKey *ecdh.PrivateKey
+		secrets   Secrets
+		broker    Broker
+
+		mu       sync.Mutex
+		inflight map[string]context.CancelFunc
 	}
 
+	// The APIOption type is a function that modifies the behaviour of the API.
+	APIOption func(api *API)
+
 	// The CommandHandlers type is a map that stores command names against their execution functions.
 	CommandHandlers map[string]func(ctx context.Context, input *anypb.Any) (*anypb.Any, error)
 
@@ -29,16 +66,125 @@ type (
 		// The Version of the plugin.
 		Version string
 		// Commands provided by the plugin.
-		Commands []string
+		Commands []Command
+		// Sandboxed is true if the plugin has enforced the sandbox profile given to it by the host application.
+		Sandboxed bool
+		// Configurable is true if the plugin must be configured via Configure before its commands can be executed.
+		Configurable bool
+		// The SecretKey the plugin expects secrets given to SetSecrets to be sealed to, if any.
+		SecretKey []byte
+	}
+
+	// The Command type describes a single command provided by a plugin.
+	Command struct {
+		// The Name of the command.
+		Name string
+		// A human-readable Description of the command.
+		Description string
+		// An Example describing how the command should be used.
+		Example string
+		// Whether the command is Deprecated.
+		Deprecated bool
+		// Whether the command is Idempotent.
+		Idempotent bool
+		// The names of the Codec implementations supported by the command, in order of preference. When empty, only
+		// the ProtoCodec is supported.
+		Codecs []string
+		// The RateLimit of the command, enforced by the interceptors returned by RateLimitServerOptions.
+		RateLimit RateLimit
+		// The MaxInputSize of the command, in bytes, enforced by the interceptors returned by InputSizeServerOptions.
+		// When zero, the size of its input is only limited by the maximum message size.
+		MaxInputSize int
+		// The Input message of the command, if known.
+		Input protoreflect.MessageDescriptor
+		// The Output message of the command, if known.
+		Output protoreflect.MessageDescriptor
+	}
+
+	// The ResourceUsage type describes the resources consumed by a plugin process.
+	ResourceUsage struct {
+		// The number of bytes of allocated heap objects.
+		HeapBytes uint64
+		// The total number of bytes of memory obtained from the operating system.
+		SysBytes uint64
+		// The number of goroutines that currently exist.
+		Goroutines int
+		// The total time spent executing in user mode.
+		UserCPUTime time.Duration
+		// The total time spent executing in kernel mode.
+		SystemCPUTime time.Duration
+	}
+
+	// The ProcessDetails type describes the process serving a plugin and the environment it was built for.
+	ProcessDetails struct {
+		// The process identifier.
+		PID int
+		// The time the process started.
+		StartedAt time.Time
+		// The time elapsed since the process started, as measured by the process.
+		Uptime time.Duration
+		// The operating system the plugin was built for.
+		OS string
+		// The architecture the plugin was built for.
+		Arch string
+		// The version of the runtime or toolchain the plugin was built with.
+		RuntimeVersion string
+		// The version of the plugin protocol implemented by the plugin.
+		ProtocolVersion int
+		// The version control revision the plugin was built from, if known.
+		VCSRevision string
+		// The time of the version control revision the plugin was built from, if known.
+		VCSTime time.Time
+		// Whether the plugin was built from a working tree containing uncommitted changes.
+		VCSModified bool
 	}
 )
 
+const (
+	// ErrorDomain is the domain used for errdetails.ErrorInfo details attached to errors returned by the API.
+	ErrorDomain = "plugin"
+	// ReasonUnknownCommand is the reason used for errdetails.ErrorInfo details attached to errors returned by the API
+	// when a command does not exist.
+	ReasonUnknownCommand = "UNKNOWN_COMMAND"
+	// ReasonInvalidInputType is the reason used for errdetails.ErrorInfo details attached to errors returned by
+	// commands when their input is not of the expected message type.
+	ReasonInvalidInputType = "INVALID_INPUT_TYPE"
+)
+
+// DefaultGracePeriod is the default time the API waits for a command handler to return once its context has been
+// cancelled.
+const DefaultGracePeriod = 5 * time.Second
+
 // NewAPI returns a new instance of the API type that will serve the provided plugin information and execute the
 // provided command handlers.
-func NewAPI(info Info, handlers CommandHandlers) *API {
-	return &API{
-		info:     info,
-		handlers: handlers,
+func NewAPI(info Info, handlers CommandHandlers, options ...APIOption) *API {
+	api := &API{
+		info:        info,
+		handlers:    handlers,
+		gracePeriod: DefaultGracePeriod,
+		events:      newEvents(),
+		codecs: map[string]Codec{
+			ProtoCodec{}.Name(): ProtoCodec{},
+			JSONCodec{}.Name():  JSONCodec{},
+		},
+		codecNames: []string{ProtoCodec{}.Name(), JSONCodec{}.Name()},
+	}
+
+	// Without a key, which only happens if the system's random number generator fails, secrets are sent unsealed.
+	api.secretKey, _ = ecdh.X25519().GenerateKey(rand.Reader)
+
+	for _, option := range options {
+		option(api)
+	}
+
+	return api
+}
+
+// WithGracePeriod is an APIOption that sets the maximum time the API waits for a command handler to return once its
+// context has been cancelled. Once elapsed, the request is aborted and the handler is abandoned.
+func WithGracePeriod(gracePeriod time.Duration) APIOption {
+	return func(api *API) {
+		api.gracePeriod = gracePeriod
 	}
 }
 
@@ -47,31 +193,237 @@ func (api *API) Register(s grpc.ServiceRegistrar) {
 	plugin.RegisterPluginServiceServer(s, api)
 }
 
-// Stat returns metadata about the running plugin. Includes its name, version and the commands that can be executed.
-func (api *API) Stat(context.Context, *plugin.StatRequest) (*plugin.StatResponse, error) {
-	return &plugin.StatResponse{
-		Name:     api.info.Name,
-		Version:  api.info.Version,
-		Commands: api.info.Commands,
-	}, nil
+// Stat returns metadata about the running plugin. Includes its name, version, the commands that can be executed and
+// the resources currently used by the plugin process. The host application identifies itself within the request,
+// and codes.FailedPrecondition is returned, with an errdetails.ErrorInfo detail using the ReasonIncompatibleHost
+// reason, if it does not satisfy the minimum host version given via WithMinHostVersion.
+func (api *API) Stat(_ context.Context, request *plugin.StatRequest) (*plugin.StatResponse, error) {
+	if err := api.checkHost(request); err != nil {
+		return nil, err
+	}
+
+	response := &plugin.StatResponse{
+		Name:         api.info.Name,
+		Version:      api.info.Version,
+		Usage:        resourceUsage(),
+		Sandboxed:    api.info.Sandboxed,
+		Configurable: api.configure != nil,
+		Process:      processDetails(),
+	}
+
+	if api.secretKey != nil {
+		response.SecretKey = api.secretKey.PublicKey().Bytes()
+	}
+
+	// Command names are also given separately for host applications that predate command descriptions.
+	for _, command := range api.info.Commands {
+		response.Commands = append(response.Commands, command.Name)
+		response.CommandInfo = append(response.CommandInfo, &plugin.CommandInfo{
+			Name:        command.Name,
+			Description: command.Description,
+			Example:     command.Example,
+			Deprecated:  command.Deprecated,
+			Idempotent:  command.Idempotent,
+			Codecs:      api.codecNames,
+		})
+	}
+
+	return response, nil
+}
+
+// withState returns a copy of the context containing the state given to the plugin by the host application, such as
+// its identity, secrets and the Broker used to call its services.
+func (api *API) withState(ctx context.Context) context.Context {
+	return api.withBroker(api.withSecrets(api.withHost(ctx)))
+}
+
+func resourceUsage() *plugin.ResourceUsage {
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+
+	usage := &plugin.ResourceUsage{
+		HeapBytes:  memory.HeapAlloc,
+		SysBytes:   memory.Sys,
+		Goroutines: int64(runtime.NumGoroutine()),
+	}
+
+	cpuUsage(usage)
+	return usage
 }
 
 // Execute the command describes within the request. Returns codes.NotFound if no command matching the given name
-// is registered with the plugin.
+// is registered with the plugin, with an errdetails.ErrorInfo detail using the ReasonUnknownCommand reason. Errors
+// returned by handlers that carry a gRPC status are returned as-is, preserving their code and details. All other
+// handler errors are returned with codes.Internal. Returns codes.FailedPrecondition if the plugin accepts configuration
+// but has not yet been configured via Configure.
+//
+// If the request context is cancelled, the handler's context is cancelled with it. Once the handler returns, or the
+// grace period elapses, the request fails with codes.Canceled or codes.DeadlineExceeded depending on the reason for
+// cancellation. The deadline of the request, if any, is set on the handler's context, and exceeding it is reported with
+// a message naming the command so that it can be distinguished from other failures.
+//
+// If the request names a Codec within its metadata, the handler is given its input encoded using it, and must encode
+// its output in the same way. The codec is available to the handler via CodecFromContext. Handlers that only support
+// the protobuf binary format can be wrapped using Transcode.
+//
+// Any warnings reported by the handler using AddWarning are returned within the response when it succeeds, alongside
+// the time taken by the handler.
 func (api *API) Execute(ctx context.Context, request *plugin.ExecuteRequest) (*plugin.ExecuteResponse, error) {
 	if request.GetName() == "" {
 		return nil, status.Error(codes.InvalidArgument, "missing command name")
 	}
-	
+
 	handler, ok := api.handlers[request.GetName()]
 	if !ok {
-		return nil, status.Errorf(codes.NotFound, "unknown command %q", request.GetName())
+		return nil, unknownCommand(request.GetName())
+	}
+
+	if err := api.checkConfigured(); err != nil {
+		return nil, err
 	}
 
-	output, err := handler(ctx, request.GetInput())
+	codec, err := api.codec(ctx)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, err
+	}
+
+	ctx, release, err := api.claimSharedMemory(withCodec(ctx, codec))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, warnings := withWarnings(ctx)
+	ctx, cancel := context.WithCancel(withFiles(withMetadata(api.withState(ctx), ctx), &api.files))
+	defer cancel()
+
+	if id := request.GetRequestId(); id != "" {
+		if err := api.track(id, cancel); err != nil {
+			release()
+			return nil, err
+		}
+
+		defer api.untrack(id)
+	}
+
+	type result struct {
+		output *anypb.Any
+		err    error
+	}
+
+	started := time.Now()
+	results := make(chan result, 1)
+	go func() {
+		// Shared memory is only released once the handler returns, as it may still be in use once the grace period
+		// has elapsed.
+		defer release()
+
+		output, err := handler(ctx, request.GetInput())
+		results <- result{output: output, err: err}
+	}()
+
+	var r result
+	select {
+	case r = <-results:
+	case <-ctx.Done():
+		timer := time.NewTimer(api.gracePeriod)
+		defer timer.Stop()
+
+		select {
+		case r = <-results:
+		case <-timer.C:
+			api.calls.record(request.GetName(), time.Since(started), true)
+			return nil, contextError(ctx, request.GetName())
+		}
+	}
+
+	elapsed := time.Since(started)
+	api.calls.record(request.GetName(), elapsed, r.err != nil)
+
+	if r.err != nil {
+		if ctx.Err() != nil {
+			return nil, contextError(ctx, request.GetName())
+		}
+
+		return nil, toStatus(ctx, r.err).Err()
+	}
+
+	return &plugin.ExecuteResponse{
+		Output:   r.output,
+		Warnings: warnings.collected(),
+		Duration: durationpb.New(elapsed),
+	}, nil
+}
+
+// Cancel an in-flight call to Execute by its request identifier. Its command handler's context is cancelled and the
+// call returns with the codes.Canceled status code.
+func (api *API) Cancel(_ context.Context, request *plugin.CancelRequest) (*plugin.CancelResponse, error) {
+	api.mu.Lock()
+	cancel, ok := api.inflight[request.GetRequestId()]
+	api.mu.Unlock()
+
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown request %q", request.GetRequestId())
+	}
+
+	cancel()
+	return &plugin.CancelResponse{}, nil
+}
+
+func (api *API) track(id string, cancel context.CancelFunc) error {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	if _, ok := api.inflight[id]; ok {
+		return status.Errorf(codes.AlreadyExists, "request %q is already in-flight", id)
+	}
+
+	if api.inflight == nil {
+		api.inflight = make(map[string]context.CancelFunc)
+	}
+
+	api.inflight[id] = cancel
+	return nil
+}
+
+func (api *API) untrack(id string) {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+
+	delete(api.inflight, id)
+}
+
+func unknownCommand(name string) error {
+	st, err := status.Newf(codes.NotFound, "unknown command %q", name).WithDetails(&errdetails.ErrorInfo{
+		Reason: ReasonUnknownCommand,
+		Domain: ErrorDomain,
+	})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return st.Err()
+}
+
+// contextError returns the status error describing why the context of the named command was cancelled.
+func contextError(ctx context.Context, name string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return status.Errorf(codes.DeadlineExceeded, "command %q exceeded its deadline", name)
+	}
+
+	return status.FromContextError(ctx.Err()).Err()
+}
+
+// toStatus converts an error returned by a command handler into a gRPC status. Errors caused by the cancellation of the
+// provided context are converted to the matching status code, status errors are preserved as they are, and all other
+// errors use the codes.Internal status code.
+func toStatus(ctx context.Context, err error) *status.Status {
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err())
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return st
 	}
 
-	return &plugin.ExecuteResponse{Output: output}, nil
+	return status.New(codes.Internal, err.Error())
 }
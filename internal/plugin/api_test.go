@@ -9,10 +9,13 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/types/known/anypb"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
 	"github.com/davidsbond/plugin/internal/plugin"
@@ -31,10 +34,10 @@ func TestAPI_Stat(t *testing.T) {
 			Expected: plugin.Info{
 				Name:    "test-plugin",
 				Version: "v0.1.0",
-				Commands: []string{
-					"a",
-					"b",
-					"c",
+				Commands: []plugin.Command{
+					{Name: "a", Description: "Command a"},
+					{Name: "b", Example: "b"},
+					{Name: "c", Deprecated: true},
 				},
 			},
 		},
@@ -46,11 +49,58 @@ func TestAPI_Stat(t *testing.T) {
 			require.NoError(t, err)
 			assert.EqualValues(t, tc.Expected.Name, response.GetName())
 			assert.Equal(t, tc.Expected.Version, response.GetVersion())
-			assert.EqualValues(t, tc.Expected.Commands, response.GetCommands())
+			require.Len(t, response.GetCommandInfo(), len(tc.Expected.Commands))
+			for i, command := range tc.Expected.Commands {
+				assert.EqualValues(t, command.Name, response.GetCommands()[i])
+				assert.EqualValues(t, command.Name, response.GetCommandInfo()[i].GetName())
+				assert.EqualValues(t, command.Description, response.GetCommandInfo()[i].GetDescription())
+				assert.EqualValues(t, command.Example, response.GetCommandInfo()[i].GetExample())
+				assert.EqualValues(t, command.Deprecated, response.GetCommandInfo()[i].GetDeprecated())
+			}
+
+			usage := response.GetUsage()
+			require.NotNil(t, usage)
+			assert.NotZero(t, usage.GetHeapBytes())
+			assert.NotZero(t, usage.GetSysBytes())
+			assert.NotZero(t, usage.GetGoroutines())
+			assert.NotNil(t, usage.GetUserCpuTime())
+			assert.NotNil(t, usage.GetSystemCpuTime())
 		})
 	}
 }
 
+func TestAPI_Describe(t *testing.T) {
+	t.Parallel()
+
+	api := plugin.NewAPI(plugin.Info{
+		Commands: []plugin.Command{
+			{
+				Name:   "typed",
+				Input:  (&wrapperspb.StringValue{}).ProtoReflect().Descriptor(),
+				Output: (&durationpb.Duration{}).ProtoReflect().Descriptor(),
+			},
+			{
+				Name: "untyped",
+			},
+		},
+	}, nil)
+
+	response, err := api.Describe(t.Context(), &pb.DescribeRequest{})
+	require.NoError(t, err)
+
+	require.Len(t, response.GetCommands(), 2)
+	assert.EqualValues(t, "typed", response.GetCommands()[0].GetName())
+	assert.EqualValues(t, "google.protobuf.StringValue", response.GetCommands()[0].GetInputType())
+	assert.EqualValues(t, "google.protobuf.Duration", response.GetCommands()[0].GetOutputType())
+	assert.EqualValues(t, "untyped", response.GetCommands()[1].GetName())
+	assert.Empty(t, response.GetCommands()[1].GetInputType())
+	assert.Empty(t, response.GetCommands()[1].GetOutputType())
+
+	files, err := protodesc.NewFiles(response.GetFiles())
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, files.NumFiles())
+}
+
 func TestAPI_Execute(t *testing.T) {
 	t.Parallel()
 
@@ -93,6 +143,19 @@ func TestAPI_Execute(t *testing.T) {
 				Name: "test",
 			},
 		},
+		{
+			Name:         "command returns status error",
+			ExpectsError: true,
+			ExpectedCode: codes.FailedPrecondition,
+			Handlers: plugin.CommandHandlers{
+				"test": func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+					return nil, status.Error(codes.FailedPrecondition, "failed precondition")
+				},
+			},
+			Request: &pb.ExecuteRequest{
+				Name: "test",
+			},
+		},
 		{
 			Name: "command succeeds",
 			Handlers: plugin.CommandHandlers{
@@ -119,11 +182,113 @@ func TestAPI_Execute(t *testing.T) {
 			}
 
 			require.NoError(t, err)
+			assert.NotNil(t, response.GetDuration())
+
+			// The duration is measured by the API, so cannot be known in advance.
+			response.Duration = nil
 			assert.Equal(t, tc.Expected, response)
 		})
 	}
 }
 
+func TestAPI_Execute_Cancellation(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Name            string
+		Handler         func(ctx context.Context, input *anypb.Any) (*anypb.Any, error)
+		ExpectedCode    codes.Code
+		ExpectedMessage string
+		Cancel          func(ctx context.Context) (context.Context, context.CancelFunc)
+	}{
+		{
+			Name:         "handler returns when cancelled",
+			ExpectedCode: codes.Canceled,
+			Cancel:       context.WithCancel,
+			Handler: func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		},
+		{
+			Name:         "handler ignores cancellation",
+			ExpectedCode: codes.Canceled,
+			Cancel:       context.WithCancel,
+			Handler: func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+				time.Sleep(time.Hour)
+				return nil, nil
+			},
+		},
+		{
+			Name:            "handler ignores deadline",
+			ExpectedCode:    codes.DeadlineExceeded,
+			ExpectedMessage: `command "test" exceeded its deadline`,
+			Cancel: func(ctx context.Context) (context.Context, context.CancelFunc) {
+				return context.WithTimeout(ctx, time.Millisecond)
+			},
+			Handler: func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+				time.Sleep(time.Hour)
+				return nil, nil
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			api := plugin.NewAPI(plugin.Info{}, plugin.CommandHandlers{"test": tc.Handler}, plugin.WithGracePeriod(10*time.Millisecond))
+
+			ctx, cancel := tc.Cancel(t.Context())
+			time.AfterFunc(10*time.Millisecond, cancel)
+
+			start := time.Now()
+			_, err := api.Execute(ctx, &pb.ExecuteRequest{Name: "test"})
+			require.Error(t, err)
+			assert.EqualValues(t, tc.ExpectedCode, status.Code(err))
+			if tc.ExpectedMessage != "" {
+				assert.EqualValues(t, tc.ExpectedMessage, status.Convert(err).Message())
+			}
+			assert.Less(t, time.Since(start), time.Second)
+		})
+	}
+}
+
+func TestAPI_Cancel(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{}, 1)
+	api := plugin.NewAPI(plugin.Info{}, plugin.CommandHandlers{
+		"test": func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+			started <- struct{}{}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}, plugin.WithGracePeriod(10*time.Millisecond))
+
+	t.Run("cancels in-flight request", func(t *testing.T) {
+		errs := make(chan error, 1)
+		go func() {
+			_, err := api.Execute(t.Context(), &pb.ExecuteRequest{Name: "test", RequestId: "a"})
+			errs <- err
+		}()
+
+		<-started
+
+		t.Run("error if request id is in use", func(t *testing.T) {
+			_, err := api.Execute(t.Context(), &pb.ExecuteRequest{Name: "test", RequestId: "a"})
+			assert.EqualValues(t, codes.AlreadyExists, status.Code(err))
+		})
+
+		_, err := api.Cancel(t.Context(), &pb.CancelRequest{RequestId: "a"})
+		require.NoError(t, err)
+		assert.EqualValues(t, codes.Canceled, status.Code(<-errs))
+	})
+
+	t.Run("error if request does not exist", func(t *testing.T) {
+		_, err := api.Cancel(t.Context(), &pb.CancelRequest{RequestId: "a"})
+		assert.EqualValues(t, codes.NotFound, status.Code(err))
+	})
+}
+
 func mustAny(t *testing.T, in proto.Message) *anypb.Any {
 	t.Helper()
 
@@ -131,3 +296,152 @@ func mustAny(t *testing.T, in proto.Message) *anypb.Any {
 	require.NoError(t, err)
 	return out
 }
+
+func TestAPI_Execute_Metadata(t *testing.T) {
+	t.Parallel()
+
+	api := plugin.NewAPI(plugin.Info{}, plugin.CommandHandlers{
+		"test": func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+			return anypb.New(wrapperspb.String(plugin.MetadataFromContext(ctx)["tenant"]))
+		},
+	})
+
+	ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(
+		plugin.MetadataPrefix+"tenant", "a",
+		plugin.MetadataPrefix+"tenant", "b",
+		plugin.TokenMetadataKey, "ignored",
+	))
+
+	response, err := api.Execute(ctx, &pb.ExecuteRequest{Name: "test"})
+	require.NoError(t, err)
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, response.GetOutput().UnmarshalTo(output))
+	assert.EqualValues(t, "b", output.GetValue())
+}
+
+func TestAPI_Configure(t *testing.T) {
+	t.Parallel()
+
+	handlers := plugin.CommandHandlers{
+		"test": func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+			return input, nil
+		},
+	}
+
+	t.Run("unconfigurable plugins reject configuration", func(t *testing.T) {
+		api := plugin.NewAPI(plugin.Info{}, handlers)
+
+		stat, err := api.Stat(t.Context(), &pb.StatRequest{})
+		require.NoError(t, err)
+		assert.False(t, stat.GetConfigurable())
+
+		_, err = api.Configure(t.Context(), &pb.ConfigureRequest{})
+		assert.EqualValues(t, codes.Unimplemented, status.Code(err))
+
+		_, err = api.Execute(t.Context(), &pb.ExecuteRequest{Name: "test"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("commands require configuration", func(t *testing.T) {
+		var configuration *anypb.Any
+		api := plugin.NewAPI(plugin.Info{}, handlers, plugin.WithConfigure(func(_ context.Context, c *anypb.Any) error {
+			if c.MessageIs(&durationpb.Duration{}) {
+				return io.EOF
+			}
+
+			configuration = c
+			return nil
+		}))
+
+		stat, err := api.Stat(t.Context(), &pb.StatRequest{})
+		require.NoError(t, err)
+		assert.True(t, stat.GetConfigurable())
+
+		_, err = api.Execute(t.Context(), &pb.ExecuteRequest{Name: "test"})
+		assert.EqualValues(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = api.SubmitJob(t.Context(), &pb.SubmitJobRequest{Name: "test"})
+		assert.EqualValues(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = api.Configure(t.Context(), &pb.ConfigureRequest{Configuration: mustAny(t, durationpb.New(time.Second))})
+		assert.EqualValues(t, codes.InvalidArgument, status.Code(err))
+
+		_, err = api.Execute(t.Context(), &pb.ExecuteRequest{Name: "test"})
+		assert.EqualValues(t, codes.FailedPrecondition, status.Code(err))
+
+		expected := mustAny(t, wrapperspb.String("secret"))
+		_, err = api.Configure(t.Context(), &pb.ConfigureRequest{Configuration: expected})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, configuration))
+
+		_, err = api.Execute(t.Context(), &pb.ExecuteRequest{Name: "test"})
+		assert.NoError(t, err)
+
+		_, err = api.Configure(t.Context(), &pb.ConfigureRequest{Configuration: expected})
+		assert.EqualValues(t, codes.Unimplemented, status.Code(err))
+	})
+
+	t.Run("configured plugins apply configuration changes", func(t *testing.T) {
+		var changed *anypb.Any
+		api := plugin.NewAPI(plugin.Info{}, handlers,
+			plugin.WithConfigure(func(context.Context, *anypb.Any) error {
+				return nil
+			}),
+			plugin.WithConfigChange(func(_ context.Context, c *anypb.Any) error {
+				changed = c
+				return nil
+			}),
+		)
+
+		_, err := api.Configure(t.Context(), &pb.ConfigureRequest{})
+		require.NoError(t, err)
+		assert.Nil(t, changed)
+
+		expected := mustAny(t, wrapperspb.String("rotated"))
+		_, err = api.Configure(t.Context(), &pb.ConfigureRequest{Configuration: expected})
+		require.NoError(t, err)
+		assert.True(t, proto.Equal(expected, changed))
+	})
+}
+
+func TestAPI_SetSecrets(t *testing.T) {
+	t.Parallel()
+
+	var secrets *plugin.Secrets
+	api := plugin.NewAPI(plugin.Info{}, plugin.CommandHandlers{
+		"test": func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+			secrets = plugin.SecretsFromContext(ctx)
+			return input, nil
+		},
+	})
+
+	stat, err := api.Stat(t.Context(), &pb.StatRequest{})
+	require.NoError(t, err)
+	assert.Len(t, stat.GetSecretKey(), 32)
+
+	_, err = api.SetSecrets(t.Context(), &pb.SetSecretsRequest{
+		Secrets: map[string][]byte{"token": []byte("hunter2")},
+	})
+	require.NoError(t, err)
+
+	_, err = api.Execute(t.Context(), &pb.ExecuteRequest{Name: "test"})
+	require.NoError(t, err)
+
+	secret, ok := secrets.Get("token")
+	require.True(t, ok)
+	assert.EqualValues(t, "hunter2", secret)
+	assert.EqualValues(t, []string{"token"}, secrets.Names())
+
+	t.Run("rejects secrets that cannot be opened", func(t *testing.T) {
+		_, err = api.SetSecrets(t.Context(), &pb.SetSecretsRequest{
+			Secrets:   map[string][]byte{"token": []byte("not sealed")},
+			PublicKey: stat.GetSecretKey(),
+		})
+		assert.EqualValues(t, codes.InvalidArgument, status.Code(err))
+
+		secret, ok = secrets.Get("token")
+		require.True(t, ok)
+		assert.EqualValues(t, "hunter2", secret)
+	})
+}
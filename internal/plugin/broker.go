@@ -0,0 +1,238 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// The Broker type is used by the plugin to call services provided by the host application, via the stream opened
+	// by the host application using the Broker RPC.
+	Broker struct {
+		mu      sync.Mutex
+		stream  grpc.BidiStreamingServer[plugin.HostResult, plugin.HostCall]
+		nextID  uint64
+		pending map[uint64]chan *plugin.HostResult
+	}
+
+	// The HostHandlers type is a map that stores the full names of the methods of services provided by the host
+	// application against the functions that handle calls to them.
+	HostHandlers map[string]func(ctx context.Context, input *anypb.Any) (*anypb.Any, error)
+
+	brokerKey struct{}
+)
+
+var (
+	// ErrNoBroker is the error returned by Broker.Call when the host application has not opened a Broker stream, as it
+	// does not provide any services.
+	ErrNoBroker = errors.New("host provides no services")
+
+	// ErrBrokerUnsupported is the error returned by Client.OpenBroker when the plugin does not support the Broker RPC.
+	ErrBrokerUnsupported = errors.New("broker unsupported")
+)
+
+// Call the named method of a service provided by the host application, returning its output. Returns ErrNoBroker if
+// the host application does not provide any services. Errors returned by the host application are returned as gRPC
+// statuses.
+func (b *Broker) Call(ctx context.Context, method string, input *anypb.Any) (*anypb.Any, error) {
+	if b == nil {
+		return nil, ErrNoBroker
+	}
+
+	b.mu.Lock()
+	if b.stream == nil {
+		b.mu.Unlock()
+		return nil, ErrNoBroker
+	}
+
+	b.nextID++
+	id := b.nextID
+	result := make(chan *plugin.HostResult, 1)
+	b.pending[id] = result
+
+	// Streams do not support concurrent sends, so calls are sent while holding the lock.
+	err := b.stream.Send(&plugin.HostCall{Id: id, Method: method, Input: input})
+	b.mu.Unlock()
+
+	if err != nil {
+		b.forget(id)
+		return nil, fmt.Errorf("%w: %w", ErrNoBroker, err)
+	}
+
+	select {
+	case <-ctx.Done():
+		b.forget(id)
+		return nil, ctx.Err()
+	case r, ok := <-result:
+		if !ok {
+			return nil, fmt.Errorf("%w: stream closed", ErrNoBroker)
+		}
+
+		if code := codes.Code(r.GetCode()); code != codes.OK {
+			return nil, status.Error(code, r.GetMessage())
+		}
+
+		return r.GetOutput(), nil
+	}
+}
+
+func (b *Broker) forget(id uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.pending, id)
+}
+
+// BrokerFromContext returns the Broker used to call services provided by the host application, within the context of
+// the call being handled. Returns nil if the context is not that of a call handled by the API.
+func BrokerFromContext(ctx context.Context) *Broker {
+	broker, _ := ctx.Value(brokerKey{}).(*Broker)
+	return broker
+}
+
+// withBroker returns a copy of the context containing the Broker of the API, as returned by BrokerFromContext.
+func (api *API) withBroker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, brokerKey{}, &api.broker)
+}
+
+// Broker accepts the stream through which the plugin calls services provided by the host application, until the
+// stream ends. Returns codes.AlreadyExists if the host application has already opened a stream. Calls that are
+// waiting for their result when the stream ends fail with ErrNoBroker.
+func (api *API) Broker(stream grpc.BidiStreamingServer[plugin.HostResult, plugin.HostCall]) error {
+	b := &api.broker
+
+	b.mu.Lock()
+	if b.stream != nil {
+		b.mu.Unlock()
+		return status.Error(codes.AlreadyExists, "broker stream already open")
+	}
+
+	b.stream = stream
+	b.pending = make(map[uint64]chan *plugin.HostResult)
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.stream = nil
+		for _, result := range b.pending {
+			close(result)
+		}
+
+		b.pending = nil
+	}()
+
+	// Headers tell the host application that calls can now be made.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		r, err := stream.Recv()
+		if err != nil {
+			// The stream ends when the host application closes its connection, which is not a failure.
+			return nil
+		}
+
+		b.mu.Lock()
+		result, ok := b.pending[r.GetId()]
+		delete(b.pending, r.GetId())
+		b.mu.Unlock()
+
+		if ok {
+			result <- r
+		}
+	}
+}
+
+// OpenBroker opens the stream through which the plugin calls services provided by the host application, returning
+// once the plugin is ready to make calls. Calls are handled using the given handlers until the connection to the
+// plugin is closed, and calls to methods without a handler fail with codes.Unimplemented. Returns
+// ErrBrokerUnsupported if the plugin does not support the Broker RPC.
+func (c *Client) OpenBroker(ctx context.Context, handlers HostHandlers) error {
+	// The stream outlives the context, which only applies until the plugin is ready.
+	streamCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, cancel)
+
+	stream, err := c.inner.Broker(streamCtx)
+	if err != nil {
+		cancel()
+		return err
+	}
+
+	md, err := stream.Header()
+	if err == nil && md == nil {
+		// Streams that end without headers carry their status in place of a message.
+		_, err = stream.Recv()
+	}
+
+	if !stop() {
+		cancel()
+		return ctx.Err()
+	}
+
+	if err != nil {
+		cancel()
+		if status.Code(err) == codes.Unimplemented {
+			return fmt.Errorf("%w: %s", ErrBrokerUnsupported, status.Convert(err).Message())
+		}
+
+		return err
+	}
+
+	go serveBroker(streamCtx, cancel, stream, handlers)
+	return nil
+}
+
+func serveBroker(ctx context.Context, cancel context.CancelFunc, stream grpc.BidiStreamingClient[plugin.HostResult, plugin.HostCall], handlers HostHandlers) {
+	defer cancel()
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	defer wg.Wait()
+
+	for {
+		call, err := stream.Recv()
+		if err != nil {
+			return
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := &plugin.HostResult{Id: call.GetId()}
+
+			handler, ok := handlers[call.GetMethod()]
+			if !ok {
+				result.Code = uint32(codes.Unimplemented)
+				result.Message = fmt.Sprintf("host does not provide %q", call.GetMethod())
+			} else if output, err := handler(ctx, call.GetInput()); err != nil {
+				st := status.Convert(err)
+				result.Code = uint32(st.Code())
+				result.Message = st.Message()
+			} else {
+				result.Output = output
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			_ = stream.Send(result)
+		}()
+	}
+}
@@ -0,0 +1,112 @@
+package plugin_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+func TestClient_OpenBroker(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "plugin.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	api := plugin.NewAPI(plugin.Info{Name: "test-plugin"}, plugin.CommandHandlers{
+		"call": func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+			method := &wrapperspb.StringValue{}
+			if err := input.UnmarshalTo(method); err != nil {
+				return nil, err
+			}
+
+			return plugin.BrokerFromContext(ctx).Call(ctx, method.GetValue(), input)
+		},
+	})
+	api.Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	client, err := plugin.NewClient(socket)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	call := func(t *testing.T, method string) (string, error) {
+		t.Helper()
+
+		output := &wrapperspb.StringValue{}
+		_, err := client.Execute(t.Context(), "call", wrapperspb.String(method), output, plugin.ExecuteOptions{})
+		return output.GetValue(), err
+	}
+
+	t.Run("fails without broker", func(t *testing.T) {
+		_, err := call(t, "/test.Host/Echo")
+		assert.ErrorContains(t, err, plugin.ErrNoBroker.Error())
+	})
+
+	handlers := plugin.HostHandlers{
+		"/test.Host/Echo": func(_ context.Context, input *anypb.Any) (*anypb.Any, error) {
+			return input, nil
+		},
+		"/test.Host/Fail": func(context.Context, *anypb.Any) (*anypb.Any, error) {
+			return nil, status.Error(codes.PermissionDenied, "denied")
+		},
+	}
+
+	require.NoError(t, client.OpenBroker(t.Context(), handlers))
+
+	tt := []struct {
+		Name         string
+		Method       string
+		ExpectedCode codes.Code
+	}{
+		{
+			Name:   "calls host service",
+			Method: "/test.Host/Echo",
+		},
+		{
+			Name:         "returns host errors",
+			Method:       "/test.Host/Fail",
+			ExpectedCode: codes.PermissionDenied,
+		},
+		{
+			Name:         "unknown methods are unimplemented",
+			Method:       "/test.Host/Missing",
+			ExpectedCode: codes.Unimplemented,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			output, err := call(t, tc.Method)
+			if tc.ExpectedCode != codes.OK {
+				assert.EqualValues(t, tc.ExpectedCode, status.Code(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.EqualValues(t, tc.Method, output)
+		})
+	}
+
+	t.Run("only one broker can be opened", func(t *testing.T) {
+		err := client.OpenBroker(t.Context(), handlers)
+		assert.EqualValues(t, codes.AlreadyExists, status.Code(err))
+	})
+}
@@ -0,0 +1,194 @@
+package plugin
+
+import (
+	"cmp"
+	"context"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// The CallStats type describes the calls made to a single command, as returned by Client.CallStats.
+	CallStats struct {
+		// The Name of the command.
+		Name string
+		// The number of times the command has been called.
+		Calls int64
+		// The number of calls that returned an error.
+		Errors int64
+		// The number of calls whose latency fell within each of the LatencyBuckets, with an additional final bucket
+		// counting calls slower than all of them.
+		Latency []int64
+		// The latency of the slowest call.
+		MaxLatency time.Duration
+	}
+
+	// calls records the CallStats of each command executed by the API.
+	calls struct {
+		mu       sync.Mutex
+		commands map[string]*CallStats
+	}
+)
+
+// LatencyBuckets are the upper bounds of the buckets used to record the latency of each call to a command.
+var LatencyBuckets = []time.Duration{
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+func (c *calls) record(name string, latency time.Duration, failed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.commands == nil {
+		c.commands = make(map[string]*CallStats)
+	}
+
+	cs, ok := c.commands[name]
+	if !ok {
+		cs = &CallStats{Name: name, Latency: make([]int64, len(LatencyBuckets)+1)}
+		c.commands[name] = cs
+	}
+
+	cs.Calls++
+	if failed {
+		cs.Errors++
+	}
+
+	bucket, _ := slices.BinarySearch(LatencyBuckets, latency)
+	cs.Latency[bucket]++
+	cs.MaxLatency = max(cs.MaxLatency, latency)
+}
+
+func (c *calls) snapshot() []*plugin.CommandStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	commands := make([]*plugin.CommandStats, 0, len(c.commands))
+	for _, cs := range c.commands {
+		buckets := make([]uint64, len(cs.Latency))
+		for i, count := range cs.Latency {
+			buckets[i] = uint64(count)
+		}
+
+		commands = append(commands, &plugin.CommandStats{
+			Name:           cs.Name,
+			Calls:          uint64(cs.Calls),
+			Errors:         uint64(cs.Errors),
+			LatencyBuckets: buckets,
+			MaxLatency:     durationpb.New(cs.MaxLatency),
+		})
+	}
+
+	slices.SortFunc(commands, func(a, b *plugin.CommandStats) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	})
+
+	return commands
+}
+
+// Stats returns statistics describing the calls made to each command since the API was created, whether via
+// Execute, ExecuteStream or SubmitJob. Commands that have not been called are omitted.
+func (api *API) Stats(_ context.Context, _ *plugin.StatsRequest) (*plugin.StatsResponse, error) {
+	return &plugin.StatsResponse{Commands: api.calls.snapshot()}, nil
+}
+
+// CallStats returns statistics describing the calls made to each command served by the plugin process, ordered by
+// command name. Plugins that predate the Stats RPC return no statistics.
+func (c *Client) CallStats(ctx context.Context) ([]CallStats, error) {
+	response, err := c.inner.Stats(ctx, &plugin.StatsRequest{})
+	if status.Code(err) == codes.Unimplemented {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make([]CallStats, 0, len(response.GetCommands()))
+	for _, command := range response.GetCommands() {
+		latency := make([]int64, len(command.GetLatencyBuckets()))
+		for i, count := range command.GetLatencyBuckets() {
+			latency[i] = int64(count)
+		}
+
+		commands = append(commands, CallStats{
+			Name:       command.GetName(),
+			Calls:      int64(command.GetCalls()),
+			Errors:     int64(command.GetErrors()),
+			Latency:    latency,
+			MaxLatency: command.GetMaxLatency().AsDuration(),
+		})
+	}
+
+	return commands, nil
+}
+
+// Add returns the combination of the CallStats and other, such as those of the same command served by different
+// processes.
+func (cs CallStats) Add(other CallStats) CallStats {
+	latency := make([]int64, max(len(cs.Latency), len(other.Latency)))
+	for i := range latency {
+		if i < len(cs.Latency) {
+			latency[i] += cs.Latency[i]
+		}
+
+		if i < len(other.Latency) {
+			latency[i] += other.Latency[i]
+		}
+	}
+
+	return CallStats{
+		Name:       cmp.Or(cs.Name, other.Name),
+		Calls:      cs.Calls + other.Calls,
+		Errors:     cs.Errors + other.Errors,
+		Latency:    latency,
+		MaxLatency: max(cs.MaxLatency, other.MaxLatency),
+	}
+}
+
+// Percentile returns an estimate of the latency that the given proportion of calls, between zero and one, completed
+// within. The estimate is the upper bound of the bucket containing the percentile, limited to the latency of the
+// slowest call. Returns zero if no calls have been made.
+func (cs CallStats) Percentile(p float64) time.Duration {
+	var total int64
+	for _, count := range cs.Latency {
+		total += count
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	rank := max(int64(math.Ceil(p*float64(total))), 1)
+
+	var seen int64
+	for i, count := range cs.Latency {
+		seen += count
+		if seen >= rank && i < len(LatencyBuckets) {
+			return min(LatencyBuckets[i], cs.MaxLatency)
+		}
+	}
+
+	return cs.MaxLatency
+}
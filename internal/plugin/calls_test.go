@@ -0,0 +1,134 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+func TestAPI_Stats(t *testing.T) {
+	t.Parallel()
+
+	api := plugin.NewAPI(plugin.Info{
+		Commands: []plugin.Command{
+			{Name: "succeed"},
+			{Name: "fail"},
+			{Name: "unused"},
+		},
+	}, plugin.CommandHandlers{
+		"succeed": func(_ context.Context, input *anypb.Any) (*anypb.Any, error) {
+			return input, nil
+		},
+		"fail": func(_ context.Context, _ *anypb.Any) (*anypb.Any, error) {
+			return nil, errors.New("failed")
+		},
+		"unused": func(_ context.Context, input *anypb.Any) (*anypb.Any, error) {
+			return input, nil
+		},
+	})
+	t.Cleanup(api.Close)
+
+	input, err := anypb.New(wrapperspb.String("input"))
+	require.NoError(t, err)
+
+	for range 2 {
+		_, err = api.Execute(t.Context(), &pb.ExecuteRequest{Name: "succeed", Input: input})
+		require.NoError(t, err)
+	}
+
+	_, err = api.Execute(t.Context(), &pb.ExecuteRequest{Name: "fail", Input: input})
+	require.Error(t, err)
+
+	submitted, err := api.SubmitJob(t.Context(), &pb.SubmitJobRequest{Name: "fail", Input: input})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		response, err := api.GetJob(t.Context(), &pb.GetJobRequest{Id: submitted.GetId()})
+		require.NoError(t, err)
+		return response.GetJob().GetState() == pb.JobState_JOB_STATE_FAILED
+	}, time.Second, 10*time.Millisecond)
+
+	response, err := api.Stats(t.Context(), &pb.StatsRequest{})
+	require.NoError(t, err)
+	require.Len(t, response.GetCommands(), 2)
+
+	failed := response.GetCommands()[0]
+	assert.EqualValues(t, "fail", failed.GetName())
+	assert.EqualValues(t, 2, failed.GetCalls())
+	assert.EqualValues(t, 2, failed.GetErrors())
+	assert.Len(t, failed.GetLatencyBuckets(), len(plugin.LatencyBuckets)+1)
+
+	succeeded := response.GetCommands()[1]
+	assert.EqualValues(t, "succeed", succeeded.GetName())
+	assert.EqualValues(t, 2, succeeded.GetCalls())
+	assert.EqualValues(t, 0, succeeded.GetErrors())
+
+	var total uint64
+	for _, count := range succeeded.GetLatencyBuckets() {
+		total += count
+	}
+
+	assert.EqualValues(t, 2, total)
+}
+
+func TestCallStats_Percentile(t *testing.T) {
+	t.Parallel()
+
+	// Ninety calls within 1ms, nine within 100ms and one slower than every bucket.
+	latency := make([]int64, len(plugin.LatencyBuckets)+1)
+	latency[0] = 90
+	latency[6] = 9
+	latency[len(latency)-1] = 1
+
+	cs := plugin.CallStats{Calls: 100, Latency: latency, MaxLatency: 30 * time.Second}
+
+	tt := []struct {
+		Name       string
+		Percentile float64
+		Expected   time.Duration
+	}{
+		{Name: "median", Percentile: 0.5, Expected: time.Millisecond},
+		{Name: "ninetieth", Percentile: 0.9, Expected: time.Millisecond},
+		{Name: "ninety fifth", Percentile: 0.95, Expected: 100 * time.Millisecond},
+		{Name: "maximum", Percentile: 1, Expected: 30 * time.Second},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.EqualValues(t, tc.Expected, cs.Percentile(tc.Percentile))
+		})
+	}
+
+	t.Run("limited to the slowest call", func(t *testing.T) {
+		fast := plugin.CallStats{Calls: 1, Latency: []int64{1}, MaxLatency: 50 * time.Microsecond}
+		assert.EqualValues(t, 50*time.Microsecond, fast.Percentile(0.5))
+	})
+
+	t.Run("no calls", func(t *testing.T) {
+		assert.Zero(t, plugin.CallStats{}.Percentile(0.5))
+	})
+}
+
+func TestCallStats_Add(t *testing.T) {
+	t.Parallel()
+
+	a := plugin.CallStats{Name: "command", Calls: 2, Errors: 1, Latency: []int64{1, 1}, MaxLatency: 2 * time.Millisecond}
+	b := plugin.CallStats{Name: "command", Calls: 3, Latency: []int64{3, 0, 0}, MaxLatency: time.Millisecond}
+
+	assert.EqualValues(t, plugin.CallStats{
+		Name:       "command",
+		Calls:      5,
+		Errors:     1,
+		Latency:    []int64{4, 1, 0},
+		MaxLatency: 2 * time.Millisecond,
+	}, a.Add(b))
+}
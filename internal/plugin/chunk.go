@@ -0,0 +1,154 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+// DefaultChunkSize is the size, in bytes, of the chunks of a response sent by ExecuteStream when the request does not
+// specify a chunk size.
+const DefaultChunkSize = 1 << 20
+
+// ExecuteStream executes a command whose input and output are split into chunks, allowing them to exceed the maximum
+// message size. The chunks of the input are reassembled before the command is executed as it would be by Execute, so
+// command handlers are unaware of chunking. The output is then sent in chunks no larger than the size requested in the
+// first chunk of the input.
+func (api *API) ExecuteStream(stream grpc.BidiStreamingServer[plugin.ExecuteChunk, plugin.ExecuteChunk]) error {
+	request := &plugin.ExecuteRequest{}
+	chunkSize := DefaultChunkSize
+
+	var data []byte
+	for first := true; ; first = false {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if first {
+			request.Name = chunk.GetName()
+			request.RequestId = chunk.GetRequestId()
+			if size := chunk.GetChunkSize(); size > 0 {
+				chunkSize = int(size)
+			}
+		}
+
+		data = append(data, chunk.GetData()...)
+	}
+
+	request.Input = &anypb.Any{}
+	if err := proto.Unmarshal(data, request.Input); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid input: %v", err)
+	}
+
+	response, err := api.Execute(stream.Context(), request)
+	if err != nil {
+		return err
+	}
+
+	output, err := proto.Marshal(response.GetOutput())
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	parts := split(output, chunkSize)
+	for i, part := range parts {
+		chunk := &plugin.ExecuteChunk{Data: part}
+		if i == len(parts)-1 {
+			chunk.Warnings = response.GetWarnings()
+			chunk.Duration = response.GetDuration()
+		}
+
+		if err = stream.Send(chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// executeStream executes the command described by the request using the ExecuteStream RPC, recording the size of the
+// encoded output and the details reported by the plugin within the payload. If the plugin does not implement
+// ExecuteStream, the request is sent using Execute instead.
+func (c *Client) executeStream(ctx context.Context, request *plugin.ExecuteRequest, output proto.Message, options ExecuteOptions, payload *Payload) error {
+	input, err := proto.Marshal(request.GetInput())
+	if err != nil {
+		return err
+	}
+
+	stream, err := c.inner.ExecuteStream(ctx, options.CallOptions...)
+	if err != nil {
+		return err
+	}
+
+	for i, part := range split(input, options.ChunkSize) {
+		chunk := &plugin.ExecuteChunk{Data: part}
+		if i == 0 {
+			chunk.Name = request.GetName()
+			chunk.RequestId = request.GetRequestId()
+			chunk.ChunkSize = int64(options.ChunkSize)
+		}
+
+		// Send returns io.EOF once the plugin has ended the stream, in which case the reason is given by Recv.
+		if err = stream.Send(chunk); errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = stream.CloseSend(); err != nil {
+		return err
+	}
+
+	var data []byte
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if status.Code(err) == codes.Unimplemented {
+			return c.executeUnary(ctx, request, output, options, payload)
+		}
+		if err != nil {
+			return err
+		}
+
+		data = append(data, chunk.GetData()...)
+		payload.Warnings = append(payload.Warnings, chunk.GetWarnings()...)
+		if chunk.GetDuration() != nil {
+			payload.Duration = chunk.GetDuration().AsDuration()
+		}
+	}
+
+	out := &anypb.Any{}
+	if err = proto.Unmarshal(data, out); err != nil {
+		return err
+	}
+
+	payload.ResponseSize = len(data)
+	return Decode(options.Codec, out, output)
+}
+
+// split divides the data into chunks of at most size bytes. A single chunk is always returned for empty data, so
+// that it is still sent.
+func split(data []byte, size int) [][]byte {
+	if len(data) == 0 || size <= 0 {
+		return [][]byte{data}
+	}
+
+	return slices.Collect(slices.Chunk(data, size))
+}
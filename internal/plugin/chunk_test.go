@@ -0,0 +1,112 @@
+package plugin_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// unaryOnly is a plugin API that does not implement the ExecuteStream RPC, like those of older plugins.
+	unaryOnly struct {
+		*plugin.API
+	}
+)
+
+func (unaryOnly) ExecuteStream(grpc.BidiStreamingServer[pb.ExecuteChunk, pb.ExecuteChunk]) error {
+	return status.Error(codes.Unimplemented, "method ExecuteStream not implemented")
+}
+
+func TestClient_Execute_Chunked(t *testing.T) {
+	t.Parallel()
+
+	const maxMessageSize = 64 << 10
+
+	api := plugin.NewAPI(plugin.Info{Name: "test-plugin"}, plugin.CommandHandlers{
+		"echo": func(_ context.Context, input *anypb.Any) (*anypb.Any, error) {
+			return input, nil
+		},
+	})
+
+	tt := []struct {
+		Name     string
+		Register func(s grpc.ServiceRegistrar)
+		Command  string
+		Input    *wrapperspb.StringValue
+		Expected codes.Code
+	}{
+		{
+			Name:     "exchanges messages larger than the maximum message size",
+			Register: api.Register,
+			Command:  "echo",
+			Input:    wrapperspb.String(strings.Repeat("a", 4*maxMessageSize)),
+		},
+		{
+			Name:     "exchanges empty messages",
+			Register: api.Register,
+			Command:  "echo",
+			Input:    wrapperspb.String(""),
+		},
+		{
+			Name:     "returns command errors",
+			Register: api.Register,
+			Command:  "unknown",
+			Input:    wrapperspb.String("a"),
+			Expected: codes.NotFound,
+		},
+		{
+			Name: "falls back to unary requests",
+			Register: func(s grpc.ServiceRegistrar) {
+				pb.RegisterPluginServiceServer(s, unaryOnly{API: api})
+			},
+			Command: "echo",
+			Input:   wrapperspb.String("a"),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			socket := filepath.Join(t.TempDir(), "plugin.sock")
+			listener, err := net.Listen("unix", socket)
+			require.NoError(t, err)
+
+			server := grpc.NewServer(grpc.MaxRecvMsgSize(maxMessageSize), grpc.MaxSendMsgSize(maxMessageSize))
+			tc.Register(server)
+
+			go server.Serve(listener)
+			t.Cleanup(server.Stop)
+
+			client, err := plugin.NewClient(socket, plugin.WithMaxMessageSize(maxMessageSize))
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, client.Close())
+			})
+
+			output := &wrapperspb.StringValue{}
+			payload, err := client.Execute(t.Context(), tc.Command, tc.Input, output, plugin.ExecuteOptions{ChunkSize: 1 << 10})
+			if tc.Expected != codes.OK {
+				assert.EqualValues(t, tc.Expected, status.Code(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.Input.GetValue(), output.GetValue())
+			assert.Positive(t, payload.ResponseSize)
+		})
+	}
+}
@@ -2,9 +2,17 @@ package plugin
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
 
+	spb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
@@ -16,14 +24,115 @@ type (
 	Client struct {
 		conn  *grpc.ClientConn
 		inner plugin.PluginServiceClient
+		host  Host
 	}
+
+	// The ClientOption type is a function that modifies the behaviour of the Client.
+	ClientOption func(o *clientOptions)
+
+	clientOptions struct {
+		dialOptions []grpc.DialOption
+		scheme      string
+		host        Host
+	}
+
+	// The ExecuteOptions type contains fields that modify the behaviour of a single call to Client.Execute.
+	ExecuteOptions struct {
+		// The MaxRequestSize is the maximum size, in bytes, of the encoded request. If greater than zero, requests
+		// that exceed this size are not sent to the plugin and ErrRequestTooLarge is returned.
+		MaxRequestSize int
+		// The RequestID identifies the request so that it can be cancelled using Client.Cancel. When the context of
+		// a request with an identifier is cancelled, the plugin is also sent a Cancel request.
+		RequestID string
+		// Any CallOptions to apply to the request.
+		CallOptions []grpc.CallOption
+		// The ChunkSize, if greater than zero, executes the command using the ExecuteStream RPC, splitting its input
+		// and output into chunks of at most this many bytes. This allows them to exceed the maximum message size.
+		ChunkSize int
+		// The Codec used to encode the input and decode the output of the command. The plugin must support it, as
+		// advertised by Command.Codecs. When nil, the ProtoCodec is used.
+		Codec Codec
+	}
+
+	// The Payload type describes the encoded sizes of a request sent to a plugin and the response it returned, along
+	// with any details the plugin reported about the execution.
+	Payload struct {
+		// The size of the request in bytes.
+		RequestSize int
+		// The size of the response in bytes.
+		ResponseSize int
+		// Any Warnings reported by the command alongside its output.
+		Warnings []string
+		// The Duration the plugin spent executing the command, as measured by the plugin.
+		Duration time.Duration
+	}
+
+	// The JobState type describes the lifecycle of a job.
+	JobState int
+
+	// The JobStatus type describes the current state of a job, as returned by Client.GetJob.
+	JobStatus struct {
+		// The unique identifier of the job.
+		ID string
+		// The name of the command being executed.
+		Name string
+		// The current state of the job.
+		State JobState
+		// The progress reported by the command, between zero and one.
+		Progress float64
+		// The most recent message reported by the command.
+		Message string
+		// The command output, set once the job has succeeded.
+		Output *anypb.Any
+		// The status error returned by the command, set once the job has failed or been cancelled.
+		Err error
+		// When the job was submitted.
+		CreatedAt time.Time
+		// When the job reached a terminal state. This is the zero time while the job is running.
+		CompletedAt time.Time
+	}
+)
+
+var (
+	// ErrRequestTooLarge is the error returned by Client.Execute when the encoded request is larger than the
+	// configured maximum request size.
+	ErrRequestTooLarge = errors.New("request too large")
+)
+
+const (
+	// JobRunning is the JobState of a job whose command is executing.
+	JobRunning JobState = iota + 1
+	// JobSucceeded is the JobState of a job whose command completed successfully.
+	JobSucceeded
+	// JobFailed is the JobState of a job whose command returned an error.
+	JobFailed
+	// JobCancelled is the JobState of a job that was cancelled before its command completed.
+	JobCancelled
 )
 
-// NewClient attempts to create a new connection to the plugin using the provided UNIX domain socket.
-func NewClient(socket string) (*Client, error) {
-	conn, err := grpc.NewClient("unix:///tmp/"+socket+".sock",
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+// cancelTimeout is the maximum time spent notifying a plugin that a request has been cancelled.
+const cancelTimeout = time.Second
+
+// DefaultMaxMessageSize is the default maximum size, in bytes, of messages sent and received by plugins and host
+// applications. It is larger than the gRPC default of 4MB so that commands can exchange large payloads.
+const DefaultMaxMessageSize = 64 << 20
+
+// NewClient attempts to create a new connection to the plugin using the UNIX domain socket at the provided path.
+func NewClient(socket string, options ...ClientOption) (*Client, error) {
+	o := clientOptions{
+		dialOptions: []grpc.DialOption{
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+		},
+		scheme: "unix",
+	}
+
+	o.dialOptions = append(o.dialOptions, marshalDialOptions()...)
+
+	for _, option := range options {
+		option(&o)
+	}
+
+	conn, err := grpc.NewClient(o.scheme+"://"+socket, o.dialOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -31,9 +140,52 @@ func NewClient(socket string) (*Client, error) {
 	return &Client{
 		conn:  conn,
 		inner: plugin.NewPluginServiceClient(conn),
+		host:  o.host,
 	}, nil
 }
 
+// WithDialer is a ClientOption that sets the function used to connect to the plugin, rather than dialing its UNIX
+// domain socket. The socket given to NewClient is passed to the dialer as the address. This allows the Client to
+// communicate with plugins served in-process.
+func WithDialer(dialer func(ctx context.Context, address string) (net.Conn, error)) ClientOption {
+	return func(o *clientOptions) {
+		o.scheme = "passthrough"
+		o.dialOptions = append(o.dialOptions, grpc.WithContextDialer(dialer))
+	}
+}
+
+// WithDialOptions is a ClientOption that applies the provided grpc.DialOption values to the connection, after any
+// set by other options.
+func WithDialOptions(opts ...grpc.DialOption) ClientOption {
+	return func(o *clientOptions) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// WithMaxMessageSize is a ClientOption that sets the maximum size, in bytes, of messages sent to and received from the
+// plugin.
+func WithMaxMessageSize(size int) ClientOption {
+	return func(o *clientOptions) {
+		o.dialOptions = append(o.dialOptions, grpc.WithDefaultCallOptions(
+			grpc.MaxCallRecvMsgSize(size),
+			grpc.MaxCallSendMsgSize(size),
+		))
+	}
+}
+
+// WithCompressor is a ClientOption that compresses all requests sent to the plugin using the named compressor, which
+// must be registered using encoding.RegisterCompressor. The plugin compresses its responses in the same way.
+func WithCompressor(name string) ClientOption {
+	return func(o *clientOptions) {
+		o.dialOptions = append(o.dialOptions, grpc.WithDefaultCallOptions(grpc.UseCompressor(name)))
+	}
+}
+
+// Conn returns the underlying connection to the plugin, which can be used to call any other gRPC services it serves.
+func (c *Client) Conn() grpc.ClientConnInterface {
+	return c.conn
+}
+
 // Close the connection to the plugin.
 func (c *Client) Close() error {
 	return c.conn.Close()
@@ -41,39 +193,240 @@ func (c *Client) Close() error {
 
 // Stat returns plugin metadata, such as its name, version and commands it supports.
 func (c *Client) Stat(ctx context.Context) (Info, error) {
-	response, err := c.inner.Stat(ctx, &plugin.StatRequest{})
+	response, err := c.inner.Stat(ctx, c.statRequest())
 	if err != nil {
-		return Info{}, err
+		return Info{}, incompatibleHost(err)
+	}
+
+	info := Info{
+		Name:         response.GetName(),
+		Version:      response.GetVersion(),
+		Sandboxed:    response.GetSandboxed(),
+		Configurable: response.GetConfigurable(),
+		SecretKey:    response.GetSecretKey(),
 	}
 
-	return Info{
-		Name:     response.GetName(),
-		Version:  response.GetVersion(),
-		Commands: response.GetCommands(),
+	// Plugins that predate command descriptions only provide command names.
+	if len(response.GetCommandInfo()) == 0 {
+		for _, name := range response.GetCommands() {
+			info.Commands = append(info.Commands, Command{Name: name})
+		}
+
+		return info, nil
+	}
+
+	for _, command := range response.GetCommandInfo() {
+		info.Commands = append(info.Commands, Command{
+			Name:        command.GetName(),
+			Description: command.GetDescription(),
+			Example:     command.GetExample(),
+			Deprecated:  command.GetDeprecated(),
+			Idempotent:  command.GetIdempotent(),
+			Codecs:      command.GetCodecs(),
+		})
+	}
+
+	return info, nil
+}
+
+func (c *Client) statRequest() *plugin.StatRequest {
+	return &plugin.StatRequest{
+		HostName:    c.host.Name,
+		HostVersion: c.host.Version,
+	}
+}
+
+// Usage returns the resources currently used by the plugin process.
+func (c *Client) Usage(ctx context.Context) (ResourceUsage, error) {
+	response, err := c.inner.Stat(ctx, c.statRequest())
+	if err != nil {
+		return ResourceUsage{}, incompatibleHost(err)
+	}
+
+	usage := response.GetUsage()
+	return ResourceUsage{
+		HeapBytes:     usage.GetHeapBytes(),
+		SysBytes:      usage.GetSysBytes(),
+		Goroutines:    int(usage.GetGoroutines()),
+		UserCPUTime:   usage.GetUserCpuTime().AsDuration(),
+		SystemCPUTime: usage.GetSystemCpuTime().AsDuration(),
 	}, nil
 }
 
+// Process returns details of the process serving the plugin. Plugins that predate these details return an empty
+// ProcessDetails.
+func (c *Client) Process(ctx context.Context) (ProcessDetails, error) {
+	response, err := c.inner.Stat(ctx, c.statRequest())
+	if err != nil {
+		return ProcessDetails{}, incompatibleHost(err)
+	}
+
+	process := response.GetProcess()
+	if process == nil {
+		return ProcessDetails{}, nil
+	}
+
+	details := ProcessDetails{
+		PID:             int(process.GetPid()),
+		StartedAt:       process.GetStartedAt().AsTime(),
+		Uptime:          process.GetUptime().AsDuration(),
+		OS:              process.GetOs(),
+		Arch:            process.GetArch(),
+		RuntimeVersion:  process.GetRuntimeVersion(),
+		ProtocolVersion: int(process.GetProtocolVersion()),
+		VCSRevision:     process.GetVcsRevision(),
+		VCSModified:     process.GetVcsModified(),
+	}
+
+	if process.GetVcsTime() != nil {
+		details.VCSTime = process.GetVcsTime().AsTime()
+	}
+
+	return details, nil
+}
+
 // Execute a named command with the provided input. The command output will be unmarshalled into the provided output
-// type.
-func (c *Client) Execute(ctx context.Context, name string, input proto.Message, output proto.Message) error {
-	i, err := anypb.New(input)
+// type. The returned Payload describes the size of the request sent and, if successful, the response received.
+func (c *Client) Execute(ctx context.Context, name string, input proto.Message, output proto.Message, options ExecuteOptions) (Payload, error) {
+	var payload Payload
+
+	i, err := Encode(options.Codec, input)
 	if err != nil {
-		return err
+		return payload, err
+	}
+
+	if options.Codec != nil {
+		ctx = metadata.AppendToOutgoingContext(ctx, CodecMetadataKey, options.Codec.Name())
 	}
 
 	request := &plugin.ExecuteRequest{
+		Name:      name,
+		Input:     i,
+		RequestId: options.RequestID,
+	}
+
+	payload.RequestSize = proto.Size(request)
+	if options.MaxRequestSize > 0 && payload.RequestSize > options.MaxRequestSize {
+		return payload, fmt.Errorf("%w: request for command %q is %d bytes, limit is %d bytes", ErrRequestTooLarge, name, payload.RequestSize, options.MaxRequestSize)
+	}
+
+	execute := c.executeUnary
+	if options.ChunkSize > 0 {
+		execute = c.executeStream
+	}
+
+	err = execute(ctx, request, output, options, &payload)
+	if err != nil && ctx.Err() != nil && options.RequestID != "" {
+		go c.notifyCancelled(options.RequestID)
+	}
+
+	return payload, err
+}
+
+// executeUnary executes the command described by the request using the Execute RPC, recording the size of the response
+// and the details reported by the plugin within the payload.
+func (c *Client) executeUnary(ctx context.Context, request *plugin.ExecuteRequest, output proto.Message, options ExecuteOptions, payload *Payload) error {
+	response, err := c.inner.Execute(ctx, request, options.CallOptions...)
+	if err != nil {
+		return err
+	}
+
+	// Only the output is measured, as the details reported alongside it vary in size between calls.
+	payload.ResponseSize = proto.Size(response.GetOutput())
+	payload.Warnings = response.GetWarnings()
+	payload.Duration = response.GetDuration().AsDuration()
+	return Decode(options.Codec, response.GetOutput(), output)
+}
+
+// SubmitJob begins executing the named command as a job, returning its unique identifier.
+func (c *Client) SubmitJob(ctx context.Context, name string, input proto.Message) (string, error) {
+	i, err := anypb.New(input)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := c.inner.SubmitJob(ctx, &plugin.SubmitJobRequest{
 		Name:  name,
 		Input: i,
+	})
+	if err != nil {
+		return "", err
 	}
 
-	response, err := c.inner.Execute(ctx, request)
+	return response.GetId(), nil
+}
+
+// GetJob returns the current state of a job.
+func (c *Client) GetJob(ctx context.Context, id string) (JobStatus, error) {
+	response, err := c.inner.GetJob(ctx, &plugin.GetJobRequest{Id: id})
 	if err != nil {
-		return err
+		return JobStatus{}, err
+	}
+
+	j := response.GetJob()
+	js := JobStatus{
+		ID:        j.GetId(),
+		Name:      j.GetName(),
+		State:     JobState(j.GetState()),
+		Progress:  j.GetProgress(),
+		Message:   j.GetMessage(),
+		Output:    j.GetOutput(),
+		CreatedAt: j.GetCreatedAt().AsTime(),
 	}
 
-	if err = response.GetOutput().UnmarshalTo(output); err != nil {
+	if j.GetCompletedAt() != nil {
+		js.CompletedAt = j.GetCompletedAt().AsTime()
+	}
+
+	if e := j.GetError(); e != nil {
+		js.Err = status.ErrorProto(&spb.Status{
+			Code:    e.GetCode(),
+			Message: e.GetMessage(),
+			Details: e.GetDetails(),
+		})
+	}
+
+	return js, nil
+}
+
+// CancelJob cancels a running job.
+func (c *Client) CancelJob(ctx context.Context, id string) error {
+	_, err := c.inner.CancelJob(ctx, &plugin.CancelJobRequest{Id: id})
+	return err
+}
+
+// Cancel an in-flight request by its identifier, as given by ExecuteOptions.RequestID.
+func (c *Client) Cancel(ctx context.Context, id string) error {
+	_, err := c.inner.Cancel(ctx, &plugin.CancelRequest{RequestId: id})
+	return err
+}
+
+// notifyCancelled sends a Cancel request for a request whose context has been cancelled. Cancellation is usually
+// propagated by gRPC itself, so any error is ignored.
+func (c *Client) notifyCancelled(id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+	defer cancel()
+
+	_ = c.Cancel(ctx, id)
+}
+
+// Subscribe to events published by the plugin, invoking the provided function for each. Blocks until the context is
+// cancelled or the stream ends, such as when the plugin exits or is closed.
+func (c *Client) Subscribe(ctx context.Context, fn func(event *anypb.Any, at time.Time)) error {
+	stream, err := c.inner.Subscribe(ctx, &plugin.SubscribeRequest{})
+	if err != nil {
 		return err
 	}
 
-	return nil
+	for {
+		response, err := stream.Recv()
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return err
+		}
+
+		fn(response.GetEvent(), response.GetTime().AsTime())
+	}
 }
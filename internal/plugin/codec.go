@@ -0,0 +1,198 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type (
+	// The Codec interface describes types that encode the input and output of commands within the value of the
+	// protobuf Any type, allowing encodings other than the protobuf binary format to be used.
+	Codec interface {
+		// Name returns the name of the codec, used to negotiate it between host applications and plugins.
+		Name() string
+		// Marshal encodes the message.
+		Marshal(message proto.Message) ([]byte, error)
+		// Unmarshal decodes the data into the message.
+		Unmarshal(data []byte, message proto.Message) error
+	}
+
+	// The ProtoCodec type is a Codec that uses the protobuf binary format. It is used when no other codec is
+	// negotiated.
+	ProtoCodec struct{}
+
+	// The JSONCodec type is a Codec that uses the protobuf JSON format.
+	JSONCodec struct{}
+
+	codecKey struct{}
+)
+
+// CodecMetadataKey is the gRPC metadata key containing the name of the Codec used to encode the input and output of a
+// call to Execute. When absent, the ProtoCodec is used.
+const CodecMetadataKey = "plugin-codec"
+
+// Name returns "proto".
+func (ProtoCodec) Name() string {
+	return "proto"
+}
+
+// Marshal encodes the message using the protobuf binary format.
+func (ProtoCodec) Marshal(message proto.Message) ([]byte, error) {
+	return proto.Marshal(message)
+}
+
+// Unmarshal decodes the protobuf binary data into the message.
+func (ProtoCodec) Unmarshal(data []byte, message proto.Message) error {
+	return proto.Unmarshal(data, message)
+}
+
+// Name returns "json".
+func (JSONCodec) Name() string {
+	return "json"
+}
+
+// Marshal encodes the message using the protobuf JSON format.
+func (JSONCodec) Marshal(message proto.Message) ([]byte, error) {
+	return protojson.Marshal(message)
+}
+
+// Unmarshal decodes the protobuf JSON data into the message.
+func (JSONCodec) Unmarshal(data []byte, message proto.Message) error {
+	return protojson.Unmarshal(data, message)
+}
+
+// WithCodecs is an APIOption that allows calls to Execute to use the provided codecs, in addition to the ProtoCodec
+// and JSONCodec. The codecs are advertised to host applications in the order given, after the default codecs.
+func WithCodecs(codecs ...Codec) APIOption {
+	return func(api *API) {
+		for _, codec := range codecs {
+			if _, ok := api.codecs[codec.Name()]; !ok {
+				api.codecNames = append(api.codecNames, codec.Name())
+			}
+
+			api.codecs[codec.Name()] = codec
+		}
+	}
+}
+
+// CodecFromContext returns the Codec used to encode the input and output of the call being handled. Handlers that
+// decode their input and encode their output using it, via Decode and Encode, avoid transcoding messages between
+// codecs. Returns the ProtoCodec if no other codec was negotiated.
+func CodecFromContext(ctx context.Context) Codec {
+	codec, ok := ctx.Value(codecKey{}).(Codec)
+	if !ok {
+		return ProtoCodec{}
+	}
+
+	return codec
+}
+
+// Transcode returns a handler that is always given its input and returns its output in the protobuf binary format,
+// regardless of the Codec used by the call. This allows handlers that do not use CodecFromContext to support every
+// Codec, at the cost of decoding and encoding each message twice.
+func Transcode(handler func(ctx context.Context, input *anypb.Any) (*anypb.Any, error)) func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+	return func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+		codec := CodecFromContext(ctx)
+		if _, ok := codec.(ProtoCodec); ok {
+			return handler(ctx, input)
+		}
+
+		input, err := transcode(input, codec, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := handler(context.WithValue(ctx, codecKey{}, ProtoCodec{}), input)
+		if err != nil {
+			return nil, err
+		}
+
+		if output, err = transcode(output, nil, codec); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		return output, nil
+	}
+}
+
+// Encode packs the message into the Any type using the codec, marshalling it exactly once. The ProtoCodec is used if
+// the codec is nil.
+func Encode(codec Codec, message proto.Message) (*anypb.Any, error) {
+	if codec == nil {
+		codec = ProtoCodec{}
+	}
+
+	value, err := codec.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+
+	return &anypb.Any{
+		TypeUrl: "type.googleapis.com/" + string(message.ProtoReflect().Descriptor().FullName()),
+		Value:   value,
+	}, nil
+}
+
+// Decode unpacks the Any type into the message using the codec, without resolving its type from the registry.
+// Returns an error if the Any type does not contain a message of the same type. The ProtoCodec is used if the codec is
+// nil.
+func Decode(codec Codec, a *anypb.Any, message proto.Message) error {
+	if !a.MessageIs(message) {
+		return fmt.Errorf("mismatched message type: got %q, want %q", a.GetTypeUrl(), message.ProtoReflect().Descriptor().FullName())
+	}
+
+	if codec == nil {
+		codec = ProtoCodec{}
+	}
+
+	return codec.Unmarshal(a.GetValue(), message)
+}
+
+// withCodec returns a copy of the context containing the codec, as returned by CodecFromContext.
+func withCodec(ctx context.Context, codec Codec) context.Context {
+	if codec == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, codecKey{}, codec)
+}
+
+// codec returns the Codec named within the metadata of the incoming call, or nil if the ProtoCodec is used.
+func (api *API) codec(ctx context.Context) (Codec, error) {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	names := incoming.Get(CodecMetadataKey)
+	if len(names) == 0 || names[0] == (ProtoCodec{}).Name() {
+		return nil, nil
+	}
+
+	codec, ok := api.codecs[names[0]]
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported codec %q", names[0])
+	}
+
+	return codec, nil
+}
+
+// transcode re-encodes the Any type from one codec to another, resolving its message type from the registry. The
+// message type must be linked into the plugin.
+func transcode(a *anypb.Any, from, to Codec) (*anypb.Any, error) {
+	messageType, err := protoregistry.GlobalTypes.FindMessageByURL(a.GetTypeUrl())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot decode message %q: %v", a.GetTypeUrl(), err)
+	}
+
+	message := messageType.New().Interface()
+	if err = Decode(from, a, message); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "cannot decode message %q: %v", a.GetTypeUrl(), err)
+	}
+
+	return Encode(to, message)
+}
@@ -0,0 +1,166 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// textCodec encodes wrapperspb.StringValue messages as their value.
+	textCodec struct{}
+
+	// unsupportedCodec is a Codec that is not supported by the plugin.
+	unsupportedCodec struct {
+		plugin.JSONCodec
+	}
+)
+
+func (unsupportedCodec) Name() string {
+	return "unsupported"
+}
+
+func (textCodec) Name() string {
+	return "text"
+}
+
+func (textCodec) Marshal(message proto.Message) ([]byte, error) {
+	value, ok := message.(*wrapperspb.StringValue)
+	if !ok {
+		return nil, errors.New("not a string")
+	}
+
+	return []byte(value.GetValue()), nil
+}
+
+func (textCodec) Unmarshal(data []byte, message proto.Message) error {
+	value, ok := message.(*wrapperspb.StringValue)
+	if !ok {
+		return errors.New("not a string")
+	}
+
+	value.Value = string(data)
+	return nil
+}
+
+func TestClient_Execute_Codec(t *testing.T) {
+	t.Parallel()
+
+	api := plugin.NewAPI(plugin.Info{
+		Name:     "test-plugin",
+		Commands: []plugin.Command{{Name: "upper"}},
+	}, plugin.CommandHandlers{
+		"upper": plugin.Transcode(func(_ context.Context, input *anypb.Any) (*anypb.Any, error) {
+			value := &wrapperspb.StringValue{}
+			if err := input.UnmarshalTo(value); err != nil {
+				return nil, err
+			}
+
+			return anypb.New(wrapperspb.String(strings.ToUpper(value.GetValue())))
+		}),
+		"shout": func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+			// The text codec cannot be decoded with the protobuf binary format, so this only succeeds if the handler
+			// is given the input exactly as encoded by the host.
+			codec := plugin.CodecFromContext(ctx)
+
+			value := &wrapperspb.StringValue{}
+			if err := plugin.Decode(codec, input, value); err != nil {
+				return nil, err
+			}
+
+			return plugin.Encode(codec, wrapperspb.String(strings.ToUpper(value.GetValue())+"!"))
+		},
+	}, plugin.WithCodecs(textCodec{}))
+
+	socket := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	api.Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	client, err := plugin.NewClient(socket)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	t.Run("advertises supported codecs", func(t *testing.T) {
+		info, err := client.Stat(t.Context())
+		require.NoError(t, err)
+		require.Len(t, info.Commands, 1)
+		assert.Equal(t, []string{"proto", "json", "text"}, info.Commands[0].Codecs)
+	})
+
+	t.Run("handlers decode input using the negotiated codec", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		_, err := client.Execute(t.Context(), "shout", wrapperspb.String("hello"), output, plugin.ExecuteOptions{
+			Codec: textCodec{},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "HELLO!", output.GetValue())
+	})
+
+	tt := []struct {
+		Name      string
+		Codec     plugin.Codec
+		ChunkSize int
+		Expected  codes.Code
+	}{
+		{
+			Name: "uses the proto codec by default",
+		},
+		{
+			Name:  "uses the json codec",
+			Codec: plugin.JSONCodec{},
+		},
+		{
+			Name:  "uses additional codecs",
+			Codec: textCodec{},
+		},
+		{
+			Name:      "uses codecs with chunked requests",
+			Codec:     plugin.JSONCodec{},
+			ChunkSize: 4,
+		},
+		{
+			Name:     "error for unsupported codecs",
+			Codec:    unsupportedCodec{},
+			Expected: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			output := &wrapperspb.StringValue{}
+			_, err := client.Execute(t.Context(), "upper", wrapperspb.String("hello"), output, plugin.ExecuteOptions{
+				Codec:     tc.Codec,
+				ChunkSize: tc.ChunkSize,
+			})
+			if tc.Expected != codes.OK {
+				assert.EqualValues(t, tc.Expected, status.Code(err))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, "HELLO", output.GetValue())
+		})
+	}
+}
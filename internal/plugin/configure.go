@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// The ConfigureFunc type is a function that applies the configuration provided by the host application. The
+	// configuration is nil if the host application does not provide any.
+	ConfigureFunc func(ctx context.Context, configuration *anypb.Any) error
+)
+
+// ErrConfigurationUnsupported is the error returned by Client.Configure when the plugin does not accept configuration,
+// or does not accept changes to its configuration once configured.
+var ErrConfigurationUnsupported = errors.New("configuration unsupported")
+
+// WithConfigure is an APIOption that sets the function used to apply configuration provided via Configure. Commands
+// cannot be executed until it has returned successfully.
+func WithConfigure(configure ConfigureFunc) APIOption {
+	return func(api *API) {
+		api.configure = configure
+	}
+}
+
+// WithConfigChange is an APIOption that sets the function used to apply configuration provided via Configure once the
+// plugin has already been configured, allowing host applications to change the configuration of a running plugin.
+func WithConfigChange(onConfigChange ConfigureFunc) APIOption {
+	return func(api *API) {
+		api.onConfigChange = onConfigChange
+	}
+}
+
+// Configure applies the configuration within the request using the function given via WithConfigure, or via
+// WithConfigChange once the plugin has been configured. Calls are applied one at a time, and the configuration is in
+// use once Configure returns. Returns codes.Unimplemented if the plugin does not accept the configuration and
+// codes.InvalidArgument if the function returns an error, unless the error carries a gRPC status, in which case it is
+// returned as-is.
+func (api *API) Configure(ctx context.Context, request *plugin.ConfigureRequest) (*plugin.ConfigureResponse, error) {
+	api.configureMu.Lock()
+	defer api.configureMu.Unlock()
+
+	configure := api.configure
+	if api.configured.Load() {
+		if api.onConfigChange == nil {
+			return nil, status.Error(codes.Unimplemented, "plugin does not accept configuration changes")
+		}
+
+		configure = api.onConfigChange
+	}
+
+	if configure == nil {
+		return nil, status.Error(codes.Unimplemented, "plugin does not accept configuration")
+	}
+
+	if err := configure(withMetadata(api.withState(ctx), ctx), request.GetConfiguration()); err != nil {
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+
+		return nil, status.Errorf(codes.InvalidArgument, "invalid configuration: %v", err)
+	}
+
+	api.configured.Store(true)
+	return &plugin.ConfigureResponse{}, nil
+}
+
+// checkConfigured returns an error if the plugin accepts configuration but has not yet been configured.
+func (api *API) checkConfigured() error {
+	if api.configure == nil || api.configured.Load() {
+		return nil
+	}
+
+	return status.Error(codes.FailedPrecondition, "plugin has not been configured")
+}
+
+// Configure the plugin using the provided configuration, which may be nil. Returns ErrConfigurationUnsupported if the
+// plugin does not accept configuration.
+func (c *Client) Configure(ctx context.Context, configuration *anypb.Any) error {
+	_, err := c.inner.Configure(ctx, &plugin.ConfigureRequest{Configuration: configuration})
+	if status.Code(err) == codes.Unimplemented {
+		return fmt.Errorf("%w: %s", ErrConfigurationUnsupported, status.Convert(err).Message())
+	}
+
+	return err
+}
@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+// Describe returns the input and output message types of each command, alongside the descriptors of all files that
+// define them.
+func (api *API) Describe(context.Context, *plugin.DescribeRequest) (*plugin.DescribeResponse, error) {
+	response := &plugin.DescribeResponse{
+		Files: &descriptorpb.FileDescriptorSet{},
+	}
+
+	seen := make(map[string]bool)
+	for _, command := range api.info.Commands {
+		descriptor := &plugin.CommandDescriptor{Name: command.Name}
+
+		if command.Input != nil {
+			descriptor.InputType = string(command.Input.FullName())
+			addFile(response.Files, seen, command.Input.ParentFile())
+		}
+
+		if command.Output != nil {
+			descriptor.OutputType = string(command.Output.FullName())
+			addFile(response.Files, seen, command.Output.ParentFile())
+		}
+
+		response.Commands = append(response.Commands, descriptor)
+	}
+
+	return response, nil
+}
+
+// addFile adds the file and its dependencies to the set, such that each file appears after its dependencies.
+func addFile(set *descriptorpb.FileDescriptorSet, seen map[string]bool, file protoreflect.FileDescriptor) {
+	if seen[file.Path()] {
+		return
+	}
+
+	seen[file.Path()] = true
+
+	imports := file.Imports()
+	for i := range imports.Len() {
+		addFile(set, seen, imports.Get(i).FileDescriptor)
+	}
+
+	set.File = append(set.File, protodesc.ToFileDescriptorProto(file))
+}
+
+// Describe returns the input and output message types of each command provided by the plugin. The descriptors are
+// built from the files provided by the plugin, so do not require the plugin's protobuf definitions to be compiled into
+// the caller.
+func (c *Client) Describe(ctx context.Context) ([]Command, error) {
+	response, err := c.inner.Describe(ctx, &plugin.DescribeRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := protodesc.NewFiles(response.GetFiles())
+	if err != nil {
+		return nil, fmt.Errorf("invalid file descriptors: %w", err)
+	}
+
+	commands := make([]Command, 0, len(response.GetCommands()))
+	for _, descriptor := range response.GetCommands() {
+		command := Command{Name: descriptor.GetName()}
+
+		if command.Input, err = findMessage(files, descriptor.GetInputType()); err != nil {
+			return nil, err
+		}
+
+		if command.Output, err = findMessage(files, descriptor.GetOutputType()); err != nil {
+			return nil, err
+		}
+
+		commands = append(commands, command)
+	}
+
+	return commands, nil
+}
+
+func findMessage(files *protoregistry.Files, name string) (protoreflect.MessageDescriptor, error) {
+	if name == "" {
+		return nil, nil
+	}
+
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find message %q: %w", name, err)
+	}
+
+	message, ok := descriptor.(protoreflect.MessageDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a message", name)
+	}
+
+	return message, nil
+}
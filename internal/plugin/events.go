@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	events struct {
+		mu          sync.Mutex
+		subscribers map[*subscriber]struct{}
+		done        chan struct{}
+		once        sync.Once
+	}
+
+	subscriber struct {
+		events chan *plugin.SubscribeResponse
+		done   chan struct{}
+	}
+)
+
+func newEvents() events {
+	return events{
+		subscribers: make(map[*subscriber]struct{}),
+		done:        make(chan struct{}),
+	}
+}
+
+// Publish an event to all current subscribers, blocking until each has received it or the context is cancelled.
+// Events published while there are no subscribers are discarded.
+func (api *API) Publish(ctx context.Context, event *anypb.Any) error {
+	response := &plugin.SubscribeResponse{
+		Event: event,
+		Time:  timestamppb.New(time.Now()),
+	}
+
+	api.events.mu.Lock()
+	subscribers := make([]*subscriber, 0, len(api.events.subscribers))
+	for s := range api.events.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	api.events.mu.Unlock()
+
+	for _, s := range subscribers {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.done:
+		case s.events <- response:
+		}
+	}
+
+	return nil
+}
+
+// Subscribe streams published events to the caller until its context is cancelled or the API is closed.
+func (api *API) Subscribe(_ *plugin.SubscribeRequest, stream grpc.ServerStreamingServer[plugin.SubscribeResponse]) error {
+	s := &subscriber{
+		events: make(chan *plugin.SubscribeResponse),
+		done:   make(chan struct{}),
+	}
+
+	api.events.mu.Lock()
+	api.events.subscribers[s] = struct{}{}
+	api.events.mu.Unlock()
+
+	defer func() {
+		api.events.mu.Lock()
+		delete(api.events.subscribers, s)
+		api.events.mu.Unlock()
+		close(s.done)
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-api.events.done:
+			return nil
+		case event := <-s.events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (es *events) close() {
+	es.once.Do(func() {
+		close(es.done)
+	})
+}
@@ -0,0 +1,76 @@
+package plugin_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+func TestAPI_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "plugin.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := grpc.NewServer()
+	api := plugin.NewAPI(plugin.Info{Name: "test-plugin"}, nil)
+	api.Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	client, err := plugin.NewClient(socket)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	t.Run("discards events without subscribers", func(t *testing.T) {
+		require.NoError(t, api.Publish(t.Context(), mustAny(t, wrapperspb.String("discarded"))))
+	})
+
+	t.Run("streams events to subscribers", func(t *testing.T) {
+		received := make(chan *anypb.Any, 1)
+		errs := make(chan error, 1)
+		go func() {
+			errs <- client.Subscribe(t.Context(), func(event *anypb.Any, at time.Time) {
+				select {
+				case received <- event:
+				default:
+				}
+			})
+		}()
+
+		// Events are discarded until the subscription has been registered.
+		publish := func(ctx context.Context) {
+			for ctx.Err() == nil {
+				_ = api.Publish(ctx, mustAny(t, wrapperspb.String("event")))
+			}
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		go publish(ctx)
+
+		event := <-received
+		cancel()
+
+		value := &wrapperspb.StringValue{}
+		require.NoError(t, event.UnmarshalTo(value))
+		assert.EqualValues(t, "event", value.GetValue())
+
+		api.Close()
+		assert.NoError(t, <-errs)
+	})
+}
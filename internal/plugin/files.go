@@ -0,0 +1,292 @@
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/rs/xid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// files stores the files exchanged with the host application within a temporary directory, which is created
+	// when the first file is stored.
+	files struct {
+		mu    sync.Mutex
+		dir   string
+		paths map[string]string
+	}
+
+	filesKey struct{}
+)
+
+var (
+	// ErrUnknownFile is the error returned when attempting to open a file that does not exist.
+	ErrUnknownFile = errors.New("unknown file")
+
+	// ErrNoFiles is the error returned when attempting to use files outside of a command handler.
+	ErrNoFiles = errors.New("files are only available to command handlers")
+)
+
+// fileChunkSize is the maximum size, in bytes, of the data within each chunk of a file.
+const fileChunkSize = 256 << 10
+
+// OpenFile opens the file sent by the host application with the given identifier for reading. The provided context
+// must be that given to a command handler. Returns ErrUnknownFile if the file does not exist.
+func OpenFile(ctx context.Context, id string) (*os.File, error) {
+	fs, ok := ctx.Value(filesKey{}).(*files)
+	if !ok {
+		return nil, ErrNoFiles
+	}
+
+	return fs.open(id)
+}
+
+// CreateFile creates a new file that the host application can receive using the returned identifier. The provided
+// context must be that given to a command handler.
+func CreateFile(ctx context.Context) (string, *os.File, error) {
+	fs, ok := ctx.Value(filesKey{}).(*files)
+	if !ok {
+		return "", nil, ErrNoFiles
+	}
+
+	id := xid.New().String()
+	f, err := fs.create(id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return id, f, nil
+}
+
+func (fs *files) open(id string) (*os.File, error) {
+	fs.mu.Lock()
+	path, ok := fs.paths[id]
+	fs.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFile, id)
+	}
+
+	return os.Open(path)
+}
+
+func (fs *files) create(id string) (*os.File, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if _, ok := fs.paths[id]; ok {
+		return nil, status.Errorf(codes.AlreadyExists, "file %q already exists", id)
+	}
+
+	if fs.dir == "" {
+		dir, err := os.MkdirTemp("", "plugin-files-")
+		if err != nil {
+			return nil, err
+		}
+
+		fs.dir = dir
+		fs.paths = make(map[string]string)
+	}
+
+	f, err := os.CreateTemp(fs.dir, "file-")
+	if err != nil {
+		return nil, err
+	}
+
+	fs.paths[id] = f.Name()
+	return f, nil
+}
+
+func (fs *files) remove(id string) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if path, ok := fs.paths[id]; ok {
+		_ = os.Remove(path)
+		delete(fs.paths, id)
+	}
+}
+
+func (fs *files) close() {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.dir != "" {
+		_ = os.RemoveAll(fs.dir)
+		fs.dir = ""
+		fs.paths = nil
+	}
+}
+
+func withFiles(ctx context.Context, fs *files) context.Context {
+	return context.WithValue(ctx, filesKey{}, fs)
+}
+
+// SendFile stores the file streamed by the host application, so that command handlers can open it using OpenFile.
+// The file is discarded if the stream ends before its checksum is received, or if the checksum does not match.
+func (api *API) SendFile(stream grpc.ClientStreamingServer[plugin.FileChunk, plugin.SendFileResponse]) error {
+	first, err := stream.Recv()
+	switch {
+	case errors.Is(err, io.EOF):
+		return status.Error(codes.InvalidArgument, "missing file")
+	case err != nil:
+		return err
+	case first.GetId() == "":
+		return status.Error(codes.InvalidArgument, "missing file identifier")
+	}
+
+	f, err := api.files.create(first.GetId())
+	if err != nil {
+		return err
+	}
+
+	size, err := receiveFile(f, first, stream.Recv)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		api.files.remove(first.GetId())
+		return err
+	}
+
+	return stream.SendAndClose(&plugin.SendFileResponse{Size: size})
+}
+
+// ReceiveFile streams a file created by a command handler using CreateFile to the host application.
+func (api *API) ReceiveFile(request *plugin.ReceiveFileRequest, stream grpc.ServerStreamingServer[plugin.FileChunk]) error {
+	f, err := api.files.open(request.GetId())
+	if errors.Is(err, ErrUnknownFile) {
+		return status.Errorf(codes.NotFound, "unknown file %q", request.GetId())
+	}
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+	return sendFile(f, stream.Send)
+}
+
+// SendFile streams the contents of the reader to the plugin, where it is stored as a file with the given identifier.
+// Returns the size of the file in bytes.
+func (c *Client) SendFile(ctx context.Context, id string, r io.Reader) (int64, error) {
+	// The stream is aborted if the file cannot be read, so that the plugin discards it.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := c.inner.SendFile(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	first := true
+	err = sendFile(r, func(chunk *plugin.FileChunk) error {
+		if first {
+			chunk.Id, first = id, false
+		}
+
+		return stream.Send(chunk)
+	})
+
+	// Send returns io.EOF once the plugin has ended the stream, in which case the reason is given by CloseAndRecv.
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+
+	response, err := stream.CloseAndRecv()
+	if err != nil {
+		return 0, err
+	}
+
+	return response.GetSize(), nil
+}
+
+// ReceiveFile writes the contents of the file created by the plugin with the given identifier to the writer. Returns
+// the size of the file in bytes. An error with the codes.DataLoss status code is returned if the file does not match
+// its checksum.
+func (c *Client) ReceiveFile(ctx context.Context, id string, w io.Writer) (int64, error) {
+	stream, err := c.inner.ReceiveFile(ctx, &plugin.ReceiveFileRequest{Id: id})
+	if err != nil {
+		return 0, err
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		return 0, err
+	}
+
+	return receiveFile(w, first, stream.Recv)
+}
+
+// sendFile sends the contents of the reader in chunks, followed by a final chunk containing its checksum.
+func sendFile(r io.Reader, send func(*plugin.FileChunk) error) error {
+	checksum := sha256.New()
+	buf := make([]byte, fileChunkSize)
+
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			checksum.Write(buf[:n])
+			if err := send(&plugin.FileChunk{Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return send(&plugin.FileChunk{Sha256: hex.EncodeToString(checksum.Sum(nil))})
+}
+
+// receiveFile writes the data of each chunk to the writer until the final chunk containing the checksum is received,
+// starting with the chunk already received.
+func receiveFile(w io.Writer, chunk *plugin.FileChunk, recv func() (*plugin.FileChunk, error)) (int64, error) {
+	checksum := sha256.New()
+	w = io.MultiWriter(w, checksum)
+
+	var size int64
+	for {
+		if chunk.GetSha256() != "" {
+			return size, verifyChecksum(checksum, chunk.GetSha256())
+		}
+
+		n, err := w.Write(chunk.GetData())
+		size += int64(n)
+		if err != nil {
+			return size, err
+		}
+
+		chunk, err = recv()
+		if errors.Is(err, io.EOF) {
+			return size, status.Error(codes.DataLoss, "file ended before its checksum was received")
+		}
+		if err != nil {
+			return size, err
+		}
+	}
+}
+
+func verifyChecksum(checksum hash.Hash, expected string) error {
+	if actual := hex.EncodeToString(checksum.Sum(nil)); actual != expected {
+		return status.Errorf(codes.DataLoss, "file checksum %q does not match expected checksum %q", actual, expected)
+	}
+
+	return nil
+}
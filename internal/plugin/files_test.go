@@ -0,0 +1,133 @@
+package plugin_test
+
+import (
+	"bytes"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+func TestAPI_SendFile(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	api := plugin.NewAPI(plugin.Info{Name: "test-plugin"}, nil)
+	server := grpc.NewServer()
+	api.Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(func() {
+		server.Stop()
+		api.Close()
+	})
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, conn.Close())
+	})
+
+	client, err := plugin.NewClient(socket)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	inner := pb.NewPluginServiceClient(conn)
+
+	t.Run("stores files", func(t *testing.T) {
+		size, err := client.SendFile(t.Context(), "file", strings.NewReader("contents"))
+		require.NoError(t, err)
+		assert.EqualValues(t, 8, size)
+	})
+
+	t.Run("rejects duplicate identifiers", func(t *testing.T) {
+		_, err := client.SendFile(t.Context(), "duplicate", strings.NewReader("contents"))
+		require.NoError(t, err)
+
+		_, err = client.SendFile(t.Context(), "duplicate", strings.NewReader("contents"))
+		assert.EqualValues(t, codes.AlreadyExists, status.Code(err))
+	})
+
+	tt := []struct {
+		Name     string
+		Chunks   []*pb.FileChunk
+		Expected codes.Code
+	}{
+		{
+			Name:     "rejects files without an identifier",
+			Chunks:   []*pb.FileChunk{{Data: []byte("contents")}},
+			Expected: codes.InvalidArgument,
+		},
+		{
+			Name:     "rejects files without a checksum",
+			Chunks:   []*pb.FileChunk{{Id: "unverified", Data: []byte("contents")}},
+			Expected: codes.DataLoss,
+		},
+		{
+			Name:     "rejects files that do not match their checksum",
+			Chunks:   []*pb.FileChunk{{Id: "corrupted", Data: []byte("contents")}, {Sha256: "invalid"}},
+			Expected: codes.DataLoss,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			stream, err := inner.SendFile(t.Context())
+			require.NoError(t, err)
+
+			for _, chunk := range tc.Chunks {
+				require.NoError(t, stream.Send(chunk))
+			}
+
+			_, err = stream.CloseAndRecv()
+			assert.EqualValues(t, tc.Expected, status.Code(err))
+		})
+	}
+
+	t.Run("discards rejected files", func(t *testing.T) {
+		_, err := client.SendFile(t.Context(), "corrupted", strings.NewReader("contents"))
+		assert.NoError(t, err)
+	})
+}
+
+func TestAPI_ReceiveFile(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	api := plugin.NewAPI(plugin.Info{Name: "test-plugin"}, nil)
+	server := grpc.NewServer()
+	api.Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(func() {
+		server.Stop()
+		api.Close()
+	})
+
+	client, err := plugin.NewClient(socket)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	_, err = client.ReceiveFile(t.Context(), "unknown", &bytes.Buffer{})
+	assert.EqualValues(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,136 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// The Host type describes the host application driving a plugin, as given to Stat.
+	Host struct {
+		// The Name of the host application.
+		Name string
+		// The Version of the host application.
+		Version string
+	}
+
+	hostKey struct{}
+)
+
+// ErrIncompatibleHost is the error returned by Client.Stat when the plugin requires a newer version of the host
+// application than the one that identified itself.
+var ErrIncompatibleHost = errors.New("incompatible host")
+
+// ReasonIncompatibleHost is the reason used for errdetails.ErrorInfo details attached to errors returned by Stat when
+// the host application does not satisfy the minimum version required by the plugin.
+const ReasonIncompatibleHost = "INCOMPATIBLE_HOST"
+
+// WithMinHostVersion is an APIOption that rejects calls to Stat from host applications whose version is less than the
+// given semantic version, or that do not identify their version.
+func WithMinHostVersion(version string) APIOption {
+	return func(api *API) {
+		api.minHostVersion = version
+	}
+}
+
+// WithHost is a ClientOption that identifies the host application to the plugin each time Stat is called.
+func WithHost(host Host) ClientOption {
+	return func(o *clientOptions) {
+		o.host = host
+	}
+}
+
+// Host returns the host application that last identified itself via Stat. Returns false if no host application has.
+func (api *API) Host() (Host, bool) {
+	host := api.host.Load()
+	if host == nil {
+		return Host{}, false
+	}
+
+	return *host, true
+}
+
+// HostFromContext returns the host application that last identified itself via Stat, within the context of the call
+// being handled. Returns false if no host application has.
+func HostFromContext(ctx context.Context) (Host, bool) {
+	host, ok := ctx.Value(hostKey{}).(Host)
+	return host, ok
+}
+
+// withHost returns a copy of the context containing the host application that last identified itself via Stat, as
+// returned by HostFromContext.
+func (api *API) withHost(ctx context.Context) context.Context {
+	host, ok := api.Host()
+	if !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, hostKey{}, host)
+}
+
+// checkHost records the host application described by the request, returning an error if it does not satisfy the
+// minimum version required by the plugin.
+func (api *API) checkHost(request *plugin.StatRequest) error {
+	host := Host{
+		Name:    request.GetHostName(),
+		Version: request.GetHostVersion(),
+	}
+
+	if host != (Host{}) {
+		api.host.Store(&host)
+	}
+
+	if api.minHostVersion == "" {
+		return nil
+	}
+
+	minimum, err := semver.NewVersion(api.minHostVersion)
+	if err != nil {
+		return status.Errorf(codes.Internal, "invalid minimum host version %q: %v", api.minHostVersion, err)
+	}
+
+	version, err := semver.NewVersion(host.Version)
+	if err == nil && !version.LessThan(minimum) {
+		return nil
+	}
+
+	message := fmt.Sprintf("plugin %q requires host version %q or later, host %q has version %q", api.info.Name, api.minHostVersion, host.Name, host.Version)
+	st, err := status.New(codes.FailedPrecondition, message).WithDetails(&errdetails.ErrorInfo{
+		Reason: ReasonIncompatibleHost,
+		Domain: ErrorDomain,
+		Metadata: map[string]string{
+			"min_host_version": api.minHostVersion,
+		},
+	})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return st.Err()
+}
+
+// incompatibleHost converts errors returned by Stat due to the host application not satisfying the minimum version
+// required by the plugin into ErrIncompatibleHost. Other errors are returned as-is.
+func incompatibleHost(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() != codes.FailedPrecondition {
+		return err
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if ok && info.GetDomain() == ErrorDomain && info.GetReason() == ReasonIncompatibleHost {
+			return fmt.Errorf("%w: %s", ErrIncompatibleHost, st.Message())
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// sizedStream is a server stream that enforces the maximum input size of the command named by the first chunk
+	// received by ExecuteStream.
+	sizedStream struct {
+		grpc.ServerStream
+
+		limits   map[string]int
+		name     string
+		limit    int
+		received int
+	}
+)
+
+// InputSizeServerOptions returns grpc.ServerOption values that limit the size of the encoded input given to each of
+// the provided commands, whether using Execute, ExecuteStream or SubmitJob, according to its MaxInputSize. Calls whose
+// input exceeds the limit fail with codes.ResourceExhausted before the command is executed. Inputs sent using
+// ExecuteStream are rejected as soon as the chunks received exceed the limit.
+func InputSizeServerOptions(commands []Command) []grpc.ServerOption {
+	limits := make(map[string]int)
+	for _, command := range commands {
+		if command.MaxInputSize > 0 {
+			limits[command.Name] = command.MaxInputSize
+		}
+	}
+
+	if len(limits) == 0 {
+		return nil
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			var (
+				name string
+				size int
+			)
+
+			switch request := req.(type) {
+			case *plugin.ExecuteRequest:
+				name, size = request.GetName(), proto.Size(request.GetInput())
+			case *plugin.SubmitJobRequest:
+				name, size = request.GetName(), proto.Size(request.GetInput())
+			default:
+				return handler(ctx, req)
+			}
+
+			if limit, ok := limits[name]; ok && size > limit {
+				return nil, inputTooLarge(name, limit)
+			}
+
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &sizedStream{ServerStream: ss, limits: limits})
+		}),
+	}
+}
+
+// RecvMsg receives the next message from the stream. If it is a chunk of a request to ExecuteStream that takes the
+// input beyond the maximum size of its command, an error is returned instead.
+func (s *sizedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	chunk, ok := m.(*plugin.ExecuteChunk)
+	if !ok {
+		return nil
+	}
+
+	if s.name == "" {
+		s.name = chunk.GetName()
+		s.limit = s.limits[s.name]
+	}
+
+	s.received += len(chunk.GetData())
+	if s.limit > 0 && s.received > s.limit {
+		return inputTooLarge(s.name, s.limit)
+	}
+
+	return nil
+}
+
+func inputTooLarge(name string, limit int) error {
+	return status.Errorf(codes.ResourceExhausted, "input to command %q exceeds its maximum size of %d bytes", name, limit)
+}
@@ -0,0 +1,111 @@
+package plugin_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+func TestInputSizeServerOptions(t *testing.T) {
+	t.Parallel()
+
+	info := plugin.Info{
+		Name: "test-plugin",
+		Commands: []plugin.Command{
+			{Name: "limited", MaxInputSize: 128},
+			{Name: "unlimited"},
+		},
+	}
+
+	var calls atomic.Int32
+	handler := func(_ context.Context, input *anypb.Any) (*anypb.Any, error) {
+		calls.Add(1)
+		return input, nil
+	}
+
+	socket := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := grpc.NewServer(plugin.InputSizeServerOptions(info.Commands)...)
+	plugin.NewAPI(info, plugin.CommandHandlers{"limited": handler, "unlimited": handler}).Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	client, err := plugin.NewClient(socket)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	tt := []struct {
+		Name      string
+		Command   string
+		Size      int
+		ChunkSize int
+		Expected  codes.Code
+	}{
+		{
+			Name:     "allows inputs within the limit",
+			Command:  "limited",
+			Size:     16,
+			Expected: codes.OK,
+		},
+		{
+			Name:     "rejects inputs exceeding the limit",
+			Command:  "limited",
+			Size:     256,
+			Expected: codes.ResourceExhausted,
+		},
+		{
+			Name:      "allows streamed inputs within the limit",
+			Command:   "limited",
+			Size:      16,
+			ChunkSize: 8,
+			Expected:  codes.OK,
+		},
+		{
+			Name:      "rejects streamed inputs exceeding the limit",
+			Command:   "limited",
+			Size:      256,
+			ChunkSize: 32,
+			Expected:  codes.ResourceExhausted,
+		},
+		{
+			Name:     "does not limit other commands",
+			Command:  "unlimited",
+			Size:     1024,
+			Expected: codes.OK,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			before := calls.Load()
+
+			input := wrapperspb.String(strings.Repeat("a", tc.Size))
+			_, err := client.Execute(t.Context(), tc.Command, input, &wrapperspb.StringValue{}, plugin.ExecuteOptions{
+				ChunkSize: tc.ChunkSize,
+			})
+
+			assert.EqualValues(t, tc.Expected, status.Code(err))
+			if tc.Expected != codes.OK {
+				assert.EqualValues(t, before, calls.Load())
+			}
+		})
+	}
+}
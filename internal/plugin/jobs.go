@@ -0,0 +1,212 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/rs/xid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// The ProgressFunc type is a function used by command handlers executing as a job to report their progress,
+	// between zero and one, alongside a message describing what they are doing.
+	ProgressFunc func(progress float64, message string)
+
+	jobs struct {
+		mu   sync.Mutex
+		byID map[string]*job
+	}
+
+	job struct {
+		mu     sync.Mutex
+		job    *plugin.Job
+		cancel context.CancelFunc
+	}
+
+	progressKey struct{}
+)
+
+// jobRetention is how long a completed job remains available via GetJob.
+const jobRetention = time.Hour
+
+// ProgressFromContext returns the ProgressFunc for the job being executed with the provided context. If the context
+// does not belong to a job, the returned function does nothing.
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	if fn, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		return fn
+	}
+
+	return func(float64, string) {}
+}
+
+// SubmitJob begins executing a command in the background, returning the identifier of the job. The job continues to
+// execute once the request has completed, until it either returns or is cancelled via CancelJob.
+func (api *API) SubmitJob(requestCtx context.Context, request *plugin.SubmitJobRequest) (*plugin.SubmitJobResponse, error) {
+	if request.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing command name")
+	}
+
+	handler, ok := api.handlers[request.GetName()]
+	if !ok {
+		return nil, unknownCommand(request.GetName())
+	}
+
+	if err := api.checkConfigured(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(withFiles(withMetadata(api.withState(context.Background()), requestCtx), &api.files))
+	j := &job{
+		cancel: cancel,
+		job: &plugin.Job{
+			Id:        xid.New().String(),
+			Name:      request.GetName(),
+			State:     plugin.JobState_JOB_STATE_RUNNING,
+			CreatedAt: timestamppb.Now(),
+		},
+	}
+
+	api.jobs.add(j)
+
+	ctx = context.WithValue(ctx, progressKey{}, ProgressFunc(j.progress))
+	go func() {
+		defer cancel()
+
+		started := time.Now()
+		output, err := handler(ctx, request.GetInput())
+		api.calls.record(request.GetName(), time.Since(started), err != nil)
+		j.complete(ctx, output, err)
+	}()
+
+	return &plugin.SubmitJobResponse{Id: j.job.GetId()}, nil
+}
+
+// GetJob returns the current state of a job.
+func (api *API) GetJob(_ context.Context, request *plugin.GetJobRequest) (*plugin.GetJobResponse, error) {
+	j, err := api.jobs.get(request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	return &plugin.GetJobResponse{Job: j.snapshot()}, nil
+}
+
+// CancelJob cancels the context of a running job. The job is marked as cancelled once its command handler returns.
+func (api *API) CancelJob(_ context.Context, request *plugin.CancelJobRequest) (*plugin.CancelJobResponse, error) {
+	j, err := api.jobs.get(request.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	j.cancel()
+	return &plugin.CancelJobResponse{}, nil
+}
+
+// Close cancels all running jobs, ends all event subscriptions and removes all files exchanged with the host
+// application.
+func (api *API) Close() {
+	api.events.close()
+	api.files.close()
+
+	api.jobs.mu.Lock()
+	defer api.jobs.mu.Unlock()
+
+	for _, j := range api.jobs.byID {
+		j.cancel()
+	}
+}
+
+func (js *jobs) add(j *job) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	if js.byID == nil {
+		js.byID = make(map[string]*job)
+	}
+
+	// Completed jobs are removed once their retention has elapsed.
+	cutoff := time.Now().Add(-jobRetention)
+	for id, existing := range js.byID {
+		if completed := existing.completedAt(); !completed.IsZero() && completed.Before(cutoff) {
+			delete(js.byID, id)
+		}
+	}
+
+	js.byID[j.job.GetId()] = j
+}
+
+func (js *jobs) get(id string) (*job, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	j, ok := js.byID[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown job %q", id)
+	}
+
+	return j, nil
+}
+
+func (j *job) progress(progress float64, message string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.job.GetState() != plugin.JobState_JOB_STATE_RUNNING {
+		return
+	}
+
+	j.job.Progress = min(max(progress, 0), 1)
+	j.job.Message = message
+}
+
+func (j *job) complete(ctx context.Context, output *anypb.Any, err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.job.CompletedAt = timestamppb.Now()
+
+	switch {
+	case err == nil:
+		j.job.State = plugin.JobState_JOB_STATE_SUCCEEDED
+		j.job.Progress = 1
+		j.job.Output = output
+		return
+	case ctx.Err() != nil:
+		j.job.State = plugin.JobState_JOB_STATE_CANCELLED
+	default:
+		j.job.State = plugin.JobState_JOB_STATE_FAILED
+	}
+
+	st := toStatus(ctx, err).Proto()
+	j.job.Error = &plugin.JobError{
+		Code:    st.GetCode(),
+		Message: st.GetMessage(),
+		Details: st.GetDetails(),
+	}
+}
+
+func (j *job) snapshot() *plugin.Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return proto.CloneOf(j.job)
+}
+
+func (j *job) completedAt() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.job.GetCompletedAt() == nil {
+		return time.Time{}
+	}
+
+	return j.job.GetCompletedAt().AsTime()
+}
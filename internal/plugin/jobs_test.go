@@ -0,0 +1,132 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+func TestAPI_Jobs(t *testing.T) {
+	t.Parallel()
+
+	handlers := plugin.CommandHandlers{
+		"succeed": func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+			plugin.ProgressFromContext(ctx)(0.5, "halfway")
+			return input, nil
+		},
+		"fail": func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+			return nil, errors.New("failed")
+		},
+		"block": func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+			plugin.ProgressFromContext(ctx)(2, "waiting")
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+	}
+
+	tt := []struct {
+		Name            string
+		Command         string
+		Cancel          bool
+		ExpectedState   pb.JobState
+		ExpectedCode    codes.Code
+		ExpectedMessage string
+	}{
+		{
+			Name:          "job succeeds",
+			Command:       "succeed",
+			ExpectedState: pb.JobState_JOB_STATE_SUCCEEDED,
+		},
+		{
+			Name:          "job fails",
+			Command:       "fail",
+			ExpectedState: pb.JobState_JOB_STATE_FAILED,
+			ExpectedCode:  codes.Internal,
+		},
+		{
+			Name:          "job is cancelled",
+			Command:       "block",
+			Cancel:        true,
+			ExpectedState: pb.JobState_JOB_STATE_CANCELLED,
+			ExpectedCode:  codes.Canceled,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			api := plugin.NewAPI(plugin.Info{}, handlers)
+			t.Cleanup(api.Close)
+
+			input := mustAny(t, wrapperspb.String("test"))
+			submitted, err := api.SubmitJob(t.Context(), &pb.SubmitJobRequest{Name: tc.Command, Input: input})
+			require.NoError(t, err)
+			require.NotEmpty(t, submitted.GetId())
+
+			if tc.Cancel {
+				require.Eventually(t, func() bool {
+					response, err := api.GetJob(t.Context(), &pb.GetJobRequest{Id: submitted.GetId()})
+					require.NoError(t, err)
+					return response.GetJob().GetMessage() == "waiting"
+				}, time.Second, time.Millisecond)
+
+				_, err = api.CancelJob(t.Context(), &pb.CancelJobRequest{Id: submitted.GetId()})
+				require.NoError(t, err)
+			}
+
+			var job *pb.Job
+			require.Eventually(t, func() bool {
+				response, err := api.GetJob(t.Context(), &pb.GetJobRequest{Id: submitted.GetId()})
+				require.NoError(t, err)
+
+				job = response.GetJob()
+				return job.GetState() != pb.JobState_JOB_STATE_RUNNING
+			}, time.Second, time.Millisecond)
+
+			assert.EqualValues(t, tc.ExpectedState, job.GetState())
+			assert.EqualValues(t, tc.Command, job.GetName())
+			assert.NotNil(t, job.GetCreatedAt())
+			assert.NotNil(t, job.GetCompletedAt())
+
+			if tc.ExpectedState == pb.JobState_JOB_STATE_SUCCEEDED {
+				assert.EqualValues(t, 1, job.GetProgress())
+				assert.EqualValues(t, "halfway", job.GetMessage())
+				assert.EqualValues(t, input.GetValue(), job.GetOutput().GetValue())
+				return
+			}
+
+			assert.EqualValues(t, tc.ExpectedCode, job.GetError().GetCode())
+		})
+	}
+
+	t.Run("error if unknown command", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := plugin.NewAPI(plugin.Info{}, handlers).SubmitJob(t.Context(), &pb.SubmitJobRequest{Name: "unknown"})
+		assert.EqualValues(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("error if unknown job", func(t *testing.T) {
+		t.Parallel()
+
+		api := plugin.NewAPI(plugin.Info{}, handlers)
+
+		_, err := api.GetJob(t.Context(), &pb.GetJobRequest{Id: "unknown"})
+		assert.EqualValues(t, codes.NotFound, status.Code(err))
+
+		_, err = api.CancelJob(t.Context(), &pb.CancelJobRequest{Id: "unknown"})
+		assert.EqualValues(t, codes.NotFound, status.Code(err))
+	})
+}
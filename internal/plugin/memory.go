@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/davidsbond/plugin/internal/shm"
+)
+
+type (
+	sharedMemoryKey struct{}
+)
+
+// SharedMemoryMetadataKey is the gRPC metadata key containing the identifiers of the shared memory sent with a call
+// to Execute.
+const SharedMemoryMetadataKey = "plugin-shared-memory"
+
+// WithSharedMemory is an APIOption that provides command handlers with any memory within the shm.Store whose
+// identifiers are sent with a call to Execute.
+func WithSharedMemory(store *shm.Store) APIOption {
+	return func(api *API) {
+		api.memory = store
+	}
+}
+
+// AppendSharedMemory returns a copy of the context that sends the identifiers of shared memory on any call made
+// using it.
+func AppendSharedMemory(ctx context.Context, ids []string) context.Context {
+	pairs := make([]string, 0, len(ids)*2)
+	for _, id := range ids {
+		pairs = append(pairs, SharedMemoryMetadataKey, id)
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// SharedMemoryFromContext returns the shared memory sent by the host application with the call being handled, in the
+// order it was given.
+func SharedMemoryFromContext(ctx context.Context) []*shm.Memory {
+	memory, _ := ctx.Value(sharedMemoryKey{}).([]*shm.Memory)
+	return memory
+}
+
+// claimSharedMemory returns a copy of the context containing the shared memory sent with the incoming call, and a
+// function that closes it once the handler has returned.
+func (api *API) claimSharedMemory(ctx context.Context) (context.Context, func(), error) {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	ids := incoming.Get(SharedMemoryMetadataKey)
+	if len(ids) == 0 {
+		return ctx, func() {}, nil
+	}
+
+	if api.memory == nil {
+		return nil, nil, status.Error(codes.FailedPrecondition, "shared memory is not enabled")
+	}
+
+	memory, err := api.memory.Claim(ids)
+	if err != nil {
+		return nil, nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return context.WithValue(ctx, sharedMemoryKey{}, memory), func() {
+		for _, m := range memory {
+			_ = m.Close()
+		}
+	}, nil
+}
@@ -0,0 +1,65 @@
+package plugin
+
+import (
+	"context"
+	"maps"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+type (
+	metadataKey struct{}
+)
+
+// MetadataPrefix is the prefix of gRPC metadata keys used to send metadata from the host application to command
+// handlers. The prefix separates it from metadata used by the plugin system itself, such as the token.
+const MetadataPrefix = "plugin-metadata-"
+
+// AppendMetadata returns a copy of the context that sends the provided metadata on any call made using it. Keys are
+// converted to lower case.
+func AppendMetadata(ctx context.Context, md map[string]string) context.Context {
+	pairs := make([]string, 0, len(md)*2)
+	for key, value := range md {
+		pairs = append(pairs, MetadataPrefix+strings.ToLower(key), value)
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, pairs...)
+}
+
+// MetadataFromContext returns the metadata sent by the host application with the call being handled. When the host
+// application sets the same key more than once, the last value is used.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	md, ok := ctx.Value(metadataKey{}).(map[string]string)
+	if !ok {
+		return map[string]string{}
+	}
+
+	return maps.Clone(md)
+}
+
+// withMetadata returns a copy of the context containing the metadata sent by the host application with the
+// incoming call, as given by the source context. This allows metadata to be provided to handlers that outlive the
+// call, such as jobs.
+func withMetadata(ctx context.Context, source context.Context) context.Context {
+	incoming, _ := metadata.FromIncomingContext(source)
+	return context.WithValue(ctx, metadataKey{}, fromMD(incoming))
+}
+
+// OutgoingMetadata returns the metadata that calls made using the context will send, as added using AppendMetadata.
+// When the same key has been set more than once, the last value is used.
+func OutgoingMetadata(ctx context.Context) map[string]string {
+	outgoing, _ := metadata.FromOutgoingContext(ctx)
+	return fromMD(outgoing)
+}
+
+func fromMD(source metadata.MD) map[string]string {
+	md := make(map[string]string)
+	for key, values := range source {
+		if name, ok := strings.CutPrefix(key, MetadataPrefix); ok && len(values) > 0 {
+			md[name] = values[len(values)-1]
+		}
+	}
+
+	return md
+}
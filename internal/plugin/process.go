@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+// ProtocolVersion is the version of the plugin protocol implemented by this package, as reported by the Stat RPC. It
+// is incremented when the protocol changes in a way that host applications may need to detect.
+const ProtocolVersion = 1
+
+type (
+	// The VCS type describes the version control revision a binary was built from, as recorded within its build
+	// information by the Go toolchain.
+	VCS struct {
+		// The Revision the binary was built from, such as a commit hash.
+		Revision string
+		// The Time of the revision.
+		Time time.Time
+		// Whether the working tree contained uncommitted changes, making the revision Modified.
+		Modified bool
+	}
+)
+
+// started is the time the process started, approximated by the time this package was initialised.
+var started = time.Now()
+
+// ReadVCS returns the version control revision the running binary was built from. The returned VCS is empty if the
+// binary was built without version control information, such as by go test or using the -buildvcs=false flag.
+func ReadVCS() VCS {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return VCS{}
+	}
+
+	return VCSFromBuildInfo(info)
+}
+
+// VCSFromBuildInfo returns the version control revision recorded within the build information.
+func VCSFromBuildInfo(info *debug.BuildInfo) VCS {
+	var vcs VCS
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			vcs.Revision = setting.Value
+		case "vcs.time":
+			vcs.Time, _ = time.Parse(time.RFC3339, setting.Value)
+		case "vcs.modified":
+			vcs.Modified, _ = strconv.ParseBool(setting.Value)
+		}
+	}
+
+	return vcs
+}
+
+// Version returns a pseudo-version describing the revision, in the same format used by the Go toolchain for modules
+// without a tagged version, such as "v0.0.0-20240101120000-0123456789ab". A "+dirty" suffix is added when the revision
+// is modified. Returns an empty string if the revision is unknown.
+func (vcs VCS) Version() string {
+	if vcs.Revision == "" {
+		return ""
+	}
+
+	version := "v0.0.0-"
+	if !vcs.Time.IsZero() {
+		version += vcs.Time.UTC().Format("20060102150405") + "-"
+	}
+
+	version += vcs.Revision[:min(len(vcs.Revision), 12)]
+
+	if vcs.Modified {
+		version += "+dirty"
+	}
+
+	return version
+}
+
+func processDetails() *plugin.ProcessDetails {
+	details := &plugin.ProcessDetails{
+		Pid:             int64(os.Getpid()),
+		StartedAt:       timestamppb.New(started),
+		Uptime:          durationpb.New(time.Since(started)),
+		Os:              runtime.GOOS,
+		Arch:            runtime.GOARCH,
+		RuntimeVersion:  runtime.Version(),
+		ProtocolVersion: ProtocolVersion,
+	}
+
+	if vcs := ReadVCS(); vcs.Revision != "" {
+		details.VcsRevision = vcs.Revision
+		details.VcsModified = vcs.Modified
+		if !vcs.Time.IsZero() {
+			details.VcsTime = timestamppb.New(vcs.Time)
+		}
+	}
+
+	return details
+}
@@ -0,0 +1,72 @@
+package plugin_test
+
+import (
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+func TestVCSFromBuildInfo(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Name            string
+		Settings        []debug.BuildSetting
+		Expected        plugin.VCS
+		ExpectedVersion string
+	}{
+		{
+			Name: "no version control information",
+		},
+		{
+			Name: "clean revision",
+			Settings: []debug.BuildSetting{
+				{Key: "vcs", Value: "git"},
+				{Key: "vcs.revision", Value: "0123456789abcdef0123456789abcdef01234567"},
+				{Key: "vcs.time", Value: "2024-01-01T12:00:00Z"},
+				{Key: "vcs.modified", Value: "false"},
+			},
+			Expected: plugin.VCS{
+				Revision: "0123456789abcdef0123456789abcdef01234567",
+				Time:     time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC),
+			},
+			ExpectedVersion: "v0.0.0-20240101120000-0123456789ab",
+		},
+		{
+			Name: "modified revision",
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "0123456789abcdef0123456789abcdef01234567"},
+				{Key: "vcs.time", Value: "2024-01-01T12:00:00Z"},
+				{Key: "vcs.modified", Value: "true"},
+			},
+			Expected: plugin.VCS{
+				Revision: "0123456789abcdef0123456789abcdef01234567",
+				Time:     time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC),
+				Modified: true,
+			},
+			ExpectedVersion: "v0.0.0-20240101120000-0123456789ab+dirty",
+		},
+		{
+			Name: "revision without time",
+			Settings: []debug.BuildSetting{
+				{Key: "vcs.revision", Value: "abc123"},
+			},
+			Expected: plugin.VCS{
+				Revision: "abc123",
+			},
+			ExpectedVersion: "v0.0.0-abc123",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			vcs := plugin.VCSFromBuildInfo(&debug.BuildInfo{Settings: tc.Settings})
+			assert.EqualValues(t, tc.Expected, vcs)
+			assert.EqualValues(t, tc.ExpectedVersion, vcs.Version())
+		})
+	}
+}
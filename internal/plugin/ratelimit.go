@@ -0,0 +1,144 @@
+package plugin
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// The RateLimit type describes how often a command may be executed.
+	RateLimit struct {
+		// The number of executions allowed per second. When zero, executions are not limited.
+		PerSecond float64
+		// The maximum number of executions allowed in a single burst. Defaults to 1.
+		Burst int
+	}
+
+	// limitedStream is a server stream that applies rate limits to the first chunk received by ExecuteStream.
+	limitedStream struct {
+		grpc.ServerStream
+
+		limiters map[string]*limiter
+		received bool
+	}
+
+	limiter struct {
+		mu     sync.Mutex
+		rate   float64
+		burst  float64
+		tokens float64
+		last   time.Time
+	}
+)
+
+// RateLimitServerOptions returns grpc.ServerOption values that limit how often each of the provided commands can be
+// executed, whether using Execute, ExecuteStream or SubmitJob, according to its RateLimit. Calls that exceed the limit
+// fail with codes.ResourceExhausted and an errdetails.RetryInfo detail describing when the command can next be
+// executed.
+func RateLimitServerOptions(commands []Command) []grpc.ServerOption {
+	limiters := make(map[string]*limiter)
+	for _, command := range commands {
+		if command.RateLimit.PerSecond > 0 {
+			limiters[command.Name] = newLimiter(command.RateLimit)
+		}
+	}
+
+	if len(limiters) == 0 {
+		return nil
+	}
+
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			var name string
+			switch request := req.(type) {
+			case *plugin.ExecuteRequest:
+				name = request.GetName()
+			case *plugin.SubmitJobRequest:
+				name = request.GetName()
+			default:
+				return handler(ctx, req)
+			}
+
+			if l, ok := limiters[name]; ok {
+				if delay, ok := l.allow(time.Now()); !ok {
+					return nil, rateLimited(name, delay)
+				}
+			}
+
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, &limitedStream{ServerStream: ss, limiters: limiters})
+		}),
+	}
+}
+
+// RecvMsg receives the next message from the stream. If it is the first chunk of a request to ExecuteStream and the
+// command has exceeded its rate limit, an error is returned instead.
+func (s *limitedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	chunk, ok := m.(*plugin.ExecuteChunk)
+	if !ok || s.received {
+		return nil
+	}
+
+	s.received = true
+	if l, ok := s.limiters[chunk.GetName()]; ok {
+		if delay, ok := l.allow(time.Now()); !ok {
+			return rateLimited(chunk.GetName(), delay)
+		}
+	}
+
+	return nil
+}
+
+func newLimiter(limit RateLimit) *limiter {
+	burst := float64(max(limit.Burst, 1))
+
+	return &limiter{
+		rate:   limit.PerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+	}
+}
+
+// allow consumes a token if one is available. Otherwise, it returns the time until the next token becomes available.
+func (l *limiter) allow(now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	if l.tokens < 1 {
+		return time.Duration((1 - l.tokens) / l.rate * float64(time.Second)), false
+	}
+
+	l.tokens--
+	return 0, true
+}
+
+func rateLimited(name string, delay time.Duration) error {
+	st, err := status.Newf(codes.ResourceExhausted, "command %q exceeded its rate limit", name).WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(delay),
+	})
+	if err != nil {
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+
+	return st.Err()
+}
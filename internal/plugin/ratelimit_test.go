@@ -0,0 +1,77 @@
+package plugin_test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+func TestRateLimitServerOptions(t *testing.T) {
+	t.Parallel()
+
+	info := plugin.Info{
+		Name: "test-plugin",
+		Commands: []plugin.Command{
+			{Name: "limited", RateLimit: plugin.RateLimit{PerSecond: 0.001, Burst: 2}},
+			{Name: "unlimited"},
+		},
+	}
+
+	handler := func(context.Context, *anypb.Any) (*anypb.Any, error) {
+		return anypb.New(&emptypb.Empty{})
+	}
+
+	socket := filepath.Join(t.TempDir(), "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := grpc.NewServer(plugin.RateLimitServerOptions(info.Commands)...)
+	plugin.NewAPI(info, plugin.CommandHandlers{"limited": handler, "unlimited": handler}).Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	client, err := plugin.NewClient(socket)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	execute := func(name string) error {
+		_, err := client.Execute(t.Context(), name, &emptypb.Empty{}, &emptypb.Empty{}, plugin.ExecuteOptions{})
+		return err
+	}
+
+	t.Run("allows calls within the burst", func(t *testing.T) {
+		require.NoError(t, execute("limited"))
+		require.NoError(t, execute("limited"))
+	})
+
+	t.Run("rejects calls exceeding the limit", func(t *testing.T) {
+		st := status.Convert(execute("limited"))
+		require.EqualValues(t, codes.ResourceExhausted, st.Code())
+		require.Len(t, st.Details(), 1)
+
+		info, ok := st.Details()[0].(*errdetails.RetryInfo)
+		require.True(t, ok)
+		assert.Positive(t, info.GetRetryDelay().AsDuration())
+	})
+
+	t.Run("does not limit other commands", func(t *testing.T) {
+		for range 5 {
+			require.NoError(t, execute("unlimited"))
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// loggedStream is a server stream that measures the command, request and response of a call to ExecuteStream.
+	loggedStream struct {
+		grpc.ServerStream
+
+		name         string
+		requestID    string
+		requestSize  int
+		responseSize int
+	}
+)
+
+// RequestLogServerOptions returns grpc.ServerOption values that log each command executed using Execute or
+// ExecuteStream once it has completed, describing its name, request identifier, duration, status code and the sizes of
+// its request and response. Calls that succeed are logged at the info level, and those that fail at the warn level.
+func RequestLogServerOptions(logger *slog.Logger) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			request, ok := req.(*plugin.ExecuteRequest)
+			if !ok || info.FullMethod != plugin.PluginService_Execute_FullMethodName {
+				return handler(ctx, req)
+			}
+
+			started := time.Now()
+			resp, err := handler(ctx, req)
+
+			var responseSize int
+			if response, ok := resp.(*plugin.ExecuteResponse); ok && err == nil {
+				responseSize = proto.Size(response.GetOutput())
+			}
+
+			logRequest(ctx, logger, request.GetName(), request.GetRequestId(), time.Since(started), proto.Size(request.GetInput()), responseSize, err)
+			return resp, err
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if info.FullMethod != plugin.PluginService_ExecuteStream_FullMethodName {
+				return handler(srv, ss)
+			}
+
+			started := time.Now()
+			stream := &loggedStream{ServerStream: ss}
+			err := handler(srv, stream)
+
+			logRequest(ss.Context(), logger, stream.name, stream.requestID, time.Since(started), stream.requestSize, stream.responseSize, err)
+			return err
+		}),
+	}
+}
+
+// RecvMsg receives the next message from the stream, recording the command named by the first chunk of the request.
+func (s *loggedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if chunk, ok := m.(*plugin.ExecuteChunk); ok {
+		if s.name == "" {
+			s.name, s.requestID = chunk.GetName(), chunk.GetRequestId()
+		}
+
+		s.requestSize += len(chunk.GetData())
+	}
+
+	return nil
+}
+
+// SendMsg sends the next message on the stream, recording the size of the response.
+func (s *loggedStream) SendMsg(m any) error {
+	if chunk, ok := m.(*plugin.ExecuteChunk); ok {
+		s.responseSize += len(chunk.GetData())
+	}
+
+	return s.ServerStream.SendMsg(m)
+}
+
+func logRequest(ctx context.Context, logger *slog.Logger, name, requestID string, duration time.Duration, requestSize, responseSize int, err error) {
+	code := status.Code(err)
+
+	level := slog.LevelInfo
+	if code != codes.OK {
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("command", name),
+		slog.String("request_id", requestID),
+		slog.Duration("duration", duration),
+		slog.String("code", code.String()),
+		slog.Int("request_size", requestSize),
+		slog.Int("response_size", responseSize),
+	}
+
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+
+	logger.LogAttrs(ctx, level, "executed command", attrs...)
+}
@@ -0,0 +1,203 @@
+package plugin
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// The Secrets type is an in-memory store of the secrets given to the plugin by the host application via
+	// SetSecrets.
+	Secrets struct {
+		mu      sync.RWMutex
+		secrets map[string][]byte
+	}
+
+	secretsKey struct{}
+)
+
+// ErrSecretsUnsupported is the error returned by Client.SetSecrets when the plugin does not accept secrets.
+var ErrSecretsUnsupported = errors.New("secrets unsupported")
+
+// secretsInfo is the HKDF info used to derive the key that secrets are sealed with.
+const secretsInfo = "plugin secrets"
+
+// Get returns a copy of the named secret. Returns false if the host application has not provided it.
+func (s *Secrets) Get(name string) ([]byte, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	secret, ok := s.secrets[name]
+	return slices.Clone(secret), ok
+}
+
+// Names returns the names of the secrets provided by the host application, in sorted order.
+func (s *Secrets) Names() []string {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Sorted(maps.Keys(s.secrets))
+}
+
+func (s *Secrets) set(secrets map[string][]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Previous secrets are overwritten so that they do not linger in memory until collected.
+	for _, secret := range s.secrets {
+		clear(secret)
+	}
+
+	s.secrets = secrets
+}
+
+// SecretsFromContext returns the secrets provided by the host application, within the context of the call being
+// handled. Returns nil if the context is not that of a call handled by the API.
+func SecretsFromContext(ctx context.Context) *Secrets {
+	secrets, _ := ctx.Value(secretsKey{}).(*Secrets)
+	return secrets
+}
+
+// withSecrets returns a copy of the context containing the secrets held by the API, as returned by SecretsFromContext.
+func (api *API) withSecrets(ctx context.Context) context.Context {
+	return context.WithValue(ctx, secretsKey{}, &api.secrets)
+}
+
+// SetSecrets replaces the secrets held by the plugin with those within the request, opening them using the secret key
+// returned by Stat if the host application has sealed them. Returns codes.InvalidArgument if a secret cannot be opened.
+func (api *API) SetSecrets(_ context.Context, request *plugin.SetSecretsRequest) (*plugin.SetSecretsResponse, error) {
+	secrets := request.GetSecrets()
+	if len(request.GetPublicKey()) > 0 {
+		var err error
+		if secrets, err = openSecrets(api.secretKey, request.GetPublicKey(), secrets); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid secrets: %v", err)
+		}
+	}
+
+	api.secrets.set(secrets)
+	return &plugin.SetSecretsResponse{}, nil
+}
+
+// SetSecrets replaces the secrets held by the plugin. When publicKey is the secret key returned by Stat, the secrets
+// are sealed to it, otherwise they are sent as-is. Returns ErrSecretsUnsupported if the plugin does not accept
+// secrets.
+func (c *Client) SetSecrets(ctx context.Context, publicKey []byte, secrets map[string][]byte) error {
+	request := &plugin.SetSecretsRequest{Secrets: secrets}
+	if len(publicKey) > 0 {
+		var err error
+		if request.PublicKey, request.Secrets, err = sealSecrets(publicKey, secrets); err != nil {
+			return fmt.Errorf("failed to seal secrets: %w", err)
+		}
+	}
+
+	_, err := c.inner.SetSecrets(ctx, request)
+	if status.Code(err) == codes.Unimplemented {
+		return fmt.Errorf("%w: %s", ErrSecretsUnsupported, status.Convert(err).Message())
+	}
+
+	return err
+}
+
+// sealSecrets seals each secret to the given X25519 public key using a newly generated ephemeral key, returning the
+// public key of the ephemeral key alongside the sealed secrets.
+func sealSecrets(publicKey []byte, secrets map[string][]byte) ([]byte, map[string][]byte, error) {
+	remote, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	local, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	aead, err := secretsCipher(local, remote)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sealed := make(map[string][]byte, len(secrets))
+	for name, secret := range secrets {
+		nonce := make([]byte, aead.NonceSize())
+		if _, err = rand.Read(nonce); err != nil {
+			return nil, nil, err
+		}
+
+		sealed[name] = aead.Seal(nonce, nonce, secret, []byte(name))
+	}
+
+	return local.PublicKey().Bytes(), sealed, nil
+}
+
+// openSecrets opens secrets sealed by sealSecrets using the private key whose public key they were sealed to.
+func openSecrets(key *ecdh.PrivateKey, publicKey []byte, secrets map[string][]byte) (map[string][]byte, error) {
+	if key == nil {
+		return nil, errors.New("plugin has no secret key")
+	}
+
+	remote, err := ecdh.X25519().NewPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := secretsCipher(key, remote)
+	if err != nil {
+		return nil, err
+	}
+
+	opened := make(map[string][]byte, len(secrets))
+	for name, secret := range secrets {
+		if len(secret) < aead.NonceSize() {
+			return nil, fmt.Errorf("secret %q is too short", name)
+		}
+
+		nonce, ciphertext := secret[:aead.NonceSize()], secret[aead.NonceSize():]
+		if opened[name], err = aead.Open(nil, nonce, ciphertext, []byte(name)); err != nil {
+			return nil, fmt.Errorf("failed to open secret %q: %w", name, err)
+		}
+	}
+
+	return opened, nil
+}
+
+func secretsCipher(key *ecdh.PrivateKey, remote *ecdh.PublicKey) (cipher.AEAD, error) {
+	shared, err := key.ECDH(remote)
+	if err != nil {
+		return nil, err
+	}
+
+	derived, err := hkdf.Key(sha256.New, shared, nil, secretsInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	tokenCredentials struct {
+		token string
+	}
+)
+
+const (
+	// TokenEnvironmentVariable is the environment variable used by the host application to provide a plugin with the
+	// token it must require on every call.
+	TokenEnvironmentVariable = "PLUGIN_TOKEN"
+
+	// TokenMetadataKey is the gRPC metadata key the host application uses to send the token on every call.
+	TokenMetadataKey = "plugin-token"
+)
+
+// NewToken returns a new randomly generated token used to authenticate the host application to a single plugin.
+func NewToken() string {
+	return rand.Text()
+}
+
+// WithToken is a ClientOption that sends the provided token as gRPC metadata on every call made by the Client.
+func WithToken(token string) ClientOption {
+	return func(o *clientOptions) {
+		o.dialOptions = append(o.dialOptions, grpc.WithPerRPCCredentials(tokenCredentials{token: token}))
+	}
+}
+
+func (tc tokenCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{TokenMetadataKey: tc.token}, nil
+}
+
+func (tc tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// TokenServerOptions returns grpc.ServerOption implementations that install interceptors rejecting any call that
+// does not provide the expected token with codes.Unauthenticated.
+func TokenServerOptions(token string) []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			if err := verifyToken(ctx, token); err != nil {
+				return nil, err
+			}
+
+			return handler(ctx, req)
+		}),
+		grpc.ChainStreamInterceptor(func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if err := verifyToken(ss.Context(), token); err != nil {
+				return err
+			}
+
+			return handler(srv, ss)
+		}),
+	}
+}
+
+func verifyToken(ctx context.Context, token string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get(TokenMetadataKey) {
+		if subtle.ConstantTimeCompare([]byte(value), []byte(token)) == 1 {
+			return nil
+		}
+	}
+
+	return status.Error(codes.Unauthenticated, "missing or invalid token")
+}
@@ -0,0 +1,67 @@
+package plugin_test
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+func TestTokenServerOptions(t *testing.T) {
+	t.Parallel()
+
+	token := plugin.NewToken()
+	socket := filepath.Join(t.TempDir(), "plugin.sock")
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+
+	server := grpc.NewServer(plugin.TokenServerOptions(token)...)
+	plugin.NewAPI(plugin.Info{Name: "test-plugin"}, nil).Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	tt := []struct {
+		Name     string
+		Options  []plugin.ClientOption
+		Expected codes.Code
+	}{
+		{
+			Name:     "accepts calls with the token",
+			Options:  []plugin.ClientOption{plugin.WithToken(token)},
+			Expected: codes.OK,
+		},
+		{
+			Name:     "rejects calls without a token",
+			Expected: codes.Unauthenticated,
+		},
+		{
+			Name:     "rejects calls with the wrong token",
+			Options:  []plugin.ClientOption{plugin.WithToken(plugin.NewToken())},
+			Expected: codes.Unauthenticated,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			client, err := plugin.NewClient(socket, tc.Options...)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, client.Close())
+			})
+
+			_, err = client.Stat(t.Context())
+			assert.EqualValues(t, tc.Expected, status.Code(err))
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package plugin
+
+import (
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+// cpuUsage is a no-op on platforms without getrusage, leaving the CPU times unset.
+func cpuUsage(*plugin.ResourceUsage) {}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package plugin
+
+import (
+	"syscall"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+func cpuUsage(usage *plugin.ResourceUsage) {
+	var rusage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &rusage); err != nil {
+		return
+	}
+
+	usage.UserCpuTime = durationpb.New(time.Duration(rusage.Utime.Nano()))
+	usage.SystemCpuTime = durationpb.New(time.Duration(rusage.Stime.Nano()))
+}
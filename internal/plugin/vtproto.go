@@ -0,0 +1,81 @@
+//go:build vtproto
+
+package plugin
+
+import (
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/proto"
+	"google.golang.org/grpc/mem"
+)
+
+type (
+	// vtCodec is a gRPC codec that marshals messages using the code generated by vtprotobuf, avoiding the reflection
+	// used by the protobuf runtime. Messages without generated code are marshalled by the default codec.
+	vtCodec struct{}
+
+	vtMessage interface {
+		SizeVT() int
+		MarshalToSizedBufferVT(data []byte) (int, error)
+		UnmarshalVT(data []byte) error
+	}
+)
+
+// FastMarshalling is true when the plugin system was built with the vtproto build tag, in which case the requests and
+// responses of the plugin API are marshalled using code generated by vtprotobuf.
+const FastMarshalling = true
+
+// MarshalServerOptions returns the grpc.ServerOption values that marshal the requests and responses of the plugin API
+// using code generated by vtprotobuf.
+func MarshalServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.ForceServerCodecV2(vtCodec{})}
+}
+
+func marshalDialOptions() []grpc.DialOption {
+	return []grpc.DialOption{grpc.WithDefaultCallOptions(grpc.ForceCodecV2(vtCodec{}))}
+}
+
+func (vtCodec) Name() string {
+	return proto.Name
+}
+
+func (vtCodec) Marshal(v any) (mem.BufferSlice, error) {
+	message, ok := v.(vtMessage)
+	if !ok {
+		return encoding.GetCodecV2(proto.Name).Marshal(v)
+	}
+
+	size := message.SizeVT()
+	if mem.IsBelowBufferPoolingThreshold(size) {
+		buf := make([]byte, size)
+		if _, err := message.MarshalToSizedBufferVT(buf); err != nil {
+			return nil, fmt.Errorf("vtproto: failed to marshal %T: %w", v, err)
+		}
+
+		return mem.BufferSlice{mem.SliceBuffer(buf)}, nil
+	}
+
+	pool := mem.DefaultBufferPool()
+	buf := pool.Get(size)
+	if _, err := message.MarshalToSizedBufferVT(*buf); err != nil {
+		pool.Put(buf)
+		return nil, fmt.Errorf("vtproto: failed to marshal %T: %w", v, err)
+	}
+
+	return mem.BufferSlice{mem.NewBuffer(buf, pool)}, nil
+}
+
+func (vtCodec) Unmarshal(data mem.BufferSlice, v any) error {
+	message, ok := v.(vtMessage)
+	if !ok {
+		return encoding.GetCodecV2(proto.Name).Unmarshal(data, v)
+	}
+
+	buf := data.MaterializeToBuffer(mem.DefaultBufferPool())
+	defer buf.Free()
+
+	// The generated code copies any data it retains, so the buffer can be freed once unmarshalled.
+	return message.UnmarshalVT(buf.ReadOnlyData())
+}
@@ -0,0 +1,20 @@
+//go:build !vtproto
+
+package plugin
+
+import (
+	"google.golang.org/grpc"
+)
+
+// FastMarshalling is true when the plugin system was built with the vtproto build tag, in which case the requests and
+// responses of the plugin API are marshalled using code generated by vtprotobuf.
+const FastMarshalling = false
+
+// MarshalServerOptions returns no options, as the plugin system was built without the vtproto build tag.
+func MarshalServerOptions() []grpc.ServerOption {
+	return nil
+}
+
+func marshalDialOptions() []grpc.DialOption {
+	return nil
+}
@@ -0,0 +1,46 @@
+package plugin
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+type (
+	warningsKey struct{}
+
+	// warnings collects the warnings reported by a command handler while it executes.
+	warnings struct {
+		mu   sync.Mutex
+		list []string
+	}
+)
+
+// AddWarning reports a non-fatal warning for the command being handled, which is returned to the host application
+// alongside its output if it succeeds. Warnings are discarded when the context was not given to a command handler by
+// Execute or ExecuteStream.
+func AddWarning(ctx context.Context, warning string) {
+	w, ok := ctx.Value(warningsKey{}).(*warnings)
+	if !ok {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.list = append(w.list, warning)
+}
+
+// withWarnings returns a copy of the context that collects the warnings reported using AddWarning.
+func withWarnings(ctx context.Context) (context.Context, *warnings) {
+	w := &warnings{}
+	return context.WithValue(ctx, warningsKey{}, w), w
+}
+
+// collected returns the warnings reported so far, in the order they were reported.
+func (w *warnings) collected() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return slices.Clone(w.list)
+}
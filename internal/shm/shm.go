@@ -0,0 +1,127 @@
+// Package shm provides regions of shared memory that can be exchanged between host applications and plugins. Memory
+// is passed between processes as file descriptors over a UNIX domain socket, so that only a reference to it is sent
+// with each gRPC request and its contents are never copied.
+package shm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/rs/xid"
+)
+
+type (
+	// The Memory type is a region of memory that is mapped into the current process and can be shared with another.
+	Memory struct {
+		file *os.File
+		data []byte
+	}
+
+	// The Store type contains memory received from host applications until it is claimed by a command handler.
+	Store struct {
+		mu     sync.Mutex
+		memory map[string]*Memory
+	}
+
+	// request is sent by the host application alongside the file descriptors of the memory it shares.
+	request struct {
+		Token string `json:"token"`
+	}
+
+	// response is sent by the plugin once it has mapped the shared memory, containing its identifiers in the order the
+	// file descriptors were given.
+	response struct {
+		IDs   []string `json:"ids,omitempty"`
+		Error string   `json:"error,omitempty"`
+	}
+)
+
+var (
+	// ErrUnsupported is the error returned when shared memory is used on a platform that does not support it.
+	ErrUnsupported = errors.New("shared memory is not supported on this platform")
+
+	// ErrUnknownMemory is the error returned when claiming memory that does not exist within the Store.
+	ErrUnknownMemory = errors.New("unknown shared memory")
+)
+
+// MaxRegions is the maximum number of regions of memory that can be shared with a single request.
+const MaxRegions = 64
+
+// maxRequestSize is the maximum size, in bytes, of the request sent alongside shared memory.
+const maxRequestSize = 4 << 10
+
+// Bytes returns the contents of the memory. Writes to the returned slice are visible to every process the memory is
+// shared with. The slice must not be used once the memory is closed.
+func (m *Memory) Bytes() []byte {
+	return m.data
+}
+
+// Len returns the size of the memory in bytes.
+func (m *Memory) Len() int {
+	return len(m.data)
+}
+
+// Claim removes the memory with the given identifiers from the Store, returning it in the same order. The caller is
+// responsible for closing the memory once it is no longer used. Returns ErrUnknownMemory if any of the memory does
+// not exist, in which case none of it is returned.
+func (s *Store) Claim(ids []string) ([]*Memory, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		if _, ok := s.memory[id]; !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownMemory, id)
+		}
+	}
+
+	memory := make([]*Memory, len(ids))
+	for i, id := range ids {
+		memory[i] = s.memory[id]
+		delete(s.memory, id)
+	}
+
+	return memory, nil
+}
+
+// Close all memory within the Store that has not been claimed.
+func (s *Store) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, m := range s.memory {
+		_ = m.Close()
+		delete(s.memory, id)
+	}
+}
+
+func (s *Store) add(memory []*Memory) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.memory == nil {
+		s.memory = make(map[string]*Memory)
+	}
+
+	ids := make([]string, len(memory))
+	for i, m := range memory {
+		ids[i] = xid.New().String()
+		s.memory[ids[i]] = m
+	}
+
+	return ids
+}
+
+// discard closes any of the memory with the given identifiers that has not been claimed.
+func (s *Store) discard(ids []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		if m, ok := s.memory[id]; ok {
+			_ = m.Close()
+			delete(s.memory, id)
+		}
+	}
+}
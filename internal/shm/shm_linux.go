@@ -0,0 +1,256 @@
+//go:build linux
+
+package shm
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// Supported is true if shared memory can be used on the current platform.
+const Supported = true
+
+// New returns a new region of shared memory of the given size in bytes, whose contents are initially zero.
+func New(size int) (*Memory, error) {
+	if size < 0 {
+		return nil, fmt.Errorf("invalid shared memory size %d", size)
+	}
+
+	fd, err := unix.MemfdCreate("plugin-shm", unix.MFD_CLOEXEC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shared memory: %w", err)
+	}
+
+	file := os.NewFile(uintptr(fd), "plugin-shm")
+	if err = file.Truncate(int64(size)); err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to size shared memory: %w", err), file.Close())
+	}
+
+	return mapFile(file)
+}
+
+// Close unmaps the memory from the current process. The memory is released once every process it was shared with has
+// closed it.
+func (m *Memory) Close() error {
+	var err error
+	if m.data != nil {
+		err = unix.Munmap(m.data)
+		m.data = nil
+	}
+
+	return errors.Join(err, m.file.Close())
+}
+
+// mapFile maps the entirety of the file into the current process. The file is closed if it cannot be mapped.
+func mapFile(file *os.File) (*Memory, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, errors.Join(err, file.Close())
+	}
+
+	m := &Memory{file: file}
+
+	// Empty regions cannot be mapped, so they have no contents.
+	if info.Size() == 0 {
+		return m, nil
+	}
+
+	m.data, err = unix.Mmap(int(file.Fd()), 0, int(info.Size()), unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to map shared memory: %w", err), file.Close())
+	}
+
+	return m, nil
+}
+
+// Send shares the memory with the plugin listening on the UNIX domain socket at the given path, returning the
+// identifiers it is known by within the plugin. The memory remains available to the plugin until it is claimed or the
+// returned io.Closer is closed, which should happen once the request using the memory has completed.
+func Send(ctx context.Context, socket, token string, memory []*Memory) ([]string, io.Closer, error) {
+	if len(memory) > MaxRegions {
+		return nil, nil, fmt.Errorf("cannot share %d regions of memory, limit is %d", len(memory), MaxRegions)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "unix", socket)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids, err := send(ctx, conn.(*net.UnixConn), token, memory)
+	if err != nil {
+		return nil, nil, errors.Join(err, conn.Close())
+	}
+
+	return ids, conn, nil
+}
+
+func send(ctx context.Context, conn *net.UnixConn, token string, memory []*Memory) ([]string, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	payload, err := json.Marshal(request{Token: token})
+	if err != nil {
+		return nil, err
+	}
+
+	fds := make([]int, len(memory))
+	for i, m := range memory {
+		fds[i] = int(m.file.Fd())
+	}
+
+	_, _, err = conn.WriteMsgUnix(payload, unix.UnixRights(fds...), nil)
+	runtime.KeepAlive(memory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to share memory: %w", err)
+	}
+
+	var r response
+	if err = json.NewDecoder(conn).Decode(&r); err != nil {
+		return nil, fmt.Errorf("failed to share memory: %w", err)
+	}
+
+	if r.Error != "" {
+		return nil, fmt.Errorf("failed to share memory: %s", r.Error)
+	}
+
+	if len(r.IDs) != len(memory) {
+		return nil, fmt.Errorf("failed to share memory: expected %d identifiers, got %d", len(memory), len(r.IDs))
+	}
+
+	return r.IDs, nil
+}
+
+// Serve accepts memory shared by host applications on the listener, adding it to the Store, until the context is
+// cancelled. If the token is not empty, memory shared without it is rejected.
+func Serve(ctx context.Context, listener net.Listener, token string, store *Store) error {
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		go store.receive(conn.(*net.UnixConn), token)
+	}
+}
+
+// receive memory shared on the connection, which is discarded once the connection is closed if it has not been
+// claimed.
+func (s *Store) receive(conn *net.UnixConn, token string) {
+	defer conn.Close()
+
+	memory, err := receive(conn, token)
+	if err != nil {
+		_ = json.NewEncoder(conn).Encode(response{Error: err.Error()})
+		return
+	}
+
+	ids := s.add(memory)
+	defer s.discard(ids)
+
+	if err = json.NewEncoder(conn).Encode(response{IDs: ids}); err != nil {
+		return
+	}
+
+	// The host application closes the connection once its request has completed.
+	_, _ = io.Copy(io.Discard, conn)
+}
+
+func receive(conn *net.UnixConn, token string) ([]*Memory, error) {
+	payload := make([]byte, maxRequestSize)
+	oob := make([]byte, unix.CmsgSpace(MaxRegions*4))
+
+	n, oobn, _, _, err := conn.ReadMsgUnix(payload, oob)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := parseRights(oob[:oobn])
+	if err != nil {
+		return nil, err
+	}
+
+	var r request
+	if err = json.Unmarshal(payload[:n], &r); err != nil {
+		return nil, errors.Join(err, closeFiles(files))
+	}
+
+	if token != "" && subtle.ConstantTimeCompare([]byte(r.Token), []byte(token)) != 1 {
+		return nil, errors.Join(errors.New("invalid token"), closeFiles(files))
+	}
+
+	memory := make([]*Memory, 0, len(files))
+	for i, file := range files {
+		m, err := mapFile(file)
+		if err != nil {
+			return nil, errors.Join(err, closeMemory(memory), closeFiles(files[i+1:]))
+		}
+
+		memory = append(memory, m)
+	}
+
+	return memory, nil
+}
+
+func parseRights(oob []byte) ([]*os.File, error) {
+	messages, err := unix.ParseSocketControlMessage(oob)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*os.File
+	for _, message := range messages {
+		fds, err := unix.ParseUnixRights(&message)
+		if err != nil {
+			return nil, errors.Join(err, closeFiles(files))
+		}
+
+		for _, fd := range fds {
+			files = append(files, os.NewFile(uintptr(fd), "plugin-shm"))
+		}
+	}
+
+	return files, nil
+}
+
+func closeFiles(files []*os.File) error {
+	var err error
+	for _, file := range files {
+		err = errors.Join(err, file.Close())
+	}
+
+	return err
+}
+
+func closeMemory(memory []*Memory) error {
+	var err error
+	for _, m := range memory {
+		err = errors.Join(err, m.Close())
+	}
+
+	return err
+}
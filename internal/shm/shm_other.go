@@ -0,0 +1,32 @@
+//go:build !linux
+
+package shm
+
+import (
+	"context"
+	"io"
+	"net"
+)
+
+// Supported is true if shared memory can be used on the current platform.
+const Supported = false
+
+// New returns ErrUnsupported, as shared memory is only supported on Linux.
+func New(int) (*Memory, error) {
+	return nil, ErrUnsupported
+}
+
+// Close the memory. As shared memory cannot be created on the current platform, this does nothing.
+func (m *Memory) Close() error {
+	return nil
+}
+
+// Send returns ErrUnsupported, as shared memory is only supported on Linux.
+func Send(context.Context, string, string, []*Memory) ([]string, io.Closer, error) {
+	return nil, nil, ErrUnsupported
+}
+
+// Serve returns ErrUnsupported, as shared memory is only supported on Linux.
+func Serve(context.Context, net.Listener, string, *Store) error {
+	return ErrUnsupported
+}
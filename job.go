@@ -0,0 +1,265 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The AsyncCommand type is a CommandHandler implementation for long-running commands that are intended to be
+	// executed as jobs using Plugin.Submit. It behaves like the Command type, but its Run function is also provided a
+	// ProgressFunc used to report progress to the host application. AsyncCommand implementations can still be
+	// executed using Plugin.Exec, in which case reported progress is discarded.
+	AsyncCommand[Input, Output proto.Message] struct {
+		// Use describes the name of the command.
+		Use string
+		// Description is a human-readable description of what the command does.
+		Description string
+		// Example gives hints on how the command should be used, such as an example input.
+		Example string
+		// Deprecated marks the command as one that should no longer be used.
+		Deprecated bool
+		// Idempotent marks the command as one that can safely be executed again after a transient failure, allowing
+		// host applications to retry it.
+		Idempotent bool
+		// RateLimit limits how often the command can be executed. Executions that exceed the limit fail with the
+		// codes.ResourceExhausted status code. By default, executions are not limited.
+		RateLimit RateLimit
+		// MaxInputSize is the maximum size, in bytes, of the encoded input the command accepts. Executions whose input
+		// exceeds it are rejected with the codes.ResourceExhausted status code before the command is run, independently
+		// of Config.MaxMessageSize. By default, the size of the input is not limited.
+		MaxInputSize int
+		// Run is a function that is invoked when the plugin receives a request to execute the command.
+		Run func(ctx context.Context, input Input, progress ProgressFunc) (Output, error)
+	}
+
+	// The ProgressFunc type is a function used by an AsyncCommand to report its progress, between zero and one,
+	// alongside a message describing what it is doing.
+	ProgressFunc func(progress float64, message string)
+
+	// The Job type represents a command executing in the background within a plugin, started using Plugin.Submit.
+	Job struct {
+		id      string
+		command string
+		plugin  *Plugin
+		client  *plugin.Client
+		release func()
+		once    sync.Once
+	}
+
+	// The JobState type describes the lifecycle of a Job.
+	JobState int
+
+	// The JobStatus type describes the current state of a Job.
+	JobStatus struct {
+		// The current state of the job.
+		State JobState
+		// The progress reported by the command, between zero and one.
+		Progress float64
+		// The most recent message reported by the command.
+		Message string
+		// When the job was submitted.
+		CreatedAt time.Time
+		// When the job completed. This is the zero time while the job is running.
+		CompletedAt time.Time
+	}
+)
+
+const (
+	// JobRunning is the JobState of a Job whose command is executing.
+	JobRunning = JobState(plugin.JobRunning)
+	// JobSucceeded is the JobState of a Job whose command completed successfully.
+	JobSucceeded = JobState(plugin.JobSucceeded)
+	// JobFailed is the JobState of a Job whose command returned an error.
+	JobFailed = JobState(plugin.JobFailed)
+	// JobCancelled is the JobState of a Job that was cancelled before its command completed.
+	JobCancelled = JobState(plugin.JobCancelled)
+)
+
+const (
+	minJobPollInterval = 10 * time.Millisecond
+	maxJobPollInterval = time.Second
+)
+
+// ErrJobCancelled is the error returned by Job.Wait when the Job was cancelled before its command completed.
+var ErrJobCancelled = errors.New("job cancelled")
+
+// Name returns the name of the command.
+func (ch AsyncCommand[Input, Output]) Name() string {
+	return ch.Use
+}
+
+func (ch AsyncCommand[Input, Output]) info() CommandInfo {
+	return CommandInfo{
+		Name:        ch.Use,
+		Description: ch.Description,
+		Example:     ch.Example,
+		Deprecated:  ch.Deprecated,
+		Idempotent:  ch.Idempotent,
+	}
+}
+
+func (ch AsyncCommand[Input, Output]) rateLimit() RateLimit {
+	return ch.RateLimit
+}
+
+func (ch AsyncCommand[Input, Output]) maxInputSize() int {
+	return ch.MaxInputSize
+}
+
+func (ch AsyncCommand[Input, Output]) validate() error {
+	if ch.Run == nil {
+		return fmt.Errorf("command %q has no Run function", ch.Use)
+	}
+
+	return nil
+}
+
+func (ch AsyncCommand[Input, Output]) messages() (protoreflect.MessageDescriptor, protoreflect.MessageDescriptor) {
+	return messageDescriptor[Input](), messageDescriptor[Output]()
+}
+
+// Execute the command. This method handles all conversions from the protobuf Any type to those specified by the
+// parameterized types provided by plugin authors.
+func (ch AsyncCommand[Input, Output]) Execute(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+	progress := ProgressFunc(plugin.ProgressFromContext(ctx))
+
+	return Command[Input, Output]{
+		Use: ch.Use,
+		Run: func(ctx context.Context, input Input) (Output, error) {
+			return ch.Run(ctx, input, progress)
+		},
+	}.Execute(ctx, input)
+}
+
+// String returns a human-readable representation of the JobState.
+func (s JobState) String() string {
+	switch s {
+	case JobRunning:
+		return "running"
+	case JobSucceeded:
+		return "succeeded"
+	case JobFailed:
+		return "failed"
+	case JobCancelled:
+		return "cancelled"
+	default:
+		return "unknown"
+	}
+}
+
+// Done returns true if the JobState is terminal, meaning the job is no longer executing.
+func (s JobState) Done() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// Submit the named command for execution as a Job, providing a proto-encoded input. Unlike Plugin.Exec, Submit returns
+// as soon as the plugin has accepted the command, which continues to execute in the background until it completes or
+// is cancelled via Job.Cancel. Use Job.Wait to obtain its output. Returns ErrUnknownCommand if the specified command
+// is unknown to the plugin.
+//
+// The plugin is not stopped due to inactivity while a Job is running, until either Job.Wait returns or Job.Cancel
+// is called.
+func (p *Plugin) Submit(ctx context.Context, name string, input proto.Message) (*Job, error) {
+	proc, release, err := p.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := proc.client.SubmitJob(ctx, name, input)
+	if err != nil {
+		release()
+		return nil, convertError(ctx, name, err)
+	}
+
+	return &Job{
+		id:      id,
+		command: name,
+		plugin:  p,
+		client:  proc.client,
+		release: release,
+	}, nil
+}
+
+// ID returns the unique identifier of the Job.
+func (j *Job) ID() string {
+	return j.id
+}
+
+// Command returns the name of the command the Job is executing.
+func (j *Job) Command() string {
+	return j.command
+}
+
+// Status returns the current state of the Job.
+func (j *Job) Status(ctx context.Context) (JobStatus, error) {
+	js, err := j.client.GetJob(ctx, j.id)
+	if err != nil {
+		return JobStatus{}, convertError(ctx, j.command, err)
+	}
+
+	return JobStatus{
+		State:       JobState(js.State),
+		Progress:    js.Progress,
+		Message:     js.Message,
+		CreatedAt:   js.CreatedAt,
+		CompletedAt: js.CompletedAt,
+	}, nil
+}
+
+// Wait for the Job to complete, polling the plugin for its state. Once complete, the output of the command is
+// unmarshalled into the provided output parameter. If the command failed, its error is returned as it would be by
+// Plugin.Exec. Returns ErrJobCancelled if the Job was cancelled. Cancelling the provided context stops waiting but
+// does not cancel the Job.
+func (j *Job) Wait(ctx context.Context, output proto.Message) error {
+	interval := minJobPollInterval
+	for {
+		js, err := j.client.GetJob(ctx, j.id)
+		if err != nil {
+			return convertError(ctx, j.command, err)
+		}
+
+		switch JobState(js.State) {
+		case JobSucceeded:
+			j.done()
+			return js.Output.UnmarshalTo(output)
+		case JobFailed:
+			j.done()
+			return convertError(ctx, j.command, js.Err)
+		case JobCancelled:
+			j.done()
+			return fmt.Errorf("%w: %q", ErrJobCancelled, j.id)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-j.plugin.options.clock.After(interval):
+			interval = min(interval*2, maxJobPollInterval)
+		}
+	}
+}
+
+// Cancel the Job. The context provided to the command within the plugin is cancelled. Cancelling a Job that has
+// already completed has no effect.
+func (j *Job) Cancel(ctx context.Context) error {
+	if err := j.client.CancelJob(ctx, j.id); err != nil {
+		return convertError(ctx, j.command, err)
+	}
+
+	j.done()
+	return nil
+}
+
+func (j *Job) done() {
+	j.once.Do(j.release)
+}
@@ -0,0 +1,83 @@
+package plugin_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestPlugin_Submit(t *testing.T) {
+	p, err := plugin.Use(t.Context(), "./test_plugin")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	t.Run("waits for job output", func(t *testing.T) {
+		job, err := p.Submit(t.Context(), "countdown", durationpb.New(200*time.Millisecond))
+		require.NoError(t, err)
+		assert.NotEmpty(t, job.ID())
+		assert.EqualValues(t, "countdown", job.Command())
+
+		status, err := job.Status(t.Context())
+		require.NoError(t, err)
+		assert.EqualValues(t, plugin.JobRunning, status.State)
+
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, job.Wait(t.Context(), output))
+		assert.EqualValues(t, "liftoff", output.GetValue())
+
+		status, err = job.Status(t.Context())
+		require.NoError(t, err)
+		assert.EqualValues(t, plugin.JobSucceeded, status.State)
+		assert.EqualValues(t, 1, status.Progress)
+		assert.EqualValues(t, "0 remaining", status.Message)
+		assert.False(t, status.CompletedAt.IsZero())
+	})
+
+	t.Run("reports progress", func(t *testing.T) {
+		job, err := p.Submit(t.Context(), "countdown", durationpb.New(time.Second))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, job.Cancel(context.Background()))
+		})
+
+		assert.Eventually(t, func() bool {
+			status, err := job.Status(t.Context())
+			require.NoError(t, err)
+			return status.Progress > 0 && status.State == plugin.JobRunning
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("job can be cancelled", func(t *testing.T) {
+		job, err := p.Submit(t.Context(), "countdown", durationpb.New(time.Hour))
+		require.NoError(t, err)
+		require.NoError(t, job.Cancel(t.Context()))
+
+		err = job.Wait(t.Context(), &wrapperspb.StringValue{})
+		assert.ErrorIs(t, err, plugin.ErrJobCancelled)
+	})
+
+	t.Run("job errors are returned", func(t *testing.T) {
+		job, err := p.Submit(t.Context(), "pingpong", wrapperspb.String("pung"))
+		require.NoError(t, err)
+
+		var pluginErr *plugin.Error
+		err = job.Wait(t.Context(), &wrapperspb.StringValue{})
+		require.ErrorAs(t, err, &pluginErr)
+		assert.EqualValues(t, `invalid input "pung", expected "ping" or "pong"`, pluginErr.Message)
+	})
+
+	t.Run("error if unknown command", func(t *testing.T) {
+		_, err = p.Submit(t.Context(), "unknown", wrapperspb.String("ping"))
+		assert.ErrorIs(t, err, plugin.ErrUnknownCommand)
+	})
+}
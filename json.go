@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+var (
+	// ErrInvalidJSON is the error returned by Plugin.ExecJSON when the input cannot be decoded into the command's input
+	// message.
+	ErrInvalidJSON = errors.New("invalid JSON input")
+
+	// ErrUndescribedCommand is the error returned by Plugin.ExecJSON when the plugin does not describe the input and
+	// output messages of the command, as is the case for CommandHandler implementations other than Command,
+	// AsyncCommand and RawCommand.
+	ErrUndescribedCommand = errors.New("command messages are not described")
+)
+
+// ExecJSON executes the named command using an input encoded as protobuf JSON, returning the output encoded in the same
+// way. The messages used by the command are obtained from the plugin using Plugin.Describe, so host applications do not
+// require compiled types for the command's messages. This is useful when command input is untyped, such as when it is
+// taken from configuration files or HTTP requests.
+//
+// Returns ErrUnknownCommand if the command does not exist, ErrUndescribedCommand if the plugin does not describe its
+// messages and ErrInvalidJSON if the input does not match the command's input message. Otherwise, errors are returned
+// as they would be by Plugin.Exec.
+func (p *Plugin) ExecJSON(ctx context.Context, name string, input []byte) ([]byte, error) {
+	descriptor, err := p.commandDescriptor(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	in := dynamicpb.NewMessage(descriptor.Input)
+	if err = protojson.Unmarshal(input, in); err != nil {
+		return nil, fmt.Errorf("%w for command %q: %w", ErrInvalidJSON, name, err)
+	}
+
+	out := dynamicpb.NewMessage(descriptor.Output)
+	if err = p.Exec(ctx, name, in, out); err != nil {
+		return nil, err
+	}
+
+	return protojson.Marshal(out)
+}
+
+// commandDescriptor returns the descriptor of the named command, querying the plugin the first time descriptors are
+// required by the running processes.
+func (p *Plugin) commandDescriptor(ctx context.Context, name string) (CommandDescriptor, error) {
+	pl, err := p.start(ctx)
+	if err != nil {
+		return CommandDescriptor{}, err
+	}
+
+	pl.describeMu.Lock()
+	defer pl.describeMu.Unlock()
+
+	if pl.descriptors == nil {
+		commands, err := p.Describe(ctx)
+		if err != nil {
+			return CommandDescriptor{}, err
+		}
+
+		pl.descriptors = make(map[string]CommandDescriptor, len(commands))
+		for _, command := range commands {
+			pl.descriptors[command.Name] = command
+		}
+	}
+
+	descriptor, ok := pl.descriptors[name]
+	switch {
+	case !ok:
+		return CommandDescriptor{}, fmt.Errorf("%w: %q", ErrUnknownCommand, name)
+	case descriptor.Input == nil || descriptor.Output == nil:
+		return CommandDescriptor{}, fmt.Errorf("%w: %q", ErrUndescribedCommand, name)
+	default:
+		return descriptor, nil
+	}
+}
@@ -0,0 +1,181 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The brokerHandler type is a slog.Handler that sends log records to the host application via its HostService,
+	// falling back to writing them to stderr if the host application does not provide a logger.
+	brokerHandler struct {
+		broker   *plugin.Broker
+		fallback slog.Handler
+		attrs    []*pb.LogAttr
+		prefix   string
+	}
+)
+
+// WithLogger is a UseOption that provides a logger to the plugin. Records logged by commands using the logger returned
+// by LoggerFromContext are written to it, with a "plugin" attribute naming the plugin, rather than to the stderr of
+// the plugin process.
+func WithLogger(logger *slog.Logger) UseOption {
+	return func(o *useOptions) {
+		o.logger = logger
+	}
+}
+
+// LoggerFromContext returns a structured logger for use within the context given to a command or to Config.Configure.
+// Records are written to the logger the host application provided using WithLogger, so that they are handled by the
+// logging pipeline of the host application. If the host application did not provide a logger, records are written to
+// stderr.
+func LoggerFromContext(ctx context.Context) *slog.Logger {
+	return slog.New(&brokerHandler{
+		broker:   plugin.BrokerFromContext(ctx),
+		fallback: slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}),
+	})
+}
+
+// Enabled returns true, as records are filtered by the logger of the host application.
+func (h *brokerHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+// Handle sends the record to the host application.
+func (h *brokerHandler) Handle(ctx context.Context, record slog.Record) error {
+	request := &pb.LogRequest{
+		Time:    timestamppb.New(record.Time),
+		Level:   int64(record.Level),
+		Message: record.Message,
+		Attrs:   h.attrs,
+	}
+
+	record.Attrs(func(attr slog.Attr) bool {
+		request.Attrs = appendAttr(request.Attrs, h.prefix, attr)
+		return true
+	})
+
+	input, err := anypb.New(request)
+	if err != nil {
+		return err
+	}
+
+	// Records are logged even if the call they describe has been cancelled.
+	_, err = h.broker.Call(context.WithoutCancel(ctx), pb.HostService_Log_FullMethodName, input)
+	if errors.Is(err, plugin.ErrNoBroker) {
+		return h.fallback.Handle(ctx, record)
+	}
+
+	return err
+}
+
+// WithAttrs returns a handler that includes the attributes within each record.
+func (h *brokerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handler := *h
+	handler.fallback = h.fallback.WithAttrs(attrs)
+	handler.attrs = append([]*pb.LogAttr(nil), h.attrs...)
+	for _, attr := range attrs {
+		handler.attrs = appendAttr(handler.attrs, h.prefix, attr)
+	}
+
+	return &handler
+}
+
+// WithGroup returns a handler that qualifies the keys of subsequent attributes with the name of the group.
+func (h *brokerHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	handler := *h
+	handler.fallback = h.fallback.WithGroup(name)
+	handler.prefix = h.prefix + name + "."
+	return &handler
+}
+
+func appendAttr(attrs []*pb.LogAttr, prefix string, attr slog.Attr) []*pb.LogAttr {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return attrs
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		// Attributes of groups without a key are inlined, as they are by the handlers of the slog package.
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+
+		for _, a := range attr.Value.Group() {
+			attrs = appendAttr(attrs, prefix, a)
+		}
+
+		return attrs
+	}
+
+	return append(attrs, &pb.LogAttr{Key: prefix + attr.Key, Value: logValue(attr.Value)})
+}
+
+func logValue(value slog.Value) *structpb.Value {
+	switch value.Kind() {
+	case slog.KindString:
+		return structpb.NewStringValue(value.String())
+	case slog.KindInt64:
+		return structpb.NewNumberValue(float64(value.Int64()))
+	case slog.KindUint64:
+		return structpb.NewNumberValue(float64(value.Uint64()))
+	case slog.KindFloat64:
+		return structpb.NewNumberValue(value.Float64())
+	case slog.KindBool:
+		return structpb.NewBoolValue(value.Bool())
+	case slog.KindDuration:
+		return structpb.NewStringValue(value.Duration().String())
+	case slog.KindTime:
+		return structpb.NewStringValue(value.Time().Format(time.RFC3339Nano))
+	}
+
+	if v, err := structpb.NewValue(value.Any()); err == nil {
+		return v
+	}
+
+	return structpb.NewStringValue(fmt.Sprint(value.Any()))
+}
+
+// logHandler returns the function that handles records logged by the plugin via LoggerFromContext, writing them to
+// the logger given via WithLogger.
+func (p *Plugin) logHandler() func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+	logger := p.options.logger.With("plugin", p.name)
+
+	return func(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+		request := &pb.LogRequest{}
+		if err := input.UnmarshalTo(request); err != nil {
+			return nil, err
+		}
+
+		level := slog.Level(request.GetLevel())
+		if !logger.Enabled(ctx, level) {
+			return anypb.New(&pb.LogResponse{})
+		}
+
+		record := slog.NewRecord(request.GetTime().AsTime(), level, request.GetMessage(), 0)
+		for _, attr := range request.GetAttrs() {
+			record.AddAttrs(slog.Any(attr.GetKey(), attr.GetValue().AsInterface()))
+		}
+
+		if err := logger.Handler().Handle(ctx, record); err != nil {
+			return nil, err
+		}
+
+		return anypb.New(&pb.LogResponse{})
+	}
+}
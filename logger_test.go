@@ -0,0 +1,92 @@
+package plugin_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestWithLogger(t *testing.T) {
+	t.Parallel()
+
+	config := plugin.Config{
+		Name: "logging",
+		Configure: func(ctx context.Context, _ *anypb.Any) error {
+			plugin.LoggerFromContext(ctx).Debug("configured")
+			return nil
+		},
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{
+				Use: "log",
+				Run: func(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+					logger := plugin.LoggerFromContext(ctx).With("tenant", "a").WithGroup("request")
+					logger.Info("handled", "id", 123, slog.Group("user", "name", "bob"))
+					logger.Error("failed", "retry", true)
+					return &emptypb.Empty{}, nil
+				},
+			},
+		},
+	}
+
+	tt := []struct {
+		Name     string
+		Level    slog.Level
+		Expected []map[string]any
+	}{
+		{
+			Name:  "writes records to host logger",
+			Level: slog.LevelDebug,
+			Expected: []map[string]any{
+				{"level": "DEBUG", "msg": "configured", "plugin": "logging"},
+				{"level": "INFO", "msg": "handled", "plugin": "logging", "tenant": "a", "request.id": float64(123), "request.user.name": "bob"},
+				{"level": "ERROR", "msg": "failed", "plugin": "logging", "tenant": "a", "request.retry": true},
+			},
+		},
+		{
+			Name:  "host logger filters records",
+			Level: slog.LevelError,
+			Expected: []map[string]any{
+				{"level": "ERROR", "msg": "failed", "plugin": "logging", "tenant": "a", "request.retry": true},
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var output bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{
+				Level: tc.Level,
+				ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+					if attr.Key == slog.TimeKey {
+						return slog.Attr{}
+					}
+
+					return attr
+				},
+			}))
+
+			p := plugintest.New(t, config, plugin.WithLogger(logger))
+			require.NoError(t, p.Exec(t.Context(), "log", &emptypb.Empty{}, &emptypb.Empty{}))
+
+			var actual []map[string]any
+			decoder := json.NewDecoder(&output)
+			for decoder.More() {
+				record := make(map[string]any)
+				require.NoError(t, decoder.Decode(&record))
+				actual = append(actual, record)
+			}
+
+			assert.EqualValues(t, tc.Expected, actual)
+		})
+	}
+}
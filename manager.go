@@ -0,0 +1,317 @@
+package plugin
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	// The Manager type is used by host applications to manage many plugins at once. Plugins are added to the Manager
+	// once started via Use and can then be retrieved by name or selected by the commands they provide. Any Client can
+	// be managed, allowing host code that uses a Manager to be tested using plugintest.Fake.
+	Manager struct {
+		mu           sync.RWMutex
+		plugins      []*managedPlugin
+		interceptors []ExecInterceptor
+	}
+
+	// The ManageOption type is a function that modifies how a plugin is managed when calling Manager.Add.
+	ManageOption func(mp *managedPlugin)
+
+	managedPlugin struct {
+		plugin   Client
+		priority int
+		aliases  []string
+	}
+)
+
+var (
+	// ErrDuplicatePlugin is the error returned by Manager.Add and Manager.Alias when a plugin with the same name or
+	// alias is already managed.
+	ErrDuplicatePlugin = errors.New("duplicate plugin")
+
+	// ErrNoPlugin is the error returned by Manager.ExecAny and Manager.Exec when no managed plugin is able to execute a
+	// command.
+	ErrNoPlugin = errors.New("no plugin available")
+
+	// ErrAmbiguousCommand is the error returned by Manager.Exec when a command is not qualified with the name of a
+	// plugin and more than one managed plugin provides it.
+	ErrAmbiguousCommand = errors.New("ambiguous command")
+
+	// ErrInvalidName is the error returned by Manager.Add and Manager.Alias when the name or an alias of a plugin, or
+	// the name of one of its commands, contains the CommandSeparator, as it could not be addressed by Manager.Exec.
+	ErrInvalidName = errors.New("invalid name")
+)
+
+// CommandSeparator separates the name of a plugin from the name of one of its commands within the qualified command
+// names accepted by Manager.Exec, such as "backup/snapshot".
+const CommandSeparator = "/"
+
+// NewManager returns a new instance of the Manager type that contains no plugins.
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// WithPriority is a ManageOption that sets the priority of a plugin. When more than one plugin provides the same
+// command, Manager.ExecAny prefers plugins with a higher priority. Plugins have a priority of zero by default.
+func WithPriority(priority int) ManageOption {
+	return func(mp *managedPlugin) {
+		mp.priority = priority
+	}
+}
+
+// WithAliases is a ManageOption that adds alternate names for a plugin, such as "s3" for "aws-s3-backup". Aliases are
+// resolved by every Manager method that looks up plugins by name, such as Manager.Get and Manager.Exec, so that
+// references to a plugin continue to work once it has been renamed.
+func WithAliases(aliases ...string) ManageOption {
+	return func(mp *managedPlugin) {
+		mp.aliases = append(mp.aliases, aliases...)
+	}
+}
+
+// Add a Client, such as a *Plugin, to the Manager. Returns ErrDuplicatePlugin if a plugin with the same name has
+// already been added, or if its name or any of its aliases is already used by another plugin. Returns ErrInvalidName
+// if its name, any of its aliases or the name of any of its commands contains the CommandSeparator. Once added, the
+// Manager is responsible for closing the Client when Manager.Close is called.
+func (m *Manager) Add(p Client, opts ...ManageOption) error {
+	mp := &managedPlugin{plugin: p}
+	for _, opt := range opts {
+		opt(mp)
+	}
+
+	for _, name := range append([]string{p.Name()}, mp.aliases...) {
+		if err := checkName("plugin", name); err != nil {
+			return err
+		}
+	}
+
+	for _, command := range p.Commands() {
+		if err := checkName("command", command.Name); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, name := range append([]string{p.Name()}, mp.aliases...) {
+		if _, ok := m.lookup(name); ok {
+			return fmt.Errorf("%w: %q", ErrDuplicatePlugin, name)
+		}
+	}
+
+	m.plugins = append(m.plugins, mp)
+	return nil
+}
+
+// Alias adds an alternate name for the named plugin, as described by WithAliases. Returns ErrNoPlugin if no plugin
+// with the given name has been added to the Manager, ErrDuplicatePlugin if the alias is already the name or alias
+// of a plugin, and ErrInvalidName if the alias contains the CommandSeparator.
+func (m *Manager) Alias(alias, name string) error {
+	if err := checkName("plugin", alias); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	mp, ok := m.lookup(name)
+	if !ok {
+		return fmt.Errorf("%w: no plugin named %q", ErrNoPlugin, name)
+	}
+
+	if _, ok = m.lookup(alias); ok {
+		return fmt.Errorf("%w: %q", ErrDuplicatePlugin, alias)
+	}
+
+	mp.aliases = append(mp.aliases, alias)
+	return nil
+}
+
+// Aliases returns the alternate names of the named plugin, in the order they were added. Returns nil if no plugin with
+// the given name has been added to the Manager.
+func (m *Manager) Aliases(name string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mp, ok := m.lookup(name)
+	if !ok {
+		return nil
+	}
+
+	return slices.Clone(mp.aliases)
+}
+
+// Get the plugin with the given name or alias. Returns false if no such plugin has been added to the Manager.
+func (m *Manager) Get(name string) (Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	mp, ok := m.lookup(name)
+	if !ok {
+		return nil, false
+	}
+
+	return mp.plugin, true
+}
+
+// checkName returns ErrInvalidName if the name of a plugin or command contains the CommandSeparator.
+func checkName(kind, name string) error {
+	if strings.Contains(name, CommandSeparator) {
+		return fmt.Errorf("%w: %s name %q must not contain %q", ErrInvalidName, kind, name, CommandSeparator)
+	}
+
+	return nil
+}
+
+// lookup returns the managed plugin with the given name or alias. The caller must hold the mu.
+func (m *Manager) lookup(name string) (*managedPlugin, bool) {
+	for _, mp := range m.plugins {
+		if mp.plugin.Name() == name || slices.Contains(mp.aliases, name) {
+			return mp, true
+		}
+	}
+
+	return nil, false
+}
+
+// Plugins returns all plugins that have been added to the Manager that satisfy all the provided filters, in the
+// order they were added.
+func (m *Manager) Plugins(filters ...PluginFilter) []Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	plugins := make([]Client, 0, len(m.plugins))
+	for _, mp := range m.plugins {
+		if selected(mp.plugin, filters) {
+			plugins = append(plugins, mp.plugin)
+		}
+	}
+
+	return plugins
+}
+
+func selected(p Client, filters []PluginFilter) bool {
+	for _, filter := range filters {
+		if !filter(p) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Close all plugins within the Manager. Plugins are closed in the reverse order to which they were added, so plugins
+// started via Manager.Start are closed before the plugins they depend on.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var err error
+	for _, mp := range slices.Backward(m.plugins) {
+		err = errors.Join(err, mp.plugin.Close())
+	}
+
+	m.plugins = nil
+	return err
+}
+
+func (m *Manager) remove(p Client) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.plugins = slices.DeleteFunc(m.plugins, func(mp *managedPlugin) bool {
+		return mp.plugin == p
+	})
+}
+
+// ExecAny executes the named command on any plugin that provides it. Plugins are tried in order of priority, with
+// plugins of equal priority tried in the order they were added. If a plugin cannot be reached, has been closed or its
+// circuit breaker is open, the next plugin that provides the command is tried. Returns ErrNoPlugin if no plugin
+// provides the command or if all plugins that do are unavailable. Any other error returned by a plugin is returned as
+// it would be by Plugin.Exec. The provided ExecOption functions apply to the call made to each plugin.
+func (m *Manager) ExecAny(ctx context.Context, command string, input proto.Message, output proto.Message, opts ...ExecOption) error {
+	candidates := m.candidates(command)
+	if len(candidates) == 0 {
+		return fmt.Errorf("%w: no plugin provides command %q", ErrNoPlugin, command)
+	}
+
+	var errs error
+	for _, p := range candidates {
+		err := m.invoker(p)(ctx, command, input, output, opts...)
+		if status.Code(err) == codes.Unavailable || errors.Is(err, ErrClosed) || errors.Is(err, ErrCircuitOpen) {
+			errs = errors.Join(errs, fmt.Errorf("plugin %q: %w", p.Name(), err))
+			continue
+		}
+
+		return err
+	}
+
+	return fmt.Errorf("%w: all plugins providing command %q are unavailable: %w", ErrNoPlugin, command, errs)
+}
+
+// Exec executes a command addressed by its qualified name, such as "backup/snapshot" for the "snapshot" command of the
+// "backup" plugin. The plugin may be given by its name or any of its aliases. Returns ErrNoPlugin if no plugin with
+// the given name has been added to the Manager. Errors returned by the plugin, such as ErrUnknownCommand, are
+// returned as they would be by Plugin.Exec.
+//
+// The command may also be given without the name of a plugin, in which case it is executed by the only plugin that
+// provides it. Returns ErrNoPlugin if no plugin provides the command, and ErrAmbiguousCommand if more than one plugin
+// does, regardless of their priority. Use Manager.ExecAny to execute commands that many plugins provide.
+func (m *Manager) Exec(ctx context.Context, command string, input proto.Message, output proto.Message, opts ...ExecOption) error {
+	if name, command, ok := strings.Cut(command, CommandSeparator); ok {
+		p, ok := m.Get(name)
+		if !ok {
+			return fmt.Errorf("%w: no plugin named %q", ErrNoPlugin, name)
+		}
+
+		return m.invoker(p)(ctx, command, input, output, opts...)
+	}
+
+	candidates := m.candidates(command)
+	switch len(candidates) {
+	case 0:
+		return fmt.Errorf("%w: no plugin provides command %q", ErrNoPlugin, command)
+	case 1:
+		return m.invoker(candidates[0])(ctx, command, input, output, opts...)
+	default:
+		names := make([]string, len(candidates))
+		for i, p := range candidates {
+			names[i] = p.Name()
+		}
+
+		return fmt.Errorf("%w: command %q is provided by plugins %q", ErrAmbiguousCommand, command, names)
+	}
+}
+
+func (m *Manager) candidates(command string) []Client {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	candidates := make([]*managedPlugin, 0)
+	for _, mp := range m.plugins {
+		if mp.plugin.HasCommand(command) {
+			candidates = append(candidates, mp)
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b *managedPlugin) int {
+		return cmp.Compare(b.priority, a.priority)
+	})
+
+	plugins := make([]Client, len(candidates))
+	for i, mp := range candidates {
+		plugins[i] = mp.plugin
+	}
+
+	return plugins
+}
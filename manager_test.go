@@ -0,0 +1,251 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestManager(t *testing.T) {
+	manager := plugin.NewManager()
+	t.Cleanup(func() {
+		assert.NoError(t, manager.Close())
+	})
+
+	low, err := plugin.Use(t.Context(), "./test_plugin")
+	require.NoError(t, err)
+	require.NoError(t, manager.Add(low))
+
+	high, err := plugin.Use(t.Context(), copyPlugin(t, "other_plugin"))
+	require.NoError(t, err)
+	require.NoError(t, manager.Add(high, plugin.WithPriority(10)))
+
+	t.Run("error if plugin already added", func(t *testing.T) {
+		err = manager.Add(low)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plugin.ErrDuplicatePlugin))
+	})
+
+	t.Run("gets plugins by name", func(t *testing.T) {
+		p, ok := manager.Get("other_plugin")
+		require.True(t, ok)
+		assert.Equal(t, high, p)
+
+		_, ok = manager.Get("unknown")
+		assert.False(t, ok)
+
+		assert.Equal(t, []plugin.Client{low, high}, manager.Plugins())
+	})
+
+	t.Run("executes command on highest priority plugin", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, manager.ExecAny(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+
+		assert.EqualValues(t, "pong", output.GetValue())
+		assert.EqualValues(t, 1, high.Stats().Commands["pingpong"].Calls)
+		assert.Empty(t, low.Stats().Commands)
+	})
+
+	t.Run("returns command errors", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		err = manager.ExecAny(t.Context(), "pingpong", wrapperspb.String("pung"), output)
+
+		require.Error(t, err)
+		assert.False(t, errors.Is(err, plugin.ErrNoPlugin))
+		assert.Empty(t, low.Stats().Commands)
+	})
+
+	t.Run("error if no plugin provides command", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		err = manager.ExecAny(t.Context(), "unknown", wrapperspb.String("ping"), output)
+
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plugin.ErrNoPlugin))
+	})
+}
+
+func TestManager_LazyPlugin(t *testing.T) {
+	manager := plugin.NewManager()
+	t.Cleanup(func() {
+		assert.NoError(t, manager.Close())
+	})
+
+	undeclared, err := plugin.Use(t.Context(), copyPlugin(t, "undeclared_plugin"), plugin.WithLazyStart())
+	require.NoError(t, err)
+	require.NoError(t, manager.Add(undeclared, plugin.WithPriority(10)))
+
+	declared, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithLazyStart(), plugin.WithCommands("pingpong"))
+	require.NoError(t, err)
+	require.NoError(t, manager.Add(declared))
+
+	assert.True(t, declared.HasCommand("pingpong"))
+	assert.Empty(t, declared.Version())
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, manager.ExecAny(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+	assert.EqualValues(t, "pong", output.GetValue())
+
+	assert.EqualValues(t, 1, declared.Stats().Commands["pingpong"].Calls)
+	assert.NotEmpty(t, declared.Version())
+	assert.Equal(t, []string{"pingpong", "sleep", "hang", "crash", "countdown", "pid"}, commandNames(declared.Commands()))
+
+	assert.Empty(t, undeclared.Version())
+	assert.Empty(t, undeclared.Commands())
+}
+
+func copyPlugin(t *testing.T, name string) string {
+	t.Helper()
+
+	src, err := os.Open("./test_plugin")
+	require.NoError(t, err)
+	defer src.Close()
+
+	path := filepath.Join(t.TempDir(), name)
+	dst, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o755)
+	require.NoError(t, err)
+	defer dst.Close()
+
+	_, err = io.Copy(dst, src)
+	require.NoError(t, err)
+	return path
+}
+
+func TestManager_Exec(t *testing.T) {
+	t.Parallel()
+
+	echo := func(prefix string) plugintest.HandlerFunc {
+		return func(_ context.Context, input proto.Message) (proto.Message, error) {
+			return wrapperspb.String(prefix + input.(*wrapperspb.StringValue).GetValue()), nil
+		}
+	}
+
+	manager := plugin.NewManager()
+	require.NoError(t, manager.Add(plugintest.NewFake("backup", "v1.0.0").
+		Handle("snapshot", echo("backup: ")).
+		Handle("restore", echo("restore: "))))
+	require.NoError(t, manager.Add(plugintest.NewFake("archive", "v1.0.0").
+		Handle("snapshot", echo("archive: "))))
+
+	tt := []struct {
+		Name     string
+		Command  string
+		Expected string
+		Err      error
+	}{
+		{
+			Name:     "executes qualified commands",
+			Command:  "archive/snapshot",
+			Expected: "archive: input",
+		},
+		{
+			Name:     "executes commands provided by a single plugin",
+			Command:  "restore",
+			Expected: "restore: input",
+		},
+		{
+			Name:    "error for ambiguous commands",
+			Command: "snapshot",
+			Err:     plugin.ErrAmbiguousCommand,
+		},
+		{
+			Name:    "error for unknown plugins",
+			Command: "unknown/snapshot",
+			Err:     plugin.ErrNoPlugin,
+		},
+		{
+			Name:    "error for unknown commands",
+			Command: "archive/restore",
+			Err:     plugin.ErrUnknownCommand,
+		},
+		{
+			Name:    "error if no plugin provides the command",
+			Command: "unknown",
+			Err:     plugin.ErrNoPlugin,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			output := &wrapperspb.StringValue{}
+			err := manager.Exec(t.Context(), tc.Command, wrapperspb.String("input"), output)
+			if tc.Err != nil {
+				require.ErrorIs(t, err, tc.Err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.EqualValues(t, tc.Expected, output.GetValue())
+		})
+	}
+}
+
+func TestManager_Aliases(t *testing.T) {
+	t.Parallel()
+
+	echo := func(_ context.Context, input proto.Message) (proto.Message, error) {
+		return input, nil
+	}
+
+	manager := plugin.NewManager()
+	backup := plugintest.NewFake("aws-s3-backup", "v1.0.0").Handle("snapshot", echo)
+	require.NoError(t, manager.Add(backup, plugin.WithAliases("s3")))
+	require.NoError(t, manager.Add(plugintest.NewFake("archive", "v1.0.0").Handle("snapshot", echo)))
+
+	t.Run("resolves aliases", func(t *testing.T) {
+		p, ok := manager.Get("s3")
+		require.True(t, ok)
+		assert.Equal(t, backup, p)
+	})
+
+	t.Run("routes commands using aliases", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, manager.Exec(t.Context(), "s3/snapshot", wrapperspb.String("hello"), output))
+		assert.EqualValues(t, "hello", output.GetValue())
+		assert.Len(t, backup.Calls(), 1)
+	})
+
+	t.Run("adds aliases to managed plugins", func(t *testing.T) {
+		require.NoError(t, manager.Alias("backup", "s3"))
+		assert.EqualValues(t, []string{"s3", "backup"}, manager.Aliases("aws-s3-backup"))
+
+		p, ok := manager.Get("backup")
+		require.True(t, ok)
+		assert.Equal(t, backup, p)
+	})
+
+	t.Run("error for aliases already in use", func(t *testing.T) {
+		assert.ErrorIs(t, manager.Alias("archive", "aws-s3-backup"), plugin.ErrDuplicatePlugin)
+		assert.ErrorIs(t, manager.Alias("s3", "archive"), plugin.ErrDuplicatePlugin)
+		assert.ErrorIs(t, manager.Add(plugintest.NewFake("s3", "v1.0.0")), plugin.ErrDuplicatePlugin)
+		assert.ErrorIs(t, manager.Add(plugintest.NewFake("other", "v1.0.0"), plugin.WithAliases("archive")), plugin.ErrDuplicatePlugin)
+	})
+
+	t.Run("error for names containing the separator", func(t *testing.T) {
+		assert.ErrorIs(t, manager.Alias("aws/s3", "archive"), plugin.ErrInvalidName)
+		assert.ErrorIs(t, manager.Add(plugintest.NewFake("aws/s3", "v1.0.0")), plugin.ErrInvalidName)
+		assert.ErrorIs(t, manager.Add(plugintest.NewFake("other", "v1.0.0"), plugin.WithAliases("aws/s3")), plugin.ErrInvalidName)
+		assert.ErrorIs(t, manager.Add(plugintest.NewFake("other", "v1.0.0").Handle("snapshot/full", echo)), plugin.ErrInvalidName)
+
+		_, ok := manager.Get("other")
+		assert.False(t, ok)
+		assert.Nil(t, manager.Aliases("archive"))
+	})
+
+	t.Run("error for unknown plugins", func(t *testing.T) {
+		assert.ErrorIs(t, manager.Alias("alias", "unknown"), plugin.ErrNoPlugin)
+		assert.Nil(t, manager.Aliases("unknown"))
+	})
+}
@@ -0,0 +1,23 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+// ContextWithMetadata returns a copy of the provided context that sends the given key/value metadata to the plugin
+// with any call made using it, such as Plugin.Exec or Plugin.Submit. Command handlers obtain the metadata using
+// MetadataFromContext. This can be used to provide per-call context, such as a tenant or request identifier, without
+// adding it to the command's input. Keys are case-insensitive and are converted to lower case. Calling
+// ContextWithMetadata more than once adds to the metadata, with later values taking precedence.
+func ContextWithMetadata(ctx context.Context, md map[string]string) context.Context {
+	return plugin.AppendMetadata(ctx, md)
+}
+
+// MetadataFromContext returns the metadata sent by the host application via ContextWithMetadata with the command being
+// handled. It should be called by command handlers using the context they are provided. Returns an empty map if no
+// metadata was sent.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	return plugin.MetadataFromContext(ctx)
+}
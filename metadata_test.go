@@ -0,0 +1,84 @@
+package plugin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestContextWithMetadata(t *testing.T) {
+	t.Parallel()
+
+	p := plugintest.New(t, plugin.Config{
+		Name: "metadata",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "get",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					return wrapperspb.String(plugin.MetadataFromContext(ctx)[input.GetValue()]), nil
+				},
+			},
+			&plugin.AsyncCommand[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "get-async",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue, _ plugin.ProgressFunc) (*wrapperspb.StringValue, error) {
+					return wrapperspb.String(plugin.MetadataFromContext(ctx)[input.GetValue()]), nil
+				},
+			},
+		},
+	})
+
+	ctx := plugin.ContextWithMetadata(t.Context(), map[string]string{"Tenant": "a", "request-id": "123"})
+	ctx = plugin.ContextWithMetadata(ctx, map[string]string{"tenant": "b"})
+
+	tt := []struct {
+		Name     string
+		Context  context.Context
+		Key      string
+		Expected string
+	}{
+		{
+			Name:     "provides metadata to handler",
+			Context:  ctx,
+			Key:      "request-id",
+			Expected: "123",
+		},
+		{
+			Name:     "later values take precedence",
+			Context:  ctx,
+			Key:      "tenant",
+			Expected: "b",
+		},
+		{
+			Name:    "missing without metadata",
+			Context: t.Context(),
+			Key:     "tenant",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			output := &wrapperspb.StringValue{}
+			require.NoError(t, p.Exec(tc.Context, "get", wrapperspb.String(tc.Key), output))
+			assert.EqualValues(t, tc.Expected, output.GetValue())
+		})
+	}
+
+	t.Run("provides metadata to jobs", func(t *testing.T) {
+		job, err := p.Submit(ctx, "get-async", wrapperspb.String("tenant"))
+		require.NoError(t, err)
+
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, job.Wait(t.Context(), output))
+		assert.EqualValues(t, "b", output.GetValue())
+	})
+
+	t.Run("empty outside of handlers", func(t *testing.T) {
+		assert.Empty(t, plugin.MetadataFromContext(ctx))
+	})
+}
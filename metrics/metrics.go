@@ -0,0 +1,97 @@
+// Package metrics provides a plugin.Observer implementation that records calls made from a host application to its
+// plugins as prometheus metrics.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/davidsbond/plugin"
+)
+
+type (
+	// The Metrics type contains prometheus collectors that record calls made from the host application to its
+	// plugins. All collectors are labeled by plugin and command name. It implements prometheus.Collector so that it
+	// can be registered directly with a prometheus.Registerer.
+	Metrics struct {
+		executions *prometheus.CounterVec
+		errors     *prometheus.CounterVec
+		duration   *prometheus.HistogramVec
+		requests   *prometheus.HistogramVec
+		responses  *prometheus.HistogramVec
+	}
+)
+
+// New returns a new instance of the Metrics type. It should be registered with a prometheus.Registerer and passed to
+// plugin.Use via the plugin.WithObserver option.
+func New() *Metrics {
+	labels := []string{"plugin", "command"}
+
+	return &Metrics{
+		executions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "plugin",
+			Name:      "exec_total",
+			Help:      "Total number of commands executed against plugins.",
+		}, labels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "plugin",
+			Name:      "exec_errors_total",
+			Help:      "Total number of commands executed against plugins that returned an error.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "plugin",
+			Name:      "exec_duration_seconds",
+			Help:      "Time taken for plugins to execute commands.",
+			Buckets:   prometheus.DefBuckets,
+		}, labels),
+		requests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "plugin",
+			Name:      "exec_request_bytes",
+			Help:      "Size of requests sent to plugins.",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 10),
+		}, labels),
+		responses: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "plugin",
+			Name:      "exec_response_bytes",
+			Help:      "Size of responses received from plugins.",
+			Buckets:   prometheus.ExponentialBuckets(64, 4, 10),
+		}, labels),
+	}
+}
+
+// Describe sends the descriptors of all collectors within the Metrics to the provided channel.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	m.executions.Describe(ch)
+	m.errors.Describe(ch)
+	m.duration.Describe(ch)
+	m.requests.Describe(ch)
+	m.responses.Describe(ch)
+}
+
+// Collect sends the current values of all collectors within the Metrics to the provided channel.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	m.executions.Collect(ch)
+	m.errors.Collect(ch)
+	m.duration.Collect(ch)
+	m.requests.Collect(ch)
+	m.responses.Collect(ch)
+}
+
+// ObserveExec records a single call to Plugin.Exec.
+func (m *Metrics) ObserveExec(observation plugin.ExecObservation) {
+	labels := []string{observation.Plugin, observation.Command}
+
+	m.executions.WithLabelValues(labels...).Inc()
+	m.duration.WithLabelValues(labels...).Observe(observation.Duration.Seconds())
+
+	if observation.RequestSize > 0 {
+		m.requests.WithLabelValues(labels...).Observe(float64(observation.RequestSize))
+	}
+
+	if observation.ResponseSize > 0 {
+		m.responses.WithLabelValues(labels...).Observe(float64(observation.ResponseSize))
+	}
+
+	if observation.Err != nil {
+		m.errors.WithLabelValues(labels...).Inc()
+	}
+}
@@ -0,0 +1,44 @@
+package metrics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	m := metrics.New()
+
+	p, err := plugin.Use(t.Context(), "../test_plugin", plugin.WithObserver(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+	require.Error(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("pung"), output))
+
+	expected := `
+# HELP plugin_exec_errors_total Total number of commands executed against plugins that returned an error.
+# TYPE plugin_exec_errors_total counter
+plugin_exec_errors_total{command="pingpong",plugin="test_plugin"} 1
+# HELP plugin_exec_total Total number of commands executed against plugins.
+# TYPE plugin_exec_total counter
+plugin_exec_total{command="pingpong",plugin="test_plugin"} 2
+`
+
+	err = testutil.CollectAndCompare(m, strings.NewReader(expected), "plugin_exec_total", "plugin_exec_errors_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, testutil.CollectAndCount(m, "plugin_exec_duration_seconds"))
+}
@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"time"
+)
+
+type (
+	// The Observer interface describes types that are notified of each call to Plugin.Exec, such as the Metrics type
+	// within the metrics subpackage. A single Observer can be shared across many plugins, so implementations must be
+	// safe for concurrent use.
+	Observer interface {
+		// ObserveExec is called once a call to Plugin.Exec has completed.
+		ObserveExec(observation ExecObservation)
+	}
+
+	// The ExecObservation type describes a single completed call to Plugin.Exec.
+	ExecObservation struct {
+		// The name of the Plugin.
+		Plugin string
+		// The name of the Command executed.
+		Command string
+		// The Duration of the call.
+		Duration time.Duration
+		// The size of the encoded request in bytes, or zero if the request was not sent.
+		RequestSize int
+		// The size of the encoded response in bytes, or zero if no response was received.
+		ResponseSize int
+		// The error returned by the call, if any.
+		Err error
+	}
+)
+
+// WithObserver is a UseOption that notifies the provided Observer of every call to Plugin.Exec. It can be used to
+// record metrics, for example using the metrics subpackage, without the plugin package depending on any particular
+// instrumentation library.
+func WithObserver(observer Observer) UseOption {
+	return func(o *useOptions) {
+		o.observer = observer
+	}
+}
@@ -0,0 +1,429 @@
+package plugin
+
+import (
+	"io"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The UseOption type is a function that modifies how a plugin is started and communicated with when calling Use.
+	UseOption func(o *useOptions)
+
+	useOptions struct {
+		observer            Observer
+		maxRequestSize      int
+		maxMessageSize      int
+		compressor          string
+		chunkSize           int
+		retryPolicy         RetryPolicy
+		socketDirectory     string
+		clock               Clock
+		slo                 *SLO
+		checksum            string
+		checksumFile        string
+		verifiers           []Verifier
+		versionConstraint   string
+		lazy                bool
+		commands            []string
+		idleTimeout         time.Duration
+		instances           int
+		balancing           Balancing
+		shutdownGracePeriod time.Duration
+		circuitBreaker      *CircuitBreaker
+		codecs              []Codec
+		dialOptions         []grpc.DialOption
+		env                 []string
+		inheritEnv          []string
+		restrictEnv         bool
+		args                []string
+		workingDirectory    string
+		stdin               io.Reader
+		startupTimeout      time.Duration
+		credential          *Credential
+		resourceLimits      *ResourceLimits
+		cgroup              *Cgroup
+		sandboxers          []Sandboxer
+		runtime             Runtime
+		socketDirectorySet  bool
+		token               string
+		host                plugin.Host
+		configuration       proto.Message
+		secrets             map[string][]byte
+		logger              *slog.Logger
+		egress              *Egress
+		sharedDirectory     *SharedDirectory
+		pid                 int
+		watchInterval       time.Duration
+		onReload            func(err error)
+		diagnostics         *slog.Logger
+		audit               *Audit
+		interceptors        []ExecInterceptor
+	}
+
+	// The ExecOption type is a function that modifies the behaviour of a single call to Plugin.Exec.
+	ExecOption func(o *execOptions)
+
+	execOptions struct {
+		timeout      time.Duration
+		metadata     map[string]string
+		retryPolicy  RetryPolicy
+		callOptions  []grpc.CallOption
+		sharedMemory []*SharedMemory
+		warnings     func(warnings []string)
+		result       *ExecResult
+	}
+)
+
+// DefaultMaxMessageSize is the maximum size, in bytes, of messages sent and received by plugins and host applications,
+// unless set otherwise using Config.MaxMessageSize and WithMaxMessageSize respectively.
+const DefaultMaxMessageSize = plugin.DefaultMaxMessageSize
+
+// GzipCompressor is the name of the gzip compressor, which can be used with WithCompressor. Plugins are always able to
+// decompress requests compressed using gzip.
+const GzipCompressor = gzip.Name
+
+// DefaultShutdownGracePeriod is the time Plugin.Close waits for a plugin process to exit before killing it, unless
+// set otherwise using WithShutdownGracePeriod.
+const DefaultShutdownGracePeriod = 10 * time.Second
+
+// DefaultStartupTimeout is the time Use waits for a plugin to accept connections on its UNIX domain socket, unless set
+// otherwise using WithStartupTimeout.
+const DefaultStartupTimeout = 10 * time.Second
+
+func defaultUseOptions() useOptions {
+	return useOptions{
+		socketDirectory:     defaultSocketDirectory(),
+		clock:               systemClock{},
+		instances:           1,
+		maxMessageSize:      DefaultMaxMessageSize,
+		shutdownGracePeriod: DefaultShutdownGracePeriod,
+		startupTimeout:      DefaultStartupTimeout,
+		runtime:             ProcessRuntime{},
+	}
+}
+
+// WithMaxRequestSize is a UseOption that sets the maximum size, in bytes, of a request sent to the plugin by
+// Plugin.Exec. Requests that exceed this size fail locally with ErrRequestTooLarge rather than being sent to the
+// plugin. This is useful when the plugin's gRPC server is known to reject messages over a certain size.
+func WithMaxRequestSize(size int) UseOption {
+	return func(o *useOptions) {
+		o.maxRequestSize = size
+	}
+}
+
+// WithMaxMessageSize is a UseOption that sets the maximum size, in bytes, of messages sent to and received from the
+// plugin. Unless WithChunking is used, requests larger than this fail locally with ErrRequestTooLarge. The plugin
+// enforces its own limit, set using Config.MaxMessageSize. Defaults to DefaultMaxMessageSize.
+func WithMaxMessageSize(size int) UseOption {
+	return func(o *useOptions) {
+		o.maxMessageSize = size
+	}
+}
+
+// WithCompressor is a UseOption that compresses requests sent to the plugin, and the responses it returns, using the
+// named compressor. This reduces the cost of exchanging large textual payloads. The gzip compressor, named by the
+// GzipCompressor constant, is always available. Custom compressors must be registered using
+// encoding.RegisterCompressor within both the host application and the plugin. Individual calls can use a different
+// compressor via WithCallOptions and grpc.UseCompressor.
+func WithCompressor(name string) UseOption {
+	return func(o *useOptions) {
+		o.compressor = name
+	}
+}
+
+// WithDialOptions is a UseOption that applies the provided grpc.DialOption values to every connection made to the
+// plugin, after those set by Use. This allows host applications to attach interceptors or stats handlers to calls made
+// using Plugin.Exec and the connection returned by Plugin.Conn. Options that change how the connection is established,
+// such as transport credentials, should not be used.
+func WithDialOptions(opts ...grpc.DialOption) UseOption {
+	return func(o *useOptions) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// WithCodecs is a UseOption that allows commands to be executed using the provided codecs, in addition to ProtoCodec
+// and JSONCodec. The codec used for each command is the first of those advertised by the plugin that the host
+// application supports. This allows the use of plugins whose commands do not accept the protobuf binary format, such as
+// those written in languages with poor protobuf support.
+func WithCodecs(codecs ...Codec) UseOption {
+	return func(o *useOptions) {
+		o.codecs = append(o.codecs, codecs...)
+	}
+}
+
+// WithChunking is a UseOption that executes commands using a streaming RPC, splitting inputs and outputs larger than
+// the given threshold, in bytes, into chunks that are reassembled by the receiver. This allows commands to exchange
+// messages larger than the maximum message size, without any changes to their handlers. The threshold must be smaller
+// than the maximum message size of both the host application and the plugin. Plugins that do not support streaming
+// are sent requests as they would be without this option.
+func WithChunking(threshold int) UseOption {
+	return func(o *useOptions) {
+		o.chunkSize = threshold
+	}
+}
+
+// WithRetryPolicy is a UseOption that sets the RetryPolicy used to determine whether failed calls to Plugin.Exec
+// should be attempted again. By default, failed calls are not retried.
+func WithRetryPolicy(policy RetryPolicy) UseOption {
+	return func(o *useOptions) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithSocketDirectory is a UseOption that sets the directory in which the plugin creates its UNIX domain socket. The
+// directory is always chosen by the host application and passed to the plugin when it is started, so plugins cannot
+// override it. Defaults to the directory specified by the XDG_RUNTIME_DIR environment variable if set, otherwise the
+// default directory for temporary files as given by os.TempDir.
+func WithSocketDirectory(directory string) UseOption {
+	return func(o *useOptions) {
+		o.socketDirectory = directory
+		o.socketDirectorySet = true
+	}
+}
+
+// WithClock is a UseOption that sets the Clock used for all time-based behaviour of the plugin, such as waiting for
+// it to start and waiting between retries. Defaults to the system clock.
+func WithClock(clock Clock) UseOption {
+	return func(o *useOptions) {
+		o.clock = clock
+	}
+}
+
+// WithSLO is a UseOption that tracks the error budget of the plugin against the provided SLO. The state of the error
+// budget is available via Plugin.Stats.
+func WithSLO(slo SLO) UseOption {
+	return func(o *useOptions) {
+		o.slo = &slo
+	}
+}
+
+// WithLazyStart is a UseOption that defers executing the plugin binary until it is first required, either by a call
+// to Plugin.Exec or to Plugin.Start. This avoids the cost of starting plugins that may never be used. Any errors
+// that would be returned by Use when starting the plugin are instead returned by the first call that requires it. The
+// commands provided by the plugin are unknown until it has been started, so it is not selected by Manager.ExecAny
+// before then unless its commands are declared using WithCommands.
+func WithLazyStart() UseOption {
+	return func(o *useOptions) {
+		o.lazy = true
+	}
+}
+
+// WithCommands is a UseOption that declares the names of the commands a plugin used with the WithLazyStart option
+// provides. Until the plugin has been started, Plugin.Commands and Plugin.HasCommand report the declared commands,
+// allowing Manager.ExecAny to select the plugin and start it on first use. Once started, the commands reported by
+// the plugin itself are used instead. Has no effect on plugins that are not started lazily.
+func WithCommands(names ...string) UseOption {
+	return func(o *useOptions) {
+		o.commands = names
+	}
+}
+
+// WithIdleTimeout is a UseOption that stops the plugin process once it has not executed a command for the given
+// duration. The process is transparently started again the next time a command is executed, keeping resource usage
+// bounded for host applications that use many plugins infrequently.
+func WithIdleTimeout(timeout time.Duration) UseOption {
+	return func(o *useOptions) {
+		o.idleTimeout = timeout
+	}
+}
+
+// WithShutdownGracePeriod is a UseOption that sets how long Plugin.Close waits for a plugin process to exit after
+// sending it a SIGTERM signal. Once elapsed, the process is sent a SIGKILL signal. Defaults to
+// DefaultShutdownGracePeriod.
+func WithShutdownGracePeriod(gracePeriod time.Duration) UseOption {
+	return func(o *useOptions) {
+		o.shutdownGracePeriod = gracePeriod
+	}
+}
+
+// WithEnv is a UseOption that adds the provided environment variables, in "key=value" form, to the environment of the
+// plugin process. The plugin inherits the environment of the host application, with these variables taking precedence
+// over it. Plugins can read them using os.Getenv or ConfigFromEnv.
+func WithEnv(env ...string) UseOption {
+	return func(o *useOptions) {
+		o.env = append(o.env, env...)
+	}
+}
+
+// WithInheritEnv is a UseOption that limits the environment variables the plugin process inherits from the host
+// application to those with the provided names, such as "PATH", "HOME" or "HTTPS_PROXY". This prevents secrets held in
+// the environment of the host application being exposed to plugins. Variables given to WithEnv are always set. If
+// called without any names, no variables are inherited. By default, the entire environment is inherited.
+func WithInheritEnv(names ...string) UseOption {
+	return func(o *useOptions) {
+		o.inheritEnv = append(o.inheritEnv, names...)
+		o.restrictEnv = true
+	}
+}
+
+// WithArgs is a UseOption that passes additional arguments to the plugin process, following its socket id. Flags
+// within them are parsed by Run using the flags declared by Config.Flags, and the plugin fails to start if it does not
+// declare them. Arguments following a "--" separator are not parsed as flags, and can be read by the plugin from
+// os.Args.
+func WithArgs(args ...string) UseOption {
+	return func(o *useOptions) {
+		o.args = append(o.args, args...)
+	}
+}
+
+// WithWorkingDirectory is a UseOption that sets the working directory of the plugin process. Defaults to the working
+// directory of the host application. A relative plugin path is still resolved against the working directory of the
+// host application.
+func WithWorkingDirectory(directory string) UseOption {
+	return func(o *useOptions) {
+		o.workingDirectory = directory
+	}
+}
+
+// WithStdin is a UseOption that sets the standard input of the plugin process. The same reader is used by every
+// process started for the plugin, including those started again after a crash or when using WithInstances, so an
+// *os.File is preferable to readers that can only be consumed once. By default, the plugin reads from the null device.
+func WithStdin(stdin io.Reader) UseOption {
+	return func(o *useOptions) {
+		o.stdin = stdin
+	}
+}
+
+// WithStartupTimeout is a UseOption that sets how long to wait for the plugin process to accept connections on its
+// UNIX domain socket once started. If exceeded, ErrStartupTimeout is returned. Defaults to DefaultStartupTimeout.
+func WithStartupTimeout(timeout time.Duration) UseOption {
+	return func(o *useOptions) {
+		o.startupTimeout = timeout
+	}
+}
+
+// WithCredential is a UseOption that runs the plugin process as the user and groups described by the Credential,
+// allowing untrusted plugins to be dropped to an unprivileged account. The host application must be privileged enough
+// to start processes as another user. Each plugin process is given its own directory within the socket directory,
+// owned by the plugin user, in which to create its sockets. Unless WithSocketDirectory is used, the socket directory
+// is the default directory for temporary files as given by os.TempDir. Use returns ErrCredentialUnsupported on
+// platforms that do not support running processes as another user. Has no effect on plugins used in-process.
+func WithCredential(credential Credential) UseOption {
+	return func(o *useOptions) {
+		o.credential = &credential
+	}
+}
+
+// WithResourceLimits is a UseOption that limits the operating system resources each plugin process may consume. As
+// limits cannot be set between forking and executing the plugin, they are applied using prlimit once the process has
+// started, before any command is executed, and the plugin fails to start if they cannot be applied. The plugin's own
+// startup code, such as its package initialisation, may therefore run briefly before the limits take effect, and the
+// CPU time it uses counts towards ResourceLimits.CPUTime. Use WithCgroup to contain plugins from the moment they
+// start. Use returns ErrResourceLimitsUnsupported on platforms other than Linux. Has no effect on plugins used
+// in-process.
+func WithResourceLimits(limits ResourceLimits) UseOption {
+	return func(o *useOptions) {
+		o.resourceLimits = &limits
+	}
+}
+
+// WithCgroup is a UseOption that places each plugin process into a cgroup of its own within the parent cgroup
+// described by the Cgroup, applying its memory, CPU and process limits. The process is placed into the cgroup as it
+// is started, and the cgroup is removed, killing any processes remaining within it, once the process exits. The
+// resources used by the plugin are available via Plugin.Stats. Use returns ErrCgroupUnsupported on platforms other
+// than Linux. Has no effect on plugins used in-process.
+func WithCgroup(cgroup Cgroup) UseOption {
+	return func(o *useOptions) {
+		o.cgroup = &cgroup
+	}
+}
+
+// WithSandbox is a UseOption that restricts what each plugin process may do using the provided sandboxers, applied in
+// the order given. Use a Profile to restrict filesystem access and system calls on Linux, or implement Sandboxer to
+// use other mechanisms. Has no effect on plugins used in-process.
+func WithSandbox(sandboxers ...Sandboxer) UseOption {
+	return func(o *useOptions) {
+		o.sandboxers = append(o.sandboxers, sandboxers...)
+	}
+}
+
+// WithRuntime is a UseOption that sets the Runtime used to launch plugin processes, such as a Container. The
+// UseOptions that modify the launched process, such as WithCredential, WithResourceLimits and WithCgroup, apply to
+// the command returned by the Runtime. Defaults to ProcessRuntime. Has no effect on plugins used in-process.
+func WithRuntime(runtime Runtime) UseOption {
+	return func(o *useOptions) {
+		o.runtime = runtime
+	}
+}
+
+// WithToken is a UseOption that sets the token used to authenticate with a plugin connected to using Connect. It must
+// match the value of the PLUGIN_TOKEN environment variable the plugin was started with. Plugins started via Use are
+// always given a token of their own, so the option has no effect for them.
+func WithToken(token string) UseOption {
+	return func(o *useOptions) {
+		o.token = token
+	}
+}
+
+// WithPID is a UseOption that identifies the process serving a plugin connected to using Connect, such as one started
+// by a previous instance of the host application and described by Plugin.Processes. Closing the Plugin then stops the
+// process as it would one started by Use, and its unexpected exit is reported as a *CrashError. As the process cannot
+// be started again once stopped, WithIdleTimeout should not be used. Connect returns ErrUnexpectedPID, leaving the
+// process running, if the plugin does not report the same process identifier, such as when it has since been reused
+// by another process. The option has no effect for plugins started via Use.
+func WithPID(pid int) UseOption {
+	return func(o *useOptions) {
+		o.pid = pid
+	}
+}
+
+func newExecOptions(defaults useOptions, opts []ExecOption) execOptions {
+	options := execOptions{
+		retryPolicy: defaults.retryPolicy,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return options
+}
+
+// WithTimeout is an ExecOption that limits the time a call to Plugin.Exec may take, including any retries. It applies
+// in addition to any deadline of the provided context, with whichever expires first taking effect. Once the timeout
+// elapses, the call returns an error matching context.DeadlineExceeded, even if the command ignores its context.
+func WithTimeout(timeout time.Duration) ExecOption {
+	return func(o *execOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithMetadata is an ExecOption that sends the provided key-value pairs to the plugin alongside the request, in
+// addition to any provided using ContextWithMetadata. Within the plugin, they are available via MetadataFromContext.
+func WithMetadata(md map[string]string) ExecOption {
+	return func(o *execOptions) {
+		o.metadata = md
+	}
+}
+
+// WithExecRetryPolicy is an ExecOption that sets the RetryPolicy used for a single call to Plugin.Exec, overriding
+// any set using WithRetryPolicy.
+func WithExecRetryPolicy(policy RetryPolicy) ExecOption {
+	return func(o *execOptions) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithCallOptions is an ExecOption that applies the provided grpc.CallOption values to the request sent to the
+// plugin. This allows host applications to use gRPC features not otherwise exposed, such as compression.
+func WithCallOptions(opts ...grpc.CallOption) ExecOption {
+	return func(o *execOptions) {
+		o.callOptions = append(o.callOptions, opts...)
+	}
+}
+
+// WithSharedMemory is an ExecOption that shares the provided memory with the plugin for the duration of the call.
+// Within the plugin, it is available via SharedMemoryFromContext. The plugin must enable Config.SharedMemory, otherwise
+// ErrSharedMemoryUnsupported is returned. At most 64 regions of memory can be shared with a single call.
+func WithSharedMemory(memory ...*SharedMemory) ExecOption {
+	return func(o *execOptions) {
+		o.sharedMemory = append(o.sharedMemory, memory...)
+	}
+}
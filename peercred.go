@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+)
+
+type (
+	// The PeerInfo type describes the operating system credentials of a process connected to a plugin. It is
+	// available within command handlers via peer.FromContext when Config.VerifyPeer is enabled.
+	PeerInfo struct {
+		credentials.CommonAuthInfo
+		// The process identifier of the connected process.
+		PID int
+		// The user identifier of the connected process.
+		UID int
+		// The group identifier of the connected process.
+		GID int
+	}
+
+	peerCredentials struct {
+		pid int
+	}
+)
+
+var (
+	// ErrPeerRejected is the error given when a process other than the host application that started a plugin
+	// attempts to connect to it while Config.VerifyPeer is enabled.
+	ErrPeerRejected = errors.New("peer rejected")
+
+	// ErrPeerVerificationUnsupported is the error given when Config.VerifyPeer is enabled on a platform that does not
+	// support obtaining the credentials of processes connected to a UNIX domain socket.
+	ErrPeerVerificationUnsupported = errors.New("peer verification is not supported on this platform")
+)
+
+// AuthType returns the name of the authentication mechanism used to obtain the PeerInfo.
+func (PeerInfo) AuthType() string {
+	return "peercred"
+}
+
+func (pc *peerCredentials) ClientHandshake(context.Context, string, net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("peer credentials can only be used by a plugin")
+}
+
+func (pc *peerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	info, err := peerInfo(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if info.PID != pc.pid {
+		return nil, nil, fmt.Errorf("%w: process %d is not the host process %d", ErrPeerRejected, info.PID, pc.pid)
+	}
+
+	info.SecurityLevel = credentials.NoSecurity
+	return conn, info, nil
+}
+
+func (pc *peerCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{
+		SecurityProtocol: "peercred",
+	}
+}
+
+func (pc *peerCredentials) Clone() credentials.TransportCredentials {
+	return &peerCredentials{pid: pc.pid}
+}
+
+func (pc *peerCredentials) OverrideServerName(string) error {
+	return nil
+}
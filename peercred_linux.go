@@ -0,0 +1,42 @@
+//go:build linux
+
+package plugin
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+const peerVerificationSupported = true
+
+func peerInfo(conn net.Conn) (PeerInfo, error) {
+	unix, ok := conn.(*net.UnixConn)
+	if !ok {
+		return PeerInfo{}, errors.New("connection is not a UNIX domain socket")
+	}
+
+	raw, err := unix.SyscallConn()
+	if err != nil {
+		return PeerInfo{}, err
+	}
+
+	var (
+		credentials *syscall.Ucred
+		credErr     error
+	)
+
+	err = raw.Control(func(fd uintptr) {
+		credentials, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+
+	if err = errors.Join(err, credErr); err != nil {
+		return PeerInfo{}, err
+	}
+
+	return PeerInfo{
+		PID: int(credentials.Pid),
+		UID: int(credentials.Uid),
+		GID: int(credentials.Gid),
+	}, nil
+}
@@ -0,0 +1,13 @@
+//go:build !linux
+
+package plugin
+
+import (
+	"net"
+)
+
+const peerVerificationSupported = false
+
+func peerInfo(net.Conn) (PeerInfo, error) {
+	return PeerInfo{}, ErrPeerVerificationUnsupported
+}
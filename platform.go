@@ -0,0 +1,108 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+type (
+	// The platformManifest type is the subset of the manifest of a plugin archive, as created by the pluginpkg package,
+	// used to find the binary for the current platform within an unpacked archive.
+	platformManifest struct {
+		Name     string `json:"name"`
+		Binaries []struct {
+			OS     string `json:"os"`
+			Arch   string `json:"arch"`
+			Path   string `json:"path"`
+			SHA256 string `json:"sha256"`
+		} `json:"binaries"`
+	}
+
+	// The platformBinary type describes the binary chosen for the current platform.
+	platformBinary struct {
+		path     string
+		name     string
+		checksum string
+	}
+)
+
+// ErrUnsupportedPlatform is the error returned by Use when a plugin is distributed as a directory containing binaries
+// for several platforms, none of which are for the current platform.
+var ErrUnsupportedPlatform = errors.New("no binary for platform")
+
+// platformManifestPath is the name of the manifest within an unpacked plugin archive.
+const platformManifestPath = "manifest.json"
+
+// resolvePlatform returns the binary to execute for the plugin at the given path, allowing plugins to be distributed
+// with binaries for many platforms. The path may be:
+//
+//   - A plugin binary, which is used as-is.
+//   - A directory containing an unpacked plugin archive, whose manifest lists the binary for each platform.
+//   - The path of a binary without its platform suffix, such as "./my-plugin" for "./my-plugin_linux_amd64".
+//
+// Paths that do not exist are returned as-is, so that starting the plugin fails as it otherwise would.
+func resolvePlatform(path string) (platformBinary, error) {
+	resolved := platformBinary{path: path, name: filepath.Base(path)}
+
+	info, err := os.Stat(path)
+	switch {
+	case err == nil && info.IsDir():
+		return resolveManifest(path)
+	case err == nil:
+		return resolved, nil
+	case !errors.Is(err, fs.ErrNotExist):
+		return platformBinary{}, fmt.Errorf("failed to stat plugin %q: %w", path, err)
+	}
+
+	candidate := path + "_" + runtime.GOOS + "_" + runtime.GOARCH
+	if runtime.GOOS == "windows" {
+		candidate += ".exe"
+	}
+
+	if _, err = os.Stat(candidate); err == nil {
+		resolved.path = candidate
+	}
+
+	return resolved, nil
+}
+
+func resolveManifest(directory string) (platformBinary, error) {
+	encoded, err := os.ReadFile(filepath.Join(directory, platformManifestPath))
+	if err != nil {
+		return platformBinary{}, fmt.Errorf("failed to read plugin manifest in %q: %w", directory, err)
+	}
+
+	var manifest platformManifest
+	if err = json.Unmarshal(encoded, &manifest); err != nil {
+		return platformBinary{}, fmt.Errorf("failed to parse plugin manifest in %q: %w", directory, err)
+	}
+
+	for _, binary := range manifest.Binaries {
+		if binary.OS != runtime.GOOS || binary.Arch != runtime.GOARCH {
+			continue
+		}
+
+		// Binaries outside the directory are not part of the plugin.
+		if !filepath.IsLocal(filepath.FromSlash(binary.Path)) {
+			return platformBinary{}, fmt.Errorf("invalid binary path %q in plugin manifest in %q", binary.Path, directory)
+		}
+
+		name := manifest.Name
+		if name == "" {
+			name = filepath.Base(directory)
+		}
+
+		return platformBinary{
+			path:     filepath.Join(directory, filepath.FromSlash(binary.Path)),
+			name:     name,
+			checksum: binary.SHA256,
+		}, nil
+	}
+
+	return platformBinary{}, fmt.Errorf("%w: plugin %q has no binary for %s/%s", ErrUnsupportedPlatform, manifest.Name, runtime.GOOS, runtime.GOARCH)
+}
@@ -0,0 +1,112 @@
+package plugin_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestUse_Platform(t *testing.T) {
+	t.Parallel()
+
+	binary, err := filepath.Abs("./test_plugin")
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(binary)
+	require.NoError(t, err)
+
+	sum := sha256.Sum256(contents)
+	digest := hex.EncodeToString(sum[:])
+
+	// unpacked returns a directory containing an unpacked plugin archive, with a binary for the given platform.
+	unpacked := func(t *testing.T, goos, goarch, path, digest string) string {
+		directory := filepath.Join(t.TempDir(), "test_plugin")
+		require.NoError(t, os.MkdirAll(filepath.Join(directory, filepath.Dir(path)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(directory, path), contents, 0o755))
+
+		manifest, err := json.Marshal(map[string]any{
+			"name":    "test_plugin",
+			"version": "v1.2.3",
+			"binaries": []map[string]string{
+				{"os": goos, "arch": goarch, "path": path, "sha256": digest},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(directory, "manifest.json"), manifest, 0o644))
+
+		return directory
+	}
+
+	platform := runtime.GOOS + "_" + runtime.GOARCH
+
+	tt := []struct {
+		Name string
+		Path func(t *testing.T) string
+		Err  error
+	}{
+		{
+			Name: "uses the binary for the platform from a manifest",
+			Path: func(t *testing.T) string {
+				return unpacked(t, runtime.GOOS, runtime.GOARCH, "bin/"+platform+"/test_plugin", digest)
+			},
+		},
+		{
+			Name: "uses the binary named for the platform",
+			Path: func(t *testing.T) string {
+				// The binary reports the name it is executed with, so is started via a script.
+				directory := t.TempDir()
+				script := fmt.Sprintf("#!/bin/sh\nexec %s \"$@\"\n", binary)
+				require.NoError(t, os.WriteFile(filepath.Join(directory, "test_plugin_"+platform), []byte(script), 0o755))
+				return filepath.Join(directory, "test_plugin")
+			},
+		},
+		{
+			Name: "error if the manifest has no binary for the platform",
+			Path: func(t *testing.T) string {
+				return unpacked(t, "plan9", "386", "bin/plan9_386/test_plugin", digest)
+			},
+			Err: plugin.ErrUnsupportedPlatform,
+		},
+		{
+			Name: "error if the binary does not match the manifest",
+			Path: func(t *testing.T) string {
+				return unpacked(t, runtime.GOOS, runtime.GOARCH, "bin/"+platform+"/test_plugin", hex.EncodeToString(make([]byte, sha256.Size)))
+			},
+			Err: plugin.ErrChecksumMismatch,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := plugin.Use(t.Context(), tc.Path(t))
+			if tc.Err != nil {
+				require.ErrorIs(t, err, tc.Err)
+				return
+			}
+
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, p.Close())
+			})
+
+			assert.EqualValues(t, "test_plugin", p.Name())
+
+			output := &wrapperspb.StringValue{}
+			require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+			assert.EqualValues(t, "pong", output.GetValue())
+		})
+	}
+}
@@ -1,52 +1,144 @@
 //go:generate go tool buf format -w .
 //go:generate go tool buf generate
+//go:generate go run ./cmd/conformance --output testdata/conformance
 
 // Package plugin provides a gRPC-based plugin system allowing programs to dynamically execute custom code that satisfy
 // the plugin interface.
 //
 // Plugins are external binaries that serve gRPC requests over a UNIX domain socket on the local machine. Each plugin
-// creates a socket using a unique identifier passed as an argument from the host application to the plugin. Plugins
-// make use of the protobuf "Any" type in order to allow user-defined inputs and outputs to keep strong typing across
-// application and language boundaries.
+// creates a socket using a unique identifier and directory passed as arguments from the host application to the
+// plugin. Plugins make use of the protobuf "Any" type in order to allow user-defined inputs and outputs to keep strong
+// typing across application and language boundaries.
 package plugin
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"net"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
 	"runtime/debug"
-	"syscall"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/xid"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/encoding"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/davidsbond/plugin/internal/plugin"
+	"github.com/davidsbond/plugin/internal/shm"
 )
 
 type (
 	// The Config type contains fields used to configure a plugin.
 	Config struct {
-		// The Name of the plugin. This will be used to determine the location of the UNIX domain socket the plugin
-		// will use for communication. It is typically created at /tmp/plugin_<name>.sock and is deleted when the
-		// plugin exits.
+		// The Name of the plugin. This must match the file name of the plugin binary.
 		Name string
+		// The Version of the plugin, reported to the host application when it starts. Defaults to the version of the
+		// main module as recorded in the binary's build information. Binaries built from a local checkout, which have
+		// no module version, default to a pseudo-version describing the version control revision they were built from,
+		// such as "v0.0.0-20240101120000-0123456789ab".
+		Version string
 		// The Commands the plugin is capable of handling. When attempting to use a command that does not exist within
 		// the plugin, an ErrUnknownCommand error is returned to the caller.
 		Commands []CommandHandler
 		// Any ServerOptions to apply to the gRPC server. This could be middleware, keepalives credentials etc.
+		// Interceptors should be added using grpc.ChainUnaryInterceptor and grpc.ChainStreamInterceptor so that they
+		// run after the host application's token has been verified.
 		ServerOptions []grpc.ServerOption
+		// The CancellationGracePeriod is the maximum time to wait for a command to return once the host application
+		// has cancelled it. Command contexts are always cancelled when the host cancels a call. If the command does
+		// not return within the grace period, the call is aborted and the command is abandoned. Defaults to 5 seconds.
+		CancellationGracePeriod time.Duration
+		// The SocketMode, if non-zero, sets the permissions of the UNIX domain socket, restricting which local users are
+		// able to connect to the plugin. For example, 0600 only allows the user running the plugin to connect. Ignored
+		// on platforms without UNIX file permissions, such as Windows, where access to the socket is governed by the
+		// ACL of the socket directory.
+		SocketMode os.FileMode
+		// The SocketOwner, if set, changes the owner of the UNIX domain socket once it has been created. Ignored on
+		// platforms without UNIX file permissions, such as Windows.
+		SocketOwner *SocketOwner
+		// VerifyPeer, if true, rejects connections from any process other than the host application that started the
+		// plugin, using the credentials of the connecting process provided by the operating system. This replaces any
+		// transport credentials set within ServerOptions. Only supported on Linux.
+		VerifyPeer bool
+		// Events, if set, is an EventSource that runs for the lifetime of the plugin, publishing events to the host
+		// application. The host application receives these events via Plugin.Events.
+		Events EventSource
+		// The MaxMessageSize is the maximum size, in bytes, of messages the plugin sends and receives. Host
+		// applications exchanging larger messages should also raise their own limit using WithMaxMessageSize.
+		// Defaults to DefaultMaxMessageSize.
+		MaxMessageSize int
+		// SharedMemory, if true, accepts memory shared by the host application using WithSharedMemory on a second
+		// UNIX domain socket alongside the plugin's own. Commands obtain the memory using SharedMemoryFromContext. Only
+		// supported on Linux.
+		SharedMemory bool
+		// Any Codecs that commands accept in addition to ProtoCodec and JSONCodec, allowing host applications to
+		// encode their input and output in other formats. Codecs are advertised to host applications in the order
+		// given, after ProtoCodec and JSONCodec.
+		Codecs []Codec
+		// RegisterServices, if set, is called with the plugin's gRPC server so that additional gRPC services can be
+		// served alongside the plugin API over the same socket. Host applications call these services using the
+		// connection returned by Plugin.Conn. Calls to these services are authenticated in the same way as those to
+		// the plugin API.
+		RegisterServices func(s grpc.ServiceRegistrar)
+		// Flags, if set, is called with the flag set of the plugin binary so that it can declare its own flags, such as
+		// the path to a configuration file or the verbosity of its logs. Run parses these flags from the arguments
+		// given by the host application using WithArgs before the plugin is served, so values bound to variables are
+		// available to command handlers. The flags are also accepted by the describe and exec subcommands. They are
+		// not parsed by Serve.
+		Flags func(flags *pflag.FlagSet)
+		// The MinHostVersion, if set, is the minimum semantic version of the host application the plugin can be used
+		// by, such as "v1.4.0". Host applications identify their version using WithHost, and those that are older, or
+		// that do not identify themselves, receive ErrIncompatibleHost.
+		MinHostVersion string
+		// Configure, if set, is called with the configuration provided by the host application via WithConfiguration
+		// once the plugin has started, before any of its commands can be executed. The configuration is nil if the host
+		// application provides none. Use Configuration to decode it into a specific message type. Returning an error
+		// prevents the host application from using the plugin. It is not called by the exec subcommand.
+		Configure func(ctx context.Context, configuration *anypb.Any) error
+		// OnConfigChange, if set, is called with configuration pushed by the host application via Plugin.Configure once
+		// the plugin has been configured, such as when rotating credentials. The host application is only told the
+		// configuration is in use once it returns, and returning an error rejects it. Commands continue to be executed
+		// while it is called. Requires Configure to be set.
+		OnConfigChange func(ctx context.Context, configuration *anypb.Any) error
+		// The DiagnosticLogger, if set, is the logger the package writes its own diagnostics to within the plugin
+		// process, describing when it starts listening, shuts down and removes its socket. By default, diagnostics are
+		// discarded and Run writes any error that causes the plugin to fail to stderr.
+		DiagnosticLogger *slog.Logger
+		// LogRequests, if true, logs each command executed by the host application once it has completed, describing
+		// its name, request identifier, duration, status code and the sizes of its input and output. Records are
+		// written to the DiagnosticLogger, or to stderr if it is not set, at the info level for commands that succeed
+		// and the warn level for those that fail.
+		LogRequests bool
+
+		// sandboxed is set by Serve once the sandbox profile given by the host application has been enforced.
+		sandboxed bool
+		// The Socket is the path of the UNIX domain socket the plugin listens on. It is set by Run using the arguments
+		// given by the host application, so it only needs to be set when calling Serve directly.
+		Socket string
+	}
+
+	// The SocketOwner type describes the user and group that own a plugin's UNIX domain socket.
+	SocketOwner struct {
+		// The UID of the user that owns the socket.
+		UID int
+		// The GID of the group that owns the socket.
+		GID int
 	}
 
 	// The CommandHandler interface describes types that act as individual commands a plugin can handle. Plugin authors should
@@ -64,9 +156,65 @@ type (
 	Command[Input, Output proto.Message] struct {
 		// Use describes the name of the command.
 		Use string
+		// Description is a human-readable description of what the command does.
+		Description string
+		// Example gives hints on how the command should be used, such as an example input.
+		Example string
+		// Deprecated marks the command as one that should no longer be used.
+		Deprecated bool
+		// Idempotent marks the command as one that can safely be executed again after a transient failure, allowing
+		// host applications to retry it.
+		Idempotent bool
+		// RateLimit limits how often the command can be executed. Executions that exceed the limit fail with the
+		// codes.ResourceExhausted status code. By default, executions are not limited.
+		RateLimit RateLimit
+		// MaxInputSize is the maximum size, in bytes, of the encoded input the command accepts. Executions whose input
+		// exceeds it are rejected with the codes.ResourceExhausted status code before the command is run, independently
+		// of Config.MaxMessageSize. By default, the size of the input is not limited.
+		MaxInputSize int
 		// Run is a function that is invoked when the plugin receives a request to execute the command.
 		Run func(ctx context.Context, input Input) (Output, error)
 	}
+
+	// The CommandInfo type describes a command provided by a plugin, as returned by Plugin.Commands.
+	CommandInfo struct {
+		// The Name of the command.
+		Name string
+		// A human-readable Description of what the command does.
+		Description string
+		// An Example describing how the command should be used.
+		Example string
+		// Whether the command is Deprecated and should no longer be used.
+		Deprecated bool
+		// Whether the command is Idempotent and can safely be executed again after a transient failure.
+		Idempotent bool
+		// The names of the codecs the command supports, in order of preference. When empty, only ProtoCodec is
+		// supported.
+		Codecs []string
+	}
+
+	// The RateLimit type describes how often a command may be executed, using a token bucket that is refilled at a
+	// constant rate.
+	RateLimit struct {
+		// The number of executions allowed per second. When zero, executions are not limited.
+		PerSecond float64
+		// The maximum number of executions allowed in a single burst. Defaults to 1.
+		Burst int
+	}
+
+	// validator is implemented by CommandHandler implementations that can check they are usable before the plugin is
+	// started.
+	validator interface {
+		validate() error
+	}
+
+	// describer is implemented by CommandHandler implementations that provide more than the command name.
+	describer interface {
+		info() CommandInfo
+		messages() (input, output protoreflect.MessageDescriptor)
+		rateLimit() RateLimit
+		maxInputSize() int
+	}
 )
 
 // Name returns the name of the command.
@@ -74,112 +222,474 @@ func (ch Command[Input, Output]) Name() string {
 	return ch.Use
 }
 
+func (ch Command[Input, Output]) info() CommandInfo {
+	return CommandInfo{
+		Name:        ch.Use,
+		Description: ch.Description,
+		Example:     ch.Example,
+		Deprecated:  ch.Deprecated,
+		Idempotent:  ch.Idempotent,
+	}
+}
+
+func (ch Command[Input, Output]) rateLimit() RateLimit {
+	return ch.RateLimit
+}
+
+func (ch Command[Input, Output]) maxInputSize() int {
+	return ch.MaxInputSize
+}
+
+func (ch Command[Input, Output]) validate() error {
+	if ch.Run == nil {
+		return fmt.Errorf("command %q has no Run function", ch.Use)
+	}
+
+	return nil
+}
+
+func (ch Command[Input, Output]) messages() (protoreflect.MessageDescriptor, protoreflect.MessageDescriptor) {
+	return messageDescriptor[Input](), messageDescriptor[Output]()
+}
+
 // Execute the command. This method handles all conversions from the protobuf Any type to those specified by the
-// parameterized types provided by plugin authors.
+// parameterized types provided by plugin authors. The input is decoded directly into a new instance of the Input type,
+// and the output encoded once, using the codec negotiated with the host application. Inputs that violate protovalidate
+// constraints declared by the Input type are rejected with codes.InvalidArgument before Run is called.
 func (ch Command[Input, Output]) Execute(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
-	message, err := input.UnmarshalNew()
-	if err != nil {
-		return nil, err
+	codec := plugin.CodecFromContext(ctx)
+
+	in := newMessage[Input]()
+	if !input.MessageIs(in) {
+		return nil, invalidInputType(ch.Use, in, input)
 	}
 
-	in, ok := message.(Input)
-	if !ok {
-		return nil, fmt.Errorf("invalid input type for command %q", ch.Use)
+	if err := codec.Unmarshal(input.GetValue(), in); err != nil {
+		return nil, err
 	}
 
-	output, err := ch.Run(ctx, in)
-	if err != nil {
+	if err := validateInput(ch.Use, in); err != nil {
 		return nil, err
 	}
 
-	out, err := anypb.New(output)
+	output, err := ch.Run(ctx, in)
 	if err != nil {
 		return nil, err
 	}
 
-	return out, nil
+	return plugin.Encode(codec, output)
 }
 
-// Run a plugin using the provided configuration. This function blocks until the process receives an SIGINT, SIGTERM
-// or SIGKILL signal. At which point it will gracefully stop the gRPC server and remove its UNIX domain socket.
+// Run a plugin using the provided configuration. This function blocks until the process receives a SIGINT or SIGTERM
+// signal, or on Windows a console control event. At which point it will gracefully stop the gRPC server and remove its
+// UNIX domain socket. If the plugin fails, its error is logged using Config.DiagnosticLogger, or written to stderr if
+// it is not set, and the process exits. Use Serve to run a plugin without Run taking ownership of the process.
 func Run(config Config) {
-	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL)
+	ctx, cancel := signal.NotifyContext(context.Background(), shutdownSignals...)
 	defer cancel()
 
+	// The socket directory is always decided by the host application, which needs to know where to find the socket.
+	// The default only applies when the plugin is started by hand.
+	socketDirectory := defaultSocketDirectory()
+
 	cmd := &cobra.Command{
-		Use:     fmt.Sprintf("%s [socket id]", config.Name),
-		Version: getPluginVersion(),
+		Use:     fmt.Sprintf("%s [socket id] [flags]", config.Name),
+		Version: getPluginVersion(config),
 		Short:   fmt.Sprintf("Starts the %q plugin", config.Name),
-		Long:    fmt.Sprintf("Starts the %q plugin.\n\nOnce started, the plugin will begin listening for commands on a UNIX domain socket under /tmp. This socket name is specified by the first argument passed to the command.", config.Name),
+		Long:    fmt.Sprintf("Starts the %q plugin.\n\nOnce started, the plugin will begin listening for commands on a UNIX domain socket within the socket directory. This socket name is specified by the first argument passed to the command.", config.Name),
 		CompletionOptions: cobra.CompletionOptions{
 			DisableDefaultCmd: true,
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
-		Args:          cobra.ExactArgs(1),
+		Args:          cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return startPlugin(cmd.Context(), config, args[0], cmd.Version)
+			config.Socket = socketPath(socketDirectory, args[0])
+			return Serve(cmd.Context(), config)
 		},
 	}
 
+	cmd.Flags().StringVar(&socketDirectory, socketDirectoryFlag, socketDirectory, "The directory to create the UNIX domain socket in")
+	if config.Flags != nil {
+		config.Flags(cmd.PersistentFlags())
+	}
+
+	cmd.AddCommand(describeCommand(config), execCommand(config))
+
 	if err := cmd.ExecuteContext(ctx); err != nil {
-		fmt.Printf("failed to start plugin %q: %v\n", config.Name, err)
+		// Errors are written to stderr by default, as they are likely to be read by a person using a subcommand.
+		if config.DiagnosticLogger != nil {
+			config.diagnostics().Error("plugin failed", "error", err)
+		} else {
+			fmt.Fprintf(os.Stderr, "plugin %q failed: %v\n", config.Name, err)
+		}
+
 		os.Exit(1)
 	}
 }
 
-func startPlugin(ctx context.Context, config Config, id, version string) error {
-	server := grpc.NewServer(config.ServerOptions...)
+// Serve the plugin using the provided configuration, listening on the UNIX domain socket given by Config.Socket, until
+// the context is cancelled. Unlike Run, Serve does not parse arguments, handle signals or exit the process, leaving
+// these concerns to the caller. This allows plugins to be embedded within other binaries and tested. Once the context
+// is cancelled, the gRPC server is gracefully stopped and the socket removed. Returns ErrInvalidConfig if the Config is
+// invalid, or ErrNoSocket if Config.Socket is not set.
+func Serve(ctx context.Context, config Config) error {
+	if err := config.validate(); err != nil {
+		return err
+	}
+
+	if config.Socket == "" {
+		return ErrNoSocket
+	}
+
+	socket, version := config.Socket, getPluginVersion(config)
+
+	var options []grpc.ServerOption
+	if config.VerifyPeer {
+		if !peerVerificationSupported {
+			return ErrPeerVerificationUnsupported
+		}
+
+		options = append(options, grpc.Creds(&peerCredentials{pid: os.Getppid()}))
+	}
+
+	// The token is removed from the environment so that it is not inherited by any processes started by commands.
+	token := os.Getenv(plugin.TokenEnvironmentVariable)
+	if token != "" {
+		if err := os.Unsetenv(plugin.TokenEnvironmentVariable); err != nil {
+			return err
+		}
+	}
+
+	// The sandbox is enforced before the plugin listens for connections, so that no command is ever executed
+	// outside of it.
+	sandboxed, err := enforceSandbox(filepath.Dir(socket))
+	if err != nil {
+		return err
+	}
+
+	config.sandboxed = sandboxed
+	if sandboxed {
+		config.diagnostics().Debug("enforced sandbox")
+	}
+
+	var memoryListener net.Listener
+	if config.SharedMemory {
+		if !shm.Supported {
+			return ErrSharedMemoryUnsupported
+		}
+
+		if memoryListener, err = listen(config, sharedMemorySocketPath(socket)); err != nil {
+			return err
+		}
+	}
+
+	listener, err := listen(config, socket)
+	if err != nil {
+		return errors.Join(err, closeListener(memoryListener))
+	}
+
+	return serve(ctx, config, listener, memoryListener, version, token, options...)
+}
+
+// serve the plugin's gRPC API on the listener until the context is cancelled. If the token is not empty, calls that do
+// not carry it are rejected. If the memory listener is not nil, shared memory is accepted on it.
+func serve(ctx context.Context, config Config, listener, memoryListener net.Listener, version, token string, options ...grpc.ServerOption) error {
+	// The token is verified before any user-provided interceptors are invoked, so unauthenticated calls never reach
+	// them.
+	var serverOptions []grpc.ServerOption
+	if token != "" {
+		serverOptions = plugin.TokenServerOptions(token)
+	}
 
 	info := plugin.Info{
-		Name:    config.Name,
-		Version: version,
+		Name:      config.Name,
+		Version:   version,
+		Sandboxed: config.sandboxed,
 	}
 
 	handlers := plugin.CommandHandlers{}
 	for _, command := range config.Commands {
+		// The command types provided by this package decode their input using the negotiated codec, other
+		// implementations are always given the protobuf binary format.
 		handlers[command.Name()] = command.Execute
-		info.Commands = append(info.Commands, command.Name())
+		if _, ok := command.(describer); !ok {
+			handlers[command.Name()] = plugin.Transcode(command.Execute)
+		}
+
+		info.Commands = append(info.Commands, describe(command))
 	}
 
-	plugin.NewAPI(info, handlers).Register(server)
+	maxMessageSize := config.MaxMessageSize
+	if maxMessageSize <= 0 {
+		maxMessageSize = DefaultMaxMessageSize
+	}
 
-	socket := "/tmp/" + id + ".sock"
-	listener, err := net.Listen("unix", socket)
-	if err != nil {
-		return err
+	serverOptions = append(serverOptions, grpc.MaxRecvMsgSize(maxMessageSize), grpc.MaxSendMsgSize(maxMessageSize))
+	serverOptions = append(serverOptions, plugin.MarshalServerOptions()...)
+	if config.LogRequests {
+		serverOptions = append(serverOptions, plugin.RequestLogServerOptions(config.requestLogger())...)
+	}
+
+	serverOptions = append(serverOptions, plugin.RateLimitServerOptions(info.Commands)...)
+	serverOptions = append(serverOptions, plugin.InputSizeServerOptions(info.Commands)...)
+	serverOptions = append(serverOptions, config.ServerOptions...)
+	options = append(serverOptions, options...)
+
+	server := grpc.NewServer(options...)
+	logger := config.diagnostics()
+
+	gracePeriod := config.CancellationGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = plugin.DefaultGracePeriod
+	}
+
+	apiOptions := []plugin.APIOption{
+		plugin.WithGracePeriod(gracePeriod),
+		plugin.WithMinHostVersion(config.MinHostVersion),
+	}
+	for _, codec := range config.Codecs {
+		apiOptions = append(apiOptions, plugin.WithCodecs(codec))
+	}
+
+	if config.Configure != nil {
+		apiOptions = append(apiOptions, plugin.WithConfigure(config.Configure))
+	}
+
+	if config.OnConfigChange != nil {
+		apiOptions = append(apiOptions, plugin.WithConfigChange(config.OnConfigChange))
+	}
+
+	var memory shm.Store
+	if memoryListener != nil {
+		apiOptions = append(apiOptions, plugin.WithSharedMemory(&memory))
+	}
+
+	api := plugin.NewAPI(info, handlers, apiOptions...)
+	api.Register(server)
+
+	if config.RegisterServices != nil {
+		config.RegisterServices(server)
 	}
 
 	group, ctx := errgroup.WithContext(ctx)
 	group.Go(func() error {
+		logger.Info("plugin listening", "version", version, "socket", listener.Addr().String(), "commands", len(info.Commands))
 		return server.Serve(listener)
 	})
 
+	if memoryListener != nil {
+		group.Go(func() error {
+			defer memory.Close()
+			return shm.Serve(ctx, memoryListener, token, &memory)
+		})
+	}
+
+	if config.Events != nil {
+		group.Go(func() error {
+			err := config.Events(ctx, func(ctx context.Context, event proto.Message) error {
+				payload, err := anypb.New(event)
+				if err != nil {
+					return err
+				}
+
+				return api.Publish(ctx, payload)
+			})
+
+			// The event source is expected to return the context's error once the plugin is shutting down.
+			if err != nil && ctx.Err() == nil {
+				return err
+			}
+
+			return nil
+		})
+	}
+
 	group.Go(func() error {
 		<-ctx.Done()
+		logger.Info("plugin shutting down", "reason", context.Cause(ctx))
+		api.Close()
 		server.GracefulStop()
-		return listener.Close()
+
+		// GracefulStop closes the listener itself, which removes the socket, so only unexpected errors are returned.
+		if err := listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			logger.Warn("failed to close socket", "socket", listener.Addr().String(), "error", err)
+			return err
+		}
+
+		logger.Debug("removed socket", "socket", listener.Addr().String())
+		return nil
 	})
 
 	return group.Wait()
 }
 
-func getPluginVersion() string {
-	if info, ok := debug.ReadBuildInfo(); ok {
-		return info.Main.Version
+func describe(command CommandHandler) plugin.Command {
+	described := plugin.Command{Name: command.Name()}
+	if d, ok := command.(describer); ok {
+		info := d.info()
+		described.Description = info.Description
+		described.Example = info.Example
+		described.Deprecated = info.Deprecated
+		described.Idempotent = info.Idempotent
+		described.Input, described.Output = d.messages()
+		described.RateLimit = plugin.RateLimit(d.rateLimit())
+		described.MaxInputSize = d.maxInputSize()
 	}
 
-	return "unknown"
+	return described
+}
+
+func listen(config Config, socket string) (net.Listener, error) {
+	if !fileModeSupported {
+		config.SocketMode, config.SocketOwner = 0, nil
+	}
+
+	if config.SocketMode != 0 {
+		// Restrict the umask while the socket is created so that it is never accessible with broader permissions
+		// than those requested.
+		defer restrictUmask(config.SocketMode)()
+	}
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.SocketMode != 0 {
+		if err = os.Chmod(socket, config.SocketMode); err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to set socket permissions: %w", err), listener.Close())
+		}
+	}
+
+	if config.SocketOwner != nil {
+		if err = os.Chown(socket, config.SocketOwner.UID, config.SocketOwner.GID); err != nil {
+			return nil, errors.Join(fmt.Errorf("failed to set socket owner: %w", err), listener.Close())
+		}
+	}
+
+	return listener, nil
+}
+
+const (
+	socketDirectoryFlag = "socket-dir"
+)
+
+func defaultSocketDirectory() string {
+	if directory := os.Getenv("XDG_RUNTIME_DIR"); directory != "" {
+		return directory
+	}
+
+	return os.TempDir()
+}
+
+func socketPath(directory, id string) string {
+	return filepath.Join(directory, id+".sock")
+}
+
+// sharedMemorySocketPath returns the path of the UNIX domain socket that accepts shared memory for the plugin
+// listening on the given socket.
+func sharedMemorySocketPath(socket string) string {
+	return strings.TrimSuffix(socket, ".sock") + ".shm.sock"
+}
+
+func closeListener(listener net.Listener) error {
+	if listener == nil {
+		return nil
+	}
+
+	return listener.Close()
+}
+
+func getPluginVersion(config Config) string {
+	if config.Version != "" {
+		return config.Version
+	}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+
+	// Binaries built from a local checkout have no module version, so the revision they were built from is used to
+	// identify them instead.
+	if info.Main.Version == "" || info.Main.Version == "(devel)" {
+		if version := plugin.VCSFromBuildInfo(info).Version(); version != "" {
+			return version
+		}
+	}
+
+	return info.Main.Version
 }
 
 type (
+	// The Client interface describes the operations a host application typically performs against a plugin. It is
+	// implemented by *Plugin, and by plugintest.Fake for testing host applications without starting real plugins.
+	// Host code that depends on Client rather than *Plugin can be unit tested using a fake implementation.
+	Client interface {
+		// Name returns the name of the plugin.
+		Name() string
+		// Version returns the version of the plugin.
+		Version() string
+		// Commands returns all commands the plugin provides.
+		Commands() []CommandInfo
+		// HasCommand returns true if the plugin provides the named command.
+		HasCommand(name string) bool
+		// Exec executes the named command, unmarshalling its output into the provided output parameter.
+		Exec(ctx context.Context, name string, input proto.Message, output proto.Message, opts ...ExecOption) error
+		// Close the plugin.
+		Close() error
+	}
+
 	// The Plugin type represents a running instance of a plugin referenced by the application that is invoking it. It
 	// is intended to be used as a client for the plugin.
 	Plugin struct {
-		command *exec.Cmd
+		path    string
+		address string
+		name    string
 		err     error
-		client  *plugin.Client
-		info    plugin.Info
+		options useOptions
+		stats   stats
+		slo     *sloTracker
+		breaker *circuitBreaker
+		config  *Config
+
+		mu        sync.RWMutex
+		reloadMu  sync.Mutex
+		pool      *pool
+		info      plugin.Info
+		closed    bool
+		binary    atomic.Pointer[binary]
+		stopWatch chan struct{}
+		inflight  atomic.Int64
+		lastUsed  atomic.Int64
+
+		// The configuration given to new processes, which is only changed while holding both mu and reloadMu, so that
+		// holding either is sufficient to read it.
+		configuration proto.Message
+
+		exitsMu sync.Mutex
+		exits   []ExitStatus
+	}
+
+	process struct {
+		command     *exec.Cmd
+		client      *plugin.Client
+		inflight    atomic.Int64
+		clock       Clock
+		gracePeriod time.Duration
+		exited      chan struct{}
+		status      ExitStatus
+		stderr      *tailBuffer
+		closing     atomic.Bool
+		stop        func()
+		token       string
+		socket      string
+		memory      string
+		cgroup      *cgroup
+		path        string
+		logger      *slog.Logger
+		version     string
 	}
 )
 
@@ -187,113 +697,811 @@ var (
 	// ErrUnexpectedName is the error given when a started plugin returns a name that is different to that of its
 	// filename. For example, a plugin called "foo" whose binary is located at "/tmp/bar".
 	ErrUnexpectedName = errors.New("unexpected plugin name")
+
+	// ErrUnexpectedPID is the error given by Connect when the process identifier given using WithPID is not that of
+	// the process serving the plugin, such as when it has since been reused by another process.
+	ErrUnexpectedPID = errors.New("unexpected plugin process")
+
+	// ErrStartupTimeout is the error given when a started plugin does not accept connections on its UNIX domain socket
+	// within the startup timeout.
+	ErrStartupTimeout = errors.New("startup timeout")
+
+	// ErrClosed is the error given when attempting to use a Plugin that has been closed.
+	ErrClosed = errors.New("plugin closed")
+
+	// ErrKilled is the error given by Plugin.Close when a plugin process did not exit within the shutdown grace
+	// period after being sent a SIGTERM signal, and was sent a SIGKILL signal instead.
+	ErrKilled = errors.New("plugin killed")
+
+	// ErrNoSocket is the error given by Serve when the Config does not specify the UNIX domain socket to listen on.
+	ErrNoSocket = errors.New("no socket")
+
+	// ErrInvalidConfig is the error given by Run and Serve when the Config is invalid, such as when it contains more
+	// than one command with the same name.
+	ErrInvalidConfig = errors.New("invalid config")
 )
 
+const startupInterval = 10 * time.Millisecond
+
+func (p *Plugin) waitForSocket(ctx context.Context, proc *process, socket string) error {
+	deadline := p.options.clock.Now().Add(p.options.startupTimeout)
+	for {
+		conn, err := net.Dial("unix", socket)
+		if err == nil {
+			proc.logger.Debug("plugin socket ready", "socket", socket)
+			return conn.Close()
+		}
+
+		if startErr := proc.startError(); startErr != nil {
+			return startErr
+		}
+
+		if !p.options.clock.Now().Before(deadline) {
+			return fmt.Errorf("%w: socket %q was not ready within %s: %w", ErrStartupTimeout, socket, p.options.startupTimeout, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-proc.exited:
+		case <-p.options.clock.After(startupInterval):
+		}
+	}
+}
+
 // Use the plugin at the given path. This function executes the plugin binary which will begin serving gRPC requests
-// on its UNIX domain socket. Once started, a small wait is performed to allow any startup actions the plugin requires
-// before it is queried for its name, version and available commands.
+// on its UNIX domain socket. Once started, Use waits for the plugin to create its socket before it is queried for its
+// name, version and available commands. If the socket does not accept connections within the startup timeout,
+// ErrStartupTimeout is returned. If the plugin process exits before it has started, a *StartError is returned,
+// containing its exit status and final stderr output.
 //
 // The name returned by the plugin must match the base of the given path. If they do not match, ErrUnexpectedName
 // is returned.
 //
+// Plugins distributed with binaries for several platforms are started using the binary for the current platform. The
+// path may be a directory containing an unpacked plugin archive, as created by the pluginpkg package, in which case the
+// binary listed for runtime.GOOS and runtime.GOARCH within its manifest is used, its digest is verified as if
+// WithChecksum had been provided, and the plugin must return the name within the manifest. Otherwise, if no file
+// exists at the path, a binary named using the path and the platform, such as "./my-plugin_linux_amd64", is used if
+// it exists. ErrUnsupportedPlatform is returned if a manifest does not list a binary for the current platform.
+//
+// If the WithLazyStart option is provided, the plugin is not executed until it is first required, or until
+// Plugin.Start is called.
+//
+// If a plugin has been registered under the given path using Register, it is used in-process rather than executing a
+// binary. Paths to plugin binaries within the working directory should be given as "./name" to avoid these being
+// confused.
+//
 // If successful, it is up to the caller to eventually call Plugin.Close when they no longer require use of the plugin.
-func Use(ctx context.Context, path string) (*Plugin, error) {
-	socket := xid.New().String()
-
-	cmd := &exec.Cmd{
-		Path: path,
-		Args: []string{
-			path,
-			socket,
-		},
+func Use(ctx context.Context, path string, opts ...UseOption) (*Plugin, error) {
+	if config, ok := registered(path); ok {
+		return useInProcess(ctx, config, opts...)
+	}
+
+	binary, err := resolvePlatform(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if binary.checksum != "" {
+		// Checksums provided by the host application are applied afterwards, so take precedence.
+		opts = append([]UseOption{WithChecksum(binary.checksum)}, opts...)
+	}
+
+	return use(ctx, &Plugin{path: binary.path, name: binary.name}, opts...)
+}
+
+func use(ctx context.Context, p *Plugin, opts ...UseOption) (*Plugin, error) {
+	options := defaultUseOptions()
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.maxMessageSize <= 0 {
+		options.maxMessageSize = DefaultMaxMessageSize
+	}
+
+	if options.runtime == nil {
+		options.runtime = ProcessRuntime{}
+	}
+
+	if options.compressor != "" && encoding.GetCompressor(options.compressor) == nil {
+		return nil, fmt.Errorf("compressor %q is not registered", options.compressor)
+	}
+
+	if options.credential != nil {
+		if !credentialSupported {
+			return nil, ErrCredentialUnsupported
+		}
+
+		// The runtime directory of the host application is typically inaccessible to other users.
+		if !options.socketDirectorySet {
+			options.socketDirectory = os.TempDir()
+		}
+	}
+
+	if options.resourceLimits != nil && !resourceLimitsSupported {
+		return nil, ErrResourceLimitsUnsupported
+	}
+
+	if options.cgroup != nil && !cgroupSupported {
+		return nil, ErrCgroupUnsupported
+	}
+
+	p.options = options
+	p.configuration = options.configuration
+	if options.slo != nil {
+		p.slo = newSLOTracker(*options.slo, options.clock)
+	}
+
+	if options.circuitBreaker != nil {
+		p.breaker = newCircuitBreaker(*options.circuitBreaker, options.clock)
+	}
+
+	// Only plugins executed from a binary can be watched for changes. The binary is inspected before the plugin is
+	// started, so that any change made while it starts is detected. A binary that cannot be read is treated as
+	// changed once it can be.
+	watched := options.watchInterval > 0 && p.config == nil && p.address == ""
+	var loaded binaryState
+	if watched {
+		loaded, _ = statBinary(p.current().path)
+	}
+
+	if options.lazy && p.address == "" {
+		for _, name := range options.commands {
+			p.info.Commands = append(p.info.Commands, plugin.Command{Name: name})
+		}
+	} else if err := p.Start(ctx); err != nil {
+		return nil, err
+	}
+
+	if watched {
+		p.stopWatch = make(chan struct{})
+		go p.watch(loaded, p.stopWatch)
 	}
 
-	err := cmd.Start()
+	return p, nil
+}
+
+// Start the plugin if it is not already running. Calling Start is only required for plugins used with the
+// WithLazyStart option whose version and commands must be known before a command is executed. Returns ErrClosed if
+// the Plugin has been closed.
+func (p *Plugin) Start(ctx context.Context) error {
+	_, err := p.start(ctx)
+	return err
+}
+
+// acquire returns a running plugin process chosen by the balancing strategy, starting the plugin first if required.
+// The plugin will not be stopped due to inactivity until the returned function is called.
+func (p *Plugin) acquire(ctx context.Context) (*process, func(), error) {
+	// The hold is taken before the pool is obtained so that it cannot be stopped for inactivity in between.
+	release := p.hold()
+
+	var proc *process
+	for proc == nil {
+		pl, err := p.start(ctx)
+		if err != nil {
+			release()
+			return nil, nil, err
+		}
+
+		// The process is chosen while the pool is current, so that Plugin.Reload cannot replace it without waiting
+		// for the call to complete.
+		p.mu.RLock()
+		if p.pool == pl {
+			proc = pl.pick()
+			proc.inflight.Add(1)
+		}
+		p.mu.RUnlock()
+	}
+
+	return proc, func() {
+		proc.inflight.Add(-1)
+		release()
+	}, nil
+}
+
+// hold prevents the plugin from being stopped due to inactivity until the returned function is called.
+func (p *Plugin) hold() func() {
+	p.inflight.Add(1)
+
+	return func() {
+		p.lastUsed.Store(p.options.clock.Now().UnixNano())
+		p.inflight.Add(-1)
+	}
+}
+
+// start returns the pool of running plugin processes, starting them first if required.
+func (p *Plugin) start(ctx context.Context) (*pool, error) {
+	p.mu.RLock()
+	if p.pool != nil {
+		defer p.mu.RUnlock()
+		return p.pool, nil
+	}
+	p.mu.RUnlock()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case p.closed:
+		return nil, fmt.Errorf("%w: %q", ErrClosed, p.name)
+	case p.pool != nil:
+		return p.pool, nil
+	}
+
+	pl, info, err := p.startPool(ctx, p.current())
 	if err != nil {
-		return nil, fmt.Errorf("failed to start plugin at %q: %w", path, err)
+		return nil, err
+	}
+
+	p.pool = pl
+	p.info = info
+	p.lastUsed.Store(p.options.clock.Now().UnixNano())
+	if p.options.idleTimeout > 0 {
+		go p.stopWhenIdle(pl)
+	}
+
+	return pl, nil
+}
+
+// stopWhenIdle stops the plugin processes once they have not been used for the idle timeout. The processes are
+// started again the next time they are required.
+func (p *Plugin) stopWhenIdle(pl *pool) {
+	timeout := p.options.idleTimeout
+	wait := timeout
+
+	for {
+		select {
+		case <-pl.done:
+			return
+		case <-p.options.clock.After(wait):
+		}
+
+		p.mu.Lock()
+		if p.pool != pl {
+			p.mu.Unlock()
+			return
+		}
+
+		idle := p.options.clock.Now().Sub(time.Unix(0, p.lastUsed.Load()))
+		if p.inflight.Load() == 0 && idle >= timeout {
+			p.pool = nil
+			p.mu.Unlock()
+			p.diagnostics().Debug("stopping idle plugin", "idle", idle)
+			_ = pl.close()
+			return
+		}
+
+		wait = timeout - idle
+		if wait <= 0 {
+			wait = timeout
+		}
+
+		p.mu.Unlock()
+	}
+}
+
+// clientOptions returns the options used to create clients for the plugin's processes, which authenticate using the
+// provided token.
+func (p *Plugin) clientOptions(token string) []plugin.ClientOption {
+	options := []plugin.ClientOption{
+		plugin.WithToken(token),
+		plugin.WithMaxMessageSize(p.options.maxMessageSize),
+	}
+
+	if p.options.compressor != "" {
+		options = append(options, plugin.WithCompressor(p.options.compressor))
+	}
+
+	if p.options.host != (plugin.Host{}) {
+		options = append(options, plugin.WithHost(p.options.host))
+	}
+
+	if len(p.options.dialOptions) > 0 {
+		options = append(options, plugin.WithDialOptions(p.options.dialOptions...))
+	}
+
+	return options
+}
+
+func (p *Plugin) startProcess(ctx context.Context, binary string) (*process, plugin.Info, error) {
+	if p.config != nil {
+		return p.startInProcess(ctx)
 	}
 
-	p := &Plugin{
-		command: cmd,
+	if p.address != "" {
+		return p.startConnection(ctx)
 	}
 
-	name := filepath.Base(path)
-	p.client, err = plugin.NewClient(socket)
+	id := xid.New().String()
+
+	token := plugin.NewToken()
+
+	var err error
+
+	path := binary
+	if p.options.workingDirectory != "" {
+		// Relative paths would otherwise be resolved against the working directory of the plugin.
+		if path, err = filepath.Abs(path); err != nil {
+			return nil, plugin.Info{}, fmt.Errorf("failed to resolve plugin path %q: %w", binary, err)
+		}
+	}
+
+	// Plugins run as another user are given a directory of their own, as they may not be able to create their socket
+	// within the socket directory of the host application.
+	directory := p.options.socketDirectory
+	if p.options.credential != nil {
+		if directory, err = p.options.credential.socketDirectory(directory, id); err != nil {
+			return nil, plugin.Info{}, fmt.Errorf("failed to start plugin at %q: %w", binary, err)
+		}
+	}
+
+	removeDirectory := func() {
+		if directory != p.options.socketDirectory {
+			_ = os.RemoveAll(directory)
+		}
+	}
+
+	args := []string{
+		"--" + socketDirectoryFlag,
+		directory,
+		id,
+	}
+
+	cmd, err := p.options.runtime.Command(Process{
+		ID:              id,
+		Name:            p.name,
+		Path:            path,
+		Args:            append(args, p.options.args...),
+		Env:             p.options.pluginEnv(token),
+		Environ:         p.options.inheritedEnv(),
+		Dir:             p.options.workingDirectory,
+		SocketDirectory: directory,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to dial plugin %q: %w", name, err)
+		removeDirectory()
+		return nil, plugin.Info{}, fmt.Errorf("failed to start plugin at %q: %w", binary, err)
+	}
+
+	cmd.Stdin = p.options.stdin
+	prepareTermination(cmd)
+
+	if _, ok := p.options.stdin.(*os.File); p.options.stdin != nil && !ok {
+		// Otherwise, waiting on the process blocks until the reader is exhausted, even once the process has exited.
+		cmd.WaitDelay = p.options.shutdownGracePeriod
+	}
+
+	if p.options.credential != nil {
+		p.options.credential.apply(cmd)
+	}
+
+	var cg *cgroup
+	if p.options.cgroup != nil {
+		if cg, err = p.options.cgroup.create(p.name + "-" + id); err != nil {
+			removeDirectory()
+			return nil, plugin.Info{}, fmt.Errorf("failed to start plugin at %q: %w", binary, err)
+		}
+
+		cg.attach(cmd)
+	}
+
+	removeCgroup := func() {
+		if cg != nil {
+			_ = cg.remove()
+		}
+	}
+
+	for _, sandboxer := range p.options.sandboxers {
+		if err = sandboxer.Sandbox(cmd); err != nil {
+			removeDirectory()
+			removeCgroup()
+			return nil, plugin.Info{}, fmt.Errorf("failed to sandbox plugin at %q: %w", binary, err)
+		}
+	}
+
+	socket := socketPath(directory, id)
+	proc := &process{
+		command:     cmd,
+		clock:       p.options.clock,
+		gracePeriod: p.options.shutdownGracePeriod,
+		exited:      make(chan struct{}),
+		stderr:      newTailBuffer(stderrTailSize),
+		token:       token,
+		socket:      socket,
+		memory:      sharedMemorySocketPath(socket),
+		cgroup:      cg,
+		path:        binary,
+		logger:      p.diagnostics().With("id", id),
+	}
+
+	cmd.Stderr = proc.stderr
+
+	proc.logger.Debug("starting plugin process", "path", binary, "socket", socket)
+	if err = cmd.Start(); err != nil {
+		removeDirectory()
+		removeCgroup()
+		return nil, plugin.Info{}, fmt.Errorf("failed to start plugin at %q: %w", binary, err)
+	}
+
+	proc.logger = proc.logger.With("pid", cmd.Process.Pid)
+	proc.logger.Info("started plugin process")
+
+	if cg != nil {
+		cg.started()
 	}
 
-	ticker := time.NewTicker(time.Second)
-	defer ticker.Stop()
-	<-ticker.C
+	// Limits cannot be set between forking and executing the plugin, so they are applied as soon as it has started,
+	// before it is given any work, leaving a short window in which its startup code runs without them. A plugin
+	// that cannot be contained is not used.
+	var limitErr error
+	if p.options.resourceLimits != nil {
+		if limitErr = p.options.resourceLimits.apply(cmd.Process.Pid); limitErr != nil {
+			_ = cmd.Process.Kill()
+		}
+	}
 
-	info, err := p.client.Stat(ctx)
+	// The process is always waited on so that it is reaped once it exits, however it exits.
+	go func() {
+		_ = cmd.Wait()
+		proc.removeSockets()
+		removeDirectory()
+		removeCgroup()
+		proc.status = newExitStatus(cmd.ProcessState, p.options.clock.Now())
+		p.recordExit(proc.status)
+
+		// Processes are only expected to exit once they have been told to stop.
+		if proc.closing.Load() {
+			proc.logger.Debug("plugin process exited", "status", proc.status.String())
+		} else {
+			proc.logger.Warn("plugin process exited unexpectedly", "status", proc.status.String(), "stderr", proc.stderr.String())
+		}
+
+		close(proc.exited)
+	}()
+
+	if limitErr != nil {
+		<-proc.exited
+		return nil, plugin.Info{}, fmt.Errorf("failed to start plugin %q: %w", p.name, limitErr)
+	}
+
+	proc.logger.Debug("dialing plugin", "socket", socket)
+	proc.client, err = plugin.NewClient(socket, p.clientOptions(token)...)
 	if err != nil {
-		return nil, errors.Join(p.Close(), err)
+		return nil, plugin.Info{}, errors.Join(fmt.Errorf("failed to dial plugin %q: %w", p.name, err), proc.close())
 	}
 
-	if info.Name != name {
-		return nil, fmt.Errorf("%w: expected %q, got %q", ErrUnexpectedName, name, info.Name)
+	if err = p.waitForSocket(ctx, proc, socket); err != nil {
+		return nil, plugin.Info{}, errors.Join(fmt.Errorf("failed to start plugin %q: %w", p.name, err), proc.close())
 	}
 
-	p.info = info
+	info, err := proc.client.Stat(ctx)
+	if err != nil {
+		if startErr := proc.startError(); startErr != nil {
+			err = startErr
+		}
 
-	return p, nil
+		return nil, plugin.Info{}, errors.Join(proc.close(), err)
+	}
+
+	proc.logger.Debug("completed handshake", "name", info.Name, "version", info.Version, "commands", len(info.Commands))
+
+	if info.Name != p.name {
+		return nil, plugin.Info{}, errors.Join(fmt.Errorf("%w: expected %q, got %q", ErrUnexpectedName, p.name, info.Name), proc.close())
+	}
+
+	if err = checkVersion(p.name, info.Version, p.options.versionConstraint); err != nil {
+		return nil, plugin.Info{}, errors.Join(err, proc.close())
+	}
+
+	if sandboxed(p.options.sandboxers) && !info.Sandboxed {
+		return nil, plugin.Info{}, errors.Join(fmt.Errorf("%w: %q", ErrNotSandboxed, p.name), proc.close())
+	}
+
+	return proc, info, nil
 }
 
-// Close the plugin. This method terminates the gRPC connection to the plugin and sends a SIGTERM signal to the process,
-// allowing the plugin to gracefully shutdown.
+// Close the plugin. This method terminates the gRPC connection to the plugin and sends a SIGTERM signal to each of
+// its processes, allowing the plugin to gracefully shutdown. Close then waits for the processes to exit. Any process
+// that has not exited within the shutdown grace period, as set using WithShutdownGracePeriod, is sent a SIGKILL signal
+// and ErrKilled is returned. Once closed, the Plugin cannot be started again.
+//
+// On Windows, each process is sent a CTRL_BREAK_EVENT rather than a SIGTERM signal, which Run handles in the same way,
+// and processes that have not exited are terminated. Host applications without a console cannot send console control
+// events, so their plugin processes are terminated immediately.
 func (p *Plugin) Close() error {
+	p.mu.Lock()
+	pl := p.pool
+	p.pool = nil
+	if p.stopWatch != nil && !p.closed {
+		close(p.stopWatch)
+	}
+	p.closed = true
+	p.mu.Unlock()
+
+	if pl == nil {
+		return nil
+	}
+
+	return pl.close()
+}
+
+// removeSockets removes any UNIX domain sockets left behind by a plugin process that has exited, such as when it was
+// killed before it could remove them itself.
+func (p *process) removeSockets() {
+	for _, socket := range []string{p.socket, p.memory} {
+		err := os.Remove(socket)
+		switch {
+		case err == nil:
+			p.logger.Debug("removed stale socket", "socket", socket)
+		case !errors.Is(err, os.ErrNotExist):
+			p.logger.Warn("failed to remove stale socket", "socket", socket, "error", err)
+		}
+	}
+}
+
+func (p *process) close() error {
+	p.closing.Store(true)
+
 	var err error
 	if p.client != nil {
 		err = errors.Join(err, p.client.Close())
 	}
 
-	if p.command.Process != nil {
-		err = errors.Join(err, p.command.Process.Signal(syscall.SIGTERM))
+	if p.stop != nil {
+		p.stop()
+		<-p.exited
+		return err
 	}
 
-	return err
+	if p.command.Process == nil {
+		return err
+	}
+
+	p.logger.Debug("stopping plugin process")
+	if sigErr := terminate(p.command.Process); sigErr != nil && !errors.Is(sigErr, os.ErrProcessDone) {
+		err = errors.Join(err, sigErr)
+	}
+
+	select {
+	case <-p.exited:
+		return err
+	case <-p.clock.After(p.gracePeriod):
+	}
+
+	p.logger.Warn("killing plugin process that did not exit within the shutdown grace period", "grace_period", p.gracePeriod)
+
+	if killErr := p.command.Process.Kill(); killErr != nil && !errors.Is(killErr, os.ErrProcessDone) {
+		err = errors.Join(err, killErr)
+	}
+
+	<-p.exited
+	return errors.Join(err, fmt.Errorf("%w: process %d did not exit within %s", ErrKilled, p.command.Process.Pid, p.gracePeriod))
 }
 
 var (
 	// ErrUnknownCommand is an error returned by Plugin.Exec when attempting to execute a command that does not
 	// exist within the plugin.
 	ErrUnknownCommand = errors.New("unknown command")
+
+	// ErrRequestTooLarge is an error returned by Plugin.Exec when the encoded request is larger than the maximum size
+	// set using WithMaxRequestSize or WithMaxMessageSize.
+	ErrRequestTooLarge = plugin.ErrRequestTooLarge
 )
 
 // Exec executes the named command, providing a proto-encoded input. The provided input will be wrapped in a protobuf
 // Any type. Returns ErrUnknownCommand if the specified command is unknown to the plugin. The command output will be
 // unmarshalled directly into the provided output parameter.
-func (p *Plugin) Exec(ctx context.Context, name string, input proto.Message, output proto.Message) error {
-	err := p.client.Execute(ctx, name, input, output)
-	if status.Code(err) == codes.NotFound {
-		return fmt.Errorf("%w: %q", ErrUnknownCommand, name)
+//
+// If a maximum request size has been set using WithMaxRequestSize and the encoded request exceeds it,
+// ErrRequestTooLarge is returned without the request being sent to the plugin. If a RetryPolicy has been set using
+// WithRetryPolicy, failed attempts are retried for as long as the policy allows.
+//
+// Cancelling the provided context aborts the call and cancels the context provided to the command within the plugin.
+// In this case, the error returned matches ctx.Err().
+//
+// Any deadline of the provided context is propagated to the plugin and set on the context provided to the command.
+// Once the deadline passes, the call returns an error matching context.DeadlineExceeded that names the command, even
+// if the command ignores its context.
+//
+// Errors returned by the command, or describing why it could not be executed, keep the gRPC status code describing
+// them, which can be obtained using status.Code. For example, codes.InvalidArgument when the command rejects its input,
+// codes.DeadlineExceeded when its deadline passes and codes.NotFound when it is unknown to the plugin.
+//
+// If a CircuitBreaker has been set using WithCircuitBreaker and the plugin has failed repeatedly, ErrCircuitOpen is
+// returned without the request being sent to the plugin.
+//
+// If an Audit has been set using WithAudit, a record of the call is written to its sink before Exec returns, whether
+// or not the call succeeds.
+//
+// Any interceptors given using WithExecInterceptors are called before the command is executed, and may modify the
+// call or prevent it from being made.
+//
+// The behaviour of an individual call can be modified using ExecOption functions, such as WithTimeout.
+//
+// All errors are returned as an *ExecError naming the plugin and command, which wraps the errors described above.
+func (p *Plugin) Exec(ctx context.Context, name string, input proto.Message, output proto.Message, opts ...ExecOption) error {
+	invoke := p.invoke
+	if len(p.options.interceptors) > 0 {
+		invoke = chainInterceptors(p.options.interceptors, p.name, p.invoke)
+	}
+
+	if err := invoke(ctx, name, input, output, opts...); err != nil {
+		return &ExecError{Plugin: p.name, Command: name, Err: err}
+	}
+
+	return nil
+}
+
+// invoke executes the named command once any interceptors have been called.
+func (p *Plugin) invoke(ctx context.Context, name string, input proto.Message, output proto.Message, opts ...ExecOption) error {
+	options := newExecOptions(p.options, opts)
+	if p.options.audit == nil {
+		return p.execWithBreaker(ctx, name, input, output, options)
+	}
+
+	return p.audited(ctx, name, input, output, options, func(ctx context.Context) error {
+		return p.execWithBreaker(ctx, name, input, output, options)
+	})
+}
+
+func (p *Plugin) execWithBreaker(ctx context.Context, name string, input proto.Message, output proto.Message, options execOptions) error {
+	if p.breaker == nil {
+		return p.execWithOptions(ctx, name, input, output, options)
 	}
 
+	probe, err := p.breaker.allow(p.name)
 	if err != nil {
-		st, ok := status.FromError(err)
-		if ok {
-			return errors.New(st.Message())
+		return err
+	}
+
+	err = p.execWithOptions(ctx, name, input, output, options)
+	p.breaker.done(ctx, probe, err)
+	return err
+}
+
+func (p *Plugin) execWithOptions(ctx context.Context, name string, input proto.Message, output proto.Message, options execOptions) error {
+	if options.timeout <= 0 {
+		return convertError(ctx, name, p.exec(ctx, name, input, output, options))
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, options.timeout)
+	defer cancel()
+
+	err := convertError(timeoutCtx, name, p.exec(timeoutCtx, name, input, output, options))
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return &statusError{
+			code: codes.DeadlineExceeded,
+			err:  fmt.Errorf("%w: command %q did not complete within %s", context.DeadlineExceeded, name, options.timeout),
 		}
+	}
 
-		return err
+	return err
+}
+
+// exec executes the named command, returning any error from the plugin without converting it, so that callers can
+// inspect its status code.
+func (p *Plugin) exec(ctx context.Context, name string, input proto.Message, output proto.Message, options execOptions) error {
+	start := p.options.clock.Now()
+	payload, err := p.execute(ctx, name, input, output, options)
+	duration := p.options.clock.Now().Sub(start)
+
+	if p.options.observer != nil {
+		p.options.observer.ObserveExec(ExecObservation{
+			Plugin:       p.name,
+			Command:      name,
+			Duration:     duration,
+			RequestSize:  payload.RequestSize,
+			ResponseSize: payload.ResponseSize,
+			Err:          err,
+		})
 	}
 
-	return nil
+	if p.slo != nil {
+		p.slo.record(p.name, duration, err)
+	}
+
+	if err == nil && len(payload.Warnings) > 0 && options.warnings != nil {
+		options.warnings(payload.Warnings)
+	}
+
+	return err
+}
+
+func (p *Plugin) execute(ctx context.Context, name string, input proto.Message, output proto.Message, opts execOptions) (plugin.Payload, error) {
+	if len(opts.metadata) > 0 {
+		ctx = plugin.AppendMetadata(ctx, opts.metadata)
+	}
+
+	// Chunked requests are not constrained by the maximum message size.
+	maxRequestSize := p.options.maxRequestSize
+	if p.options.chunkSize <= 0 && (maxRequestSize <= 0 || maxRequestSize > p.options.maxMessageSize) {
+		maxRequestSize = p.options.maxMessageSize
+	}
+
+	options := plugin.ExecuteOptions{
+		MaxRequestSize: maxRequestSize,
+		RequestID:      requestID(ctx),
+		CallOptions:    opts.callOptions,
+		ChunkSize:      p.options.chunkSize,
+	}
+
+	for attempt := 1; ; attempt++ {
+		proc, release, err := p.acquire(ctx)
+		if err != nil {
+			return plugin.Payload{}, err
+		}
+
+		// The codec is chosen once the plugin has started, as its commands are unknown until then.
+		if options.Codec, err = p.codec(name); err != nil {
+			release()
+			return plugin.Payload{}, err
+		}
+
+		payload, err := p.executeProcess(ctx, proc, name, input, output, options, opts.sharedMemory)
+		release()
+		if err != nil && ctx.Err() == nil {
+			err = p.detectCrash(proc, err)
+		}
+
+		if errors.Is(err, plugin.ErrRequestTooLarge) {
+			return payload, err
+		}
+
+		p.stats.record(name, payload)
+		if opts.result != nil {
+			*opts.result = ExecResult{
+				Duration: payload.Duration,
+				Version:  proc.version,
+				Attempts: attempt,
+				Warnings: payload.Warnings,
+			}
+		}
+
+		if err == nil || !p.retry(ctx, opts.retryPolicy, name, attempt, err) {
+			return payload, err
+		}
+	}
 }
 
-// Commands returns all commands the Plugin provides.
-func (p *Plugin) Commands() []string {
-	return p.info.Commands
+// Commands returns all commands the Plugin provides, including any descriptions given by the plugin. If the Plugin
+// was used with the WithLazyStart option, no commands are returned until it has been started.
+func (p *Plugin) Commands() []CommandInfo {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	commands := make([]CommandInfo, 0, len(p.info.Commands))
+	for _, command := range p.info.Commands {
+		commands = append(commands, CommandInfo{
+			Name:        command.Name,
+			Description: command.Description,
+			Example:     command.Example,
+			Deprecated:  command.Deprecated,
+			Idempotent:  command.Idempotent,
+			Codecs:      command.Codecs,
+		})
+	}
+
+	return commands
+}
+
+// HasCommand returns true if the Plugin provides the named command.
+func (p *Plugin) HasCommand(name string) bool {
+	return slices.ContainsFunc(p.Commands(), func(command CommandInfo) bool {
+		return command.Name == name
+	})
 }
 
 // Name returns the name of the Plugin.
 func (p *Plugin) Name() string {
-	return p.info.Name
+	return p.name
 }
 
-// Version returns the version of the plugin.
+// Version returns the version of the plugin. If the Plugin was used with the WithLazyStart option, the version is
+// empty until it has been started.
 func (p *Plugin) Version() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
 	return p.info.Version
 }
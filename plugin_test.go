@@ -1,16 +1,36 @@
 package plugin_test
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
 	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/davidsbond/plugin"
+	internal "github.com/davidsbond/plugin/internal/plugin"
+	"github.com/davidsbond/plugin/plugintest"
 )
 
 func TestUse(t *testing.T) {
@@ -24,11 +44,21 @@ func TestUse(t *testing.T) {
 	})
 
 	assert.EqualValues(t, "test_plugin", p.Name())
-	assert.NotEmpty(t, p.Version())
+	assert.EqualValues(t, "v1.2.3", p.Version())
 
-	if assert.Len(t, p.Commands(), 1) {
-		assert.EqualValues(t, "pingpong", p.Commands()[0])
-	}
+	assert.EqualValues(t, []string{"pingpong", "sleep", "hang", "crash", "countdown", "pid"}, commandNames(p.Commands()))
+
+	t.Run("commands are described", func(t *testing.T) {
+		commands := p.Commands()
+		assert.EqualValues(t, plugin.CommandInfo{
+			Name:        "pingpong",
+			Description: "Responds to ping with pong, and to pong with ping.",
+			Example:     `"ping"`,
+			Codecs:      []string{"proto", "json"},
+		}, commands[0])
+		assert.True(t, commands[2].Deprecated)
+		assert.False(t, commands[1].Deprecated)
+	})
 
 	t.Run("command pings", func(t *testing.T) {
 		input := wrapperspb.String("ping")
@@ -58,6 +88,23 @@ func TestUse(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("command errors contain details", func(t *testing.T) {
+		input := wrapperspb.String("pung")
+		output := &wrapperspb.StringValue{}
+		err = p.Exec(t.Context(), "pingpong", input, output)
+
+		var pluginErr *plugin.Error
+		require.True(t, errors.As(err, &pluginErr))
+		assert.EqualValues(t, `invalid input "pung", expected "ping" or "pong"`, pluginErr.Message)
+		assert.EqualValues(t, codes.InvalidArgument, pluginErr.Code)
+		require.Len(t, pluginErr.Details, 1)
+
+		detail, ok := pluginErr.Details[0].(*errdetails.BadRequest)
+		require.True(t, ok)
+		require.Len(t, detail.GetFieldViolations(), 1)
+		assert.EqualValues(t, "value", detail.GetFieldViolations()[0].GetField())
+	})
+
 	t.Run("unknown command", func(t *testing.T) {
 		input := wrapperspb.String("pong")
 		output := &wrapperspb.StringValue{}
@@ -67,11 +114,1040 @@ func TestUse(t *testing.T) {
 		assert.True(t, errors.Is(err, plugin.ErrUnknownCommand))
 	})
 
+	t.Run("reports resource usage", func(t *testing.T) {
+		usage, err := p.Usage(t.Context())
+		require.NoError(t, err)
+		assert.NotZero(t, usage.HeapBytes)
+		assert.NotZero(t, usage.SysBytes)
+		assert.NotZero(t, usage.Goroutines)
+	})
+
+	t.Run("reports process details", func(t *testing.T) {
+		details, err := p.Inspect(t.Context())
+		require.NoError(t, err)
+		require.Len(t, details, 1)
+
+		processes := p.Processes()
+		require.Len(t, processes, 1)
+
+		assert.EqualValues(t, processes[0].PID, details[0].PID)
+		assert.False(t, details[0].StartedAt.IsZero())
+		assert.Positive(t, details[0].Uptime)
+		assert.EqualValues(t, runtime.GOOS, details[0].OS)
+		assert.EqualValues(t, runtime.GOARCH, details[0].Arch)
+		assert.EqualValues(t, runtime.Version(), details[0].RuntimeVersion)
+		assert.EqualValues(t, plugin.ProtocolVersion, details[0].ProtocolVersion)
+	})
+
+	t.Run("command is cancelled", func(t *testing.T) {
+		plugintest.AssertExecCancels(t, p, "sleep", durationpb.New(time.Hour), &durationpb.Duration{}, 50*time.Millisecond, time.Second)
+	})
+
+	t.Run("error if context cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		err = p.Exec(ctx, "sleep", durationpb.New(time.Hour), &durationpb.Duration{})
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("error if deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		err = p.Exec(ctx, "sleep", durationpb.New(time.Hour), &durationpb.Duration{})
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
 	t.Run("error if invalid input type", func(t *testing.T) {
 		input := durationpb.New(time.Hour)
 		output := &wrapperspb.StringValue{}
 
-		err = p.Exec(t.Context(), "test", input, output)
+		err = p.Exec(t.Context(), "pingpong", input, output)
+		require.Error(t, err)
+
+		var pluginErr *plugin.Error
+		require.True(t, errors.As(err, &pluginErr))
+		assert.EqualValues(t, codes.InvalidArgument, pluginErr.Code)
+		require.Len(t, pluginErr.Details, 1)
+
+		info, ok := pluginErr.Details[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.EqualValues(t, "INVALID_INPUT_TYPE", info.GetReason())
+		assert.EqualValues(t, "google.protobuf.StringValue", info.GetMetadata()["expected"])
+		assert.EqualValues(t, "google.protobuf.Duration", info.GetMetadata()["received"])
+	})
+}
+
+func TestPlugin_Stats(t *testing.T) {
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithMaxRequestSize(128))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	t.Run("records payload sizes", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+		require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("pong"), output))
+
+		stats := p.Stats().Commands["pingpong"]
+		assert.EqualValues(t, 2, stats.Calls)
+		assert.NotZero(t, stats.MaxRequestBytes)
+		assert.NotZero(t, stats.MaxResponseBytes)
+		assert.EqualValues(t, 2*stats.MaxRequestBytes, stats.RequestBytes)
+		assert.EqualValues(t, 2*stats.MaxResponseBytes, stats.ResponseBytes)
+	})
+
+	t.Run("error if request too large", func(t *testing.T) {
+		input := wrapperspb.String(strings.Repeat("ping", 64))
+		output := &wrapperspb.StringValue{}
+
+		err = p.Exec(t.Context(), "pingpong", input, output)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, plugin.ErrRequestTooLarge))
+		assert.EqualValues(t, 2, p.Stats().Commands["pingpong"].Calls)
+	})
+}
+
+type (
+	CountingRetryPolicy struct {
+		Attempts []int
+		Max      int
+		Delay    time.Duration
+	}
+)
+
+func (c *CountingRetryPolicy) Next(_ context.Context, attempt int, _ error) (time.Duration, bool) {
+	c.Attempts = append(c.Attempts, attempt)
+	return c.Delay, attempt < c.Max
+}
+
+func TestUse_WithRetryPolicy(t *testing.T) {
+	policy := &CountingRetryPolicy{Max: 3, Delay: time.Millisecond}
+
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	output := &wrapperspb.StringValue{}
+	err = p.Exec(t.Context(), "pingpong", wrapperspb.String("pung"), output)
+	require.Error(t, err)
+
+	assert.Equal(t, []int{1, 2, 3}, policy.Attempts)
+	assert.EqualValues(t, 3, p.Stats().Commands["pingpong"].Calls)
+}
+
+func TestUse_WithSocketDirectory(t *testing.T) {
+	directory := t.TempDir()
+
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithSocketDirectory(directory))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	sockets, err := filepath.Glob(filepath.Join(directory, "*.sock"))
+	require.NoError(t, err)
+	require.Len(t, sockets, 1)
+
+	info, err := os.Stat(sockets[0])
+	require.NoError(t, err)
+	assert.EqualValues(t, 0o600, info.Mode().Perm())
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+	assert.EqualValues(t, "pong", output.GetValue())
+}
+
+func TestUse_RejectsUnauthenticatedCalls(t *testing.T) {
+	directory := t.TempDir()
+
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithSocketDirectory(directory))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	sockets, err := filepath.Glob(filepath.Join(directory, "*.sock"))
+	require.NoError(t, err)
+	require.Len(t, sockets, 1)
+
+	client, err := internal.NewClient(sockets[0])
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, client.Close())
+	})
+
+	_, err = client.Stat(t.Context())
+	assert.EqualValues(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestUse_DefaultSocketDirectory(t *testing.T) {
+	directory := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", directory)
+
+	p, err := plugin.Use(t.Context(), "./test_plugin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	sockets, err := filepath.Glob(filepath.Join(directory, "*.sock"))
+	require.NoError(t, err)
+	assert.Len(t, sockets, 1)
+}
+
+func TestUse_VerifyPeer(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("peer verification is only supported on linux")
+	}
+
+	// The wrapper starts the plugin as a child process, so the host is not the plugin's parent and must be rejected.
+	binary, err := filepath.Abs("./test_plugin")
+	require.NoError(t, err)
+
+	wrapper := filepath.Join(t.TempDir(), "test_plugin")
+	script := fmt.Sprintf("#!/bin/sh\n%q \"$@\" &\npid=$!\ntrap 'kill $pid' TERM\nwait $pid\n", binary)
+	require.NoError(t, os.WriteFile(wrapper, []byte(script), 0o755))
+
+	p, err := plugin.Use(t.Context(), wrapper)
+	if p != nil {
+		assert.NoError(t, p.Close())
+	}
+
+	assert.Error(t, err)
+}
+
+func TestUse_WithVersionConstraint(t *testing.T) {
+	tt := []struct {
+		Name        string
+		Constraint  string
+		ExpectError error
+	}{
+		{
+			Name:       "starts when the constraint is satisfied",
+			Constraint: ">=1.2.0, <2.0.0",
+		},
+		{
+			Name:        "fails when the constraint is not satisfied",
+			Constraint:  ">=2.0.0",
+			ExpectError: plugin.ErrIncompatibleVersion,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithVersionConstraint(tc.Constraint))
+			if tc.ExpectError != nil {
+				assert.ErrorIs(t, err, tc.ExpectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NoError(t, p.Close())
+		})
+	}
+}
+
+func TestUse_WithLazyStart(t *testing.T) {
+	directory := t.TempDir()
+
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithLazyStart(), plugin.WithSocketDirectory(directory))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	assert.EqualValues(t, "test_plugin", p.Name())
+	assert.Empty(t, p.Version())
+	assert.Empty(t, p.Commands())
+
+	sockets, err := filepath.Glob(filepath.Join(directory, "*.sock"))
+	require.NoError(t, err)
+	assert.Empty(t, sockets)
+
+	t.Run("starts on first exec", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+		assert.EqualValues(t, "pong", output.GetValue())
+
+		assert.NotEmpty(t, p.Version())
+		assert.EqualValues(t, []string{"pingpong", "sleep", "hang", "crash", "countdown", "pid"}, commandNames(p.Commands()))
+	})
+
+	t.Run("start is a no-op once running", func(t *testing.T) {
+		require.NoError(t, p.Start(t.Context()))
+
+		sockets, err = filepath.Glob(filepath.Join(directory, "*.sock"))
+		require.NoError(t, err)
+		assert.Len(t, sockets, 1)
+	})
+
+	t.Run("errors once closed", func(t *testing.T) {
+		require.NoError(t, p.Close())
+
+		output := &wrapperspb.StringValue{}
+		err = p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output)
+		assert.ErrorIs(t, err, plugin.ErrClosed)
+	})
+}
+
+func TestUse_WithIdleTimeout(t *testing.T) {
+	directory := t.TempDir()
+	sockets := func() []string {
+		matches, err := filepath.Glob(filepath.Join(directory, "*.sock"))
+		require.NoError(t, err)
+		return matches
+	}
+
+	p, err := plugin.Use(t.Context(), "./test_plugin",
+		plugin.WithIdleTimeout(100*time.Millisecond),
+		plugin.WithSocketDirectory(directory),
+	)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	require.Len(t, sockets(), 1)
+	first := sockets()[0]
+
+	t.Run("stops once idle", func(t *testing.T) {
+		assert.Eventually(t, func() bool {
+			return len(sockets()) == 0
+		}, 5*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("restarts on next exec", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+		assert.EqualValues(t, "pong", output.GetValue())
+
+		current := sockets()
+		require.Len(t, current, 1)
+		assert.NotEqual(t, first, current[0])
+	})
+
+	t.Run("does not stop while executing", func(t *testing.T) {
+		output := &durationpb.Duration{}
+		require.NoError(t, p.Exec(t.Context(), "sleep", durationpb.New(300*time.Millisecond), output))
+		assert.Len(t, sockets(), 1)
+	})
+}
+
+func TestPlugin_Close(t *testing.T) {
+	t.Run("waits for the plugin to exit", func(t *testing.T) {
+		p, err := plugin.Use(t.Context(), "./test_plugin")
+		require.NoError(t, err)
+
+		assert.NoError(t, p.Close())
+
+		exits := p.Exits()
+		require.Len(t, exits, 1)
+		assert.NotZero(t, exits[0].PID)
+		assert.True(t, exits[0].Success(), "exit status %+v", exits[0])
+	})
+
+	t.Run("kills the plugin after the grace period", func(t *testing.T) {
+		p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithShutdownGracePeriod(100*time.Millisecond))
+		require.NoError(t, err)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = p.Exec(context.Background(), "hang", &emptypb.Empty{}, &emptypb.Empty{})
+		}()
+
+		// Give the command time to reach the plugin before closing it.
+		time.Sleep(100 * time.Millisecond)
+
+		assert.ErrorIs(t, p.Close(), plugin.ErrKilled)
+		<-done
+
+		exits := p.Exits()
+		require.Len(t, exits, 1)
+		assert.False(t, exits[0].Success())
+		assert.EqualValues(t, -1, exits[0].Code)
+		assert.EqualValues(t, syscall.SIGKILL, exits[0].Signal)
+	})
+}
+
+func TestUse_LaunchOptions(t *testing.T) {
+	binary, err := filepath.Abs("./test_plugin")
+	require.NoError(t, err)
+
+	directory, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+
+	t.Setenv("PLUGIN_TEST_SECRET", "secret")
+
+	tt := []struct {
+		Name   string
+		Check  string
+		Option plugin.UseOption
+	}{
+		{
+			Name:   "sets environment variables",
+			Check:  `[ "$PLUGIN_TEST_VALUE" = "hello" ]`,
+			Option: plugin.WithEnv("PLUGIN_TEST_VALUE=hello"),
+		},
+		{
+			Name:   "limits the inherited environment",
+			Check:  `[ -z "$PLUGIN_TEST_SECRET" ] && [ -n "$PATH" ]`,
+			Option: plugin.WithInheritEnv("PATH"),
+		},
+		{
+			Name:   "passes additional arguments",
+			Check:  `for last; do :; done; [ "$last" = "positional" ]`,
+			Option: plugin.WithArgs("--", "positional"),
+		},
+		{
+			Name:   "sets the working directory",
+			Check:  fmt.Sprintf(`[ "$(pwd -P)" = %q ]`, directory),
+			Option: plugin.WithWorkingDirectory(directory),
+		},
+		{
+			Name:   "sets stdin",
+			Check:  `read -r line; [ "$line" = "hello" ]`,
+			Option: plugin.WithStdin(strings.NewReader("hello\n")),
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			wrapper := filepath.Join(t.TempDir(), "test_plugin")
+			script := fmt.Sprintf("#!/bin/sh\n%s || { echo 'check failed' >&2; exit 3; }\nexec %q \"$@\"\n", tc.Check, binary)
+			require.NoError(t, os.WriteFile(wrapper, []byte(script), 0o755))
+
+			p, err := plugin.Use(t.Context(), wrapper, tc.Option)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				assert.NoError(t, p.Close())
+			})
+
+			output := &wrapperspb.StringValue{}
+			require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+			assert.EqualValues(t, "pong", output.GetValue())
+		})
+	}
+
+	t.Run("error if the plugin does not start within the startup timeout", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sleep_plugin")
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexec sleep 60\n"), 0o755))
+
+		_, err := plugin.Use(t.Context(), path, plugin.WithStartupTimeout(100*time.Millisecond))
+		assert.ErrorIs(t, err, plugin.ErrStartupTimeout)
+	})
+}
+
+func TestUse_WithArgs(t *testing.T) {
+	t.Run("plugins parse declared flags", func(t *testing.T) {
+		p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithArgs("--pong", "hello"))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, p.Close())
+		})
+
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+		assert.EqualValues(t, "hello", output.GetValue())
+	})
+
+	t.Run("error for undeclared flags", func(t *testing.T) {
+		_, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithArgs("--unknown"))
+		require.ErrorIs(t, err, plugin.ErrPluginStartFailed)
+
+		var startErr *plugin.StartError
+		require.ErrorAs(t, err, &startErr)
+		assert.Contains(t, startErr.Stderr, "unknown flag: --unknown")
+	})
+}
+
+func TestUse_StartFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "broken_plugin")
+	script := "#!/bin/sh\necho 'starting' >&2\necho 'panic: failed to initialise' >&2\nexit 3\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o755))
+
+	_, err := plugin.Use(t.Context(), path)
+	require.ErrorIs(t, err, plugin.ErrPluginStartFailed)
+
+	var startErr *plugin.StartError
+	require.ErrorAs(t, err, &startErr)
+	assert.EqualValues(t, path, startErr.Path)
+	assert.EqualValues(t, 3, startErr.Status.Code)
+	assert.Contains(t, startErr.Stderr, "panic: failed to initialise")
+	assert.Contains(t, err.Error(), "exited with code 3")
+}
+
+func TestPlugin_Exec_Crash(t *testing.T) {
+	p, err := plugin.Use(t.Context(), "./test_plugin")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	t.Run("error if plugin crashes", func(t *testing.T) {
+		err = p.Exec(t.Context(), "crash", &emptypb.Empty{}, &emptypb.Empty{})
+		require.ErrorIs(t, err, plugin.ErrPluginCrashed)
+
+		var crashErr *plugin.CrashError
+		require.ErrorAs(t, err, &crashErr)
+		assert.EqualValues(t, "test_plugin", crashErr.Plugin)
+		assert.EqualValues(t, 2, crashErr.Status.Code)
+		assert.Contains(t, crashErr.Stderr, "crashing")
+	})
+
+	t.Run("restarts after a crash", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+		assert.EqualValues(t, "pong", output.GetValue())
+	})
+}
+
+func TestPlugin_Cancel(t *testing.T) {
+	p, err := plugin.Use(t.Context(), "./test_plugin")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	t.Run("cancels in-flight command", func(t *testing.T) {
+		ctx := plugin.ContextWithRequestID(t.Context(), "sleeper")
+
+		errs := make(chan error, 1)
+		go func() {
+			errs <- p.Exec(ctx, "sleep", durationpb.New(time.Minute), &durationpb.Duration{})
+		}()
+
+		require.EventuallyWithT(t, func(c *assert.CollectT) {
+			assert.NoError(c, p.Cancel(t.Context(), "sleeper"))
+		}, 5*time.Second, 10*time.Millisecond)
+
+		assert.ErrorIs(t, <-errs, context.Canceled)
+	})
+
+	t.Run("error if request does not exist", func(t *testing.T) {
+		assert.ErrorIs(t, p.Cancel(t.Context(), "unknown"), plugin.ErrUnknownRequest)
+	})
+}
+
+func TestPlugin_Events(t *testing.T) {
+	p, err := plugin.Use(t.Context(), "./test_plugin")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	t.Run("receives events until cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		events := p.Events(ctx)
+
+		for range 3 {
+			event := <-events
+			assert.EqualValues(t, "test_plugin", event.Plugin)
+			assert.False(t, event.Time.IsZero())
+
+			count := &wrapperspb.Int64Value{}
+			require.NoError(t, event.UnmarshalTo(count))
+			assert.Positive(t, count.GetValue())
+		}
+
+		cancel()
+		for range events {
+		}
+	})
+
+	t.Run("channel is closed with the plugin", func(t *testing.T) {
+		events := p.Events(t.Context())
+		<-events
+
+		require.NoError(t, p.Close())
+		for range events {
+		}
+	})
+}
+
+func commandNames(commands []plugin.CommandInfo) []string {
+	names := make([]string, 0, len(commands))
+	for _, command := range commands {
+		names = append(names, command.Name)
+	}
+
+	return names
+}
+
+func TestPlugin_Describe(t *testing.T) {
+	p, err := plugin.Use(t.Context(), "./test_plugin")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	commands, err := p.Describe(t.Context())
+	require.NoError(t, err)
+	require.Len(t, commands, 6)
+
+	countdown := commands[4]
+	assert.EqualValues(t, "countdown", countdown.Name)
+	assert.EqualValues(t, "google.protobuf.Duration", countdown.Input.FullName())
+	assert.EqualValues(t, "google.protobuf.StringValue", countdown.Output.FullName())
+
+	t.Run("constructs messages from descriptors", func(t *testing.T) {
+		input := dynamicpb.NewMessage(commands[0].Input)
+		input.Set(commands[0].Input.Fields().ByName("value"), protoreflect.ValueOfString("ping"))
+
+		output := dynamicpb.NewMessage(commands[0].Output)
+		require.NoError(t, p.Exec(t.Context(), "pingpong", input, output))
+		assert.EqualValues(t, "pong", output.Get(commands[0].Output.Fields().ByName("value")).String())
+	})
+}
+
+func TestPlugin_ExecJSON(t *testing.T) {
+	p, err := plugin.Use(t.Context(), "./test_plugin")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	tt := []struct {
+		Name          string
+		Command       string
+		Input         string
+		Expected      string
+		ExpectedError error
+	}{
+		{
+			Name:     "converts input and output",
+			Command:  "pingpong",
+			Input:    `"ping"`,
+			Expected: `"pong"`,
+		},
+		{
+			Name:          "error if input does not match",
+			Command:       "pingpong",
+			Input:         `{"value": "ping"}`,
+			ExpectedError: plugin.ErrInvalidJSON,
+		},
+		{
+			Name:          "error if command does not exist",
+			Command:       "unknown",
+			Input:         `{}`,
+			ExpectedError: plugin.ErrUnknownCommand,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			output, err := p.ExecJSON(t.Context(), tc.Command, []byte(tc.Input))
+			if tc.ExpectedError != nil {
+				require.ErrorIs(t, err, tc.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.Expected, string(output))
+		})
+	}
+}
+
+func TestRun_Describe(t *testing.T) {
+	output, err := exec.CommandContext(t.Context(), "./test_plugin", "describe").Output()
+	require.NoError(t, err)
+
+	var description struct {
+		Name     string `json:"name"`
+		Version  string `json:"version"`
+		Commands []struct {
+			Name       string `json:"name"`
+			Deprecated bool   `json:"deprecated"`
+			Input      string `json:"input"`
+			Output     string `json:"output"`
+		} `json:"commands"`
+	}
+
+	require.NoError(t, json.Unmarshal(output, &description))
+	assert.EqualValues(t, "test_plugin", description.Name)
+	assert.EqualValues(t, "v1.2.3", description.Version)
+	require.Len(t, description.Commands, 6)
+	assert.EqualValues(t, "pingpong", description.Commands[0].Name)
+	assert.EqualValues(t, "type.googleapis.com/google.protobuf.StringValue", description.Commands[0].Input)
+	assert.EqualValues(t, "type.googleapis.com/google.protobuf.StringValue", description.Commands[0].Output)
+	assert.True(t, description.Commands[2].Deprecated)
+}
+
+func TestRun_Exec(t *testing.T) {
+	tt := []struct {
+		Name          string
+		Args          []string
+		Stdin         string
+		Expected      string
+		ExpectedError string
+	}{
+		{
+			Name:     "reads input from stdin",
+			Args:     []string{"exec", "pingpong"},
+			Stdin:    `"ping"`,
+			Expected: `"pong"`,
+		},
+		{
+			Name:     "reads input from flag",
+			Args:     []string{"exec", "pingpong", "--input", `"pong"`},
+			Expected: `"ping"`,
+		},
+		{
+			Name:     "parses plugin flags",
+			Args:     []string{"exec", "pingpong", "--input", `"ping"`, "--pong", "hello"},
+			Expected: `"hello"`,
+		},
+		{
+			Name:          "error if command fails",
+			Args:          []string{"exec", "pingpong", "--input", `"pung"`},
+			ExpectedError: `invalid input "pung"`,
+		},
+		{
+			Name:          "error if command does not exist",
+			Args:          []string{"exec", "unknown", "--input", `{}`},
+			ExpectedError: "unknown command",
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			var stderr strings.Builder
+
+			cmd := exec.CommandContext(t.Context(), "./test_plugin", tc.Args...)
+			cmd.Stdin = strings.NewReader(tc.Stdin)
+			cmd.Stderr = &stderr
+
+			output, err := cmd.Output()
+			if tc.ExpectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, stderr.String(), tc.ExpectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tc.Expected, string(output))
+		})
+	}
+}
+
+func TestPlugin_Exec_Deadline(t *testing.T) {
+	t.Parallel()
+
+	p := plugintest.New(t, plugin.Config{
+		Name:                    "deadline",
+		CancellationGracePeriod: 10 * time.Millisecond,
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*emptypb.Empty, *durationpb.Duration]{
+				Use: "deadline",
+				Run: func(ctx context.Context, input *emptypb.Empty) (*durationpb.Duration, error) {
+					deadline, ok := ctx.Deadline()
+					if !ok {
+						return nil, errors.New("no deadline")
+					}
+
+					return durationpb.New(time.Until(deadline)), nil
+				},
+			},
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{
+				Use: "ignore",
+				Run: func(ctx context.Context, input *emptypb.Empty) (*emptypb.Empty, error) {
+					time.Sleep(time.Second)
+					return &emptypb.Empty{}, nil
+				},
+			},
+		},
+	})
+
+	t.Run("propagates deadline to command", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), time.Minute)
+		defer cancel()
+
+		output := &durationpb.Duration{}
+		require.NoError(t, p.Exec(ctx, "deadline", &emptypb.Empty{}, output))
+		assert.Greater(t, output.AsDuration(), 50*time.Second)
+		assert.LessOrEqual(t, output.AsDuration(), time.Minute)
+	})
+
+	t.Run("error if command ignores deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		err := p.Exec(ctx, "ignore", &emptypb.Empty{}, &emptypb.Empty{})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), `command "ignore" did not complete before its deadline`)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+}
+
+func TestPlugin_Exec_Options(t *testing.T) {
+	t.Parallel()
+
+	useDefault := &CountingRetryPolicy{Max: 2, Delay: time.Millisecond}
+	p := plugintest.New(t, plugin.Config{
+		Name:                    "options",
+		CancellationGracePeriod: 10 * time.Millisecond,
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{
+				Use: "ignore",
+				Run: func(ctx context.Context, input *emptypb.Empty) (*emptypb.Empty, error) {
+					time.Sleep(time.Second)
+					return &emptypb.Empty{}, nil
+				},
+			},
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "metadata",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					return wrapperspb.String(plugin.MetadataFromContext(ctx)[input.GetValue()]), nil
+				},
+			},
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{
+				Use: "fail",
+				Run: func(ctx context.Context, input *emptypb.Empty) (*emptypb.Empty, error) {
+					return nil, errors.New("failed")
+				},
+			},
+		},
+	}, plugin.WithRetryPolicy(useDefault))
+
+	t.Run("WithTimeout", func(t *testing.T) {
+		start := time.Now()
+		err := p.Exec(t.Context(), "ignore", &emptypb.Empty{}, &emptypb.Empty{}, plugin.WithTimeout(50*time.Millisecond))
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Contains(t, err.Error(), `command "ignore" did not complete within 50ms`)
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+	})
+
+	t.Run("WithMetadata", func(t *testing.T) {
+		ctx := plugin.ContextWithMetadata(t.Context(), map[string]string{"tenant": "a"})
+
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(ctx, "metadata", wrapperspb.String("tenant"), output, plugin.WithMetadata(map[string]string{"Tenant": "b"})))
+		assert.Equal(t, "b", output.GetValue())
+	})
+
+	t.Run("WithExecRetryPolicy", func(t *testing.T) {
+		policy := &CountingRetryPolicy{Max: 3, Delay: time.Millisecond}
+		defaults := len(useDefault.Attempts)
+
+		err := p.Exec(t.Context(), "fail", &emptypb.Empty{}, &emptypb.Empty{}, plugin.WithExecRetryPolicy(policy))
 		require.Error(t, err)
+		assert.Equal(t, []int{1, 2, 3}, policy.Attempts)
+		assert.Len(t, useDefault.Attempts, defaults)
+	})
+
+	t.Run("WithCallOptions", func(t *testing.T) {
+		err := p.Exec(t.Context(), "metadata", wrapperspb.String("tenant"), &wrapperspb.StringValue{},
+			plugin.WithExecRetryPolicy(nil),
+			plugin.WithCallOptions(grpc.MaxCallSendMsgSize(1)),
+		)
+
+		var e *plugin.Error
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, codes.ResourceExhausted, e.Code)
+	})
+}
+
+func TestPlugin_Exec_MaxMessageSize(t *testing.T) {
+	t.Parallel()
+
+	config := plugin.Config{
+		Name: "messages",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.BytesValue, *wrapperspb.BytesValue]{
+				Use: "echo",
+				Run: func(ctx context.Context, input *wrapperspb.BytesValue) (*wrapperspb.BytesValue, error) {
+					return input, nil
+				},
+			},
+		},
+	}
+
+	large := wrapperspb.Bytes(make([]byte, 8<<20))
+
+	t.Run("exchanges messages larger than the gRPC default", func(t *testing.T) {
+		p := plugintest.New(t, config)
+
+		output := &wrapperspb.BytesValue{}
+		require.NoError(t, p.Exec(t.Context(), "echo", large, output))
+		assert.Len(t, output.GetValue(), len(large.GetValue()))
+	})
+
+	t.Run("host rejects requests larger than its maximum", func(t *testing.T) {
+		p := plugintest.New(t, config, plugin.WithMaxMessageSize(1<<20))
+
+		err := p.Exec(t.Context(), "echo", large, &wrapperspb.BytesValue{})
+		require.ErrorIs(t, err, plugin.ErrRequestTooLarge)
+	})
+
+	t.Run("plugin rejects requests larger than its maximum", func(t *testing.T) {
+		limited := config
+		limited.MaxMessageSize = 1 << 20
+		p := plugintest.New(t, limited)
+
+		var e *plugin.Error
+		err := p.Exec(t.Context(), "echo", large, &wrapperspb.BytesValue{})
+		require.ErrorAs(t, err, &e)
+		assert.Equal(t, codes.ResourceExhausted, e.Code)
+	})
+}
+
+type compressionRecorder struct {
+	compression atomic.Value
+}
+
+func (r *compressionRecorder) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleRPC(_ context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok && header.FullMethod == "/plugin.PluginService/Execute" {
+		r.compression.Store(header.Compression)
+	}
+}
+
+func (r *compressionRecorder) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *compressionRecorder) HandleConn(context.Context, stats.ConnStats) {}
+
+func TestUse_WithCompressor(t *testing.T) {
+	t.Parallel()
+
+	recorder := &compressionRecorder{}
+	config := plugin.Config{
+		Name:          "compressed",
+		ServerOptions: []grpc.ServerOption{grpc.StatsHandler(recorder)},
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "echo",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					return input, nil
+				},
+			},
+		},
+	}
+
+	t.Run("compresses requests", func(t *testing.T) {
+		p := plugintest.New(t, config, plugin.WithCompressor(plugin.GzipCompressor))
+
+		input := wrapperspb.String(strings.Repeat("plugin", 1024))
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "echo", input, output))
+		assert.Equal(t, input.GetValue(), output.GetValue())
+		assert.Equal(t, plugin.GzipCompressor, recorder.compression.Load())
+	})
+
+	t.Run("error if compressor is not registered", func(t *testing.T) {
+		_, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithCompressor("unknown"))
+		assert.EqualError(t, err, `compressor "unknown" is not registered`)
+	})
+}
+
+func TestUse_WithChunking(t *testing.T) {
+	t.Parallel()
+
+	p := plugintest.New(t, plugin.Config{
+		Name:           "chunked",
+		MaxMessageSize: 1 << 20,
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.BytesValue, *wrapperspb.BytesValue]{
+				Use: "echo",
+				Run: func(ctx context.Context, input *wrapperspb.BytesValue) (*wrapperspb.BytesValue, error) {
+					return input, nil
+				},
+			},
+		},
+	}, plugin.WithMaxMessageSize(1<<20), plugin.WithChunking(256<<10))
+
+	input := wrapperspb.Bytes(make([]byte, 8<<20))
+	output := &wrapperspb.BytesValue{}
+	require.NoError(t, p.Exec(t.Context(), "echo", input, output))
+	assert.Len(t, output.GetValue(), len(input.GetValue()))
+}
+
+func TestServe(t *testing.T) {
+	t.Parallel()
+
+	t.Run("serves commands until the context is cancelled", func(t *testing.T) {
+		socket := filepath.Join(t.TempDir(), "serve.sock")
+
+		ctx, cancel := context.WithCancel(t.Context())
+		errs := make(chan error, 1)
+		go func() {
+			errs <- plugin.Serve(ctx, plugin.Config{
+				Name:    "serve",
+				Version: "v1.0.0",
+				Socket:  socket,
+				Commands: []plugin.CommandHandler{
+					&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+						Use: "echo",
+						Run: func(_ context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+							return input, nil
+						},
+					},
+				},
+			})
+		}()
+
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(socket)
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+
+		client, err := internal.NewClient(socket)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, client.Close())
+		})
+
+		info, err := client.Stat(t.Context())
+		require.NoError(t, err)
+		assert.Equal(t, "serve", info.Name)
+		assert.Equal(t, "v1.0.0", info.Version)
+
+		output := &wrapperspb.StringValue{}
+		_, err = client.Execute(t.Context(), "echo", wrapperspb.String("hello"), output, internal.ExecuteOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "hello", output.GetValue())
+
+		cancel()
+		require.NoError(t, <-errs)
+		assert.NoFileExists(t, socket)
+	})
+
+	t.Run("error if no socket is given", func(t *testing.T) {
+		assert.ErrorIs(t, plugin.Serve(t.Context(), plugin.Config{Name: "serve"}), plugin.ErrNoSocket)
 	})
 }
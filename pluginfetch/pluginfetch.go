@@ -0,0 +1,599 @@
+// Package pluginfetch downloads plugin binaries and archives over HTTP(S), storing them in a local cache keyed by their
+// SHA-256 digest. Each download is validated against the digest expected by the host application before it is made
+// available, and interrupted downloads are resumed from where they stopped the next time they are fetched.
+//
+// URLs whose path ends with pluginpkg.Extension are treated as archives, and the binary for the current platform is
+// extracted from them using pluginpkg.Extract. Any other URL is treated as a plugin binary. In both cases, the path
+// returned by Fetcher.Fetch can be provided to plugin.Use.
+package pluginfetch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/pluginpkg"
+)
+
+type (
+	// The Fetcher type downloads plugins into a local cache. A Fetcher is safe for concurrent use.
+	Fetcher struct {
+		directory string
+		client    *http.Client
+		maxSize   int64
+		maxAge    time.Duration
+		extract   []pluginpkg.ExtractOption
+
+		// mu guards the entries within the cache directory. It is only held while entries are looked up, committed
+		// or removed, so that downloads do not block each other.
+		mu sync.Mutex
+
+		// digestsMu guards digests, which holds a lock for each digest being fetched or evicted, so that the same
+		// plugin is only downloaded once at a time.
+		digestsMu sync.Mutex
+		digests   map[string]*digestLock
+	}
+
+	// digestLock is held while a single digest is fetched or evicted.
+	digestLock struct {
+		held  chan struct{}
+		users int
+	}
+
+	// The Option type is a function that modifies the behaviour of a Fetcher.
+	Option func(f *Fetcher)
+
+	// The Entry type describes a plugin stored within the cache.
+	Entry struct {
+		// The hex-encoded SHA-256 digest of the downloaded binary or archive.
+		Digest string
+		// The Path to the plugin binary, which can be provided to plugin.Use.
+		Path string
+		// The Size of the entry on disk, in bytes.
+		Size int64
+		// When the entry was last returned by Fetcher.Fetch.
+		LastUsed time.Time
+	}
+)
+
+var (
+	// ErrInvalidDigest is the error returned by Fetcher.Fetch when the expected digest is not a hex-encoded SHA-256
+	// digest.
+	ErrInvalidDigest = errors.New("invalid digest")
+
+	// ErrUnexpectedStatus is the error returned by Fetcher.Fetch when the server responds with a status code other than
+	// 200 OK or 206 Partial Content.
+	ErrUnexpectedStatus = errors.New("unexpected status")
+)
+
+const (
+	partialExtension = ".partial"
+	tempPrefix       = ".tmp-"
+)
+
+// WithDirectory is an Option that stores the cache within the given directory, rather than a "plugin" directory
+// within the cache directory of the current user, as returned by os.UserCacheDir.
+func WithDirectory(directory string) Option {
+	return func(f *Fetcher) {
+		f.directory = directory
+	}
+}
+
+// WithHTTPClient is an Option that uses the provided http.Client to download plugins, rather than http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(f *Fetcher) {
+		f.client = client
+	}
+}
+
+// WithMaxSize is an Option that limits the total size of the cache, in bytes. When a fetch causes the cache to exceed
+// the limit, the least recently used entries are evicted until it no longer does. By default, the size of the cache is
+// not limited.
+func WithMaxSize(size int64) Option {
+	return func(f *Fetcher) {
+		f.maxSize = size
+	}
+}
+
+// WithMaxAge is an Option that evicts entries, and incomplete downloads, that have not been used within the given
+// duration. By default, entries are never evicted due to their age.
+func WithMaxAge(age time.Duration) Option {
+	return func(f *Fetcher) {
+		f.maxAge = age
+	}
+}
+
+// WithExtractOptions is an Option that provides the options used to extract binaries from archives, such as the
+// trusted public keys given using pluginpkg.WithPublicKeys.
+func WithExtractOptions(opts ...pluginpkg.ExtractOption) Option {
+	return func(f *Fetcher) {
+		f.extract = append(f.extract, opts...)
+	}
+}
+
+// New returns a Fetcher that stores plugins within the cache directory of the current user. Use WithDirectory to store
+// them elsewhere.
+func New(opts ...Option) (*Fetcher, error) {
+	f := &Fetcher{
+		client: http.DefaultClient,
+	}
+
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	if f.directory == "" {
+		directory, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine cache directory: %w", err)
+		}
+
+		f.directory = filepath.Join(directory, "plugin")
+	}
+
+	if err := os.MkdirAll(f.directory, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory %q: %w", f.directory, err)
+	}
+
+	return f, nil
+}
+
+// Fetch returns the path to the plugin at the given URL, downloading it into the cache if it is not already present.
+// The digest is the hex-encoded SHA-256 digest of the binary or archive served at the URL. Returns
+// plugin.ErrChecksumMismatch if the downloaded content does not match it, in which case nothing is added to the cache.
+//
+// If the download is interrupted, such as by the cancellation of the context, the content received so far is kept and
+// the next call to Fetch for the same digest requests only the remainder, using an HTTP range request. Concurrent
+// calls to Fetch for the same digest wait for the first to complete rather than downloading it again, while those for
+// other digests proceed independently.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL, digest string) (string, error) {
+	digest, err := parseDigest(digest)
+	if err != nil {
+		return "", err
+	}
+
+	location, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse url %q: %w", rawURL, err)
+	}
+
+	release, err := f.lockDigest(ctx, digest)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+
+	entry := filepath.Join(f.directory, digest)
+	if binary, err := f.lookup(entry); err == nil {
+		return binary, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+
+	partial := entry + partialExtension
+	if err = f.download(ctx, location.String(), partial, digest); err != nil {
+		return "", err
+	}
+
+	binary, err := f.install(location, partial, entry)
+	if err != nil {
+		return "", err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err = f.prune(digest); err != nil {
+		return "", err
+	}
+
+	return binary, nil
+}
+
+// Use fetches the plugin at the given URL and starts it using plugin.Use with the provided options. When the URL
+// refers to a plugin binary rather than an archive, the digest of the cached binary is verified again as it is
+// started, as if plugin.WithChecksum had been provided.
+func (f *Fetcher) Use(ctx context.Context, rawURL, digest string, opts ...plugin.UseOption) (*plugin.Plugin, error) {
+	binary, err := f.Fetch(ctx, rawURL, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isArchive(rawURL) {
+		opts = append([]plugin.UseOption{plugin.WithChecksum(digest)}, opts...)
+	}
+
+	return plugin.Use(ctx, binary, opts...)
+}
+
+// Source returns a plugin.Source that fetches the plugin at the given URL, so that running plugins can be upgraded to
+// it using plugin.Manager.Upgrade.
+func (f *Fetcher) Source(rawURL, digest string) plugin.Source {
+	return plugin.SourceFunc(func(ctx context.Context) (string, error) {
+		return f.Fetch(ctx, rawURL, digest)
+	})
+}
+
+// Entries returns the plugins stored within the cache, from the least to the most recently used.
+func (f *Fetcher) Entries() ([]Entry, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.entries()
+}
+
+// Evict removes the plugin with the given digest from the cache, along with any incomplete download of it. Evicting a
+// plugin that is not in the cache is not an error. If the plugin is being fetched, Evict waits for the fetch to
+// complete.
+func (f *Fetcher) Evict(digest string) error {
+	digest, err := parseDigest(digest)
+	if err != nil {
+		return err
+	}
+
+	release, err := f.lockDigest(context.Background(), digest)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entry := filepath.Join(f.directory, digest)
+	if err = os.RemoveAll(entry); err != nil {
+		return fmt.Errorf("failed to evict %q: %w", digest, err)
+	}
+
+	if err = os.Remove(entry + partialExtension); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to evict %q: %w", digest, err)
+	}
+
+	return nil
+}
+
+// Prune evicts entries from the cache according to the limits given using WithMaxSize and WithMaxAge. It is called
+// automatically after each download, but can also be called periodically by host applications.
+func (f *Fetcher) Prune() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.prune("")
+}
+
+// lockDigest waits until no other fetch or eviction of the digest is in progress, or the context is cancelled.
+// The returned function releases the lock.
+func (f *Fetcher) lockDigest(ctx context.Context, digest string) (func(), error) {
+	f.digestsMu.Lock()
+	if f.digests == nil {
+		f.digests = make(map[string]*digestLock)
+	}
+
+	lock, ok := f.digests[digest]
+	if !ok {
+		lock = &digestLock{held: make(chan struct{}, 1)}
+		f.digests[digest] = lock
+	}
+
+	lock.users++
+	f.digestsMu.Unlock()
+
+	done := func() {
+		f.digestsMu.Lock()
+		defer f.digestsMu.Unlock()
+
+		lock.users--
+		if lock.users == 0 {
+			delete(f.digests, digest)
+		}
+	}
+
+	select {
+	case lock.held <- struct{}{}:
+		return func() {
+			<-lock.held
+			done()
+		}, nil
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+}
+
+// lookup returns the path to the binary within the cache entry, marking it as recently used.
+func (f *Fetcher) lookup(entry string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	files, err := os.ReadDir(entry)
+	if err != nil {
+		return "", err
+	}
+
+	if len(files) != 1 {
+		return "", fmt.Errorf("cache entry %q is corrupt", entry)
+	}
+
+	now := time.Now()
+	if err = os.Chtimes(entry, now, now); err != nil {
+		return "", fmt.Errorf("failed to update cache entry %q: %w", entry, err)
+	}
+
+	return filepath.Join(entry, files[0].Name()), nil
+}
+
+// download writes the content at the URL to the partial file, resuming from its current size, and verifies that the
+// complete content matches the digest.
+func (f *Fetcher) download(ctx context.Context, location, partial, digest string) error {
+	file, err := os.OpenFile(partial, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open %q: %w", partial, err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	offset, err := io.Copy(hash, file)
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", partial, err)
+	}
+
+	response, err := f.get(ctx, location, offset)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusPartialContent && offset > 0:
+		if !strings.HasPrefix(response.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			return fmt.Errorf("%w: unexpected content range %q", ErrUnexpectedStatus, response.Header.Get("Content-Range"))
+		}
+	case response.StatusCode == http.StatusOK:
+		// The server does not support range requests, so the download starts again.
+		if err = file.Truncate(0); err != nil {
+			return fmt.Errorf("failed to truncate %q: %w", partial, err)
+		}
+
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return fmt.Errorf("failed to truncate %q: %w", partial, err)
+		}
+
+		hash.Reset()
+	case response.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is already as large as the content, which happens if the process stopped after the download
+		// completed but before it was installed.
+		if hex.EncodeToString(hash.Sum(nil)) == digest {
+			return nil
+		}
+
+		if err = os.Remove(partial); err != nil {
+			return fmt.Errorf("failed to remove %q: %w", partial, err)
+		}
+
+		return fmt.Errorf("%w: %s", ErrUnexpectedStatus, response.Status)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnexpectedStatus, response.Status)
+	}
+
+	if _, err = io.Copy(io.MultiWriter(file, hash), response.Body); err != nil {
+		return fmt.Errorf("failed to download %q: %w", location, err)
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); actual != digest {
+		if err = os.Remove(partial); err != nil {
+			return fmt.Errorf("failed to remove %q: %w", partial, err)
+		}
+
+		return fmt.Errorf("%w: expected %q, got %q", plugin.ErrChecksumMismatch, digest, actual)
+	}
+
+	return nil
+}
+
+func (f *Fetcher) get(ctx context.Context, location string, offset int64) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	response, err := f.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %q: %w", location, err)
+	}
+
+	return response, nil
+}
+
+// install moves the verified download into its cache entry, extracting the binary from it if it is an archive. The
+// entry is populated within a temporary directory that is renamed into place, so incomplete entries are never
+// visible, and only the rename is made while holding the lock on the cache.
+func (f *Fetcher) install(location *url.URL, partial, entry string) (string, error) {
+	temp, err := os.MkdirTemp(f.directory, tempPrefix)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cache entry: %w", err)
+	}
+	defer os.RemoveAll(temp)
+
+	var name string
+	if isArchive(location.Path) {
+		archive, err := os.Open(partial)
+		if err != nil {
+			return "", fmt.Errorf("failed to open %q: %w", partial, err)
+		}
+		defer archive.Close()
+
+		binary, _, err := pluginpkg.Extract(archive, temp, f.extract...)
+		if err != nil {
+			// The archive matches the expected digest, so downloading it again would not produce a different result.
+			return "", errors.Join(err, os.Remove(partial))
+		}
+
+		name = filepath.Base(binary)
+		if err = os.Remove(partial); err != nil {
+			return "", fmt.Errorf("failed to remove %q: %w", partial, err)
+		}
+	} else {
+		name = path.Base(location.Path)
+		if name == "." || name == "/" {
+			name = filepath.Base(entry)
+		}
+
+		if err = os.Chmod(partial, 0o755); err != nil {
+			return "", fmt.Errorf("failed to make %q executable: %w", partial, err)
+		}
+
+		if err = os.Rename(partial, filepath.Join(temp, name)); err != nil {
+			return "", fmt.Errorf("failed to create cache entry: %w", err)
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err = os.Rename(temp, entry); err != nil {
+		return "", fmt.Errorf("failed to create cache entry: %w", err)
+	}
+
+	return filepath.Join(entry, name), nil
+}
+
+// entries returns the entries within the cache, ordered from the least to the most recently used.
+func (f *Fetcher) entries() ([]Entry, error) {
+	files, err := os.ReadDir(f.directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory %q: %w", f.directory, err)
+	}
+
+	entries := make([]Entry, 0, len(files))
+	for _, file := range files {
+		if !file.IsDir() || strings.HasPrefix(file.Name(), tempPrefix) {
+			continue
+		}
+
+		info, err := file.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cache entry %q: %w", file.Name(), err)
+		}
+
+		directory := filepath.Join(f.directory, file.Name())
+		binaries, err := os.ReadDir(directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read cache entry %q: %w", file.Name(), err)
+		}
+
+		entry := Entry{
+			Digest:   file.Name(),
+			LastUsed: info.ModTime(),
+		}
+
+		for _, binary := range binaries {
+			info, err := binary.Info()
+			if err != nil {
+				return nil, fmt.Errorf("failed to read cache entry %q: %w", file.Name(), err)
+			}
+
+			entry.Path = filepath.Join(directory, binary.Name())
+			entry.Size += info.Size()
+		}
+
+		entries = append(entries, entry)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return a.LastUsed.Compare(b.LastUsed)
+	})
+
+	return entries, nil
+}
+
+// prune evicts entries according to the configured limits, other than the entry with the given digest.
+func (f *Fetcher) prune(keep string) error {
+	if f.maxAge <= 0 && f.maxSize <= 0 {
+		return nil
+	}
+
+	if f.maxAge > 0 {
+		partials, err := filepath.Glob(filepath.Join(f.directory, "*"+partialExtension))
+		if err != nil {
+			return fmt.Errorf("failed to read cache directory %q: %w", f.directory, err)
+		}
+
+		for _, partial := range partials {
+			info, err := os.Stat(partial)
+			if err != nil || time.Since(info.ModTime()) <= f.maxAge {
+				continue
+			}
+
+			if err = os.Remove(partial); err != nil {
+				return fmt.Errorf("failed to remove %q: %w", partial, err)
+			}
+		}
+	}
+
+	entries, err := f.entries()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	for _, entry := range entries {
+		if entry.Digest == keep {
+			continue
+		}
+
+		expired := f.maxAge > 0 && time.Since(entry.LastUsed) > f.maxAge
+		oversized := f.maxSize > 0 && total > f.maxSize
+		if !expired && !oversized {
+			continue
+		}
+
+		if err = os.RemoveAll(filepath.Join(f.directory, entry.Digest)); err != nil {
+			return fmt.Errorf("failed to evict %q: %w", entry.Digest, err)
+		}
+
+		total -= entry.Size
+	}
+
+	return nil
+}
+
+// parseDigest returns the digest in lower case, or ErrInvalidDigest if it is not a hex-encoded SHA-256 digest. This
+// also prevents digests from referring to locations outside the cache directory.
+func parseDigest(digest string) (string, error) {
+	digest = strings.ToLower(digest)
+	if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDigest, digest)
+	}
+
+	return digest, nil
+}
+
+func isArchive(location string) bool {
+	if parsed, err := url.Parse(location); err == nil {
+		location = parsed.Path
+	}
+
+	return strings.HasSuffix(location, pluginpkg.Extension)
+}
@@ -0,0 +1,423 @@
+package pluginfetch_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/pluginfetch"
+	"github.com/davidsbond/plugin/pluginpkg"
+)
+
+type (
+	server struct {
+		*httptest.Server
+
+		mu       sync.Mutex
+		ranges   []string
+		contents map[string][]byte
+	}
+)
+
+func newServer(t *testing.T, contents map[string][]byte) *server {
+	t.Helper()
+
+	s := &server{contents: contents}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+		s.mu.Unlock()
+
+		content, ok := s.contents[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, bytes.NewReader(content))
+	}))
+
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *server) requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.ranges
+}
+
+func digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestFetcher_Fetch(t *testing.T) {
+	t.Parallel()
+
+	binary := []byte("plugin binary")
+	s := newServer(t, map[string][]byte{
+		"/my-plugin": binary,
+	})
+
+	tt := []struct {
+		Name   string
+		Path   string
+		Digest string
+		Err    error
+	}{
+		{
+			Name:   "downloads plugin binaries",
+			Path:   "/my-plugin",
+			Digest: digest(binary),
+		},
+		{
+			Name:   "accepts upper case digests",
+			Path:   "/my-plugin",
+			Digest: strings.ToUpper(digest(binary)),
+		},
+		{
+			Name:   "error for mismatched digests",
+			Path:   "/my-plugin",
+			Digest: digest([]byte("other binary")),
+			Err:    plugin.ErrChecksumMismatch,
+		},
+		{
+			Name:   "error for unexpected status codes",
+			Path:   "/unknown",
+			Digest: digest(binary),
+			Err:    pluginfetch.ErrUnexpectedStatus,
+		},
+		{
+			Name:   "error for invalid digests",
+			Path:   "/my-plugin",
+			Digest: "../../etc",
+			Err:    pluginfetch.ErrInvalidDigest,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			fetcher, err := pluginfetch.New(pluginfetch.WithDirectory(t.TempDir()))
+			require.NoError(t, err)
+
+			path, err := fetcher.Fetch(t.Context(), s.URL+tc.Path, tc.Digest)
+			if tc.Err != nil {
+				require.ErrorIs(t, err, tc.Err)
+
+				entries, err := fetcher.Entries()
+				require.NoError(t, err)
+				assert.Empty(t, entries)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.EqualValues(t, "my-plugin", filepath.Base(path))
+
+			contents, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.EqualValues(t, binary, contents)
+		})
+	}
+}
+
+func TestFetcher_Fetch_Cached(t *testing.T) {
+	t.Parallel()
+
+	binary := []byte("plugin binary")
+	s := newServer(t, map[string][]byte{
+		"/my-plugin": binary,
+	})
+
+	fetcher, err := pluginfetch.New(pluginfetch.WithDirectory(t.TempDir()))
+	require.NoError(t, err)
+
+	first, err := fetcher.Fetch(t.Context(), s.URL+"/my-plugin", digest(binary))
+	require.NoError(t, err)
+
+	second, err := fetcher.Fetch(t.Context(), s.URL+"/my-plugin", digest(binary))
+	require.NoError(t, err)
+
+	assert.EqualValues(t, first, second)
+	assert.Len(t, s.requests(), 1)
+}
+
+func TestFetcher_Fetch_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	slow, fast := []byte("slow plugin binary"), []byte("fast plugin binary")
+
+	var (
+		requested = make(chan struct{}, 2)
+		unblock   = make(chan struct{})
+		requests  atomic.Int32
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content := fast
+		if r.URL.Path == "/slow" {
+			requests.Add(1)
+			requested <- struct{}{}
+			<-unblock
+			content = slow
+		}
+
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(server.Close)
+
+	fetcher, err := pluginfetch.New(pluginfetch.WithDirectory(t.TempDir()))
+	require.NoError(t, err)
+
+	var (
+		wg    sync.WaitGroup
+		paths [2]string
+		errs  [2]error
+	)
+
+	for i := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			paths[i], errs[i] = fetcher.Fetch(t.Context(), server.URL+"/slow", digest(slow))
+		}()
+	}
+
+	<-requested
+
+	t.Run("does not block other digests", func(t *testing.T) {
+		_, err := fetcher.Fetch(t.Context(), server.URL+"/fast", digest(fast))
+		require.NoError(t, err)
+
+		entries, err := fetcher.Entries()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.EqualValues(t, digest(fast), entries[0].Digest)
+	})
+
+	t.Run("waits for fetches of the same digest", func(t *testing.T) {
+		close(unblock)
+		wg.Wait()
+
+		require.NoError(t, errs[0])
+		require.NoError(t, errs[1])
+		assert.EqualValues(t, paths[0], paths[1])
+		assert.EqualValues(t, 1, requests.Load())
+	})
+
+	t.Run("stops waiting once cancelled", func(t *testing.T) {
+		blocked := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requested <- struct{}{}
+			<-r.Context().Done()
+		}))
+		t.Cleanup(blocked.Close)
+
+		content := []byte("blocked plugin binary")
+		ctx, cancel := context.WithCancel(t.Context())
+
+		fetched := make(chan error, 1)
+		go func() {
+			_, err := fetcher.Fetch(ctx, blocked.URL+"/blocked", digest(content))
+			fetched <- err
+		}()
+
+		<-requested
+
+		waiting, stop := context.WithTimeout(t.Context(), 50*time.Millisecond)
+		defer stop()
+
+		_, err := fetcher.Fetch(waiting, blocked.URL+"/blocked", digest(content))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+		cancel()
+		assert.ErrorIs(t, <-fetched, context.Canceled)
+	})
+}
+
+func TestFetcher_Fetch_Resume(t *testing.T) {
+	t.Parallel()
+
+	binary := []byte("a plugin binary that was partially downloaded")
+	s := newServer(t, map[string][]byte{
+		"/my-plugin": binary,
+	})
+
+	directory := t.TempDir()
+	partial := filepath.Join(directory, digest(binary)+".partial")
+	require.NoError(t, os.WriteFile(partial, binary[:10], 0o600))
+
+	fetcher, err := pluginfetch.New(pluginfetch.WithDirectory(directory))
+	require.NoError(t, err)
+
+	path, err := fetcher.Fetch(t.Context(), s.URL+"/my-plugin", digest(binary))
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.EqualValues(t, binary, contents)
+	assert.EqualValues(t, []string{"bytes=10-"}, s.requests())
+	assert.NoFileExists(t, partial)
+}
+
+func TestFetcher_Fetch_Archive(t *testing.T) {
+	t.Parallel()
+
+	source := filepath.Join(t.TempDir(), "binary")
+	require.NoError(t, os.WriteFile(source, []byte("plugin binary"), 0o755))
+
+	archive := &bytes.Buffer{}
+	require.NoError(t, pluginpkg.Build(archive, "example", "v1.0.0", []pluginpkg.Source{
+		{OS: "linux", Arch: "amd64", Path: source},
+	}))
+
+	s := newServer(t, map[string][]byte{
+		"/example" + pluginpkg.Extension: archive.Bytes(),
+	})
+
+	fetcher, err := pluginfetch.New(
+		pluginfetch.WithDirectory(t.TempDir()),
+		pluginfetch.WithExtractOptions(pluginpkg.WithPlatform("linux", "amd64")),
+	)
+	require.NoError(t, err)
+
+	path, err := fetcher.Fetch(t.Context(), s.URL+"/example"+pluginpkg.Extension, digest(archive.Bytes()))
+	require.NoError(t, err)
+	assert.EqualValues(t, "example", filepath.Base(path))
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.EqualValues(t, "plugin binary", string(contents))
+}
+
+func TestFetcher_Prune(t *testing.T) {
+	t.Parallel()
+
+	first, second := []byte("first binary"), []byte("second binary")
+	s := newServer(t, map[string][]byte{
+		"/first":  first,
+		"/second": second,
+	})
+
+	t.Run("evicts the least recently used entries", func(t *testing.T) {
+		t.Parallel()
+
+		fetcher, err := pluginfetch.New(
+			pluginfetch.WithDirectory(t.TempDir()),
+			pluginfetch.WithMaxSize(int64(len(second))),
+		)
+		require.NoError(t, err)
+
+		_, err = fetcher.Fetch(t.Context(), s.URL+"/first", digest(first))
+		require.NoError(t, err)
+
+		path, err := fetcher.Fetch(t.Context(), s.URL+"/second", digest(second))
+		require.NoError(t, err)
+
+		entries, err := fetcher.Entries()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.EqualValues(t, digest(second), entries[0].Digest)
+		assert.EqualValues(t, path, entries[0].Path)
+		assert.EqualValues(t, len(second), entries[0].Size)
+	})
+
+	t.Run("evicts expired entries", func(t *testing.T) {
+		t.Parallel()
+
+		directory := t.TempDir()
+		fetcher, err := pluginfetch.New(
+			pluginfetch.WithDirectory(directory),
+			pluginfetch.WithMaxAge(time.Hour),
+		)
+		require.NoError(t, err)
+
+		_, err = fetcher.Fetch(t.Context(), s.URL+"/first", digest(first))
+		require.NoError(t, err)
+
+		expired := time.Now().Add(-2 * time.Hour)
+		require.NoError(t, os.Chtimes(filepath.Join(directory, digest(first)), expired, expired))
+
+		require.NoError(t, fetcher.Prune())
+
+		entries, err := fetcher.Entries()
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("evicts entries by digest", func(t *testing.T) {
+		t.Parallel()
+
+		fetcher, err := pluginfetch.New(pluginfetch.WithDirectory(t.TempDir()))
+		require.NoError(t, err)
+
+		_, err = fetcher.Fetch(t.Context(), s.URL+"/first", digest(first))
+		require.NoError(t, err)
+
+		require.NoError(t, fetcher.Evict(digest(first)))
+
+		entries, err := fetcher.Entries()
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
+
+func TestFetcher_Use(t *testing.T) {
+	t.Parallel()
+
+	binary, err := os.ReadFile("../test_plugin")
+	require.NoError(t, err)
+
+	s := newServer(t, map[string][]byte{
+		"/test_plugin": binary,
+	})
+
+	fetcher, err := pluginfetch.New(pluginfetch.WithDirectory(t.TempDir()))
+	require.NoError(t, err)
+
+	p, err := fetcher.Use(t.Context(), s.URL+"/test_plugin", digest(binary))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+	assert.EqualValues(t, "pong", output.GetValue())
+}
+
+func TestFetcher_Source(t *testing.T) {
+	t.Parallel()
+
+	binary := []byte("plugin binary")
+	s := newServer(t, map[string][]byte{
+		"/my-plugin": binary,
+	})
+
+	fetcher, err := pluginfetch.New(pluginfetch.WithDirectory(t.TempDir()))
+	require.NoError(t, err)
+
+	path, err := fetcher.Source(s.URL+"/my-plugin", digest(binary)).Fetch(t.Context())
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.EqualValues(t, binary, contents)
+}
@@ -0,0 +1,407 @@
+// Package pluginpkg provides a distributable archive format for plugins. An archive is a zstd-compressed tar file
+// containing a manifest describing the plugin, a binary for each supported platform and, optionally, a detached
+// Ed25519 signature of the manifest. The manifest contains the SHA-256 digest of each binary, so the signature covers
+// the entire archive.
+//
+// Archives are created using Build, and the binary for the current platform is extracted using Extract, which
+// verifies its digest and, when trusted public keys are provided, the signature of the manifest. The extracted
+// binary can then be started using plugin.Use.
+package pluginpkg
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/davidsbond/plugin"
+)
+
+type (
+	// The Manifest type describes the plugin contained within an archive.
+	Manifest struct {
+		// The Name of the plugin, which is also the name of each binary once extracted.
+		Name string `json:"name"`
+		// The Version of the plugin.
+		Version string `json:"version"`
+		// The Binaries contained within the archive, one for each supported platform.
+		Binaries []Binary `json:"binaries"`
+	}
+
+	// The Binary type describes a plugin binary for a single platform within an archive.
+	Binary struct {
+		// The operating system the binary runs on, using the values of runtime.GOOS.
+		OS string `json:"os"`
+		// The architecture the binary runs on, using the values of runtime.GOARCH.
+		Arch string `json:"arch"`
+		// The path of the binary within the archive.
+		Path string `json:"path"`
+		// The hex-encoded SHA-256 digest of the binary.
+		SHA256 string `json:"sha256"`
+	}
+
+	// The Source type describes a plugin binary to be added to an archive by Build.
+	Source struct {
+		// The operating system the binary runs on, using the values of runtime.GOOS.
+		OS string
+		// The architecture the binary runs on, using the values of runtime.GOARCH.
+		Arch string
+		// The Path to the binary on disk.
+		Path string
+	}
+
+	// The BuildOption type is a function that modifies how an archive is created by Build.
+	BuildOption func(o *buildOptions)
+
+	buildOptions struct {
+		key ed25519.PrivateKey
+	}
+
+	// The ExtractOption type is a function that modifies how a binary is extracted from an archive by Extract.
+	ExtractOption func(o *extractOptions)
+
+	extractOptions struct {
+		os         string
+		arch       string
+		publicKeys []ed25519.PublicKey
+	}
+)
+
+var (
+	// ErrInvalidArchive is the error returned by Extract and ReadManifest when the archive is malformed, such as when
+	// it does not begin with a manifest.
+	ErrInvalidArchive = errors.New("invalid archive")
+
+	// ErrNoBinary is the error returned by Extract when the archive does not contain a binary for the requested
+	// platform.
+	ErrNoBinary = errors.New("no binary for platform")
+
+	// ErrUnsigned is the error returned by Extract when trusted public keys are provided using WithPublicKeys but the
+	// archive does not contain a signature.
+	ErrUnsigned = errors.New("archive is not signed")
+)
+
+const (
+	// ManifestPath is the path of the manifest within an archive. It is always the first file.
+	ManifestPath = "manifest.json"
+
+	// SignaturePath is the path of the detached Ed25519 signature of the manifest within an archive. When present, it
+	// always follows the manifest.
+	SignaturePath = "manifest.json.sig"
+
+	// Extension is the conventional file extension of an archive.
+	Extension = ".tar.zst"
+)
+
+// WithSigningKey is a BuildOption that signs the manifest of the archive using the provided Ed25519 private key.
+func WithSigningKey(key ed25519.PrivateKey) BuildOption {
+	return func(o *buildOptions) {
+		o.key = key
+	}
+}
+
+// WithPlatform is an ExtractOption that extracts the binary for the given operating system and architecture, rather
+// than the platform of the host application.
+func WithPlatform(os, arch string) ExtractOption {
+	return func(o *extractOptions) {
+		o.os = os
+		o.arch = arch
+	}
+}
+
+// WithPublicKeys is an ExtractOption that requires the manifest of the archive to be signed by one of the provided
+// Ed25519 public keys. Extract returns ErrUnsigned if the archive is not signed, and plugin.ErrUntrustedSignature if
+// it is not signed by any of the keys.
+func WithPublicKeys(keys ...ed25519.PublicKey) ExtractOption {
+	return func(o *extractOptions) {
+		o.publicKeys = append(o.publicKeys, keys...)
+	}
+}
+
+// Build writes an archive containing the provided binaries for the named plugin to w. Each source must be for a
+// different platform.
+func Build(w io.Writer, name, version string, sources []Source, opts ...BuildOption) error {
+	var options buildOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	manifest := Manifest{Name: name, Version: version}
+	for _, source := range sources {
+		if slices.ContainsFunc(manifest.Binaries, func(binary Binary) bool {
+			return binary.OS == source.OS && binary.Arch == source.Arch
+		}) {
+			return fmt.Errorf("duplicate binary for platform %s/%s", source.OS, source.Arch)
+		}
+
+		digest, err := digestFile(source.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read binary %q: %w", source.Path, err)
+		}
+
+		manifest.Binaries = append(manifest.Binaries, Binary{
+			OS:     source.OS,
+			Arch:   source.Arch,
+			Path:   path.Join("bin", source.OS+"_"+source.Arch, name),
+			SHA256: digest,
+		})
+	}
+
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(zw)
+	if err = writeFile(tw, ManifestPath, 0o644, int64(len(encoded)), bytes.NewReader(encoded)); err != nil {
+		return err
+	}
+
+	if options.key != nil {
+		signature := ed25519.Sign(options.key, encoded)
+		if err = writeFile(tw, SignaturePath, 0o644, int64(len(signature)), bytes.NewReader(signature)); err != nil {
+			return err
+		}
+	}
+
+	for i, source := range sources {
+		if err = writeBinary(tw, manifest.Binaries[i].Path, source.Path); err != nil {
+			return err
+		}
+	}
+
+	if err = tw.Close(); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func writeBinary(tw *tar.Writer, name, source string) error {
+	f, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	return writeFile(tw, name, 0o755, info.Size(), f)
+}
+
+func writeFile(tw *tar.Writer, name string, mode int64, size int64, r io.Reader) error {
+	err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     mode,
+		Size:     size,
+		ModTime:  time.Unix(0, 0),
+		Format:   tar.FormatPAX,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, r)
+	return err
+}
+
+// ReadManifest returns the manifest of the archive read from r, without verifying its signature.
+func ReadManifest(r io.Reader) (Manifest, error) {
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	defer zr.Close()
+
+	manifest, _, err := readManifest(tar.NewReader(zr))
+	return manifest, err
+}
+
+// readManifest reads the manifest from the start of the archive, returning its encoded form so that its signature
+// can be verified.
+func readManifest(tr *tar.Reader) (Manifest, []byte, error) {
+	header, err := tr.Next()
+	if err != nil {
+		return Manifest{}, nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	if header.Name != ManifestPath {
+		return Manifest{}, nil, fmt.Errorf("%w: expected %q, got %q", ErrInvalidArchive, ManifestPath, header.Name)
+	}
+
+	encoded, err := io.ReadAll(tr)
+	if err != nil {
+		return Manifest{}, nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	var manifest Manifest
+	if err = json.Unmarshal(encoded, &manifest); err != nil {
+		return Manifest{}, nil, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	// The name is used as the file name of extracted binaries, so must not refer to any other location.
+	if manifest.Name == "" || manifest.Name == ".." || filepath.Base(manifest.Name) != manifest.Name {
+		return Manifest{}, nil, fmt.Errorf("%w: invalid plugin name %q", ErrInvalidArchive, manifest.Name)
+	}
+
+	return manifest, encoded, nil
+}
+
+// Extract the binary for the current platform from the archive read from r, writing it to the given directory using
+// the name of the plugin. Returns the path to the extracted binary, which can be provided to plugin.Use, and the
+// manifest of the archive.
+//
+// Returns ErrNoBinary if the archive does not contain a binary for the platform, and plugin.ErrChecksumMismatch if the
+// binary does not match the digest within the manifest, in which case nothing is written to the directory. If trusted
+// public keys have been provided using WithPublicKeys, the manifest must be signed by one of them.
+func Extract(r io.Reader, directory string, opts ...ExtractOption) (string, Manifest, error) {
+	options := extractOptions{
+		os:   runtime.GOOS,
+		arch: runtime.GOARCH,
+	}
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return "", Manifest{}, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	defer zr.Close()
+
+	tr := tar.NewReader(zr)
+	manifest, encoded, err := readManifest(tr)
+	if err != nil {
+		return "", Manifest{}, err
+	}
+
+	index := slices.IndexFunc(manifest.Binaries, func(binary Binary) bool {
+		return binary.OS == options.os && binary.Arch == options.arch
+	})
+
+	if index < 0 {
+		return "", manifest, fmt.Errorf("%w: %s/%s", ErrNoBinary, options.os, options.arch)
+	}
+
+	binary := manifest.Binaries[index]
+
+	// The manifest can be followed by its signature, then the binaries in any order.
+	header, err := tr.Next()
+	if err == nil && header.Name == SignaturePath {
+		var signature []byte
+		if signature, err = io.ReadAll(tr); err != nil {
+			return "", manifest, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+
+		if err = verifySignature(options.publicKeys, encoded, signature); err != nil {
+			return "", manifest, err
+		}
+
+		header, err = tr.Next()
+	} else if len(options.publicKeys) > 0 {
+		return "", manifest, ErrUnsigned
+	}
+
+	for ; err == nil; header, err = tr.Next() {
+		if header.Name != binary.Path {
+			continue
+		}
+
+		target := filepath.Join(directory, manifest.Name)
+		if err = extractBinary(tr, target, binary.SHA256); err != nil {
+			return "", manifest, err
+		}
+
+		return target, manifest, nil
+	}
+
+	if errors.Is(err, io.EOF) {
+		return "", manifest, fmt.Errorf("%w: binary %q is missing", ErrInvalidArchive, binary.Path)
+	}
+
+	return "", manifest, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+}
+
+func verifySignature(keys []ed25519.PublicKey, manifest, signature []byte) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	for _, key := range keys {
+		if len(key) == ed25519.PublicKeySize && ed25519.Verify(key, manifest, signature) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: manifest is not signed by a trusted key", plugin.ErrUntrustedSignature)
+}
+
+// extractBinary writes the binary to a temporary file alongside the target, which is only renamed to the target once
+// its digest has been verified.
+func extractBinary(r io.Reader, target, expected string) error {
+	f, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+"-*")
+	if err != nil {
+		return err
+	}
+
+	// Removing the temporary file once renamed has no effect.
+	defer os.Remove(f.Name())
+
+	hash := sha256.New()
+	if _, err = io.Copy(io.MultiWriter(f, hash), r); err != nil {
+		return errors.Join(err, f.Close())
+	}
+
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("%w: expected %q, got %q", plugin.ErrChecksumMismatch, expected, actual)
+	}
+
+	if err = os.Chmod(f.Name(), 0o755); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), target)
+}
+
+func digestFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
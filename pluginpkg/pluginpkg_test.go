@@ -0,0 +1,224 @@
+package pluginpkg_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/pluginpkg"
+)
+
+func TestExtract(t *testing.T) {
+	t.Parallel()
+
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	untrusted, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	sources := t.TempDir()
+	linux := filepath.Join(sources, "linux")
+	require.NoError(t, os.WriteFile(linux, []byte("linux binary"), 0o755))
+	darwin := filepath.Join(sources, "darwin")
+	require.NoError(t, os.WriteFile(darwin, []byte("darwin binary"), 0o755))
+
+	build := func(t *testing.T, opts ...pluginpkg.BuildOption) []byte {
+		archive := &bytes.Buffer{}
+		require.NoError(t, pluginpkg.Build(archive, "example", "v1.0.0", []pluginpkg.Source{
+			{OS: "linux", Arch: "amd64", Path: linux},
+			{OS: "darwin", Arch: "arm64", Path: darwin},
+		}, opts...))
+
+		return archive.Bytes()
+	}
+
+	signed := build(t, pluginpkg.WithSigningKey(private))
+	unsigned := build(t)
+
+	tt := []struct {
+		Name     string
+		Archive  []byte
+		Options  []pluginpkg.ExtractOption
+		Expected string
+		Err      error
+	}{
+		{
+			Name:     "extracts the binary for the platform",
+			Archive:  unsigned,
+			Options:  []pluginpkg.ExtractOption{pluginpkg.WithPlatform("darwin", "arm64")},
+			Expected: "darwin binary",
+		},
+		{
+			Name:    "extracts binaries signed by a trusted key",
+			Archive: signed,
+			Options: []pluginpkg.ExtractOption{
+				pluginpkg.WithPlatform("linux", "amd64"),
+				pluginpkg.WithPublicKeys(untrusted, public),
+			},
+			Expected: "linux binary",
+		},
+		{
+			Name:    "error for binaries signed by an untrusted key",
+			Archive: signed,
+			Options: []pluginpkg.ExtractOption{
+				pluginpkg.WithPlatform("linux", "amd64"),
+				pluginpkg.WithPublicKeys(untrusted),
+			},
+			Err: plugin.ErrUntrustedSignature,
+		},
+		{
+			Name:    "error for unsigned archives when keys are provided",
+			Archive: unsigned,
+			Options: []pluginpkg.ExtractOption{
+				pluginpkg.WithPlatform("linux", "amd64"),
+				pluginpkg.WithPublicKeys(public),
+			},
+			Err: pluginpkg.ErrUnsigned,
+		},
+		{
+			Name:    "error for unsupported platforms",
+			Archive: unsigned,
+			Options: []pluginpkg.ExtractOption{pluginpkg.WithPlatform("windows", "amd64")},
+			Err:     pluginpkg.ErrNoBinary,
+		},
+		{
+			Name:    "error for modified binaries",
+			Archive: tamper(t, unsigned, "bin/linux_amd64/example", []byte("modified binary")),
+			Options: []pluginpkg.ExtractOption{pluginpkg.WithPlatform("linux", "amd64")},
+			Err:     plugin.ErrChecksumMismatch,
+		},
+		{
+			Name:    "error for invalid archives",
+			Archive: []byte("not an archive"),
+			Err:     pluginpkg.ErrInvalidArchive,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			directory := t.TempDir()
+			path, manifest, err := pluginpkg.Extract(bytes.NewReader(tc.Archive), directory, tc.Options...)
+			if tc.Err != nil {
+				require.ErrorIs(t, err, tc.Err)
+
+				// Nothing is left behind when extraction fails.
+				entries, err := os.ReadDir(directory)
+				require.NoError(t, err)
+				assert.Empty(t, entries)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.EqualValues(t, filepath.Join(directory, "example"), path)
+			assert.EqualValues(t, "v1.0.0", manifest.Version)
+
+			contents, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.EqualValues(t, tc.Expected, string(contents))
+
+			info, err := os.Stat(path)
+			require.NoError(t, err)
+			assert.EqualValues(t, os.FileMode(0o755), info.Mode().Perm())
+		})
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	t.Parallel()
+
+	source := filepath.Join(t.TempDir(), "linux")
+	require.NoError(t, os.WriteFile(source, []byte("linux binary"), 0o755))
+
+	archive := &bytes.Buffer{}
+	require.NoError(t, pluginpkg.Build(archive, "example", "v1.0.0", []pluginpkg.Source{
+		{OS: "linux", Arch: "amd64", Path: source},
+	}))
+
+	manifest, err := pluginpkg.ReadManifest(archive)
+	require.NoError(t, err)
+	assert.EqualValues(t, pluginpkg.Manifest{
+		Name:    "example",
+		Version: "v1.0.0",
+		Binaries: []pluginpkg.Binary{
+			{
+				OS:     "linux",
+				Arch:   "amd64",
+				Path:   "bin/linux_amd64/example",
+				SHA256: "6e19f8ee94bd465d67f62861d35a8f7c2c59c111f73a22f17e6c013e9641f651",
+			},
+		},
+	}, manifest)
+}
+
+func TestExtract_Use(t *testing.T) {
+	t.Parallel()
+
+	archive := &bytes.Buffer{}
+	require.NoError(t, pluginpkg.Build(archive, "test_plugin", "v1.2.3", []pluginpkg.Source{
+		{OS: runtime.GOOS, Arch: runtime.GOARCH, Path: "../test_plugin"},
+	}))
+
+	path, _, err := pluginpkg.Extract(archive, t.TempDir())
+	require.NoError(t, err)
+
+	p, err := plugin.Use(t.Context(), path)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+	assert.EqualValues(t, "pong", output.GetValue())
+}
+
+// tamper returns a copy of the archive with the contents of the named file replaced.
+func tamper(t *testing.T, archive []byte, name string, contents []byte) []byte {
+	t.Helper()
+
+	zr, err := zstd.NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+	defer zr.Close()
+
+	output := &bytes.Buffer{}
+	zw, err := zstd.NewWriter(output)
+	require.NoError(t, err)
+
+	tr, tw := tar.NewReader(zr), tar.NewWriter(zw)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+
+		data := &bytes.Buffer{}
+		_, err = data.ReadFrom(tr)
+		require.NoError(t, err)
+
+		if header.Name == name {
+			data = bytes.NewBuffer(contents)
+			header.Size = int64(len(contents))
+		}
+
+		require.NoError(t, tw.WriteHeader(header))
+		_, err = tw.Write(data.Bytes())
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, tw.Close())
+	require.NoError(t, zw.Close())
+	return output.Bytes()
+}
@@ -0,0 +1,87 @@
+// Package plugintest provides utilities for testing plugins and the host applications that use them.
+package plugintest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/davidsbond/plugin"
+)
+
+// AssertHandlerCancels asserts that the CommandHandler returns within the given duration of its context being
+// cancelled. The context is cancelled once the handler has been running for the provided delay. This can be used by
+// plugin authors to ensure their commands honour cancellation, rather than relying on the plugin aborting them once
+// its cancellation grace period has elapsed. Returns true if the assertion passed.
+func AssertHandlerCancels(t testing.TB, handler plugin.CommandHandler, input proto.Message, delay, within time.Duration) bool {
+	t.Helper()
+
+	in, err := anypb.New(input)
+	if err != nil {
+		t.Errorf("failed to encode input: %v", err)
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = handler.Execute(ctx, in)
+	}()
+
+	return assertCancels(t, "command "+handler.Name(), cancel, done, delay, within)
+}
+
+// AssertExecCancels asserts that Plugin.Exec returns within the given duration of its context being cancelled and
+// that the error it returns is context.Canceled. The context is cancelled once the call has been running for the
+// provided delay. Returns true if the assertion passed.
+func AssertExecCancels(t testing.TB, p *plugin.Plugin, command string, input, output proto.Message, delay, within time.Duration) bool {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var err error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		err = p.Exec(ctx, command, input, output)
+	}()
+
+	if !assertCancels(t, "command "+command, cancel, done, delay, within) {
+		return false
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected error %v, got %v", context.Canceled, err)
+		return false
+	}
+
+	return true
+}
+
+func assertCancels(t testing.TB, name string, cancel context.CancelFunc, done <-chan struct{}, delay, within time.Duration) bool {
+	t.Helper()
+
+	select {
+	case <-done:
+		t.Errorf("%s returned before it was cancelled", name)
+		return false
+	case <-time.After(delay):
+		cancel()
+	}
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(within):
+		t.Errorf("%s did not return within %s of being cancelled", name, within)
+		return false
+	}
+}
@@ -0,0 +1,30 @@
+package plugintest_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestAssertHandlerCancels(t *testing.T) {
+	t.Parallel()
+
+	command := &plugin.Command[*durationpb.Duration, *durationpb.Duration]{
+		Use: "sleep",
+		Run: func(ctx context.Context, input *durationpb.Duration) (*durationpb.Duration, error) {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(input.AsDuration()):
+				return input, nil
+			}
+		},
+	}
+
+	plugintest.AssertHandlerCancels(t, command, durationpb.New(time.Hour), 10*time.Millisecond, time.Second)
+}
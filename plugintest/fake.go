@@ -0,0 +1,156 @@
+package plugintest
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/davidsbond/plugin"
+)
+
+type (
+	// The Fake type is an in-memory implementation of the plugin.Client interface for testing host applications. The
+	// behaviour of each command is set using Fake.Handle, and every call to Fake.Exec is recorded so that it can be
+	// asserted upon using Fake.Calls. A Fake is safe for concurrent use.
+	Fake struct {
+		name    string
+		version string
+
+		mu       sync.Mutex
+		commands []plugin.CommandInfo
+		handlers map[string]HandlerFunc
+		calls    []Call
+		closed   bool
+	}
+
+	// The HandlerFunc type is a function that implements a command of a Fake.
+	HandlerFunc func(ctx context.Context, input proto.Message) (proto.Message, error)
+
+	// The Call type describes a single call to Fake.Exec.
+	Call struct {
+		// The name of the command.
+		Command string
+		// The Input provided to the command.
+		Input proto.Message
+		// The error returned to the caller, if any.
+		Err error
+	}
+)
+
+// NewFake returns a new instance of the Fake type with the given plugin name and version, with no commands.
+func NewFake(name, version string) *Fake {
+	return &Fake{
+		name:     name,
+		version:  version,
+		handlers: make(map[string]HandlerFunc),
+	}
+}
+
+// Handle sets the function that handles the named command, adding the command to those provided by the Fake. The
+// output returned by the handler must be of the type expected by the caller of Fake.Exec. Returns the Fake so that
+// calls can be chained.
+func (f *Fake) Handle(command string, handler HandlerFunc) *Fake {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.handlers[command]; !ok {
+		f.commands = append(f.commands, plugin.CommandInfo{Name: command})
+	}
+
+	f.handlers[command] = handler
+	return f
+}
+
+// Name returns the name of the Fake.
+func (f *Fake) Name() string {
+	return f.name
+}
+
+// Version returns the version of the Fake.
+func (f *Fake) Version() string {
+	return f.version
+}
+
+// Commands returns all commands added to the Fake using Fake.Handle, in the order they were added.
+func (f *Fake) Commands() []plugin.CommandInfo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.commands)
+}
+
+// HasCommand returns true if the named command has been added to the Fake using Fake.Handle.
+func (f *Fake) HasCommand(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, ok := f.handlers[name]
+	return ok
+}
+
+// Exec executes the handler of the named command, copying the output it returns into the provided output parameter.
+// Like plugin.Plugin.Exec, it returns plugin.ErrUnknownCommand if the command does not exist and plugin.ErrClosed once
+// the Fake has been closed. Any plugin.ExecOption functions are ignored.
+func (f *Fake) Exec(ctx context.Context, name string, input proto.Message, output proto.Message, _ ...plugin.ExecOption) error {
+	err := f.exec(ctx, name, input, output)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{
+		Command: name,
+		Input:   proto.Clone(input),
+		Err:     err,
+	})
+
+	return err
+}
+
+func (f *Fake) exec(ctx context.Context, name string, input proto.Message, output proto.Message) error {
+	f.mu.Lock()
+	handler, ok := f.handlers[name]
+	closed := f.closed
+	f.mu.Unlock()
+
+	switch {
+	case closed:
+		return fmt.Errorf("%w: %q", plugin.ErrClosed, f.name)
+	case !ok:
+		return fmt.Errorf("%w: %q", plugin.ErrUnknownCommand, name)
+	}
+
+	result, err := handler(ctx, input)
+	if err != nil {
+		return err
+	}
+
+	// The output is passed through the protobuf Any type, as it would be by a real plugin, so that mismatched types
+	// are reported as errors.
+	a, err := anypb.New(result)
+	if err != nil {
+		return err
+	}
+
+	return a.UnmarshalTo(output)
+}
+
+// Calls returns all calls made to Fake.Exec, in the order they were made.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return slices.Clone(f.calls)
+}
+
+// Close the Fake. Subsequent calls to Fake.Exec return plugin.ErrClosed.
+func (f *Fake) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	return nil
+}
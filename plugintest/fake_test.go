@@ -0,0 +1,88 @@
+package plugintest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+var (
+	_ plugin.Client = (*plugin.Plugin)(nil)
+	_ plugin.Client = (*plugintest.Fake)(nil)
+)
+
+func TestFake(t *testing.T) {
+	t.Parallel()
+
+	fake := plugintest.NewFake("example", "v1.0.0").
+		Handle("greet", func(ctx context.Context, input proto.Message) (proto.Message, error) {
+			return wrapperspb.String("hello " + input.(*wrapperspb.StringValue).GetValue()), nil
+		})
+
+	var client plugin.Client = fake
+
+	assert.EqualValues(t, "example", client.Name())
+	assert.EqualValues(t, "v1.0.0", client.Version())
+	assert.EqualValues(t, []plugin.CommandInfo{{Name: "greet"}}, client.Commands())
+	assert.True(t, client.HasCommand("greet"))
+
+	t.Run("executes handler", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, client.Exec(t.Context(), "greet", wrapperspb.String("world"), output))
+		assert.EqualValues(t, "hello world", output.GetValue())
+	})
+
+	t.Run("error if output type does not match", func(t *testing.T) {
+		assert.Error(t, client.Exec(t.Context(), "greet", wrapperspb.String("world"), &durationpb.Duration{}))
+	})
+
+	t.Run("error if command does not exist", func(t *testing.T) {
+		assert.ErrorIs(t, client.Exec(t.Context(), "unknown", wrapperspb.String("world"), &wrapperspb.StringValue{}), plugin.ErrUnknownCommand)
+	})
+
+	t.Run("records calls", func(t *testing.T) {
+		calls := fake.Calls()
+		require.Len(t, calls, 3)
+		assert.EqualValues(t, "greet", calls[0].Command)
+		assert.True(t, proto.Equal(wrapperspb.String("world"), calls[0].Input))
+		assert.NoError(t, calls[0].Err)
+		assert.ErrorIs(t, calls[2].Err, plugin.ErrUnknownCommand)
+	})
+
+	t.Run("error once closed", func(t *testing.T) {
+		require.NoError(t, client.Close())
+		assert.ErrorIs(t, client.Exec(t.Context(), "greet", wrapperspb.String("world"), &wrapperspb.StringValue{}), plugin.ErrClosed)
+	})
+}
+
+func TestFake_Manager(t *testing.T) {
+	t.Parallel()
+
+	fake := plugintest.NewFake("example", "v1.0.0").
+		Handle("greet", func(ctx context.Context, input proto.Message) (proto.Message, error) {
+			return wrapperspb.String("hello " + input.(*wrapperspb.StringValue).GetValue()), nil
+		})
+
+	manager := plugin.NewManager()
+	require.NoError(t, manager.Add(fake))
+
+	client, ok := manager.Get("example")
+	require.True(t, ok)
+	assert.Equal(t, fake, client)
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, manager.ExecAny(t.Context(), "greet", wrapperspb.String("world"), output))
+	assert.EqualValues(t, "hello world", output.GetValue())
+	assert.Len(t, fake.Calls(), 1)
+
+	require.NoError(t, manager.Close())
+	assert.ErrorIs(t, fake.Exec(t.Context(), "greet", wrapperspb.String("world"), output), plugin.ErrClosed)
+}
@@ -0,0 +1,34 @@
+package plugintest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/internal/inprocess"
+)
+
+// useInProcess starts a plugin in-process. The plugin package provides it via an internal hook rather than exporting
+// it.
+var useInProcess = inprocess.Use.(func(context.Context, plugin.Config, ...plugin.UseOption) (*plugin.Plugin, error))
+
+// New serves the commands within the provided Config from within the test binary, returning a Plugin that
+// communicates with them over an in-memory gRPC connection. This allows plugins and the host applications that use
+// them to be tested without building and executing plugin binaries. The test fails immediately if the plugin cannot be
+// started, and the Plugin is closed once the test completes.
+func New(t testing.TB, config plugin.Config, options ...plugin.UseOption) *plugin.Plugin {
+	t.Helper()
+
+	p, err := useInProcess(t.Context(), config, options...)
+	if err != nil {
+		t.Fatalf("failed to start plugin %q: %v", config.Name, err)
+	}
+
+	t.Cleanup(func() {
+		if err := p.Close(); err != nil {
+			t.Errorf("failed to close plugin %q: %v", config.Name, err)
+		}
+	})
+
+	return p
+}
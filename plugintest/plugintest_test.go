@@ -0,0 +1,55 @@
+package plugintest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	p := plugintest.New(t, plugin.Config{
+		Name: "echo",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "echo",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					if input.GetValue() == "" {
+						return nil, errors.New("empty input")
+					}
+
+					return input, nil
+				},
+			},
+		},
+	})
+
+	assert.EqualValues(t, "echo", p.Name())
+	assert.True(t, p.HasCommand("echo"))
+
+	t.Run("executes command", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "echo", wrapperspb.String("hello"), output))
+		assert.EqualValues(t, "hello", output.GetValue())
+	})
+
+	t.Run("returns command errors", func(t *testing.T) {
+		var pluginErr *plugin.Error
+		err := p.Exec(t.Context(), "echo", wrapperspb.String(""), &wrapperspb.StringValue{})
+		require.ErrorAs(t, err, &pluginErr)
+		assert.EqualValues(t, "empty input", pluginErr.Message)
+	})
+
+	t.Run("error if command does not exist", func(t *testing.T) {
+		err := p.Exec(t.Context(), "unknown", wrapperspb.String("hello"), &wrapperspb.StringValue{})
+		assert.ErrorIs(t, err, plugin.ErrUnknownCommand)
+	})
+}
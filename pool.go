@@ -0,0 +1,161 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The Balancing type determines how calls to Plugin.Exec are distributed between instances of a plugin started
+	// using WithInstances.
+	Balancing int
+
+	pool struct {
+		processes []*process
+		balancing Balancing
+		next      atomic.Uint64
+		done      chan struct{}
+
+		// Command descriptors are cached for the lifetime of the processes, as they cannot change while running.
+		describeMu  sync.Mutex
+		descriptors map[string]CommandDescriptor
+	}
+)
+
+const (
+	// RoundRobin is a Balancing strategy that sends each call to the next instance in turn.
+	RoundRobin Balancing = iota
+	// LeastBusy is a Balancing strategy that sends each call to the instance executing the fewest commands, which
+	// suits commands whose durations vary widely.
+	LeastBusy
+)
+
+// WithInstances is a UseOption that starts the given number of instances of the plugin, each with its own process
+// and UNIX domain socket. Calls to Plugin.Exec are distributed between instances using the strategy set via
+// WithBalancing, allowing CPU-bound commands to execute in parallel. Defaults to a single instance.
+func WithInstances(n int) UseOption {
+	return func(o *useOptions) {
+		o.instances = max(1, n)
+	}
+}
+
+// WithBalancing is a UseOption that sets the strategy used to distribute calls to Plugin.Exec between instances of a
+// plugin started using WithInstances. Defaults to RoundRobin.
+func WithBalancing(balancing Balancing) UseOption {
+	return func(o *useOptions) {
+		o.balancing = balancing
+	}
+}
+
+func (p *Plugin) startPool(ctx context.Context, b *binary) (*pool, plugin.Info, error) {
+	// Plugins served in-process or started by another process have no binary to verify.
+	if p.config == nil && p.address == "" {
+		options := p.options
+		if b.upgraded {
+			// Checksums given to Use describe the original binary, the integrity of upgrades is checked by their Source.
+			options.checksum, options.checksumFile = "", ""
+		}
+
+		if err := verify(ctx, b.path, options); err != nil {
+			return nil, plugin.Info{}, err
+		}
+	}
+
+	pl := &pool{
+		processes: make([]*process, p.options.instances),
+		balancing: p.options.balancing,
+		done:      make(chan struct{}),
+	}
+
+	infos := make([]plugin.Info, p.options.instances)
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	for i := range pl.processes {
+		group.Go(func() error {
+			proc, info, err := p.startProcess(groupCtx, b.path)
+			if err != nil {
+				p.diagnostics().Warn("failed to start plugin", "error", err)
+				return err
+			}
+
+			if err = p.openBroker(groupCtx, proc); err != nil {
+				return errors.Join(err, proc.close())
+			}
+
+			if err = p.sendSecrets(groupCtx, proc, info); err != nil {
+				return errors.Join(err, proc.close())
+			}
+
+			if err = p.configure(groupCtx, proc, info); err != nil {
+				return errors.Join(err, proc.close())
+			}
+
+			proc.version = info.Version
+			pl.processes[i] = proc
+			infos[i] = info
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, plugin.Info{}, errors.Join(err, pl.close())
+	}
+
+	return pl, infos[0], nil
+}
+
+func (pl *pool) pick() *process {
+	if len(pl.processes) == 1 {
+		return pl.processes[0]
+	}
+
+	if pl.balancing == LeastBusy {
+		var (
+			chosen *process
+			least  int64 = math.MaxInt64
+		)
+
+		// Start from the next instance in turn so that idle instances share load evenly.
+		start := pl.next.Add(1)
+		for i := range pl.processes {
+			proc := pl.processes[(start+uint64(i))%uint64(len(pl.processes))]
+			if n := proc.inflight.Load(); n < least {
+				chosen, least = proc, n
+			}
+		}
+
+		return chosen
+	}
+
+	return pl.processes[(pl.next.Add(1)-1)%uint64(len(pl.processes))]
+}
+
+func (pl *pool) close() error {
+	close(pl.done)
+
+	// Processes are closed concurrently so that closing the pool takes no longer than the shutdown grace period.
+	errs := make([]error, len(pl.processes))
+
+	var wg sync.WaitGroup
+	for i, proc := range pl.processes {
+		if proc == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = proc.close()
+		}()
+	}
+
+	wg.Wait()
+	return errors.Join(errs...)
+}
@@ -0,0 +1,124 @@
+package plugin_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestUse_WithInstances(t *testing.T) {
+	tt := []struct {
+		Name      string
+		Balancing plugin.Balancing
+	}{
+		{
+			Name:      "round robin",
+			Balancing: plugin.RoundRobin,
+		},
+		{
+			Name:      "least busy",
+			Balancing: plugin.LeastBusy,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			directory := t.TempDir()
+			sockets := func() []string {
+				matches, err := filepath.Glob(filepath.Join(directory, "*.sock"))
+				require.NoError(t, err)
+				return matches
+			}
+
+			p, err := plugin.Use(t.Context(), "./test_plugin",
+				plugin.WithInstances(3),
+				plugin.WithBalancing(tc.Balancing),
+				plugin.WithSocketDirectory(directory),
+			)
+			require.NoError(t, err)
+
+			assert.Len(t, sockets(), 3)
+			assert.EqualValues(t, []string{"pingpong", "sleep", "hang", "crash", "countdown", "pid"}, commandNames(p.Commands()))
+
+			var wg sync.WaitGroup
+			for range 9 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					output := &wrapperspb.StringValue{}
+					assert.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+					assert.EqualValues(t, "pong", output.GetValue())
+				}()
+			}
+
+			wg.Wait()
+			assert.EqualValues(t, 9, p.Stats().Commands["pingpong"].Calls)
+
+			// Calls made one at a time are shared evenly between the idle instances by both strategies.
+			pids := make(map[int64]int)
+			for range 9 {
+				output := &wrapperspb.Int64Value{}
+				require.NoError(t, p.Exec(t.Context(), "pid", &emptypb.Empty{}, output))
+				pids[output.GetValue()]++
+			}
+
+			assert.Len(t, pids, 3)
+			for pid, calls := range pids {
+				assert.EqualValues(t, 3, calls, "instance %d", pid)
+			}
+
+			usage, err := p.Usage(t.Context())
+			require.NoError(t, err)
+			assert.NotZero(t, usage.Goroutines)
+
+			require.NoError(t, p.Close())
+			assert.Eventually(t, func() bool {
+				return len(sockets()) == 0
+			}, 5*time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestUse_WithInstances_LeastBusy(t *testing.T) {
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithInstances(3), plugin.WithBalancing(plugin.LeastBusy))
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	busy := make(chan error, 1)
+	go func() {
+		busy <- p.Exec(ctx, "sleep", durationpb.New(time.Minute), &durationpb.Duration{})
+	}()
+
+	// Give the call time to reach an instance before checking that subsequent calls avoid it.
+	time.Sleep(100 * time.Millisecond)
+
+	pids := make(map[int64]int)
+	for range 6 {
+		output := &wrapperspb.Int64Value{}
+		require.NoError(t, p.Exec(t.Context(), "pid", &emptypb.Empty{}, output))
+		pids[output.GetValue()]++
+	}
+
+	// The busy instance is never chosen, so the calls are split between the other two.
+	assert.Len(t, pids, 2)
+
+	cancel()
+	assert.ErrorIs(t, <-busy, context.Canceled)
+}
@@ -0,0 +1,103 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	pb "github.com/davidsbond/plugin/internal/generated/proto/plugin"
+)
+
+type (
+	// The RawCommand type is a CommandHandler implementation for commands whose input and output are raw bytes rather
+	// than protobuf messages, such as compressed data, images or arbitrary JSON. Each payload is accompanied by a
+	// content type describing its data, such as "image/png". Host applications execute raw commands using
+	// Plugin.ExecRaw.
+	RawCommand struct {
+		// Use describes the name of the command.
+		Use string
+		// Description is a human-readable description of what the command does.
+		Description string
+		// Example gives hints on how the command should be used, such as an example input.
+		Example string
+		// Deprecated marks the command as one that should no longer be used.
+		Deprecated bool
+		// Idempotent marks the command as one that can safely be executed again after a transient failure, allowing
+		// host applications to retry it.
+		Idempotent bool
+		// RateLimit limits how often the command can be executed. Executions that exceed the limit fail with the
+		// codes.ResourceExhausted status code. By default, executions are not limited.
+		RateLimit RateLimit
+		// MaxInputSize is the maximum size, in bytes, of the encoded input the command accepts. Executions whose input
+		// exceeds it are rejected with the codes.ResourceExhausted status code before the command is run, independently
+		// of Config.MaxMessageSize. By default, the size of the input is not limited.
+		MaxInputSize int
+		// Run is a function that is invoked when the plugin receives a request to execute the command. It is given the
+		// content type and data provided by the host application, returning the content type and data of its output.
+		Run func(ctx context.Context, contentType string, input []byte) (string, []byte, error)
+	}
+)
+
+// Name returns the name of the command.
+func (ch RawCommand) Name() string {
+	return ch.Use
+}
+
+func (ch RawCommand) info() CommandInfo {
+	return CommandInfo{
+		Name:        ch.Use,
+		Description: ch.Description,
+		Example:     ch.Example,
+		Deprecated:  ch.Deprecated,
+		Idempotent:  ch.Idempotent,
+	}
+}
+
+func (ch RawCommand) rateLimit() RateLimit {
+	return ch.RateLimit
+}
+
+func (ch RawCommand) maxInputSize() int {
+	return ch.MaxInputSize
+}
+
+func (ch RawCommand) validate() error {
+	if ch.Run == nil {
+		return fmt.Errorf("command %q has no Run function", ch.Use)
+	}
+
+	return nil
+}
+
+func (ch RawCommand) messages() (protoreflect.MessageDescriptor, protoreflect.MessageDescriptor) {
+	return messageDescriptor[*pb.RawMessage](), messageDescriptor[*pb.RawMessage]()
+}
+
+// Execute the command. This method handles the conversion from the protobuf Any type to the raw data and content type
+// given to the Run function.
+func (ch RawCommand) Execute(ctx context.Context, input *anypb.Any) (*anypb.Any, error) {
+	return Command[*pb.RawMessage, *pb.RawMessage]{
+		Use: ch.Use,
+		Run: func(ctx context.Context, input *pb.RawMessage) (*pb.RawMessage, error) {
+			contentType, data, err := ch.Run(ctx, input.GetContentType(), input.GetData())
+			if err != nil {
+				return nil, err
+			}
+
+			return &pb.RawMessage{ContentType: contentType, Data: data}, nil
+		},
+	}.Execute(ctx, input)
+}
+
+// ExecRaw executes the named RawCommand using the provided content type and data, returning the content type and data
+// of its output. Errors are returned as they would be by Plugin.Exec.
+func (p *Plugin) ExecRaw(ctx context.Context, name, contentType string, input []byte, opts ...ExecOption) (string, []byte, error) {
+	output := &pb.RawMessage{}
+	if err := p.Exec(ctx, name, &pb.RawMessage{ContentType: contentType, Data: input}, output, opts...); err != nil {
+		return "", nil, err
+	}
+
+	return output.GetContentType(), output.GetData(), nil
+}
@@ -0,0 +1,51 @@
+package plugin_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestPlugin_ExecRaw(t *testing.T) {
+	t.Parallel()
+
+	p := plugintest.New(t, plugin.Config{
+		Name: "raw",
+		Commands: []plugin.CommandHandler{
+			&plugin.RawCommand{
+				Use: "upper",
+				Run: func(ctx context.Context, contentType string, input []byte) (string, []byte, error) {
+					if contentType != "text/plain" {
+						return "", nil, errors.New("unsupported content type")
+					}
+
+					return "text/plain; charset=utf-8", bytes.ToUpper(input), nil
+				},
+			},
+		},
+	})
+
+	t.Run("returns the command output", func(t *testing.T) {
+		contentType, output, err := p.ExecRaw(t.Context(), "upper", "text/plain", []byte("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, "text/plain; charset=utf-8", contentType)
+		assert.Equal(t, []byte("HELLO"), output)
+	})
+
+	t.Run("returns command errors", func(t *testing.T) {
+		_, _, err := p.ExecRaw(t.Context(), "upper", "image/png", []byte{0x89})
+		assert.EqualError(t, err, `plugin "raw": command "upper": unsupported content type`)
+	})
+
+	t.Run("error for unknown commands", func(t *testing.T) {
+		_, _, err := p.ExecRaw(t.Context(), "unknown", "text/plain", nil)
+		assert.ErrorIs(t, err, plugin.ErrUnknownCommand)
+	})
+}
@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Config)
+)
+
+// Register a plugin compiled into the host application, so that it can be used via Use without executing a binary or
+// creating a UNIX domain socket. This supports deployments where executing plugin binaries is forbidden, while host
+// applications use a single code path for both kinds of plugin. Register is intended to be called from the init
+// function of the package implementing the plugin, it panics if the Config is invalid or a plugin with the same name
+// has already been registered.
+//
+// Calls to registered plugins are handled exactly as they would be by a plugin binary, via gRPC over an in-memory
+// connection. Options relating to the plugin process or binary, such as WithChecksum, have no effect.
+func Register(config Config) {
+	if err := config.validate(); err != nil {
+		panic(fmt.Sprintf("plugin: cannot register plugin %q: %v", config.Name, err))
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[config.Name]; ok {
+		panic(fmt.Sprintf("plugin: plugin %q is already registered", config.Name))
+	}
+
+	registry[config.Name] = config
+}
+
+// Registered returns the names of all plugins registered using Register, in sorted order.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+	return names
+}
+
+// registered returns the Config of the plugin registered with the given name, if any.
+func registered(name string) (Config, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	config, ok := registry[name]
+	return config, ok
+}
@@ -0,0 +1,57 @@
+package plugin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestRegister(t *testing.T) {
+	config := plugin.Config{
+		Name:    "registered_plugin",
+		Version: "v1.0.0",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "echo",
+				Run: func(_ context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					return input, nil
+				},
+			},
+		},
+	}
+
+	plugin.Register(config)
+	assert.Contains(t, plugin.Registered(), "registered_plugin")
+
+	t.Run("registered plugins are used in-process", func(t *testing.T) {
+		p, err := plugin.Use(t.Context(), "registered_plugin")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, p.Close())
+		})
+
+		assert.EqualValues(t, "registered_plugin", p.Name())
+		assert.EqualValues(t, "v1.0.0", p.Version())
+
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "echo", wrapperspb.String("hello"), output))
+		assert.EqualValues(t, "hello", output.GetValue())
+	})
+
+	t.Run("panics if already registered", func(t *testing.T) {
+		assert.Panics(t, func() {
+			plugin.Register(config)
+		})
+	})
+
+	t.Run("panics if invalid", func(t *testing.T) {
+		assert.Panics(t, func() {
+			plugin.Register(plugin.Config{Name: "invalid/name"})
+		})
+	})
+}
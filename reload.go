@@ -0,0 +1,124 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+// The reloader interface describes a Client that can be reloaded by Manager.Reload, such as a *Plugin.
+type reloader interface {
+	Reload(ctx context.Context) error
+}
+
+// ErrReloadUnsupported is the error returned by Manager.Reload when the named plugin is a Client that cannot be
+// reloaded, such as a fake used for testing.
+var ErrReloadUnsupported = errors.New("reload unsupported")
+
+// drainInterval is how often Plugin.Reload checks whether calls to the previous plugin processes have completed.
+const drainInterval = 10 * time.Millisecond
+
+// Reload the plugin by starting new plugin processes from its binary, allowing the binary to be replaced while the
+// host application is running. The new processes are verified and queried for their version and commands exactly as
+// they would be by Use. If they fail to start, the error is returned and calls continue to be handled by the
+// running processes.
+//
+// Once started, new calls are sent to the new processes, while calls already in flight complete on the previous
+// processes, which are then closed as they would be by Plugin.Close. If the context is cancelled before the in-flight
+// calls complete, the previous processes are closed without waiting further. Returns ErrClosed if the Plugin has been
+// closed.
+func (p *Plugin) Reload(ctx context.Context) error {
+	// Reloads are serialized so that each one replaces the processes started by the last.
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+
+	return p.reload(ctx, p.current(), nil)
+}
+
+// reload replaces the running processes with new ones started from the binary. If check is not nil, it is given the
+// information reported by the running and the new processes, and the new processes are only used if it returns nil.
+// The caller must hold the reloadMu.
+func (p *Plugin) reload(ctx context.Context, b *binary, check func(current, next plugin.Info) error) error {
+	p.mu.RLock()
+	closed := p.closed
+	current := p.info
+	p.mu.RUnlock()
+
+	if closed {
+		return fmt.Errorf("%w: %q", ErrClosed, p.name)
+	}
+
+	pl, info, err := p.startPool(ctx, b)
+	if err != nil {
+		return fmt.Errorf("failed to reload plugin %q: %w", p.name, err)
+	}
+
+	if check != nil {
+		if err = check(current, info); err != nil {
+			return errors.Join(err, pl.close())
+		}
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return errors.Join(fmt.Errorf("%w: %q", ErrClosed, p.name), pl.close())
+	}
+
+	previous := p.pool
+	p.pool = pl
+	p.info = info
+	p.binary.Store(b)
+	p.lastUsed.Store(p.options.clock.Now().UnixNano())
+	if p.options.idleTimeout > 0 {
+		go p.stopWhenIdle(pl)
+	}
+	p.mu.Unlock()
+
+	// The plugin may not have been running, such as when started lazily or stopped due to inactivity.
+	if previous == nil {
+		return nil
+	}
+
+	previous.drain(ctx, p.options.clock)
+	return previous.close()
+}
+
+// drain waits until none of the processes are executing calls, or until the context is cancelled.
+func (pl *pool) drain(ctx context.Context, clock Clock) {
+	for {
+		busy := false
+		for _, proc := range pl.processes {
+			busy = busy || proc.inflight.Load() > 0
+		}
+
+		if !busy {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-clock.After(drainInterval):
+		}
+	}
+}
+
+// Reload the named plugin, as described by Plugin.Reload. Returns ErrNoPlugin if no plugin with the given name has
+// been added to the Manager, and ErrReloadUnsupported if the plugin cannot be reloaded.
+func (m *Manager) Reload(ctx context.Context, name string) error {
+	p, ok := m.Get(name)
+	if !ok {
+		return fmt.Errorf("%w: no plugin named %q", ErrNoPlugin, name)
+	}
+
+	r, ok := p.(reloader)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrReloadUnsupported, name)
+	}
+
+	return r.Reload(ctx)
+}
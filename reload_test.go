@@ -0,0 +1,152 @@
+package plugin_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestPlugin_Reload(t *testing.T) {
+	t.Parallel()
+
+	binary, err := filepath.Abs("./test_plugin")
+	require.NoError(t, err)
+
+	pid := func(t *testing.T, p *plugin.Plugin) int64 {
+		output := &wrapperspb.Int64Value{}
+		require.NoError(t, p.Exec(t.Context(), "pid", &emptypb.Empty{}, output))
+		return output.GetValue()
+	}
+
+	t.Run("replaces the plugin processes", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := plugin.Use(t.Context(), "./test_plugin")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, p.Close())
+		})
+
+		before := pid(t, p)
+
+		// Calls in flight during the reload complete on the previous process.
+		done := make(chan error, 1)
+		go func() {
+			done <- p.Exec(t.Context(), "sleep", durationpb.New(500*time.Millisecond), &durationpb.Duration{})
+		}()
+
+		// Allow the call to reach the plugin before reloading.
+		time.Sleep(100 * time.Millisecond)
+
+		require.NoError(t, p.Reload(t.Context()))
+		assert.NotEqual(t, before, pid(t, p))
+
+		// The previous process is only closed once the call has completed.
+		select {
+		case err = <-done:
+			assert.NoError(t, err)
+		default:
+			assert.Fail(t, "reload did not wait for the in-flight call")
+		}
+
+		exits := p.Exits()
+		require.Len(t, exits, 1)
+		assert.EqualValues(t, before, exits[0].PID)
+	})
+
+	t.Run("uses the replaced binary", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "test_plugin")
+		contents, err := os.ReadFile(binary)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, contents, 0o755))
+
+		p, err := plugin.Use(t.Context(), path)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, p.Close())
+		})
+
+		// The binary is replaced by renaming, as a deployment would, so that the running process is unaffected.
+		replacement := filepath.Join(filepath.Dir(path), "replacement")
+		require.NoError(t, os.WriteFile(replacement, []byte("#!/bin/sh\nexec "+binary+" \"$@\" --pong reloaded\n"), 0o755))
+		require.NoError(t, os.Rename(replacement, path))
+
+		require.NoError(t, p.Reload(t.Context()))
+
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+		assert.EqualValues(t, "reloaded", output.GetValue())
+	})
+
+	t.Run("keeps the running processes if the reload fails", func(t *testing.T) {
+		t.Parallel()
+
+		path := filepath.Join(t.TempDir(), "test_plugin")
+		require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexec "+binary+" \"$@\"\n"), 0o755))
+
+		p, err := plugin.Use(t.Context(), path)
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, p.Close())
+		})
+
+		before := pid(t, p)
+
+		replacement := filepath.Join(filepath.Dir(path), "replacement")
+		require.NoError(t, os.WriteFile(replacement, []byte("#!/bin/sh\necho broken >&2\nexit 1\n"), 0o755))
+		require.NoError(t, os.Rename(replacement, path))
+
+		err = p.Reload(t.Context())
+		require.ErrorIs(t, err, plugin.ErrPluginStartFailed)
+		assert.EqualValues(t, before, pid(t, p))
+	})
+
+	t.Run("error if closed", func(t *testing.T) {
+		t.Parallel()
+
+		p, err := plugin.Use(t.Context(), "./test_plugin")
+		require.NoError(t, err)
+		require.NoError(t, p.Close())
+
+		assert.ErrorIs(t, p.Reload(t.Context()), plugin.ErrClosed)
+	})
+}
+
+func TestManager_Reload(t *testing.T) {
+	t.Parallel()
+
+	p, err := plugin.Use(t.Context(), "./test_plugin")
+	require.NoError(t, err)
+
+	manager := plugin.NewManager()
+	require.NoError(t, manager.Add(p))
+	require.NoError(t, manager.Add(plugintest.NewFake("fake", "v1.0.0")))
+	t.Cleanup(func() {
+		assert.NoError(t, manager.Close())
+	})
+
+	t.Run("reloads the named plugin", func(t *testing.T) {
+		assert.NoError(t, manager.Reload(t.Context(), "test_plugin"))
+		assert.Len(t, p.Exits(), 1)
+	})
+
+	t.Run("error for unknown plugins", func(t *testing.T) {
+		assert.ErrorIs(t, manager.Reload(t.Context(), "unknown"), plugin.ErrNoPlugin)
+	})
+
+	t.Run("error for clients that cannot be reloaded", func(t *testing.T) {
+		assert.ErrorIs(t, manager.Reload(t.Context(), "fake"), plugin.ErrReloadUnsupported)
+	})
+}
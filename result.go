@@ -0,0 +1,45 @@
+package plugin
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	// The ExecResult type describes a call to Plugin.ExecWithResult, as reported by the plugin and the host
+	// application, which can be used for purposes such as billing and diagnostics.
+	ExecResult struct {
+		// The Duration the plugin spent executing the command, as measured by the plugin. This excludes the time spent
+		// sending the request and response, and waiting to be retried.
+		Duration time.Duration
+		// The Version of the plugin process that executed the command. This may differ between calls while the plugin
+		// is being reloaded.
+		Version string
+		// The number of Attempts made to execute the command, including the first. This is greater than one when
+		// failed attempts have been retried according to a RetryPolicy.
+		Attempts int
+		// Any Warnings reported by the command using AddWarning.
+		Warnings []string
+	}
+)
+
+// ExecWithResult executes the named command in the same way as Plugin.Exec, additionally returning an ExecResult
+// describing the call. The ExecResult describes the final attempt to execute the command, and is returned even when
+// the command fails. It is empty if the command was never sent to the plugin, such as when an ExecInterceptor
+// returns without executing it.
+func (p *Plugin) ExecWithResult(ctx context.Context, name string, input proto.Message, output proto.Message, opts ...ExecOption) (ExecResult, error) {
+	var result ExecResult
+	err := p.Exec(ctx, name, input, output, append(slices.Clip(opts), withResult(&result))...)
+
+	return result, err
+}
+
+// withResult is an ExecOption that records the ExecResult of the call.
+func withResult(result *ExecResult) ExecOption {
+	return func(o *execOptions) {
+		o.result = result
+	}
+}
@@ -0,0 +1,74 @@
+package plugin_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestPlugin_ExecWithResult(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	p := plugintest.New(t, plugin.Config{
+		Name:    "result",
+		Version: "v1.2.3",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "flaky",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					if input.GetValue() == "retry" && calls.Add(1) == 1 {
+						return nil, status.Error(codes.Unavailable, "try again")
+					}
+
+					time.Sleep(10 * time.Millisecond)
+					plugin.AddWarning(ctx, "slow")
+					return input, nil
+				},
+			},
+		},
+	})
+
+	policy := plugin.ExponentialBackoff{InitialDelay: time.Millisecond}
+
+	tt := []struct {
+		Name             string
+		Input            string
+		ExpectedAttempts int
+	}{
+		{
+			Name:             "single attempt",
+			Input:            "once",
+			ExpectedAttempts: 1,
+		},
+		{
+			Name:             "retried attempt",
+			Input:            "retry",
+			ExpectedAttempts: 2,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			output := &wrapperspb.StringValue{}
+			result, err := p.ExecWithResult(t.Context(), "flaky", wrapperspb.String(tc.Input), output, plugin.WithExecRetryPolicy(policy))
+			require.NoError(t, err)
+
+			assert.EqualValues(t, tc.Input, output.GetValue())
+			assert.EqualValues(t, "v1.2.3", result.Version)
+			assert.EqualValues(t, tc.ExpectedAttempts, result.Attempts)
+			assert.GreaterOrEqual(t, result.Duration, 10*time.Millisecond)
+			assert.EqualValues(t, []string{"slow"}, result.Warnings)
+		})
+	}
+}
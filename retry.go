@@ -0,0 +1,118 @@
+package plugin
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// The RetryPolicy interface describes types that determine whether a failed call to Plugin.Exec should be
+	// attempted again, and how long to wait before doing so. Host applications can implement this interface to provide
+	// their own retry strategies, or use the ExponentialBackoff type.
+	RetryPolicy interface {
+		// Next is called after each failed attempt to execute a command. It is provided the number of attempts made
+		// so far and the error returned by the most recent attempt, which can be inspected using status.Code. It
+		// returns the time to wait before the next attempt and whether another attempt should be made.
+		Next(ctx context.Context, attempt int, err error) (time.Duration, bool)
+	}
+
+	// The ExponentialBackoff type is a RetryPolicy that retries commands that failed because the plugin was
+	// unavailable, or that failed with a transient error when the command is idempotent. The delay between each attempt
+	// grows exponentially up to a maximum.
+	ExponentialBackoff struct {
+		// The maximum number of attempts to make, including the first. Defaults to 3.
+		MaxAttempts int
+		// The time to wait before the first retry. Defaults to 100 milliseconds.
+		InitialDelay time.Duration
+		// The maximum time to wait between attempts. Defaults to 5 seconds.
+		MaxDelay time.Duration
+		// The factor the delay is multiplied by after each attempt. Defaults to 2.
+		Multiplier float64
+		// The Transient status codes that are also retried for commands the plugin declares as idempotent, such as
+		// codes.ResourceExhausted or codes.Aborted.
+		Transient []codes.Code
+	}
+
+	idempotentKey struct{}
+)
+
+// Next returns the time to wait before the next attempt to execute a command. Errors with the codes.Unavailable
+// status code are always retried, while those with one of the Transient status codes are only retried if the command
+// is idempotent.
+func (eb ExponentialBackoff) Next(ctx context.Context, attempt int, err error) (time.Duration, bool) {
+	maxAttempts := eb.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+
+	if attempt >= maxAttempts || !eb.retryable(ctx, status.Code(err)) {
+		return 0, false
+	}
+
+	delay := eb.InitialDelay
+	if delay <= 0 {
+		delay = 100 * time.Millisecond
+	}
+
+	maxDelay := eb.MaxDelay
+	if maxDelay <= 0 {
+		maxDelay = 5 * time.Second
+	}
+
+	multiplier := eb.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay = time.Duration(float64(delay) * multiplier)
+	}
+
+	return min(delay, maxDelay), true
+}
+
+func (eb ExponentialBackoff) retryable(ctx context.Context, code codes.Code) bool {
+	if code == codes.Unavailable {
+		return true
+	}
+
+	return IsIdempotent(ctx) && slices.Contains(eb.Transient, code)
+}
+
+// IsIdempotent returns true if the plugin declares the command being executed as idempotent. The context provided to
+// RetryPolicy.Next can be checked using this function to decide whether an error that may have occurred after the
+// command started can safely be retried.
+func IsIdempotent(ctx context.Context) bool {
+	idempotent, _ := ctx.Value(idempotentKey{}).(bool)
+	return idempotent
+}
+
+func (p *Plugin) retry(ctx context.Context, policy RetryPolicy, name string, attempt int, err error) bool {
+	if policy == nil {
+		return false
+	}
+
+	idempotent := slices.ContainsFunc(p.Commands(), func(command CommandInfo) bool {
+		return command.Name == name && command.Idempotent
+	})
+
+	ctx = context.WithValue(ctx, idempotentKey{}, idempotent)
+
+	delay, ok := policy.Next(ctx, attempt, err)
+	if !ok {
+		return false
+	}
+
+	p.diagnostics().Debug("retrying command", "command", name, "attempt", attempt, "delay", delay, "error", err)
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-p.options.clock.After(delay):
+		return true
+	}
+}
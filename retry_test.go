@@ -0,0 +1,128 @@
+package plugin_test
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestExponentialBackoff_Next(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Name          string
+		Policy        plugin.ExponentialBackoff
+		Attempt       int
+		Error         error
+		ExpectedDelay time.Duration
+		ExpectedRetry bool
+	}{
+		{
+			Name:          "retries unavailable errors using defaults",
+			Attempt:       1,
+			Error:         status.Error(codes.Unavailable, "unavailable"),
+			ExpectedDelay: 100 * time.Millisecond,
+			ExpectedRetry: true,
+		},
+		{
+			Name: "delay grows exponentially",
+			Policy: plugin.ExponentialBackoff{
+				MaxAttempts:  5,
+				InitialDelay: time.Second,
+				MaxDelay:     time.Minute,
+				Multiplier:   3,
+			},
+			Attempt:       3,
+			Error:         status.Error(codes.Unavailable, "unavailable"),
+			ExpectedDelay: 9 * time.Second,
+			ExpectedRetry: true,
+		},
+		{
+			Name: "delay does not exceed maximum",
+			Policy: plugin.ExponentialBackoff{
+				MaxAttempts:  10,
+				InitialDelay: time.Second,
+				MaxDelay:     5 * time.Second,
+			},
+			Attempt:       8,
+			Error:         status.Error(codes.Unavailable, "unavailable"),
+			ExpectedDelay: 5 * time.Second,
+			ExpectedRetry: true,
+		},
+		{
+			Name:    "does not retry after maximum attempts",
+			Attempt: 3,
+			Error:   status.Error(codes.Unavailable, "unavailable"),
+		},
+		{
+			Name:    "does not retry other status codes",
+			Attempt: 1,
+			Error:   status.Error(codes.Internal, "internal"),
+		},
+		{
+			Name: "does not retry transient errors for commands that are not idempotent",
+			Policy: plugin.ExponentialBackoff{
+				Transient: []codes.Code{codes.ResourceExhausted},
+			},
+			Attempt: 1,
+			Error:   status.Error(codes.ResourceExhausted, "exhausted"),
+		},
+		{
+			Name:    "does not retry non-status errors",
+			Attempt: 1,
+			Error:   io.EOF,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			delay, retry := tc.Policy.Next(t.Context(), tc.Attempt, tc.Error)
+			assert.Equal(t, tc.ExpectedRetry, retry)
+			assert.Equal(t, tc.ExpectedDelay, delay)
+		})
+	}
+}
+
+func TestExponentialBackoff_Idempotent(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int64
+	run := func(ctx context.Context, input *emptypb.Empty) (*emptypb.Empty, error) {
+		calls.Add(1)
+		return nil, status.Error(codes.ResourceExhausted, "exhausted")
+	}
+
+	p := plugintest.New(t, plugin.Config{
+		Name: "idempotent",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{Use: "idempotent", Idempotent: true, Run: run},
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{Use: "mutating", Run: run},
+		},
+	}, plugin.WithRetryPolicy(plugin.ExponentialBackoff{
+		MaxAttempts:  3,
+		InitialDelay: time.Millisecond,
+		Transient:    []codes.Code{codes.ResourceExhausted},
+	}))
+
+	t.Run("retries transient errors for idempotent commands", func(t *testing.T) {
+		calls.Store(0)
+		assert.Error(t, p.Exec(t.Context(), "idempotent", &emptypb.Empty{}, &emptypb.Empty{}))
+		assert.EqualValues(t, 3, calls.Load())
+	})
+
+	t.Run("does not retry transient errors for other commands", func(t *testing.T) {
+		calls.Store(0)
+		assert.Error(t, p.Exec(t.Context(), "mutating", &emptypb.Empty{}, &emptypb.Empty{}))
+		assert.EqualValues(t, 1, calls.Load())
+	})
+}
@@ -0,0 +1,36 @@
+package plugin
+
+import (
+	"errors"
+	"time"
+)
+
+type (
+	// The ResourceLimits type describes limits on the operating system resources a plugin process may consume,
+	// providing basic containment of third-party plugins. Each limit is applied as both the soft and hard limit, so
+	// the plugin cannot raise it. Zero values leave the corresponding limit unchanged.
+	ResourceLimits struct {
+		// The maximum size, in bytes, of the virtual memory of the process (RLIMIT_AS). Allocations beyond this limit
+		// fail.
+		AddressSpace uint64
+		// The maximum amount of CPU time the process may consume (RLIMIT_CPU), rounded up to the nearest second. Once
+		// exceeded, the process is killed by the operating system.
+		CPUTime time.Duration
+		// The maximum number of file descriptors the process may have open (RLIMIT_NOFILE), including its sockets.
+		OpenFiles uint64
+		// The maximum size, in bytes, of any file written by the process (RLIMIT_FSIZE).
+		FileSize uint64
+		// The maximum number of processes and threads that may exist for the user the plugin runs as (RLIMIT_NPROC).
+		// As this limit is shared by every process of the same user, it is best combined with WithCredential.
+		Processes uint64
+	}
+)
+
+// ErrResourceLimitsUnsupported is the error given by Use when the WithResourceLimits option is used on a platform that
+// does not support setting the resource limits of another process.
+var ErrResourceLimitsUnsupported = errors.New("resource limits are not supported on this platform")
+
+// cpuSeconds returns the CPUTime limit in whole seconds, rounded up.
+func (l ResourceLimits) cpuSeconds() uint64 {
+	return uint64((l.CPUTime + time.Second - 1) / time.Second)
+}
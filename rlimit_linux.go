@@ -0,0 +1,39 @@
+//go:build linux
+
+package plugin
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+const resourceLimitsSupported = true
+
+// apply sets the resource limits of the process with the given identifier.
+func (l ResourceLimits) apply(pid int) error {
+	limits := []struct {
+		name     string
+		resource int
+		value    uint64
+	}{
+		{name: "address space", resource: unix.RLIMIT_AS, value: l.AddressSpace},
+		{name: "CPU time", resource: unix.RLIMIT_CPU, value: l.cpuSeconds()},
+		{name: "open files", resource: unix.RLIMIT_NOFILE, value: l.OpenFiles},
+		{name: "file size", resource: unix.RLIMIT_FSIZE, value: l.FileSize},
+		{name: "processes", resource: unix.RLIMIT_NPROC, value: l.Processes},
+	}
+
+	for _, limit := range limits {
+		if limit.value == 0 {
+			continue
+		}
+
+		rlimit := &unix.Rlimit{Cur: limit.value, Max: limit.value}
+		if err := unix.Prlimit(pid, limit.resource, rlimit, nil); err != nil {
+			return fmt.Errorf("failed to limit %s: %w", limit.name, err)
+		}
+	}
+
+	return nil
+}
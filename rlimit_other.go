@@ -0,0 +1,9 @@
+//go:build !linux
+
+package plugin
+
+const resourceLimitsSupported = false
+
+func (ResourceLimits) apply(int) error {
+	return ErrResourceLimitsUnsupported
+}
@@ -0,0 +1,39 @@
+//go:build linux
+
+package plugin_test
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestUse_WithResourceLimits(t *testing.T) {
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithResourceLimits(plugin.ResourceLimits{
+		CPUTime:   1500 * time.Millisecond,
+		OpenFiles: 128,
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	output := &wrapperspb.Int64Value{}
+	require.NoError(t, p.Exec(t.Context(), "pid", &emptypb.Empty{}, output))
+
+	limits, err := os.ReadFile(fmt.Sprintf("/proc/%d/limits", output.GetValue()))
+	require.NoError(t, err)
+
+	assert.Regexp(t, regexp.MustCompile(`Max cpu time\s+2\s+2\s+seconds`), string(limits))
+	assert.Regexp(t, regexp.MustCompile(`Max open files\s+128\s+128\s+files`), string(limits))
+	assert.Regexp(t, regexp.MustCompile(`Max file size\s+unlimited`), string(limits))
+}
@@ -0,0 +1,118 @@
+package plugin
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+type (
+	// The Runtime interface describes types that launch plugin processes. By default, plugins are executed directly
+	// as a child process of the host application. Other runtimes may launch them elsewhere, such as within a
+	// container, provided the plugin is able to create its UNIX domain socket within the socket directory of the
+	// host application.
+	Runtime interface {
+		// Command returns the command used to start the described plugin process. The command must run until the
+		// plugin exits, and pass any signals it receives on to the plugin.
+		Command(process Process) (*exec.Cmd, error)
+	}
+
+	// The Process type describes a plugin process to be started by a Runtime.
+	Process struct {
+		// The unique identifier of the process, which is also the name of its UNIX domain socket.
+		ID string
+		// The name of the plugin.
+		Name string
+		// The path to the plugin, as given to Use.
+		Path string
+		// The arguments to pass to the plugin, not including its path.
+		Args []string
+		// The environment variables set for the plugin, in "key=value" form, including those given to WithEnv and
+		// those used to authenticate the host application. These must always be given to the plugin.
+		Env []string
+		// The environment variables inherited from the host application, in "key=value" form, limited to those
+		// given to WithInheritEnv if used.
+		Environ []string
+		// The working directory given to WithWorkingDirectory, if any.
+		Dir string
+		// The directory in which the plugin creates its UNIX domain socket.
+		SocketDirectory string
+	}
+
+	// The ProcessRuntime type is a Runtime that executes the plugin binary at the given path directly. It is used
+	// unless another Runtime is given to WithRuntime.
+	ProcessRuntime struct{}
+
+	// The Container type is a Runtime that runs the plugin within an OCI container using a container engine such as
+	// Docker or Podman. The entrypoint of the image must be the plugin binary, and the plugin name given to Use must
+	// match the name the plugin reports. The socket directory is bind-mounted into the container at the same path,
+	// so the host application connects to the plugin as it would any other. As the host application is not the parent
+	// of the plugin, plugins with Config.VerifyPeer enabled reject it. Likewise, a Profile cannot be enforced within a
+	// container, so Options such as "--security-opt" should be used instead.
+	//
+	// The container is removed once the plugin exits. Signals sent to stop the plugin are proxied to the container by
+	// the container engine, however a container engine that is killed may leave its container running.
+	Container struct {
+		// The image to run.
+		Image string
+		// The container engine to use, which must provide a Docker-compatible "run" command. Defaults to "docker".
+		Engine string
+		// Any additional options for the "run" command, such as "--network=none" or "--read-only".
+		Options []string
+	}
+)
+
+// Command returns a command that executes the plugin binary.
+func (ProcessRuntime) Command(process Process) (*exec.Cmd, error) {
+	return &exec.Cmd{
+		Path: process.Path,
+		Args: append([]string{process.Path}, process.Args...),
+		Env:  append(process.Environ, process.Env...),
+		Dir:  process.Dir,
+	}, nil
+}
+
+// Command returns a command that runs the plugin within a container. Only the environment variables within
+// Process.Env are given to the container.
+func (c Container) Command(process Process) (*exec.Cmd, error) {
+	if c.Image == "" {
+		return nil, fmt.Errorf("no image specified for plugin %q", process.Name)
+	}
+
+	engine := c.Engine
+	if engine == "" {
+		engine = "docker"
+	}
+
+	path, err := exec.LookPath(engine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find container engine %q: %w", engine, err)
+	}
+
+	args := []string{
+		engine,
+		"run",
+		"--rm",
+		"--interactive",
+		"--name", process.Name + "-" + process.ID,
+		"--volume", process.SocketDirectory + ":" + process.SocketDirectory,
+	}
+
+	// Variables are named without their values, which the container engine reads from its own environment, so that
+	// they are not visible within its arguments.
+	for _, variable := range process.Env {
+		name, _, _ := strings.Cut(variable, "=")
+		args = append(args, "--env", name)
+	}
+
+	args = append(args, c.Options...)
+	args = append(args, c.Image)
+	args = append(args, process.Args...)
+
+	return &exec.Cmd{
+		Path: path,
+		Args: args,
+		Env:  append(process.Environ, process.Env...),
+		Dir:  process.Dir,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package plugin_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+type (
+	// envRuntime is a Runtime that launches plugins using the env command, recording the processes it launches.
+	envRuntime struct {
+		processes []plugin.Process
+	}
+)
+
+func (r *envRuntime) Command(process plugin.Process) (*exec.Cmd, error) {
+	r.processes = append(r.processes, process)
+
+	path, err := exec.LookPath("env")
+	if err != nil {
+		return nil, err
+	}
+
+	return &exec.Cmd{
+		Path: path,
+		Args: append([]string{"env", process.Path}, process.Args...),
+		Env:  append(process.Environ, process.Env...),
+	}, nil
+}
+
+func TestUse_WithRuntime(t *testing.T) {
+	runtime := &envRuntime{}
+	directory := t.TempDir()
+
+	p, err := plugin.Use(t.Context(), "./test_plugin",
+		plugin.WithRuntime(runtime),
+		plugin.WithSocketDirectory(directory),
+		plugin.WithEnv("PLUGIN_TEST_VALUE=hello"),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+	assert.EqualValues(t, "pong", output.GetValue())
+
+	require.Len(t, runtime.processes, 1)
+	process := runtime.processes[0]
+	assert.EqualValues(t, "test_plugin", process.Name)
+	assert.EqualValues(t, "./test_plugin", process.Path)
+	assert.EqualValues(t, directory, process.SocketDirectory)
+	assert.Contains(t, process.Args, process.ID)
+	assert.Contains(t, process.Env, "PLUGIN_TEST_VALUE=hello")
+}
+
+func TestContainer_Command(t *testing.T) {
+	engine, err := exec.LookPath("env")
+	require.NoError(t, err)
+
+	directory := t.TempDir()
+	container := plugin.Container{
+		Image:   "example.com/plugins/test:latest",
+		Engine:  "env",
+		Options: []string{"--network=none"},
+	}
+
+	t.Run("runs the plugin within a container", func(t *testing.T) {
+		cmd, err := container.Command(plugin.Process{
+			ID:              "abc",
+			Name:            "test",
+			Path:            "test",
+			Args:            []string{"--socket-directory", directory, "abc"},
+			Env:             []string{"PLUGIN_TOKEN=secret"},
+			Environ:         []string{"PATH=/usr/bin"},
+			SocketDirectory: directory,
+		})
+		require.NoError(t, err)
+
+		assert.EqualValues(t, engine, cmd.Path)
+		assert.Equal(t, []string{
+			"env", "run", "--rm", "--interactive",
+			"--name", "test-abc",
+			"--volume", directory + ":" + directory,
+			"--env", "PLUGIN_TOKEN",
+			"--network=none",
+			"example.com/plugins/test:latest",
+			"--socket-directory", directory, "abc",
+		}, cmd.Args)
+		assert.Equal(t, []string{"PATH=/usr/bin", "PLUGIN_TOKEN=secret"}, cmd.Env)
+	})
+
+	t.Run("error if no image is specified", func(t *testing.T) {
+		_, err := plugin.Container{}.Command(plugin.Process{Name: "test"})
+		assert.Error(t, err)
+	})
+
+	t.Run("error if the container engine is not installed", func(t *testing.T) {
+		_, err := plugin.Container{Image: "test", Engine: filepath.Join(directory, "missing")}.Command(plugin.Process{Name: "test"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+type (
+	// The Sandboxer interface describes types that restrict what a plugin process may do once started. Sandboxers
+	// are given the command used to start the plugin, after every other UseOption has been applied, and may modify
+	// it as required, such as by setting its SysProcAttr or wrapping it in another program like bubblewrap.
+	Sandboxer interface {
+		// Sandbox modifies the command used to start the plugin process. If it returns an error, the plugin is not
+		// started.
+		Sandbox(cmd *exec.Cmd) error
+	}
+
+	// The SandboxerFunc type is a function that implements the Sandboxer interface.
+	SandboxerFunc func(cmd *exec.Cmd) error
+
+	// The Profile type is a Sandboxer that restricts the filesystem access and system calls of a plugin process using
+	// Landlock and seccomp. The profile is enforced by the plugin itself as it starts, before it serves any commands,
+	// and the plugin fails to start if it cannot be enforced. This contains plugins that are compromised while running,
+	// but not a plugin binary that has been modified to ignore the profile, so it is best combined with checksum or
+	// signature verification. Profiles are only supported on Linux, on the amd64 and arm64 architectures, by plugins
+	// built without cgo.
+	//
+	// Once enforced, the plugin may only access the filesystem beneath the given paths, along with the directory
+	// containing its UNIX domain socket. Paths that do not exist are ignored. System calls that allow the plugin to
+	// inspect other processes, load kernel modules or BPF programs, change namespaces or mounts, or reboot the
+	// system are rejected with EPERM.
+	Profile struct {
+		// Paths the plugin may read and execute files beneath.
+		ReadOnly []string `json:"readOnly,omitempty"`
+		// Paths the plugin may read, write, execute, create and remove files beneath.
+		ReadWrite []string `json:"readWrite,omitempty"`
+	}
+)
+
+var (
+	// ErrSandboxUnsupported is the error given when a Profile is used on a platform that does not support enforcing
+	// it.
+	ErrSandboxUnsupported = errors.New("sandboxing is not supported on this platform")
+
+	// ErrNotSandboxed is the error given by Use when a plugin started with a Profile does not report that it has
+	// enforced it, such as when it was built using a version of this package that does not support profiles.
+	ErrNotSandboxed = errors.New("plugin is not sandboxed")
+)
+
+// sandboxEnvironmentVariable is the environment variable used to give a Profile to a plugin process.
+const sandboxEnvironmentVariable = "PLUGIN_SANDBOX"
+
+// Sandbox calls the function.
+func (fn SandboxerFunc) Sandbox(cmd *exec.Cmd) error {
+	return fn(cmd)
+}
+
+// Sandbox gives the Profile to the plugin process, to be enforced as it starts.
+func (p Profile) Sandbox(cmd *exec.Cmd) error {
+	if !sandboxSupported {
+		return ErrSandboxUnsupported
+	}
+
+	profile, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	cmd.Env = append(cmd.Env, sandboxEnvironmentVariable+"="+string(profile))
+	return nil
+}
+
+// enforceSandbox enforces the Profile given to the plugin process by the host application, if any, allowing access to
+// the socket directory. Returns true if a Profile was enforced.
+func enforceSandbox(socketDirectory string) (bool, error) {
+	value, ok := os.LookupEnv(sandboxEnvironmentVariable)
+	if !ok {
+		return false, nil
+	}
+
+	// The profile is removed from the environment so that processes started by commands do not attempt to enforce
+	// it again.
+	if err := os.Unsetenv(sandboxEnvironmentVariable); err != nil {
+		return false, err
+	}
+
+	var profile Profile
+	if err := json.Unmarshal([]byte(value), &profile); err != nil {
+		return false, fmt.Errorf("invalid sandbox profile: %w", err)
+	}
+
+	profile.ReadWrite = append(profile.ReadWrite, socketDirectory)
+	if err := profile.enforce(); err != nil {
+		return false, fmt.Errorf("failed to enforce sandbox profile: %w", err)
+	}
+
+	return true, nil
+}
+
+// sandboxed returns true if any of the sandboxers is a Profile, which plugins must report having enforced.
+func sandboxed(sandboxers []Sandboxer) bool {
+	for _, sandboxer := range sandboxers {
+		if _, ok := sandboxer.(Profile); ok {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,207 @@
+//go:build linux
+
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const sandboxSupported = true
+
+const (
+	landlockReadOnly = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_READ_DIR
+
+	landlockReadWrite = landlockReadOnly | unix.LANDLOCK_ACCESS_FS_WRITE_FILE | unix.LANDLOCK_ACCESS_FS_REMOVE_DIR |
+		unix.LANDLOCK_ACCESS_FS_REMOVE_FILE | unix.LANDLOCK_ACCESS_FS_MAKE_CHAR | unix.LANDLOCK_ACCESS_FS_MAKE_DIR |
+		unix.LANDLOCK_ACCESS_FS_MAKE_REG | unix.LANDLOCK_ACCESS_FS_MAKE_SOCK | unix.LANDLOCK_ACCESS_FS_MAKE_FIFO |
+		unix.LANDLOCK_ACCESS_FS_MAKE_BLOCK | unix.LANDLOCK_ACCESS_FS_MAKE_SYM
+
+	// landlockFile contains the only access rights that can be granted on files rather than directories.
+	landlockFile = unix.LANDLOCK_ACCESS_FS_EXECUTE | unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE | unix.LANDLOCK_ACCESS_FS_TRUNCATE
+
+	// x32ABI is set within the system call numbers of the x32 ABI, which are otherwise indistinguishable from those
+	// of amd64.
+	x32ABI = 0x40000000
+)
+
+// deniedSyscalls are the system calls rejected by a Profile.
+var deniedSyscalls = []uint32{
+	unix.SYS_PTRACE,
+	unix.SYS_PROCESS_VM_READV,
+	unix.SYS_PROCESS_VM_WRITEV,
+	unix.SYS_MOUNT,
+	unix.SYS_UMOUNT2,
+	unix.SYS_PIVOT_ROOT,
+	unix.SYS_SETNS,
+	unix.SYS_UNSHARE,
+	unix.SYS_OPEN_BY_HANDLE_AT,
+	unix.SYS_KEXEC_LOAD,
+	unix.SYS_KEXEC_FILE_LOAD,
+	unix.SYS_INIT_MODULE,
+	unix.SYS_FINIT_MODULE,
+	unix.SYS_DELETE_MODULE,
+	unix.SYS_BPF,
+	unix.SYS_PERF_EVENT_OPEN,
+	unix.SYS_USERFAULTFD,
+	unix.SYS_KEYCTL,
+	unix.SYS_ADD_KEY,
+	unix.SYS_REQUEST_KEY,
+	unix.SYS_SWAPON,
+	unix.SYS_SWAPOFF,
+	unix.SYS_REBOOT,
+}
+
+// enforce the Profile on every thread of the current process. Threads created afterwards inherit its restrictions.
+func (p Profile) enforce() error {
+	// Both Landlock and seccomp require that the process cannot gain privileges by executing other programs. Landlock
+	// restrictions only apply to the calling thread, so must be applied to every thread, which the runtime does not
+	// support within binaries that use cgo.
+	_, _, errno := syscall.AllThreadsSyscall(syscall.SYS_PRCTL, unix.PR_SET_NO_NEW_PRIVS, 1, 0)
+	switch {
+	case errno == syscall.ENOTSUP:
+		return fmt.Errorf("%w: plugins using cgo cannot enforce profiles, build them with CGO_ENABLED=0", ErrSandboxUnsupported)
+	case errno != 0:
+		return fmt.Errorf("failed to set no_new_privs: %w", errno)
+	}
+
+	if err := p.restrictFilesystem(); err != nil {
+		return fmt.Errorf("failed to restrict filesystem access: %w", err)
+	}
+
+	if err := restrictSyscalls(); err != nil {
+		return fmt.Errorf("failed to restrict system calls: %w", err)
+	}
+
+	return nil
+}
+
+func (p Profile) restrictFilesystem() error {
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, 0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if errno != 0 {
+		return fmt.Errorf("%w: landlock is unavailable: %w", ErrSandboxUnsupported, errno)
+	}
+
+	// Later ABI versions handle additional access rights, which would otherwise remain unrestricted.
+	readWrite := uint64(landlockReadWrite)
+	if abi >= 2 {
+		readWrite |= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+
+	if abi >= 3 {
+		readWrite |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+
+	attr := unix.LandlockRulesetAttr{Access_fs: readWrite}
+	ruleset, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr), 0)
+	if errno != 0 {
+		return fmt.Errorf("failed to create ruleset: %w", errno)
+	}
+	defer unix.Close(int(ruleset))
+
+	for _, path := range p.ReadOnly {
+		if err := addLandlockRule(int(ruleset), path, landlockReadOnly); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range p.ReadWrite {
+		if err := addLandlockRule(int(ruleset), path, readWrite); err != nil {
+			return err
+		}
+	}
+
+	if _, _, errno = syscall.AllThreadsSyscall(unix.SYS_LANDLOCK_RESTRICT_SELF, ruleset, 0, 0); errno != 0 {
+		return fmt.Errorf("failed to restrict threads: %w", errno)
+	}
+
+	return nil
+}
+
+func addLandlockRule(ruleset int, path string, access uint64) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	switch {
+	case errors.Is(err, unix.ENOENT):
+		return nil
+	case err != nil:
+		return fmt.Errorf("failed to open %q: %w", path, err)
+	}
+	defer unix.Close(fd)
+
+	var stat unix.Stat_t
+	if err = unix.Fstat(fd, &stat); err != nil {
+		return fmt.Errorf("failed to stat %q: %w", path, err)
+	}
+
+	if stat.Mode&unix.S_IFMT != unix.S_IFDIR {
+		access &= landlockFile
+	}
+
+	attr := unix.LandlockPathBeneathAttr{Allowed_access: access, Parent_fd: int32(fd)}
+	if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(ruleset), unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&attr)), 0, 0, 0); errno != 0 {
+		return fmt.Errorf("failed to allow access to %q: %w", path, errno)
+	}
+
+	return nil
+}
+
+func restrictSyscalls() error {
+	var arch uint32
+	switch runtime.GOARCH {
+	case "amd64":
+		arch = unix.AUDIT_ARCH_X86_64
+	case "arm64":
+		arch = unix.AUDIT_ARCH_AARCH64
+	default:
+		return fmt.Errorf("%w: unsupported architecture %s", ErrSandboxUnsupported, runtime.GOARCH)
+	}
+
+	const (
+		load        = unix.BPF_LD | unix.BPF_W | unix.BPF_ABS
+		jumpEqual   = unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K
+		jumpAtLeast = unix.BPF_JMP | unix.BPF_JGE | unix.BPF_K
+		ret         = unix.BPF_RET | unix.BPF_K
+		deny        = unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)
+
+		// Offsets of the system call number and architecture within struct seccomp_data.
+		nrOffset   = 0
+		archOffset = 4
+	)
+
+	filter := []unix.SockFilter{
+		{Code: load, K: archOffset},
+		{Code: jumpEqual, Jt: 1, K: arch},
+		{Code: ret, K: unix.SECCOMP_RET_KILL_PROCESS},
+		{Code: load, K: nrOffset},
+		{Code: jumpAtLeast, Jf: 1, K: x32ABI},
+		{Code: ret, K: deny},
+	}
+
+	for _, nr := range deniedSyscalls {
+		filter = append(filter, unix.SockFilter{Code: jumpEqual, Jf: 1, K: nr}, unix.SockFilter{Code: ret, K: deny})
+	}
+
+	filter = append(filter, unix.SockFilter{Code: ret, K: unix.SECCOMP_RET_ALLOW})
+
+	program := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+
+	// The filter is synchronised to every other thread, which all have no_new_privs set.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	thread, _, errno := unix.Syscall(unix.SYS_SECCOMP, unix.SECCOMP_SET_MODE_FILTER, unix.SECCOMP_FILTER_FLAG_TSYNC, uintptr(unsafe.Pointer(&program)))
+	switch {
+	case errno != 0:
+		return errno
+	case thread != 0:
+		return fmt.Errorf("thread %d could not be synchronised", thread)
+	}
+
+	return nil
+}
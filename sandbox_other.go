@@ -0,0 +1,9 @@
+//go:build !linux
+
+package plugin
+
+const sandboxSupported = false
+
+func (Profile) enforce() error {
+	return ErrSandboxUnsupported
+}
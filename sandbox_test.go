@@ -0,0 +1,66 @@
+package plugin_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestUse_WithSandbox(t *testing.T) {
+	t.Run("sandboxers modify the command", func(t *testing.T) {
+		var path string
+		p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithSandbox(plugin.SandboxerFunc(func(cmd *exec.Cmd) error {
+			path = cmd.Path
+			return nil
+		})))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, p.Close())
+		})
+
+		assert.EqualValues(t, "./test_plugin", path)
+	})
+
+	t.Run("error if a sandboxer fails", func(t *testing.T) {
+		expected := errors.New("no sandbox available")
+		_, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithSandbox(plugin.SandboxerFunc(func(*exec.Cmd) error {
+			return expected
+		})))
+		assert.ErrorIs(t, err, expected)
+	})
+
+	t.Run("plugins enforce profiles", func(t *testing.T) {
+		if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+			t.Skip("profiles are only supported on linux/amd64 and linux/arm64")
+		}
+
+		p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithSandbox(plugin.Profile{}))
+		var startErr *plugin.StartError
+		if errors.As(err, &startErr) && strings.Contains(startErr.Stderr, "landlock is unavailable") {
+			t.Skip("landlock is not enabled within the kernel")
+		}
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			assert.NoError(t, p.Close())
+		})
+
+		output := &wrapperspb.Int64Value{}
+		require.NoError(t, p.Exec(t.Context(), "pid", &emptypb.Empty{}, output))
+
+		status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", output.GetValue()))
+		require.NoError(t, err)
+		assert.Regexp(t, `NoNewPrivs:\s+1`, string(status))
+		assert.Regexp(t, `Seccomp:\s+2`, string(status))
+	})
+}
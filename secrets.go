@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The SecretStore type provides access to the secrets given to the plugin by its host application using WithSecret,
+	// as returned by SecretsFromContext.
+	SecretStore struct {
+		secrets *plugin.Secrets
+	}
+)
+
+// ErrSecretsUnsupported is the error returned by Use and Connect when secrets are provided using WithSecret to a
+// plugin that does not accept them, such as one built using an older version of this package.
+var ErrSecretsUnsupported = plugin.ErrSecretsUnsupported
+
+// WithSecret is a UseOption that gives a secret, such as a credential, to the plugin. Unlike arguments, environment
+// variables and configuration, secrets are only ever held in memory and are sent to each plugin process once it has
+// started, before it is configured. Secrets are sealed to a key generated by the plugin process when it starts, so that
+// they cannot be read from captures or logs of the calls made to it. Plugins implemented in other languages may not
+// provide a key, in which case secrets are sent unsealed. Plugins obtain secrets using SecretsFromContext.
+// This option can be given many times to provide many secrets.
+func WithSecret(name string, value []byte) UseOption {
+	return func(o *useOptions) {
+		if o.secrets == nil {
+			o.secrets = make(map[string][]byte)
+		}
+
+		o.secrets[name] = slices.Clone(value)
+	}
+}
+
+// SecretsFromContext returns the secrets given to the plugin by the host application using WithSecret, within the
+// context given to a command or to Config.Configure.
+func SecretsFromContext(ctx context.Context) SecretStore {
+	return SecretStore{secrets: plugin.SecretsFromContext(ctx)}
+}
+
+// Get returns the named secret. The returned slice is a copy that can be cleared once no longer needed. Returns false
+// if the host application has not provided the secret.
+func (s SecretStore) Get(name string) ([]byte, bool) {
+	return s.secrets.Get(name)
+}
+
+// Names returns the names of the secrets provided by the host application, in sorted order.
+func (s SecretStore) Names() []string {
+	return s.secrets.Names()
+}
+
+// sendSecrets gives the secrets provided via WithSecret to a newly started plugin process, sealed to its secret key.
+func (p *Plugin) sendSecrets(ctx context.Context, proc *process, info plugin.Info) error {
+	if len(p.options.secrets) == 0 {
+		return nil
+	}
+
+	if err := proc.client.SetSecrets(ctx, info.SecretKey, p.options.secrets); err != nil {
+		return fmt.Errorf("failed to send secrets to plugin %q: %w", p.name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,73 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestWithSecret(t *testing.T) {
+	t.Parallel()
+
+	var configured []string
+	p := plugintest.New(t, plugin.Config{
+		Name: "secrets",
+		Configure: func(ctx context.Context, _ *anypb.Any) error {
+			configured = plugin.SecretsFromContext(ctx).Names()
+			return nil
+		},
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.BytesValue]{
+				Use: "secret",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.BytesValue, error) {
+					secret, ok := plugin.SecretsFromContext(ctx).Get(input.GetValue())
+					if !ok {
+						return nil, errors.New("no secret")
+					}
+
+					return wrapperspb.Bytes(secret), nil
+				},
+			},
+			&plugin.Command[*emptypb.Empty, *wrapperspb.StringValue]{
+				Use: "names",
+				Run: func(ctx context.Context, _ *emptypb.Empty) (*wrapperspb.StringValue, error) {
+					return wrapperspb.String(strings.Join(plugin.SecretsFromContext(ctx).Names(), ",")), nil
+				},
+			},
+		},
+	},
+		plugin.WithSecret("token", []byte("hunter2")),
+		plugin.WithSecret("key", []byte{0x00, 0x01}),
+	)
+
+	assert.EqualValues(t, []string{"key", "token"}, configured)
+
+	t.Run("provides secrets to commands", func(t *testing.T) {
+		output := &wrapperspb.BytesValue{}
+		require.NoError(t, p.Exec(t.Context(), "secret", wrapperspb.String("token"), output))
+		assert.EqualValues(t, []byte("hunter2"), output.GetValue())
+
+		require.NoError(t, p.Exec(t.Context(), "secret", wrapperspb.String("key"), output))
+		assert.EqualValues(t, []byte{0x00, 0x01}, output.GetValue())
+	})
+
+	t.Run("missing secrets are not found", func(t *testing.T) {
+		assert.Error(t, p.Exec(t.Context(), "secret", wrapperspb.String("missing"), &wrapperspb.BytesValue{}))
+	})
+
+	t.Run("lists secret names", func(t *testing.T) {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "names", &emptypb.Empty{}, output))
+		assert.EqualValues(t, "key,token", output.GetValue())
+	})
+}
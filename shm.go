@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"syscall"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+	"github.com/davidsbond/plugin/internal/shm"
+)
+
+type (
+	// The SharedMemory type is a region of memory that can be shared with plugins using WithSharedMemory, so that large
+	// payloads can be provided to commands without being copied into gRPC messages. Only a reference to the memory
+	// is sent to the plugin, which maps the same memory into its own process. Writes made by the command are visible
+	// to the host application once Plugin.Exec returns, allowing commands to return their results in the same way.
+	SharedMemory struct {
+		memory *shm.Memory
+	}
+)
+
+var (
+	// ErrSharedMemoryUnsupported is the error returned when shared memory is used on a platform other than Linux, or
+	// with a plugin whose Config does not enable SharedMemory.
+	ErrSharedMemoryUnsupported = shm.ErrUnsupported
+)
+
+// NewSharedMemory returns a new region of shared memory of the given size in bytes, whose contents are initially
+// zero. The memory should be closed once it is no longer needed. Returns ErrSharedMemoryUnsupported on platforms
+// other than Linux.
+func NewSharedMemory(size int) (*SharedMemory, error) {
+	memory, err := shm.New(size)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SharedMemory{memory: memory}, nil
+}
+
+// Bytes returns the contents of the shared memory. The returned slice must not be used once the memory is closed.
+func (m *SharedMemory) Bytes() []byte {
+	return m.memory.Bytes()
+}
+
+// Len returns the size of the shared memory in bytes.
+func (m *SharedMemory) Len() int {
+	return m.memory.Len()
+}
+
+// Close the shared memory, unmapping it from the current process.
+func (m *SharedMemory) Close() error {
+	return m.memory.Close()
+}
+
+// SharedMemoryFromContext returns the shared memory provided by the host application via WithSharedMemory with the
+// command being handled, in the order it was given. It should be called by command handlers using the context they
+// are provided. The memory is closed once the command returns, so it must not be used afterwards. Returns nil if no
+// memory was shared.
+func SharedMemoryFromContext(ctx context.Context) []*SharedMemory {
+	var memory []*SharedMemory
+	for _, m := range plugin.SharedMemoryFromContext(ctx) {
+		memory = append(memory, &SharedMemory{memory: m})
+	}
+
+	return memory
+}
+
+// executeProcess executes the named command using the process, sharing any memory with it for the duration of the
+// call.
+func (p *Plugin) executeProcess(ctx context.Context, proc *process, name string, input, output proto.Message, options plugin.ExecuteOptions, memory []*SharedMemory) (plugin.Payload, error) {
+	if len(memory) == 0 {
+		return proc.client.Execute(ctx, name, input, output, options)
+	}
+
+	regions := make([]*shm.Memory, len(memory))
+	for i, m := range memory {
+		regions[i] = m.memory
+	}
+
+	ids, closer, err := shm.Send(ctx, proc.memory, proc.token, regions)
+	switch {
+	case errors.Is(err, fs.ErrNotExist), errors.Is(err, syscall.ECONNREFUSED):
+		return plugin.Payload{}, fmt.Errorf("%w: plugin %q does not accept shared memory", ErrSharedMemoryUnsupported, p.name)
+	case err != nil:
+		return plugin.Payload{}, err
+	}
+
+	defer closer.Close()
+	return proc.client.Execute(plugin.AppendSharedMemory(ctx, ids), name, input, output, options)
+}
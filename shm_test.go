@@ -0,0 +1,82 @@
+//go:build linux
+
+package plugin_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestPlugin_Exec_SharedMemory(t *testing.T) {
+	t.Parallel()
+
+	upper := &plugin.Command[*emptypb.Empty, *wrapperspb.Int64Value]{
+		Use: "upper",
+		Run: func(ctx context.Context, _ *emptypb.Empty) (*wrapperspb.Int64Value, error) {
+			var total int64
+			for _, memory := range plugin.SharedMemoryFromContext(ctx) {
+				copy(memory.Bytes(), bytes.ToUpper(memory.Bytes()))
+				total += int64(memory.Len())
+			}
+
+			return wrapperspb.Int64(total), nil
+		},
+	}
+
+	newMemory := func(t *testing.T, contents string) *plugin.SharedMemory {
+		memory, err := plugin.NewSharedMemory(len(contents))
+		require.NoError(t, err)
+		t.Cleanup(func() { assert.NoError(t, memory.Close()) })
+
+		copy(memory.Bytes(), contents)
+		return memory
+	}
+
+	t.Run("commands modify shared memory in place", func(t *testing.T) {
+		p := plugintest.New(t, plugin.Config{
+			Name:         "shm",
+			Commands:     []plugin.CommandHandler{upper},
+			SharedMemory: true,
+		})
+
+		first := newMemory(t, "hello")
+		second := newMemory(t, "world")
+
+		output := &wrapperspb.Int64Value{}
+		require.NoError(t, p.Exec(t.Context(), "upper", &emptypb.Empty{}, output, plugin.WithSharedMemory(first, second)))
+		assert.EqualValues(t, 10, output.GetValue())
+		assert.Equal(t, "HELLO", string(first.Bytes()))
+		assert.Equal(t, "WORLD", string(second.Bytes()))
+	})
+
+	t.Run("commands without shared memory receive none", func(t *testing.T) {
+		p := plugintest.New(t, plugin.Config{
+			Name:         "shm",
+			Commands:     []plugin.CommandHandler{upper},
+			SharedMemory: true,
+		})
+
+		output := &wrapperspb.Int64Value{}
+		require.NoError(t, p.Exec(t.Context(), "upper", &emptypb.Empty{}, output))
+		assert.Zero(t, output.GetValue())
+	})
+
+	t.Run("error if the plugin does not accept shared memory", func(t *testing.T) {
+		p := plugintest.New(t, plugin.Config{
+			Name:     "shm",
+			Commands: []plugin.CommandHandler{upper},
+		})
+
+		err := p.Exec(t.Context(), "upper", &emptypb.Empty{}, &wrapperspb.Int64Value{}, plugin.WithSharedMemory(newMemory(t, "hello")))
+		assert.ErrorIs(t, err, plugin.ErrSharedMemoryUnsupported)
+	})
+}
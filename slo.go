@@ -0,0 +1,145 @@
+package plugin
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+type (
+	// The SLO type describes the service level objectives of a plugin. When provided to Use via the WithSLO option,
+	// every call to Plugin.Exec is classified as good or bad and used to compute how quickly the plugin is consuming
+	// its error budget.
+	SLO struct {
+		// The Objective is the target proportion of calls that should be good, between zero and one. For example, 0.999
+		// allows one in every thousand calls to be bad.
+		Objective float64
+		// The Latency objective. If greater than zero, calls that take longer than this are considered bad even when
+		// they succeed.
+		Latency time.Duration
+		// The Window over which the burn rate is calculated. Defaults to one hour.
+		Window time.Duration
+		// OnBudgetExhausted, if set, is called with the name of the plugin each time its error budget becomes
+		// exhausted. It is not called again until the budget has recovered and is exhausted once more.
+		OnBudgetExhausted func(name string, status SLOStatus)
+	}
+
+	// The SLOStatus type describes the state of a plugin's error budget within the current SLO window.
+	SLOStatus struct {
+		// The total number of calls made within the window.
+		Total int64
+		// The number of bad calls made within the window.
+		Bad int64
+		// The BurnRate is the rate at which the error budget is being consumed, relative to the objective. A burn rate
+		// of one means the budget will be exactly consumed by the end of the window.
+		BurnRate float64
+		// The proportion of the error budget remaining, between zero and one.
+		BudgetRemaining float64
+		// Exhausted is true when the error budget for the window has been consumed.
+		Exhausted bool
+	}
+
+	sloTracker struct {
+		mu        sync.Mutex
+		slo       SLO
+		clock     Clock
+		buckets   []sloBucket
+		exhausted bool
+	}
+
+	sloBucket struct {
+		start time.Time
+		total int64
+		bad   int64
+	}
+)
+
+const (
+	defaultSLOWindow = time.Hour
+	sloBuckets       = 60
+)
+
+func newSLOTracker(slo SLO, clock Clock) *sloTracker {
+	if slo.Window <= 0 {
+		slo.Window = defaultSLOWindow
+	}
+
+	return &sloTracker{
+		slo:   slo,
+		clock: clock,
+	}
+}
+
+func (t *sloTracker) record(name string, duration time.Duration, err error) {
+	bad := err != nil || (t.slo.Latency > 0 && duration > t.slo.Latency)
+
+	t.mu.Lock()
+	now := t.clock.Now()
+	t.expire(now)
+
+	start := now.Truncate(t.slo.Window / sloBuckets)
+	if len(t.buckets) == 0 || !t.buckets[len(t.buckets)-1].start.Equal(start) {
+		t.buckets = append(t.buckets, sloBucket{start: start})
+	}
+
+	bucket := &t.buckets[len(t.buckets)-1]
+	bucket.total++
+	if bad {
+		bucket.bad++
+	}
+
+	status := t.status()
+	notify := status.Exhausted && !t.exhausted
+	t.exhausted = status.Exhausted
+	t.mu.Unlock()
+
+	if notify && t.slo.OnBudgetExhausted != nil {
+		t.slo.OnBudgetExhausted(name, status)
+	}
+}
+
+func (t *sloTracker) snapshot() SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(t.clock.Now())
+	return t.status()
+}
+
+func (t *sloTracker) expire(now time.Time) {
+	cutoff := now.Add(-t.slo.Window)
+
+	i := 0
+	for i < len(t.buckets) && !t.buckets[i].start.After(cutoff) {
+		i++
+	}
+
+	t.buckets = t.buckets[i:]
+}
+
+func (t *sloTracker) status() SLOStatus {
+	var status SLOStatus
+	for _, bucket := range t.buckets {
+		status.Total += bucket.total
+		status.Bad += bucket.bad
+	}
+
+	status.BudgetRemaining = 1
+	if status.Total == 0 {
+		return status
+	}
+
+	allowed := 1 - t.slo.Objective
+	switch {
+	case status.Bad == 0:
+		status.BurnRate = 0
+	case allowed <= 0:
+		status.BurnRate = math.Inf(1)
+	default:
+		status.BurnRate = (float64(status.Bad) / float64(status.Total)) / allowed
+	}
+
+	status.BudgetRemaining = max(0, 1-status.BurnRate)
+	status.Exhausted = status.BurnRate >= 1
+	return status
+}
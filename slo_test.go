@@ -0,0 +1,68 @@
+package plugin_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestUse_WithSLO(t *testing.T) {
+	var exhausted []plugin.SLOStatus
+
+	clock := NewManualClock()
+	p := useWithClock(t, clock, plugin.WithSLO(plugin.SLO{
+		Objective: 0.5,
+		Window:    time.Minute,
+		OnBudgetExhausted: func(name string, status plugin.SLOStatus) {
+			assert.EqualValues(t, "test_plugin", name)
+			exhausted = append(exhausted, status)
+		},
+	}))
+
+	output := &wrapperspb.StringValue{}
+
+	t.Run("budget is full after successful call", func(t *testing.T) {
+		require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+
+		status := p.Stats().SLO
+		require.NotNil(t, status)
+		assert.EqualValues(t, 1, status.Total)
+		assert.EqualValues(t, 0, status.Bad)
+		assert.EqualValues(t, 0, status.BurnRate)
+		assert.EqualValues(t, 1, status.BudgetRemaining)
+		assert.False(t, status.Exhausted)
+		assert.Empty(t, exhausted)
+	})
+
+	t.Run("budget is exhausted after failed calls", func(t *testing.T) {
+		require.Error(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("pung"), output))
+		require.Error(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("pung"), output))
+
+		status := p.Stats().SLO
+		require.NotNil(t, status)
+		assert.EqualValues(t, 3, status.Total)
+		assert.EqualValues(t, 2, status.Bad)
+		assert.InDelta(t, 4.0/3.0, status.BurnRate, 0.0001)
+		assert.EqualValues(t, 0, status.BudgetRemaining)
+		assert.True(t, status.Exhausted)
+
+		if assert.Len(t, exhausted, 1) {
+			assert.EqualValues(t, 2, exhausted[0].Total)
+		}
+	})
+
+	t.Run("budget recovers after window", func(t *testing.T) {
+		clock.Advance(2 * time.Minute)
+
+		status := p.Stats().SLO
+		require.NotNil(t, status)
+		assert.EqualValues(t, 0, status.Total)
+		assert.EqualValues(t, 1, status.BudgetRemaining)
+		assert.False(t, status.Exhausted)
+	})
+}
@@ -0,0 +1,88 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+type (
+	// The StartError type is the error returned by Use when a plugin process exits before it has finished starting,
+	// for example due to invalid arguments or a panic during initialisation. It matches ErrPluginStartFailed when
+	// using errors.Is.
+	StartError struct {
+		// The Path of the plugin binary.
+		Path string
+		// The ExitStatus of the plugin process.
+		Status ExitStatus
+		// The final output written by the plugin process to stderr, limited to the last few kilobytes.
+		Stderr string
+	}
+
+	// tailBuffer is an io.Writer that retains only the most recent bytes written to it.
+	tailBuffer struct {
+		mu   sync.Mutex
+		size int
+		data []byte
+	}
+)
+
+// ErrPluginStartFailed is the error given when a plugin process exits before it has finished starting. The error is
+// always a *StartError that can be obtained using errors.As.
+var ErrPluginStartFailed = errors.New("plugin start failed")
+
+// stderrTailSize is the number of bytes of stderr output retained for each plugin process.
+const stderrTailSize = 4096
+
+// Error returns a description of how the plugin process exited, including its final stderr output.
+func (e *StartError) Error() string {
+	message := fmt.Sprintf("%s: plugin at %q %s", ErrPluginStartFailed, e.Path, e.Status)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		message += ": " + stderr
+	}
+
+	return message
+}
+
+// Unwrap returns ErrPluginStartFailed.
+func (e *StartError) Unwrap() error {
+	return ErrPluginStartFailed
+}
+
+func newTailBuffer(size int) *tailBuffer {
+	return &tailBuffer{size: size}
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if len(b.data) > b.size {
+		b.data = b.data[len(b.data)-b.size:]
+	}
+
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return string(b.data)
+}
+
+// startError returns a *StartError if the process has exited, otherwise it returns nil.
+func (p *process) startError() error {
+	select {
+	case <-p.exited:
+		return &StartError{
+			Path:   p.path,
+			Status: p.status,
+			Stderr: p.stderr.String(),
+		}
+	default:
+		return nil
+	}
+}
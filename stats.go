@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The Stats type contains statistics describing how a Plugin has been used by the host application.
+	Stats struct {
+		// Statistics for each command that has been executed, keyed by command name.
+		Commands map[string]CommandStats
+		// The state of the plugin's error budget. This is nil unless an SLO was provided using WithSLO.
+		SLO *SLOStatus
+		// The resources used by the running plugin processes. This is nil unless a Cgroup was provided using
+		// WithCgroup.
+		Cgroup *CgroupStats
+	}
+
+	// The CommandStats type contains statistics describing the payloads sent to and received from a single command.
+	CommandStats struct {
+		// The number of requests sent to the command.
+		Calls int64
+		// The total size, in bytes, of all requests sent to the command.
+		RequestBytes int64
+		// The total size, in bytes, of all responses received from the command.
+		ResponseBytes int64
+		// The size, in bytes, of the largest request sent to the command.
+		MaxRequestBytes int
+		// The size, in bytes, of the largest response received from the command.
+		MaxResponseBytes int
+	}
+
+	// The CommandCallStats type contains statistics describing the calls made to a single command, as recorded by
+	// the plugin itself.
+	CommandCallStats struct {
+		// The number of times the command has been called.
+		Calls int64
+		// The number of calls that returned an error, including those that were cancelled or exceeded their deadline.
+		Errors int64
+		// Estimates of the latency within which 50%, 90% and 99% of calls completed.
+		P50, P90, P99 time.Duration
+		// The latency of the slowest call.
+		MaxLatency time.Duration
+	}
+
+	stats struct {
+		mu       sync.Mutex
+		commands map[string]CommandStats
+	}
+)
+
+func (s *stats) record(command string, payload plugin.Payload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.commands == nil {
+		s.commands = make(map[string]CommandStats)
+	}
+
+	cs := s.commands[command]
+	cs.Calls++
+	cs.RequestBytes += int64(payload.RequestSize)
+	cs.ResponseBytes += int64(payload.ResponseSize)
+	cs.MaxRequestBytes = max(cs.MaxRequestBytes, payload.RequestSize)
+	cs.MaxResponseBytes = max(cs.MaxResponseBytes, payload.ResponseSize)
+	s.commands[command] = cs
+}
+
+func (s *stats) snapshot() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return Stats{
+		Commands: maps.Clone(s.commands),
+	}
+}
+
+// Stats returns a snapshot of the statistics recorded for the Plugin since it was started.
+func (p *Plugin) Stats() Stats {
+	stats := p.stats.snapshot()
+	if p.slo != nil {
+		status := p.slo.snapshot()
+		stats.SLO = &status
+	}
+
+	if p.options.cgroup != nil {
+		usage := p.cgroupStats()
+		stats.Cgroup = &usage
+	}
+
+	return stats
+}
+
+// CommandStats queries each running plugin process for statistics describing the calls made to its commands since it
+// started, keyed by command name. Unlike Stats, these are recorded by the plugin, so latencies exclude the time spent
+// sending requests and responses and include calls made via Plugin.Submit. When the plugin is running more than one
+// instance, as set using WithInstances, the statistics of every instance are combined. Commands that have not been
+// called are omitted, as are all commands of plugins that predate these statistics.
+func (p *Plugin) CommandStats(ctx context.Context) (map[string]CommandCallStats, error) {
+	release := p.hold()
+	defer release()
+
+	pl, err := p.start(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	combined := make(map[string]plugin.CallStats)
+	for _, proc := range pl.processes {
+		calls, err := proc.client.CallStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, cs := range calls {
+			combined[cs.Name] = combined[cs.Name].Add(cs)
+		}
+	}
+
+	commands := make(map[string]CommandCallStats, len(combined))
+	for name, cs := range combined {
+		commands[name] = CommandCallStats{
+			Calls:      cs.Calls,
+			Errors:     cs.Errors,
+			P50:        cs.Percentile(0.5),
+			P90:        cs.Percentile(0.9),
+			P99:        cs.Percentile(0.99),
+			MaxLatency: cs.MaxLatency,
+		}
+	}
+
+	return commands, nil
+}
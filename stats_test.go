@@ -0,0 +1,61 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestPlugin_CommandStats(t *testing.T) {
+	t.Parallel()
+
+	p := plugintest.New(t, plugin.Config{
+		Name: "stats",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "echo",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					switch input.GetValue() {
+					case "fail":
+						return nil, errors.New("failed")
+					case "slow":
+						time.Sleep(20 * time.Millisecond)
+					}
+
+					return input, nil
+				},
+			},
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "unused",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					return input, nil
+				},
+			},
+		},
+	}, plugin.WithInstances(2))
+
+	for _, input := range []string{"fast", "fast", "slow", "fail"} {
+		_ = p.Exec(t.Context(), "echo", wrapperspb.String(input), &wrapperspb.StringValue{})
+	}
+
+	stats, err := p.CommandStats(t.Context())
+	require.NoError(t, err)
+	require.Contains(t, stats, "echo")
+	assert.NotContains(t, stats, "unused")
+
+	echo := stats["echo"]
+	assert.EqualValues(t, 4, echo.Calls)
+	assert.EqualValues(t, 1, echo.Errors)
+	assert.GreaterOrEqual(t, echo.MaxLatency, 20*time.Millisecond)
+	assert.LessOrEqual(t, echo.P50, echo.P90)
+	assert.LessOrEqual(t, echo.P90, echo.P99)
+	assert.LessOrEqual(t, echo.P99, echo.MaxLatency)
+}
@@ -0,0 +1,46 @@
+//go:build !windows
+
+package plugin
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// shutdownSignals are the signals that cause Run to gracefully stop the plugin.
+var shutdownSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+
+// prepareTermination configures the command so that the started process can be terminated using terminate.
+func prepareTermination(*exec.Cmd) {}
+
+// terminate asks the process to exit by sending it a SIGTERM signal.
+func terminate(process *os.Process) error {
+	return process.Signal(syscall.SIGTERM)
+}
+
+// findProcess returns the running process with the given process identifier.
+func findProcess(pid int) (*os.Process, error) {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Finding a process always succeeds, so a signal is sent to check that it exists.
+	if err = process.Signal(syscall.Signal(0)); errors.Is(err, os.ErrProcessDone) {
+		return nil, err
+	}
+
+	return process, nil
+}
+
+// waitAttached blocks until the process, which was not started by the host application, has exited. As it cannot be
+// waited on, it is polled until it no longer exists and its exit code is unknown.
+func waitAttached(process *os.Process, clock Clock) ExitStatus {
+	for !errors.Is(process.Signal(syscall.Signal(0)), os.ErrProcessDone) {
+		<-clock.After(exitPollInterval)
+	}
+
+	return ExitStatus{PID: process.Pid, Code: -1, Time: clock.Now()}
+}
@@ -0,0 +1,59 @@
+//go:build windows
+
+package plugin
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/windows"
+)
+
+// shutdownSignals are the signals that cause Run to gracefully stop the plugin. The Go runtime delivers console
+// CTRL_C_EVENT and CTRL_BREAK_EVENT events as os.Interrupt, and CTRL_CLOSE_EVENT, CTRL_LOGOFF_EVENT and
+// CTRL_SHUTDOWN_EVENT events as syscall.SIGTERM.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// prepareTermination starts the process within a process group of its own, so that console control events sent by
+// terminate reach only the plugin and not the host application.
+func prepareTermination(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	cmd.SysProcAttr.CreationFlags |= windows.CREATE_NEW_PROCESS_GROUP
+}
+
+// terminate asks the process to exit by sending a CTRL_BREAK_EVENT to its process group. Console control events
+// cannot be sent when the host application has no console, such as when it runs as a service, so the process is
+// terminated immediately instead.
+func terminate(process *os.Process) error {
+	if err := windows.GenerateConsoleCtrlEvent(windows.CTRL_BREAK_EVENT, uint32(process.Pid)); err != nil {
+		return process.Kill()
+	}
+
+	return nil
+}
+
+// findProcess returns the running process with the given process identifier.
+func findProcess(pid int) (*os.Process, error) {
+	process, err := os.FindProcess(pid)
+	if errors.Is(err, windows.ERROR_INVALID_PARAMETER) {
+		return nil, os.ErrProcessDone
+	}
+
+	return process, err
+}
+
+// waitAttached blocks until the process, which was not started by the host application, has exited. Unlike other
+// platforms, any process can be waited on by a process with a handle to it.
+func waitAttached(process *os.Process, clock Clock) ExitStatus {
+	state, err := process.Wait()
+	if err != nil {
+		return ExitStatus{PID: process.Pid, Code: -1, Time: clock.Now()}
+	}
+
+	return newExitStatus(state, clock.Now())
+}
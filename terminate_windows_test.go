@@ -0,0 +1,32 @@
+//go:build windows
+
+package plugin_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestPlugin_Close_Windows(t *testing.T) {
+	t.Parallel()
+
+	p, err := plugin.Use(t.Context(), "./test_plugin", plugin.WithShutdownGracePeriod(5*time.Second))
+	require.NoError(t, err)
+
+	output := &wrapperspb.StringValue{}
+	require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+	assert.EqualValues(t, "pong", output.GetValue())
+
+	// The plugin exits in response to the console control event, or is terminated if it could not be sent, rather
+	// than waiting out the grace period.
+	start := time.Now()
+	require.NoError(t, p.Close())
+	assert.Less(t, time.Since(start), 5*time.Second)
+	require.Len(t, p.Exits(), 1)
+}
@@ -0,0 +1,233 @@
+// Command legacy_plugin imitates a plugin built using github.com/hashicorp/go-plugin, performing its handshake and
+// serving its controller and broker services over gRPC. It is used to test the goplugin package.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+type (
+	// LegacyPlugin serves the health service, forwarding checks of the "host" service to the host application via the
+	// broker.
+	LegacyPlugin struct {
+		grpc_health_v1.UnimplementedHealthServer
+
+		server *grpc.Server
+		dir    string
+		mu     sync.Mutex
+		conns  map[uint32]chan string
+	}
+
+	// frame contains an encoded message of one of go-plugin's own services.
+	frame struct {
+		data []byte
+	}
+
+	// codec encodes frames as-is, and other messages using the protobuf binary format.
+	codec struct{}
+)
+
+const (
+	cookieKey   = "LEGACY_PLUGIN"
+	cookieValue = "hello"
+	version     = "2"
+)
+
+func (codec) Name() string {
+	return "proto"
+}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	if f, ok := v.(*frame); ok {
+		return f.data, nil
+	}
+
+	return proto.Marshal(v.(proto.Message))
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	if f, ok := v.(*frame); ok {
+		f.data = append([]byte(nil), data...)
+		return nil
+	}
+
+	return proto.Unmarshal(data, v.(proto.Message))
+}
+
+func (lp *LegacyPlugin) Run() error {
+	if os.Getenv(cookieKey) != cookieValue {
+		return fmt.Errorf("this binary is a plugin and is not meant to be executed directly")
+	}
+
+	if !slices.Contains(strings.Split(os.Getenv("PLUGIN_PROTOCOL_VERSIONS"), ","), version) {
+		return fmt.Errorf("protocol version %s is not supported by the host", version)
+	}
+
+	dir, err := os.MkdirTemp("", "legacy-plugin-")
+	if err != nil {
+		return err
+	}
+
+	defer os.RemoveAll(dir)
+	lp.dir = dir
+
+	socket := filepath.Join(dir, "plugin.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+
+	lp.server = grpc.NewServer(grpc.ForceServerCodec(codec{}))
+	grpc_health_v1.RegisterHealthServer(lp.server, lp)
+	lp.server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "plugin.GRPCController",
+		Methods: []grpc.MethodDesc{
+			{MethodName: "Shutdown", Handler: lp.Shutdown},
+		},
+	}, nil)
+	lp.server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "plugin.GRPCBroker",
+		Streams: []grpc.StreamDesc{
+			{StreamName: "StartStream", Handler: lp.StartStream, ServerStreams: true, ClientStreams: true},
+		},
+	}, nil)
+
+	protocol := os.Getenv("LEGACY_PLUGIN_PROTOCOL")
+	if protocol == "" {
+		protocol = "grpc"
+	}
+
+	fmt.Printf("1|%s|unix|%s|%s\n", version, socket, protocol)
+	fmt.Println("output after the handshake is ignored")
+	fmt.Fprintln(os.Stderr, "plugin started")
+
+	return lp.server.Serve(listener)
+}
+
+// Check reports the plugin as serving, or reports the status of the host application when checking the "host" service.
+func (lp *LegacyPlugin) Check(ctx context.Context, req *grpc_health_v1.HealthCheckRequest) (*grpc_health_v1.HealthCheckResponse, error) {
+	if req.GetService() != "host" {
+		return &grpc_health_v1.HealthCheckResponse{Status: grpc_health_v1.HealthCheckResponse_SERVING}, nil
+	}
+
+	var address string
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case address = <-lp.conn(1):
+	}
+
+	conn, err := grpc.NewClient("unix://"+address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Close()
+	return grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+}
+
+// Shutdown stops the plugin once the response has been sent.
+func (lp *LegacyPlugin) Shutdown(_ any, ctx context.Context, decode func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+	if err := decode(&frame{}); err != nil {
+		return nil, err
+	}
+
+	go lp.server.Stop()
+	return &frame{}, nil
+}
+
+// StartStream serves a health service under id 2, and records the addresses of services served by the host.
+func (lp *LegacyPlugin) StartStream(_ any, stream grpc.ServerStream) error {
+	socket := filepath.Join(lp.dir, "brokered.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+
+	brokered := grpc.NewServer()
+	defer brokered.Stop()
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("brokered", grpc_health_v1.HealthCheckResponse_SERVING)
+	grpc_health_v1.RegisterHealthServer(brokered, healthServer)
+	go brokered.Serve(listener)
+
+	var info []byte
+	info = protowire.AppendTag(info, 1, protowire.VarintType)
+	info = protowire.AppendVarint(info, 2)
+	info = protowire.AppendTag(info, 2, protowire.BytesType)
+	info = protowire.AppendString(info, "unix")
+	info = protowire.AppendTag(info, 3, protowire.BytesType)
+	info = protowire.AppendString(info, socket)
+	if err = stream.SendMsg(&frame{data: info}); err != nil {
+		return err
+	}
+
+	for {
+		f := &frame{}
+		if err = stream.RecvMsg(f); err != nil {
+			return err
+		}
+
+		var (
+			id      uint32
+			address string
+		)
+
+		for data := f.data; len(data) > 0; {
+			number, typ, n := protowire.ConsumeTag(data)
+			data = data[n:]
+
+			switch number {
+			case 1:
+				var value uint64
+				value, n = protowire.ConsumeVarint(data)
+				id = uint32(value)
+			case 3:
+				address, n = protowire.ConsumeString(data)
+			default:
+				n = protowire.ConsumeFieldValue(number, typ, data)
+			}
+
+			data = data[n:]
+		}
+
+		lp.conn(id) <- address
+	}
+}
+
+func (lp *LegacyPlugin) conn(id uint32) chan string {
+	lp.mu.Lock()
+	defer lp.mu.Unlock()
+
+	if lp.conns == nil {
+		lp.conns = make(map[uint32]chan string)
+	}
+
+	if _, ok := lp.conns[id]; !ok {
+		lp.conns[id] = make(chan string, 1)
+	}
+
+	return lp.conns[id]
+}
+
+func main() {
+	if err := (&LegacyPlugin{}).Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
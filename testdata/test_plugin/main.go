@@ -3,23 +3,74 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
 
+	"github.com/spf13/pflag"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/davidsbond/plugin"
 )
 
 type (
-	PingPongPlugin struct{}
+	PingPongPlugin struct {
+		pong string
+	}
 )
 
 func (tp *PingPongPlugin) Run() {
+	// Tests override the version to start what appear to be other versions of the plugin.
+	version := os.Getenv("TEST_PLUGIN_VERSION")
+	if version == "" {
+		version = "v1.2.3"
+	}
+
 	plugin.Run(plugin.Config{
-		Name: "test_plugin",
+		Name:       filepath.Base(os.Args[0]),
+		Version:    version,
+		SocketMode: 0o600,
+		SocketOwner: &plugin.SocketOwner{
+			UID: os.Getuid(),
+			GID: os.Getgid(),
+		},
+		VerifyPeer: runtime.GOOS == "linux",
+		Events:     tp.Tick,
+		Flags: func(flags *pflag.FlagSet) {
+			flags.StringVar(&tp.pong, "pong", "pong", "The response to ping")
+		},
 		Commands: []plugin.CommandHandler{
 			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
-				Use: "pingpong",
-				Run: tp.PingPong,
+				Use:         "pingpong",
+				Description: "Responds to ping with pong, and to pong with ping.",
+				Example:     `"ping"`,
+				Run:         tp.PingPong,
+			},
+			&plugin.Command[*durationpb.Duration, *durationpb.Duration]{
+				Use: "sleep",
+				Run: tp.Sleep,
+			},
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{
+				Use:        "hang",
+				Deprecated: true,
+				Run:        tp.Hang,
+			},
+			&plugin.Command[*emptypb.Empty, *emptypb.Empty]{
+				Use: "crash",
+				Run: tp.Crash,
+			},
+			&plugin.AsyncCommand[*durationpb.Duration, *wrapperspb.StringValue]{
+				Use: "countdown",
+				Run: tp.Countdown,
+			},
+			&plugin.Command[*emptypb.Empty, *wrapperspb.Int64Value]{
+				Use: "pid",
+				Run: tp.PID,
 			},
 		},
 	})
@@ -27,14 +78,84 @@ func (tp *PingPongPlugin) Run() {
 
 func (tp *PingPongPlugin) PingPong(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
 	if input.GetValue() == "ping" {
-		return &wrapperspb.StringValue{Value: "pong"}, nil
+		return &wrapperspb.StringValue{Value: tp.pong}, nil
 	}
 
 	if input.GetValue() == "pong" {
 		return &wrapperspb.StringValue{Value: "ping"}, nil
 	}
 
-	return nil, fmt.Errorf(`invalid input %q, expected "ping" or "pong"`, input.Value)
+	err := plugin.NewError(fmt.Sprintf(`invalid input %q, expected "ping" or "pong"`, input.GetValue()), &errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{
+				Field:       "value",
+				Description: `must be "ping" or "pong"`,
+			},
+		},
+	})
+
+	err.Code = codes.InvalidArgument
+	return nil, err
+}
+
+func (tp *PingPongPlugin) Sleep(ctx context.Context, input *durationpb.Duration) (*durationpb.Duration, error) {
+	start := time.Now()
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(input.AsDuration()):
+		return durationpb.New(time.Since(start)), nil
+	}
+}
+
+// PID returns the process identifier of the plugin instance handling the call.
+func (tp *PingPongPlugin) PID(context.Context, *emptypb.Empty) (*wrapperspb.Int64Value, error) {
+	return wrapperspb.Int64(int64(os.Getpid())), nil
+}
+
+// Hang never returns, ignoring cancellation of its context.
+func (tp *PingPongPlugin) Hang(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	select {}
+}
+
+// Crash exits the plugin process without returning.
+func (tp *PingPongPlugin) Crash(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	fmt.Fprintln(os.Stderr, "crashing")
+	os.Exit(2)
+	return nil, nil
+}
+
+// Countdown waits for the input duration in ten steps, reporting progress after each one.
+func (tp *PingPongPlugin) Countdown(ctx context.Context, input *durationpb.Duration, progress plugin.ProgressFunc) (*wrapperspb.StringValue, error) {
+	const steps = 10
+
+	for i := range steps {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(input.AsDuration() / steps):
+			progress(float64(i+1)/steps, fmt.Sprintf("%d remaining", steps-i-1))
+		}
+	}
+
+	return wrapperspb.String("liftoff"), nil
+}
+
+func (tp *PingPongPlugin) Tick(ctx context.Context, publish plugin.PublishFunc) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for count := int64(1); ; count++ {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := publish(ctx, wrapperspb.Int64(count)); err != nil {
+				return err
+			}
+		}
+	}
 }
 
 func main() {
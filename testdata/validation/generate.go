@@ -0,0 +1,5 @@
+// Package validation contains messages constrained using protovalidate, which are used to test the validation of
+// command inputs.
+package validation
+
+//go:generate go tool buf generate
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package plugin
+
+import (
+	"os"
+)
+
+// fileModeSupported is false on platforms without UNIX file permissions.
+const fileModeSupported = false
+
+// restrictUmask is a no-op on platforms without a umask, where socket permissions are not applied.
+func restrictUmask(os.FileMode) func() {
+	return func() {}
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package plugin
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileModeSupported is true on platforms where the permissions and owner of a socket can be set.
+const fileModeSupported = true
+
+// restrictUmask sets the process umask so that newly created files are given no more than the provided permissions.
+// The returned function restores the previous umask.
+func restrictUmask(mode os.FileMode) func() {
+	umask := syscall.Umask(int(^mode & os.ModePerm))
+	return func() {
+		syscall.Umask(umask)
+	}
+}
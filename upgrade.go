@@ -0,0 +1,202 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Masterminds/semver/v3"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+type (
+	// The Source interface describes types that obtain a newer version of a plugin binary for Plugin.Upgrade, such as
+	// by downloading it. Sources are responsible for the integrity of the binaries they return, checksums provided to
+	// Use via WithChecksum or WithChecksumFile only apply to the original binary. Verifiers provided via WithVerifier
+	// are used for every binary.
+	Source interface {
+		// Fetch the plugin binary, returning its path.
+		Fetch(ctx context.Context) (string, error)
+	}
+
+	// The SourceFunc type is an adapter that allows ordinary functions to be used as a Source.
+	SourceFunc func(ctx context.Context) (string, error)
+
+	// The upgrader interface describes a Client that can be upgraded by Manager.Upgrade, such as a *Plugin.
+	upgrader interface {
+		Upgrade(ctx context.Context, source Source) error
+		Rollback(ctx context.Context) error
+	}
+
+	// The binary type describes a plugin binary that processes are started from, which changes as the plugin is
+	// upgraded and rolled back.
+	binary struct {
+		path     string
+		upgraded bool
+		previous *binary
+	}
+)
+
+var (
+	// ErrUpgradeUnsupported is the error returned by Plugin.Upgrade and Plugin.Rollback for plugins that are not
+	// executed from a binary, such as those registered using Register or connected to using Connect. It is also
+	// returned by Manager.Upgrade and Manager.Rollback when the named plugin is a Client that cannot be upgraded.
+	ErrUpgradeUnsupported = errors.New("upgrade unsupported")
+
+	// ErrIncompatibleUpgrade is the error returned by Plugin.Upgrade when the new version of a plugin is not newer
+	// than the running version, or no longer provides one of its commands using the same input and output messages.
+	ErrIncompatibleUpgrade = errors.New("incompatible upgrade")
+
+	// ErrNoRollback is the error returned by Plugin.Rollback when the plugin has not been upgraded.
+	ErrNoRollback = errors.New("no previous version")
+)
+
+// Fetch calls f(ctx).
+func (f SourceFunc) Fetch(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// Upgrade the plugin to the binary obtained from the source, replacing the running processes as described by
+// Plugin.Reload. The new processes must report a semantic version greater than that of the running processes, and
+// provide every command the running processes do with the same input and output messages, otherwise
+// ErrIncompatibleUpgrade is returned. Any version constraint given via WithVersionConstraint must also be satisfied.
+// Plugins started lazily are started first, so that their version is known.
+//
+// If the new processes fail to start or are incompatible, they are closed and calls continue to be handled by the
+// running processes, so a failed upgrade leaves the plugin as it was. Once upgraded, the previous binary can be
+// restored using Plugin.Rollback. The new binary is used whenever the plugin is started again, such as after a crash
+// or when stopped due to inactivity.
+func (p *Plugin) Upgrade(ctx context.Context, source Source) error {
+	if p.config != nil || p.address != "" {
+		return fmt.Errorf("%w: %q", ErrUpgradeUnsupported, p.name)
+	}
+
+	p.mu.RLock()
+	started := p.info.Version != ""
+	p.mu.RUnlock()
+
+	if !started {
+		if err := p.Start(ctx); err != nil {
+			return err
+		}
+	}
+
+	path, err := source.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch upgrade for plugin %q: %w", p.name, err)
+	}
+
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+
+	next := &binary{path: path, upgraded: true, previous: p.current()}
+	return p.reload(ctx, next, func(current, next plugin.Info) error {
+		return checkUpgrade(p.name, current, next)
+	})
+}
+
+// Rollback the plugin to the binary it used before it was last upgraded using Plugin.Upgrade, replacing the running
+// processes as described by Plugin.Reload. Rolling back many times restores each previous binary in turn. Returns
+// ErrNoRollback if the plugin has not been upgraded. If the previous binary fails to start, calls continue to be
+// handled by the running processes.
+func (p *Plugin) Rollback(ctx context.Context) error {
+	if p.config != nil || p.address != "" {
+		return fmt.Errorf("%w: %q", ErrUpgradeUnsupported, p.name)
+	}
+
+	p.reloadMu.Lock()
+	defer p.reloadMu.Unlock()
+
+	previous := p.current().previous
+	if previous == nil {
+		return fmt.Errorf("%w: %q", ErrNoRollback, p.name)
+	}
+
+	return p.reload(ctx, previous, nil)
+}
+
+// current returns the binary that processes of the plugin are started from.
+func (p *Plugin) current() *binary {
+	if b := p.binary.Load(); b != nil {
+		return b
+	}
+
+	return &binary{path: p.path}
+}
+
+func checkUpgrade(name string, current, next plugin.Info) error {
+	from, err := semver.NewVersion(current.Version)
+	if err != nil {
+		return fmt.Errorf("%w: plugin %q has version %q: %w", ErrIncompatibleVersion, name, current.Version, err)
+	}
+
+	to, err := semver.NewVersion(next.Version)
+	if err != nil {
+		return fmt.Errorf("%w: plugin %q has version %q: %w", ErrIncompatibleVersion, name, next.Version, err)
+	}
+
+	if !to.GreaterThan(from) {
+		return fmt.Errorf("%w: plugin %q version %q is not newer than %q", ErrIncompatibleUpgrade, name, next.Version, current.Version)
+	}
+
+	for _, command := range current.Commands {
+		index := slices.IndexFunc(next.Commands, func(c plugin.Command) bool {
+			return c.Name == command.Name
+		})
+
+		if index < 0 {
+			return fmt.Errorf("%w: plugin %q version %q does not provide command %q", ErrIncompatibleUpgrade, name, next.Version, command.Name)
+		}
+
+		upgraded := next.Commands[index]
+		if !sameMessage(command.Input, upgraded.Input) || !sameMessage(command.Output, upgraded.Output) {
+			return fmt.Errorf("%w: plugin %q version %q changes the messages of command %q", ErrIncompatibleUpgrade, name, next.Version, command.Name)
+		}
+	}
+
+	return nil
+}
+
+// sameMessage returns true if the message descriptors describe the same message, or either is unknown.
+func sameMessage(a, b protoreflect.MessageDescriptor) bool {
+	return a == nil || b == nil || a.FullName() == b.FullName()
+}
+
+// Upgrade the named plugin, as described by Plugin.Upgrade. Returns ErrNoPlugin if no plugin with the given name has
+// been added to the Manager, and ErrUpgradeUnsupported if the plugin cannot be upgraded.
+func (m *Manager) Upgrade(ctx context.Context, name string, source Source) error {
+	u, err := m.upgrader(name)
+	if err != nil {
+		return err
+	}
+
+	return u.Upgrade(ctx, source)
+}
+
+// Rollback the named plugin to its previous binary, as described by Plugin.Rollback. Returns ErrNoPlugin if no plugin
+// with the given name has been added to the Manager, and ErrUpgradeUnsupported if the plugin cannot be upgraded.
+func (m *Manager) Rollback(ctx context.Context, name string) error {
+	u, err := m.upgrader(name)
+	if err != nil {
+		return err
+	}
+
+	return u.Rollback(ctx)
+}
+
+func (m *Manager) upgrader(name string) (upgrader, error) {
+	p, ok := m.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: no plugin named %q", ErrNoPlugin, name)
+	}
+
+	u, ok := p.(upgrader)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUpgradeUnsupported, name)
+	}
+
+	return u, nil
+}
@@ -0,0 +1,159 @@
+package plugin_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestManager_Upgrade(t *testing.T) {
+	t.Parallel()
+
+	binary, err := filepath.Abs("./test_plugin")
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(binary)
+	require.NoError(t, err)
+	sum := sha256.Sum256(contents)
+
+	// version returns a Source for a script that starts the test plugin reporting the given version.
+	version := func(t *testing.T, version string) plugin.Source {
+		return plugin.SourceFunc(func(ctx context.Context) (string, error) {
+			path := filepath.Join(t.TempDir(), "test_plugin")
+			script := fmt.Sprintf("#!/bin/sh\nTEST_PLUGIN_VERSION=%s exec %s \"$@\"\n", version, binary)
+			return path, os.WriteFile(path, []byte(script), 0o755)
+		})
+	}
+
+	pid := func(t *testing.T, p *plugin.Plugin) int64 {
+		output := &wrapperspb.Int64Value{}
+		require.NoError(t, p.Exec(t.Context(), "pid", &emptypb.Empty{}, output))
+		return output.GetValue()
+	}
+
+	setup := func(t *testing.T, opts ...plugin.UseOption) (*plugin.Manager, *plugin.Plugin) {
+		p, err := plugin.Use(t.Context(), "./test_plugin", opts...)
+		require.NoError(t, err)
+
+		manager := plugin.NewManager()
+		require.NoError(t, manager.Add(p))
+		t.Cleanup(func() {
+			assert.NoError(t, manager.Close())
+		})
+
+		return manager, p
+	}
+
+	t.Run("upgrades and rolls back the plugin", func(t *testing.T) {
+		t.Parallel()
+
+		// The checksum only applies to the original binary.
+		manager, p := setup(t, plugin.WithChecksum(hex.EncodeToString(sum[:])))
+		before := pid(t, p)
+
+		require.NoError(t, manager.Upgrade(t.Context(), "test_plugin", version(t, "v1.3.0")))
+		assert.EqualValues(t, "v1.3.0", p.Version())
+		assert.NotEqual(t, before, pid(t, p))
+
+		require.NoError(t, manager.Rollback(t.Context(), "test_plugin"))
+		assert.EqualValues(t, "v1.2.3", p.Version())
+
+		assert.ErrorIs(t, manager.Rollback(t.Context(), "test_plugin"), plugin.ErrNoRollback)
+	})
+
+	t.Run("upgraded binaries are used when the plugin restarts", func(t *testing.T) {
+		t.Parallel()
+
+		manager, p := setup(t)
+		require.NoError(t, manager.Upgrade(t.Context(), "test_plugin", version(t, "v1.3.0")))
+
+		err := p.Exec(t.Context(), "crash", &emptypb.Empty{}, &emptypb.Empty{})
+		var crash *plugin.CrashError
+		require.ErrorAs(t, err, &crash)
+
+		require.NoError(t, p.Start(t.Context()))
+		assert.EqualValues(t, "v1.3.0", p.Version())
+	})
+
+	tt := []struct {
+		Name   string
+		Source func(t *testing.T) plugin.Source
+		Err    error
+	}{
+		{
+			Name: "error for versions that are not newer",
+			Source: func(t *testing.T) plugin.Source {
+				return version(t, "v1.2.3")
+			},
+			Err: plugin.ErrIncompatibleUpgrade,
+		},
+		{
+			Name: "error for invalid versions",
+			Source: func(t *testing.T) plugin.Source {
+				return version(t, "latest")
+			},
+			Err: plugin.ErrIncompatibleVersion,
+		},
+		{
+			Name: "error for binaries that fail to start",
+			Source: func(t *testing.T) plugin.Source {
+				return plugin.SourceFunc(func(ctx context.Context) (string, error) {
+					return filepath.Join(t.TempDir(), "missing"), nil
+				})
+			},
+			Err: os.ErrNotExist,
+		},
+		{
+			Name: "error from the source",
+			Source: func(t *testing.T) plugin.Source {
+				return plugin.SourceFunc(func(ctx context.Context) (string, error) {
+					return "", os.ErrPermission
+				})
+			},
+			Err: os.ErrPermission,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+
+			manager, p := setup(t)
+			before := pid(t, p)
+
+			// A failed upgrade leaves the running plugin in place.
+			err := manager.Upgrade(t.Context(), "test_plugin", tc.Source(t))
+			require.ErrorIs(t, err, tc.Err)
+			assert.EqualValues(t, "v1.2.3", p.Version())
+			assert.EqualValues(t, before, pid(t, p))
+			assert.ErrorIs(t, manager.Rollback(t.Context(), "test_plugin"), plugin.ErrNoRollback)
+		})
+	}
+
+	t.Run("error for unknown plugins", func(t *testing.T) {
+		t.Parallel()
+
+		err := plugin.NewManager().Upgrade(t.Context(), "unknown", version(t, "v1.3.0"))
+		assert.ErrorIs(t, err, plugin.ErrNoPlugin)
+	})
+
+	t.Run("error for plugins without a binary", func(t *testing.T) {
+		t.Parallel()
+
+		p := plugintest.New(t, plugin.Config{Name: "in-process"})
+		err := p.Upgrade(t.Context(), version(t, "v1.3.0"))
+		assert.ErrorIs(t, err, plugin.ErrUpgradeUnsupported)
+	})
+}
@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// The ResourceUsage type describes the resources consumed by a running plugin process. It can be used by host
+	// applications to detect plugins that are using more memory or CPU than expected.
+	ResourceUsage struct {
+		// The number of bytes of allocated heap objects.
+		HeapBytes uint64
+		// The total number of bytes of memory obtained from the operating system.
+		SysBytes uint64
+		// The number of goroutines that currently exist within the plugin.
+		Goroutines int
+		// The total time the plugin has spent executing in user mode.
+		UserCPUTime time.Duration
+		// The total time the plugin has spent executing in kernel mode.
+		SystemCPUTime time.Duration
+	}
+)
+
+// Usage queries the plugin for the resources it is currently consuming. When the plugin is running more than one
+// instance, as set using WithInstances, the usage of each instance is summed.
+func (p *Plugin) Usage(ctx context.Context) (ResourceUsage, error) {
+	release := p.hold()
+	defer release()
+
+	pl, err := p.start(ctx)
+	if err != nil {
+		return ResourceUsage{}, err
+	}
+
+	var total ResourceUsage
+	for _, proc := range pl.processes {
+		usage, err := proc.client.Usage(ctx)
+		if err != nil {
+			return ResourceUsage{}, err
+		}
+
+		total.HeapBytes += usage.HeapBytes
+		total.SysBytes += usage.SysBytes
+		total.Goroutines += usage.Goroutines
+		total.UserCPUTime += usage.UserCPUTime
+		total.SystemCPUTime += usage.SystemCPUTime
+	}
+
+	return total, nil
+}
@@ -0,0 +1,42 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+
+	"buf.build/go/protovalidate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+)
+
+// validateInput checks the input to the named command against any protovalidate constraints declared by its message
+// type. When the input violates a constraint, an *Error using the codes.InvalidArgument code is returned, with an
+// *errdetails.BadRequest detail containing a field violation for each broken constraint. Messages that declare no
+// constraints are always valid.
+func validateInput(name string, input proto.Message) error {
+	err := protovalidate.Validate(input)
+
+	var invalid *protovalidate.ValidationError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &invalid):
+		request := &errdetails.BadRequest{}
+		for _, violation := range invalid.Violations {
+			request.FieldViolations = append(request.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       protovalidate.FieldPathString(violation.Proto.GetField()),
+				Description: violation.Proto.GetMessage(),
+				Reason:      violation.Proto.GetRuleId(),
+			})
+		}
+
+		return &Error{
+			Code:    codes.InvalidArgument,
+			Message: fmt.Sprintf("invalid input for command %q: %v", name, invalid),
+			Details: []proto.Message{request},
+		}
+	default:
+		return fmt.Errorf("failed to validate input for command %q: %w", name, err)
+	}
+}
@@ -0,0 +1,95 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+	"github.com/davidsbond/plugin/testdata/validation"
+)
+
+func TestCommand_Validation(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	p := plugintest.New(t, plugin.Config{
+		Name: "validation",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*validation.Greeting, *wrapperspb.StringValue]{
+				Use: "greet",
+				Run: func(ctx context.Context, input *validation.Greeting) (*wrapperspb.StringValue, error) {
+					calls.Add(1)
+					return wrapperspb.String("hello " + input.GetName()), nil
+				},
+			},
+		},
+	})
+
+	tt := []struct {
+		Name       string
+		Input      *validation.Greeting
+		Expected   string
+		Violations map[string]string
+	}{
+		{
+			Name:     "valid input",
+			Input:    &validation.Greeting{Name: "alice", Count: 1},
+			Expected: "hello alice",
+		},
+		{
+			Name:  "missing name",
+			Input: &validation.Greeting{Count: 1},
+			Violations: map[string]string{
+				"name": "string.min_len",
+			},
+		},
+		{
+			Name:  "multiple violations",
+			Input: &validation.Greeting{Count: 11},
+			Violations: map[string]string{
+				"name":  "string.min_len",
+				"count": "uint32.lte",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			before := calls.Load()
+
+			output := &wrapperspb.StringValue{}
+			err := p.Exec(t.Context(), "greet", tc.Input, output)
+			if len(tc.Violations) == 0 {
+				require.NoError(t, err)
+				assert.EqualValues(t, tc.Expected, output.GetValue())
+				return
+			}
+
+			var pluginErr *plugin.Error
+			require.True(t, errors.As(err, &pluginErr))
+			assert.EqualValues(t, codes.InvalidArgument, pluginErr.Code)
+			assert.EqualValues(t, before, calls.Load())
+			require.Len(t, pluginErr.Details, 1)
+
+			detail, ok := pluginErr.Details[0].(*errdetails.BadRequest)
+			require.True(t, ok)
+
+			violations := make(map[string]string)
+			for _, violation := range detail.GetFieldViolations() {
+				assert.NotEmpty(t, violation.GetDescription())
+				violations[violation.GetField()] = violation.GetReason()
+			}
+
+			assert.EqualValues(t, tc.Violations, violations)
+		})
+	}
+}
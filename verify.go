@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+)
+
+type (
+	// The Verifier interface describes types that verify a plugin binary before it is started by Use. Host
+	// applications can implement this interface to support their own signing schemes, such as sigstore or minisign,
+	// or use the Ed25519Verifier type.
+	Verifier interface {
+		// Verify the plugin binary at the given path. Returning a non-nil error prevents the plugin from starting.
+		Verify(ctx context.Context, path string) error
+	}
+
+	// The VerifierFunc type is an adapter that allows ordinary functions to be used as a Verifier.
+	VerifierFunc func(ctx context.Context, path string) error
+
+	// The Ed25519Verifier type is a Verifier that checks a detached Ed25519 signature of the plugin binary against a
+	// set of trusted public keys. The signature is read from a file alongside the binary, named using the binary's
+	// path and the SignatureSuffix.
+	Ed25519Verifier struct {
+		// The public keys of trusted signers. The binary must be signed by at least one of them.
+		PublicKeys []ed25519.PublicKey
+		// The suffix appended to the path of the binary to locate its signature. Defaults to ".sig".
+		SignatureSuffix string
+	}
+)
+
+// ErrUntrustedSignature is the error returned by the Ed25519Verifier when a plugin binary has not been signed by any
+// of the trusted public keys.
+var ErrUntrustedSignature = errors.New("untrusted signature")
+
+// Verify calls f(ctx, path).
+func (f VerifierFunc) Verify(ctx context.Context, path string) error {
+	return f(ctx, path)
+}
+
+// Verify that the plugin binary at the given path has been signed by one of the trusted public keys. Returns
+// ErrUntrustedSignature if it has not.
+func (v Ed25519Verifier) Verify(_ context.Context, path string) error {
+	suffix := v.SignatureSuffix
+	if suffix == "" {
+		suffix = ".sig"
+	}
+
+	signature, err := os.ReadFile(path + suffix)
+	if err != nil {
+		return fmt.Errorf("failed to read signature for %q: %w", path, err)
+	}
+
+	binary, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %q: %w", path, err)
+	}
+
+	for _, key := range v.PublicKeys {
+		if len(key) == ed25519.PublicKeySize && ed25519.Verify(key, binary, signature) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: %q is not signed by a trusted key", ErrUntrustedSignature, path)
+}
+
+// WithVerifier is a UseOption that adds a Verifier used to check the plugin binary before it is started. This option
+// can be provided many times, in which case each Verifier must succeed, in the order given.
+func WithVerifier(verifier Verifier) UseOption {
+	return func(o *useOptions) {
+		o.verifiers = append(o.verifiers, verifier)
+	}
+}
+
+func verify(ctx context.Context, path string, options useOptions) error {
+	if err := verifyChecksum(path, options); err != nil {
+		return err
+	}
+
+	for _, verifier := range options.verifiers {
+		if err := verifier.Verify(ctx, path); err != nil {
+			return fmt.Errorf("failed to verify plugin at %q: %w", path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package plugin_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestUse_WithVerifier(t *testing.T) {
+	trusted, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	untrusted, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	path := copyPlugin(t, "test_plugin")
+	binary, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path+".sig", ed25519.Sign(private, binary), 0o644))
+
+	errRejected := errors.New("rejected")
+
+	tt := []struct {
+		Name        string
+		Verifier    plugin.Verifier
+		ExpectError error
+	}{
+		{
+			Name:     "starts when signed by a trusted key",
+			Verifier: plugin.Ed25519Verifier{PublicKeys: []ed25519.PublicKey{untrusted, trusted}},
+		},
+		{
+			Name:        "fails when not signed by a trusted key",
+			Verifier:    plugin.Ed25519Verifier{PublicKeys: []ed25519.PublicKey{untrusted}},
+			ExpectError: plugin.ErrUntrustedSignature,
+		},
+		{
+			Name:        "fails when the signature cannot be found",
+			Verifier:    plugin.Ed25519Verifier{PublicKeys: []ed25519.PublicKey{trusted}, SignatureSuffix: ".minisig"},
+			ExpectError: os.ErrNotExist,
+		},
+		{
+			Name: "fails when a custom verifier rejects the plugin",
+			Verifier: plugin.VerifierFunc(func(ctx context.Context, p string) error {
+				assert.EqualValues(t, path, p)
+				return errRejected
+			}),
+			ExpectError: errRejected,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			p, err := plugin.Use(t.Context(), path, plugin.WithVerifier(tc.Verifier))
+			if tc.ExpectError != nil {
+				assert.ErrorIs(t, err, tc.ExpectError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.NoError(t, p.Close())
+		})
+	}
+}
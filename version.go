@@ -0,0 +1,141 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+// ErrIncompatibleVersion is the error returned by Use when the version reported by a plugin does not satisfy the
+// constraint provided via WithVersionConstraint, or cannot be parsed as a semantic version.
+var ErrIncompatibleVersion = errors.New("incompatible plugin version")
+
+// WithVersionConstraint is a UseOption that requires the version reported by the plugin to satisfy the provided
+// semantic version constraint, such as ">=1.2.0, <2.0.0". Use returns ErrIncompatibleVersion if it does not. Note
+// that pre-release versions only satisfy constraints that themselves contain a pre-release.
+func WithVersionConstraint(constraint string) UseOption {
+	return func(o *useOptions) {
+		o.versionConstraint = constraint
+	}
+}
+
+func checkVersion(name, version, constraint string) error {
+	if constraint == "" {
+		return nil
+	}
+
+	constraints, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+	}
+
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return fmt.Errorf("%w: plugin %q has version %q: %w", ErrIncompatibleVersion, name, version, err)
+	}
+
+	if !constraints.Check(v) {
+		return fmt.Errorf("%w: plugin %q has version %q, expected %q", ErrIncompatibleVersion, name, version, constraint)
+	}
+
+	return nil
+}
+
+// The Version type describes a semantic version, such as the version reported by a plugin. Versions should be
+// compared using Version.Compare rather than by their string representations.
+type Version struct {
+	// The major, minor and patch components of the version.
+	Major, Minor, Patch uint64
+	// The pre-release identifiers of the version, such as "rc.1", if any.
+	Prerelease string
+	// The build metadata of the version, such as "20240101120000-0123456789ab", if any.
+	Metadata string
+}
+
+// ParseVersion parses a semantic version, such as "v1.2.3" or "1.2.3-rc.1". The leading "v" is optional.
+func ParseVersion(version string) (Version, error) {
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return Version{}, fmt.Errorf("invalid version %q: %w", version, err)
+	}
+
+	return Version{
+		Major:      v.Major(),
+		Minor:      v.Minor(),
+		Patch:      v.Patch(),
+		Prerelease: v.Prerelease(),
+		Metadata:   v.Metadata(),
+	}, nil
+}
+
+// Compare returns -1 if the version is lower than other, 1 if it is greater, and 0 if they are equal. Pre-release
+// versions are lower than their associated release, and build metadata is ignored.
+func (v Version) Compare(other Version) int {
+	return v.semver().Compare(other.semver())
+}
+
+// String returns the version in its canonical form, without a leading "v", such as "1.2.3-rc.1".
+func (v Version) String() string {
+	return v.semver().String()
+}
+
+func (v Version) semver() *semver.Version {
+	return semver.New(v.Major, v.Minor, v.Patch, v.Prerelease, v.Metadata)
+}
+
+// SemanticVersion returns the version of the plugin parsed as a semantic version. It returns an error if the plugin
+// does not report a valid semantic version, or has not yet been started when using the WithLazyStart option.
+func (p *Plugin) SemanticVersion() (Version, error) {
+	return ParseVersion(p.Version())
+}
+
+// VersionAtLeast returns true if the version of the plugin is equal to, or greater than, the provided minimum
+// version, such as "1.4.0". It returns false if either version is not a valid semantic version.
+func (p *Plugin) VersionAtLeast(minimum string) bool {
+	return versionAtLeast(p.Version(), minimum)
+}
+
+func versionAtLeast(version, minimum string) bool {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false
+	}
+
+	m, err := ParseVersion(minimum)
+	if err != nil {
+		return false
+	}
+
+	return v.Compare(m) >= 0
+}
+
+// The PluginFilter type is a function that selects the plugins returned by Manager.Plugins.
+type PluginFilter func(p Client) bool
+
+// PluginsWithVersion is a PluginFilter that selects plugins whose version satisfies the provided semantic version
+// constraint, such as ">=1.2.0, <2.0.0". Plugins that do not report a valid semantic version are not selected, nor
+// are any plugins if the constraint is invalid.
+func PluginsWithVersion(constraint string) PluginFilter {
+	constraints, err := semver.NewConstraint(constraint)
+	return func(p Client) bool {
+		if err != nil {
+			return false
+		}
+
+		v, err := semver.NewVersion(p.Version())
+		if err != nil {
+			return false
+		}
+
+		return constraints.Check(v)
+	}
+}
+
+// PluginsAtLeast is a PluginFilter that selects plugins whose version is equal to, or greater than, the provided
+// minimum version, such as "1.4.0". Plugins that do not report a valid semantic version are not selected.
+func PluginsAtLeast(minimum string) PluginFilter {
+	return func(p Client) bool {
+		return versionAtLeast(p.Version(), minimum)
+	}
+}
@@ -0,0 +1,156 @@
+package plugin_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestParseVersion(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Name     string
+		Version  string
+		Expected plugin.Version
+		Error    bool
+	}{
+		{
+			Name:     "release version",
+			Version:  "v1.2.3",
+			Expected: plugin.Version{Major: 1, Minor: 2, Patch: 3},
+		},
+		{
+			Name:     "pre-release version with metadata",
+			Version:  "1.10.0-rc.1+build.5",
+			Expected: plugin.Version{Major: 1, Minor: 10, Prerelease: "rc.1", Metadata: "build.5"},
+		},
+		{
+			Name:    "invalid version",
+			Version: "(devel)",
+			Error:   true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			actual, err := plugin.ParseVersion(tc.Version)
+			if tc.Error {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.EqualValues(t, tc.Expected, actual)
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	t.Parallel()
+
+	tt := []struct {
+		Name     string
+		A        string
+		B        string
+		Expected int
+	}{
+		{Name: "equal versions", A: "1.2.3", B: "v1.2.3", Expected: 0},
+		{Name: "numeric rather than lexical ordering", A: "1.10.0", B: "1.9.0", Expected: 1},
+		{Name: "pre-release lower than release", A: "2.0.0-rc.1", B: "2.0.0", Expected: -1},
+		{Name: "metadata ignored", A: "1.0.0+a", B: "1.0.0+b", Expected: 0},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			a, err := plugin.ParseVersion(tc.A)
+			require.NoError(t, err)
+
+			b, err := plugin.ParseVersion(tc.B)
+			require.NoError(t, err)
+
+			assert.EqualValues(t, tc.Expected, a.Compare(b))
+		})
+	}
+}
+
+func TestPlugin_VersionAtLeast(t *testing.T) {
+	t.Parallel()
+
+	p := plugintest.New(t, plugin.Config{
+		Name:    "versioned",
+		Version: "v1.10.0",
+	})
+
+	version, err := p.SemanticVersion()
+	require.NoError(t, err)
+	assert.EqualValues(t, "1.10.0", version.String())
+
+	assert.True(t, p.VersionAtLeast("1.9.0"))
+	assert.True(t, p.VersionAtLeast("v1.10.0"))
+	assert.False(t, p.VersionAtLeast("1.10.1"))
+	assert.False(t, p.VersionAtLeast("invalid"))
+}
+
+func TestManager_Plugins_Version(t *testing.T) {
+	t.Parallel()
+
+	manager := plugin.NewManager()
+	require.NoError(t, manager.Add(plugintest.NewFake("old", "v1.2.0")))
+	require.NoError(t, manager.Add(plugintest.NewFake("new", "v1.10.0")))
+	require.NoError(t, manager.Add(plugintest.NewFake("next", "v2.0.0-rc.1")))
+	require.NoError(t, manager.Add(plugintest.NewFake("devel", "(devel)")))
+
+	names := func(plugins []plugin.Client) []string {
+		selected := make([]string, len(plugins))
+		for i, p := range plugins {
+			selected[i] = p.Name()
+		}
+
+		return selected
+	}
+
+	tt := []struct {
+		Name     string
+		Filters  []plugin.PluginFilter
+		Expected []string
+	}{
+		{
+			Name:     "no filters",
+			Expected: []string{"old", "new", "next", "devel"},
+		},
+		{
+			Name:     "at least a version",
+			Filters:  []plugin.PluginFilter{plugin.PluginsAtLeast("1.9.0")},
+			Expected: []string{"new", "next"},
+		},
+		{
+			Name:     "satisfying a constraint",
+			Filters:  []plugin.PluginFilter{plugin.PluginsWithVersion(">=1.0.0, <2.0.0")},
+			Expected: []string{"old", "new"},
+		},
+		{
+			Name:     "invalid constraint",
+			Filters:  []plugin.PluginFilter{plugin.PluginsWithVersion("not a constraint")},
+			Expected: []string{},
+		},
+		{
+			Name: "multiple filters",
+			Filters: []plugin.PluginFilter{
+				plugin.PluginsAtLeast("1.9.0"),
+				plugin.PluginsWithVersion("<2.0.0"),
+			},
+			Expected: []string{"new"},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			assert.EqualValues(t, tc.Expected, names(manager.Plugins(tc.Filters...)))
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/davidsbond/plugin/internal/plugin"
+)
+
+// AddWarning reports a non-fatal warning for the command being executed, such as when it has only partially
+// succeeded. The provided context must be that given to the command. Warnings are returned to the host application
+// alongside the command's output, where they are received using WithWarnings, and are discarded if the command fails
+// or is executed as a job using Plugin.Submit.
+func AddWarning(ctx context.Context, warning string) {
+	plugin.AddWarning(ctx, warning)
+}
+
+// WithWarnings is an ExecOption that calls the provided function with any warnings the command reported using
+// AddWarning, once it has succeeded. The function is not called if the command reported no warnings.
+func WithWarnings(fn func(warnings []string)) ExecOption {
+	return func(o *execOptions) {
+		o.warnings = fn
+	}
+}
@@ -0,0 +1,86 @@
+package plugin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+	"github.com/davidsbond/plugin/plugintest"
+)
+
+func TestWithWarnings(t *testing.T) {
+	t.Parallel()
+
+	config := plugin.Config{
+		Name: "warnings",
+		Commands: []plugin.CommandHandler{
+			&plugin.Command[*wrapperspb.StringValue, *wrapperspb.StringValue]{
+				Use: "import",
+				Run: func(ctx context.Context, input *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+					switch input.GetValue() {
+					case "partial":
+						plugin.AddWarning(ctx, "skipped 2 records")
+						plugin.AddWarning(ctx, "record 7 truncated")
+					case "fail":
+						plugin.AddWarning(ctx, "skipped 2 records")
+						return nil, plugin.NewError("import failed")
+					}
+
+					return input, nil
+				},
+			},
+		},
+	}
+
+	tt := []struct {
+		Name        string
+		Options     []plugin.UseOption
+		Input       string
+		ExpectError bool
+		Expected    []string
+	}{
+		{
+			Name:     "returns warnings",
+			Input:    "partial",
+			Expected: []string{"skipped 2 records", "record 7 truncated"},
+		},
+		{
+			Name:     "returns warnings when chunked",
+			Options:  []plugin.UseOption{plugin.WithChunking(4)},
+			Input:    "partial",
+			Expected: []string{"skipped 2 records", "record 7 truncated"},
+		},
+		{
+			Name:  "no warnings",
+			Input: "complete",
+		},
+		{
+			Name:        "discards warnings on failure",
+			Input:       "fail",
+			ExpectError: true,
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.Name, func(t *testing.T) {
+			p := plugintest.New(t, config, tc.Options...)
+
+			var warnings []string
+			err := p.Exec(t.Context(), "import", wrapperspb.String(tc.Input), &wrapperspb.StringValue{}, plugin.WithWarnings(func(w []string) {
+				warnings = w
+			}))
+
+			if tc.ExpectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.EqualValues(t, tc.Expected, warnings)
+		})
+	}
+}
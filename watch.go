@@ -0,0 +1,93 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+)
+
+// WithWatch is a UseOption intended for use during development, that reloads the plugin using Plugin.Reload whenever
+// its binary changes, so that plugin authors can rebuild their plugin without restarting the host application. The
+// binary is checked for changes to its size or modification time at the given interval, and is only reloaded once it
+// has stopped changing, so that a binary that is still being written is not started.
+//
+// The onReload function, if not nil, is called with the result of each reload. If a reload fails, the previous
+// processes continue to handle calls until the binary changes again. The option has no effect for plugins that are
+// not executed from a binary, such as those registered using Register or connected to using Connect.
+func WithWatch(interval time.Duration, onReload func(err error)) UseOption {
+	return func(o *useOptions) {
+		o.watchInterval = interval
+		o.onReload = onReload
+	}
+}
+
+// The binaryState type describes the state of a plugin binary used to detect changes to it.
+type binaryState struct {
+	size    int64
+	modTime time.Time
+}
+
+func statBinary(path string) (binaryState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return binaryState{}, err
+	}
+
+	return binaryState{size: info.Size(), modTime: info.ModTime()}, nil
+}
+
+// watch reloads the plugin whenever its binary changes from the loaded state, until the done channel is closed.
+func (p *Plugin) watch(loaded binaryState, done <-chan struct{}) {
+	previous := loaded
+	watched := p.current().path
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-p.options.clock.After(p.options.watchInterval):
+		}
+
+		// Upgrading the plugin changes the binary being watched, which is already running once the upgrade completes.
+		path := p.current().path
+		if path != watched {
+			watched = path
+			loaded, _ = statBinary(path)
+			previous = loaded
+			continue
+		}
+
+		current, err := statBinary(path)
+		if err != nil {
+			continue
+		}
+
+		// A reload only happens once the binary has been unchanged for a whole interval.
+		stable := current == previous
+		previous = current
+		if !stable || current == loaded {
+			continue
+		}
+
+		loaded = current
+
+		// Plugins that are not running use the new binary once they are next started.
+		p.mu.RLock()
+		running := p.pool != nil
+		p.mu.RUnlock()
+
+		if !running {
+			continue
+		}
+
+		err = p.Reload(context.Background())
+		if errors.Is(err, ErrClosed) {
+			return
+		}
+
+		if p.options.onReload != nil {
+			p.options.onReload(err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package plugin_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/davidsbond/plugin"
+)
+
+func TestUse_WithWatch(t *testing.T) {
+	t.Parallel()
+
+	binary, err := filepath.Abs("./test_plugin")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "test_plugin")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\nexec "+binary+" \"$@\"\n"), 0o755))
+
+	reloads := make(chan error, 1)
+	p, err := plugin.Use(t.Context(), path, plugin.WithWatch(10*time.Millisecond, func(err error) {
+		reloads <- err
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		assert.NoError(t, p.Close())
+	})
+
+	replace := func(t *testing.T, script string) {
+		replacement := filepath.Join(filepath.Dir(path), "replacement")
+		require.NoError(t, os.WriteFile(replacement, []byte(script), 0o755))
+		require.NoError(t, os.Rename(replacement, path))
+	}
+
+	pingpong := func(t *testing.T) string {
+		output := &wrapperspb.StringValue{}
+		require.NoError(t, p.Exec(t.Context(), "pingpong", wrapperspb.String("ping"), output))
+		return output.GetValue()
+	}
+
+	t.Run("reloads the plugin when the binary changes", func(t *testing.T) {
+		replace(t, "#!/bin/sh\nexec "+binary+" \"$@\" --pong reloaded\n")
+
+		select {
+		case err := <-reloads:
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "plugin was not reloaded")
+		}
+
+		assert.EqualValues(t, "reloaded", pingpong(t))
+	})
+
+	t.Run("keeps running if the changed binary fails to start", func(t *testing.T) {
+		replace(t, "#!/bin/sh\nexit 1\n")
+
+		select {
+		case err := <-reloads:
+			require.ErrorIs(t, err, plugin.ErrPluginStartFailed)
+		case <-time.After(5 * time.Second):
+			require.Fail(t, "plugin was not reloaded")
+		}
+
+		assert.EqualValues(t, "reloaded", pingpong(t))
+	})
+}